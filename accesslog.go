@@ -0,0 +1,169 @@
+package slogflags
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AccessLogFormat determines how requests are recorded by
+// [AccessLogMiddleware].
+type AccessLogFormat string
+
+const (
+	// AccessLogStructured logs a record with attributes describing the
+	// request, encoded according to the `log.format` flag.
+	AccessLogStructured AccessLogFormat = "structured"
+
+	// AccessLogCommon logs requests in the Apache Common Log Format.
+	AccessLogCommon AccessLogFormat = "common"
+
+	// AccessLogCombined logs requests in the Apache Combined Log Format,
+	// which adds the referer and user agent to the Common Log Format.
+	AccessLogCombined AccessLogFormat = "combined"
+)
+
+// clfTimeFormat is the format of timestamps in the Common Log Format.
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogConfig configures [AccessLogMiddleware].
+type AccessLogConfig struct {
+	// Logger is used to log requests. Defaults to [L].
+	Logger *slog.Logger
+
+	// Level is the level requests are logged at. Defaults to
+	// [log/slog.LevelInfo].
+	Level slog.Level
+
+	// Format determines how requests are logged. Defaults to the value of
+	// the `log.access-format` flag.
+	Format AccessLogFormat
+
+	// Writer, if set, receives a line for each request when Format is
+	// [AccessLogCommon] or [AccessLogCombined], instead of the line being
+	// logged as the message of a record. This can be used to write a
+	// traditional access log file for legacy tooling.
+	Writer io.Writer
+}
+
+// AccessLogMiddleware wraps an HTTP handler so that a record is logged for
+// each request once it has been handled. Structured records have the message
+// "Request handled" and the attributes "method", "path", "status", "bytes",
+// "duration", "user_agent" and "remote_addr".
+func AccessLogMiddleware(config AccessLogConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &accessLogWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		logger := config.Logger
+		if logger == nil {
+			logger = L()
+		}
+
+		format := config.Format
+		if format == "" {
+			configMu.Lock()
+			format = AccessLogFormat(*logAccessFormat)
+			configMu.Unlock()
+		}
+
+		switch format {
+		case AccessLogCommon, AccessLogCombined:
+			line := formatAccessLogLine(r, rw.status, rw.bytes, start, format == AccessLogCombined)
+			if config.Writer != nil {
+				_, _ = io.WriteString(config.Writer, line+"\n")
+			} else {
+				logger.Log(r.Context(), config.Level, line)
+			}
+		default:
+			logger.LogAttrs(r.Context(), config.Level, "Request handled",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rw.status),
+				slog.Int64("bytes", rw.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("user_agent", r.UserAgent()),
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+		}
+	})
+}
+
+// formatAccessLogLine formats a request in the Common or Combined Log Format.
+func formatAccessLogLine(r *http.Request, status int, bytes int64, start time.Time, combined bool) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = clfEscape(u)
+	}
+
+	size := "-"
+	if bytes > 0 {
+		size = fmt.Sprint(bytes)
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s",
+		clfValue(host), user, start.Format(clfTimeFormat),
+		clfEscape(r.Method), clfEscape(r.RequestURI), clfEscape(r.Proto), status, size)
+
+	if combined {
+		line += fmt.Sprintf(" \"%s\" \"%s\"", clfValue(clfEscape(r.Referer())), clfValue(clfEscape(r.UserAgent())))
+	}
+	return line
+}
+
+// clfValue returns "-" in place of empty values.
+func clfValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// clfEscapeReplacer escapes characters that would break the structure of an
+// access log line.
+var clfEscapeReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+
+// clfEscape escapes a value for inclusion in an access log line.
+func clfEscape(s string) string {
+	return clfEscapeReplacer.Replace(s)
+}
+
+// accessLogWriter records the status code and number of bytes written in a
+// response.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *accessLogWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap allows [net/http.ResponseController] to access the underlying
+// writer, for flushing and hijacking.
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,67 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func accessLogTestHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, "hello")
+	})
+}
+
+func accessLogTestRequest(path string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", `curl/8.0 "test"`)
+	req.Header.Set("Referer", "https://example.com/")
+	return req
+}
+
+func Test_AccessLogMiddleware_Structured(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	handler := AccessLogMiddleware(AccessLogConfig{Logger: l}, accessLogTestHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), accessLogTestRequest("/missing?x=1"))
+
+	assert.Regexp(t, `level=INFO msg="Request handled" method=GET path=/missing status=404 bytes=19 duration=\S+ user_agent="curl/8.0 \\"test\\"" remote_addr=192.0.2.1:1234\n$`, w.String())
+}
+
+func Test_AccessLogMiddleware_Apache(t *testing.T) {
+	_ = flag.Set("log.access-format", "combined")
+	defer flag.Set("log.access-format", "structured")
+
+	w := new(bytes.Buffer)
+	handler := AccessLogMiddleware(AccessLogConfig{Writer: w}, accessLogTestHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), accessLogTestRequest("/hello?x=1"))
+
+	assert.Regexp(t, `^192\.0\.2\.1 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /hello\?x=1 HTTP/1\.1" 200 5 "https://example.com/" "curl/8\.0 \\"test\\""\n$`, w.String())
+}
+
+func Test_FormatAccessLogLine(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/apache_pb.gif", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Proto = "HTTP/1.0"
+	req.SetBasicAuth("frank", "secret")
+	start := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+
+	assert.Equal(t, `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "POST /apache_pb.gif HTTP/1.0" 200 2326`,
+		formatAccessLogLine(req, 200, 2326, start, false))
+	assert.Equal(t, `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "POST /apache_pb.gif HTTP/1.0" 304 - "-" "-"`,
+		formatAccessLogLine(req, 304, 0, start, true))
+}
@@ -0,0 +1,205 @@
+package slogflags
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// discordMaxContent is the maximum length of a Discord message.
+const discordMaxContent = 2000
+
+// defaultAlertTemplate is used to render alerts if no template is configured.
+var defaultAlertTemplate = template.Must(template.New("alert").Parse(
+	"*{{.Level}}*: {{.Message}}{{range $k, $v := .Attrs}}\n• {{$k}}: {{$v}}{{end}}",
+))
+
+// AlertService identifies the chat service an [Alerter] sends messages to.
+type AlertService int
+
+const (
+	// AlertServiceAuto detects the service based on the webhook URL.
+	AlertServiceAuto AlertService = iota
+	// AlertServiceSlack sends messages to a Slack incoming webhook.
+	AlertServiceSlack
+	// AlertServiceDiscord sends messages to a Discord webhook.
+	AlertServiceDiscord
+)
+
+// AlertData is the data passed to the template used to render alerts.
+type AlertData struct {
+	Time    time.Time
+	Level   string
+	Message string
+
+	// Attrs contains the record's attributes. Groups are represented as
+	// nested maps.
+	Attrs map[string]any
+}
+
+// AlertConfig configures an [Alerter].
+type AlertConfig struct {
+	// URL is the Slack or Discord webhook URL.
+	URL string
+
+	// Service is the chat service the webhook belongs to. Defaults to
+	// detecting it from the URL.
+	Service AlertService
+
+	// Level is the minimum level of records that trigger alerts. Defaults to
+	// [log/slog.LevelError].
+	Level slog.Leveler
+
+	// Template is used to render the text of each alert, and is executed
+	// with an [AlertData]. Defaults to the level and message, followed by
+	// each attribute on a separate line.
+	Template *template.Template
+
+	// Limit is the maximum number of alerts sent in each Interval. Alerts
+	// over the limit are dropped, and a count of them is added to the next
+	// alert that is sent. Defaults to 10.
+	Limit int
+
+	// Interval is the period the Limit applies to. Defaults to one minute.
+	Interval time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// Alerter sends records to a Slack or Discord webhook as chat messages. It is
+// used with the [WithAlerter] option, and must be closed to ensure all alerts
+// are sent.
+type Alerter struct {
+	config  AlertConfig
+	batcher *batcher[string]
+
+	mu          sync.Mutex
+	windowStart time.Time
+	sent        int
+	suppressed  int
+}
+
+// NewAlerter creates a new [Alerter] with the given config.
+func NewAlerter(config AlertConfig) (*Alerter, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("alerter: invalid webhook URL %q", config.URL)
+	}
+
+	if config.Service == AlertServiceAuto {
+		if strings.HasSuffix(u.Hostname(), "discord.com") || strings.HasSuffix(u.Hostname(), "discordapp.com") {
+			config.Service = AlertServiceDiscord
+		} else {
+			config.Service = AlertServiceSlack
+		}
+	}
+	if config.Level == nil {
+		config.Level = slog.LevelError
+	}
+	if config.Template == nil {
+		config.Template = defaultAlertTemplate
+	}
+	if config.Limit <= 0 {
+		config.Limit = 10
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+
+	a := &Alerter{config: config}
+	a.batcher = newBatcher(config.Limit, time.Second, a.send)
+	return a, nil
+}
+
+// Close sends any pending alerts.
+func (a *Alerter) Close() error {
+	return a.batcher.close()
+}
+
+// alert renders the record and queues it to be sent, if the rate limit
+// allows. Levels are named using names.
+func (a *Alerter) alert(r slog.Record, names levelNames) error {
+	a.mu.Lock()
+	now := time.Now()
+	if now.Sub(a.windowStart) >= a.config.Interval {
+		a.windowStart = now
+		a.sent = 0
+	}
+	if a.sent >= a.config.Limit {
+		a.suppressed++
+		a.mu.Unlock()
+		return nil
+	}
+	a.sent++
+	suppressed := a.suppressed
+	a.suppressed = 0
+	a.mu.Unlock()
+
+	text := new(strings.Builder)
+	err := a.config.Template.Execute(text, AlertData{
+		Time:    r.Time,
+		Level:   names.name(r.Level),
+		Message: r.Message,
+		Attrs:   recordAttrs(r),
+	})
+	if err != nil {
+		return fmt.Errorf("alerter: unable to render alert: %w", err)
+	}
+
+	if suppressed > 0 {
+		_, _ = fmt.Fprintf(text, "\n_(%d earlier alerts were suppressed)_", suppressed)
+	}
+
+	return a.batcher.add(text.String())
+}
+
+func (a *Alerter) send(messages []string) error {
+	var errs []error
+	for _, m := range messages {
+		if err := a.post(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *Alerter) post(message string) error {
+	var payload map[string]string
+	if a.config.Service == AlertServiceDiscord {
+		if runes := []rune(message); len(runes) > discordMaxContent {
+			message = string(runes[:discordMaxContent-1]) + "…"
+		}
+		payload = map[string]string{"content": message}
+	} else {
+		payload = map[string]string{"text": message}
+	}
+
+	body, _ := json.Marshal(payload)
+	res, err := a.config.Client.Post(a.config.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("alerter: request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("alerter: request returned status %d: %s", res.StatusCode, b)
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
@@ -0,0 +1,119 @@
+package slogflags
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChatWebhook struct {
+	mu       sync.Mutex
+	payloads []map[string]string
+}
+
+func (f *fakeChatWebhook) ServeHTTP(_ http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var payload map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&payload)
+	f.payloads = append(f.payloads, payload)
+}
+
+func Test_Alerter_SendsSlackMessages(t *testing.T) {
+	hook := &fakeChatWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	a, err := NewAlerter(AlertConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	l := LoggerForTest(io.Discard, WithAlerter(a))
+	l.Warn("Ignored")
+	l.With("user", "bob").Error("Failed", "count", 3)
+	require.NoError(t, a.Close())
+
+	assert.Equal(t, []map[string]string{{"text": "*ERROR*: Failed\n• count: 3\n• user: bob"}}, hook.payloads)
+}
+
+func Test_Alerter_UsesCustomLevelNames(t *testing.T) {
+	hook := &fakeChatWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	a, err := NewAlerter(AlertConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	l := LoggerForTest(io.Discard, WithAlerter(a), WithCustomLevels(map[string]slog.Level{"critical": slog.LevelError + 2}))
+	l.Log(t.Context(), slog.LevelError+2, "Disk full")
+	l.Log(t.Context(), LevelFatal, "Exiting")
+	require.NoError(t, a.Close())
+
+	assert.Equal(t, []map[string]string{
+		{"text": "*CRITICAL*: Disk full"},
+		{"text": "*FATAL*: Exiting"},
+	}, hook.payloads)
+}
+
+func Test_Alerter_SendsDiscordMessagesWithTemplate(t *testing.T) {
+	hook := &fakeChatWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	a, err := NewAlerter(AlertConfig{
+		URL:      server.URL,
+		Service:  AlertServiceDiscord,
+		Level:    slog.LevelWarn,
+		Template: template.Must(template.New("").Parse("{{.Message}} ({{.Attrs.code}})")),
+	})
+	require.NoError(t, err)
+
+	l := LoggerForTest(io.Discard, WithAlerter(a))
+	l.Warn("Slow", "code", 42)
+	l.Error(strings.Repeat("x", 3000))
+	require.NoError(t, a.Close())
+
+	require.Len(t, hook.payloads, 2)
+	assert.Equal(t, map[string]string{"content": "Slow (42)"}, hook.payloads[0])
+	assert.Len(t, []rune(hook.payloads[1]["content"]), discordMaxContent)
+}
+
+func Test_Alerter_RateLimits(t *testing.T) {
+	hook := &fakeChatWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	a, err := NewAlerter(AlertConfig{URL: server.URL, Limit: 2, Template: template.Must(template.New("").Parse("{{.Message}}"))})
+	require.NoError(t, err)
+
+	l := LoggerForTest(io.Discard, WithAlerter(a))
+	for i := 0; i < 5; i++ {
+		l.Error("Failed")
+	}
+
+	a.mu.Lock()
+	a.windowStart = a.windowStart.Add(-a.config.Interval)
+	a.mu.Unlock()
+	l.Error("Again")
+	require.NoError(t, a.Close())
+
+	require.Len(t, hook.payloads, 3)
+	assert.Equal(t, "Again\n_(3 earlier alerts were suppressed)_", hook.payloads[2]["text"])
+}
+
+func Test_NewAlerter_DetectsDiscord(t *testing.T) {
+	a, err := NewAlerter(AlertConfig{URL: "https://discord.com/api/webhooks/1/abc"})
+	require.NoError(t, err)
+	defer a.Close()
+
+	assert.Equal(t, AlertServiceDiscord, a.config.Service)
+}
@@ -0,0 +1,95 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+)
+
+// allowlistHandler is a [log/slog.Handler] that drops any attributes that
+// haven't been explicitly allowed. Keys are matched against the full path of
+// the attribute, with group names separated by dots.
+type allowlistHandler struct {
+	next    slog.Handler
+	allowed map[string]struct{}
+	metrics *Metrics
+	prefix  string
+}
+
+func newAllowlistHandler(next slog.Handler, keys []string, metrics *Metrics) *allowlistHandler {
+	h := &allowlistHandler{next: next, allowed: make(map[string]struct{}, len(keys)), metrics: metrics}
+	for _, k := range keys {
+		h.allowed[k] = struct{}{}
+	}
+	return h
+}
+
+func (h *allowlistHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *allowlistHandler) Handle(ctx context.Context, r slog.Record) error {
+	res := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if a, ok := h.filter(h.prefix, a); ok {
+			res.AddAttrs(a)
+		}
+		return true
+	})
+	return h.next.Handle(ctx, res)
+}
+
+func (h *allowlistHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var res []slog.Attr
+	for _, a := range attrs {
+		if a, ok := h.filter(h.prefix, a); ok {
+			res = append(res, a)
+		}
+	}
+	h2 := *h
+	h2.next = h.next.WithAttrs(res)
+	return &h2
+}
+
+func (h *allowlistHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// filter returns the attribute if its path is allowed. Groups that aren't
+// allowed themselves are filtered recursively, and are kept if any of their
+// attributes are allowed.
+func (h *allowlistHandler) filter(prefix string, a slog.Attr) (slog.Attr, bool) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return a, false
+	}
+
+	path := prefix + a.Key
+	if _, ok := h.allowed[path]; ok {
+		return a, true
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = path + "."
+		}
+		var res []slog.Attr
+		for _, ga := range a.Value.Group() {
+			if ga, ok := h.filter(prefix, ga); ok {
+				res = append(res, ga)
+			}
+		}
+		if len(res) > 0 {
+			a.Value = slog.GroupValue(res...)
+			return a, true
+		}
+		return a, false
+	}
+
+	if h.metrics != nil {
+		h.metrics.countDroppedAttr(path)
+	}
+	return a, false
+}
@@ -0,0 +1,66 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithAllowedKeys_DropsOtherAttrs(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithAllowedKeys("user", "status"))
+	l.With("user", "alice", "email", "alice@example.com").Info("Login", "status", 200, "password", "hunter2")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Login user=alice status=200\n", w.String())
+}
+
+func Test_WithAllowedKeys_Groups(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithAllowedKeys("http.method", "http.headers", "build"))
+	l.WithGroup("http").With("method", "GET", "path", "/secret").Info("Request",
+		slog.Group("headers", "accept", "*/*"),
+		slog.Group("client", "ip", "192.0.2.1"),
+	)
+	l.Info("Built", slog.Group("build", "version", "1.0"))
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Request http.method=GET http.headers.accept=*/*\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Built build.version=1.0\n", w.String())
+}
+
+func Test_WithAllowedKeys_Empty(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithAllowedKeys())
+	l.Info("Hello", "a", 1)
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Hello\n", w.String())
+}
+
+func Test_WithAllowedKeys_CountsDropped(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	metrics := NewMetrics()
+	l := LoggerForTest(new(bytes.Buffer), WithAllowedKeys("user"), WithMetrics(metrics))
+	l.Info("One", "user", "alice", "email", "alice@example.com")
+	l.Info("Two", slog.Group("req", "ip", "192.0.2.1"), "email", "bob@example.com")
+
+	out := new(bytes.Buffer)
+	require.NoError(t, metrics.WritePrometheus(out))
+	assert.Contains(t, out.String(), "# TYPE slogflags_attrs_dropped_total counter\n"+
+		`slogflags_attrs_dropped_total{key="email"} 2`+"\n"+
+		`slogflags_attrs_dropped_total{key="req.ip"} 1`+"\n")
+}
@@ -0,0 +1,239 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// DropPolicy determines what happens when a record is logged but a queue it
+// needs to be added to is full.
+type DropPolicy int
+
+const (
+	// DropPolicyBlock blocks the logging goroutine until there is space in
+	// the queue. No records are dropped.
+	DropPolicyBlock DropPolicy = iota
+
+	// DropPolicyNewest drops the record being logged, leaving the queue
+	// unchanged.
+	DropPolicyNewest
+
+	// DropPolicyOldest drops the oldest record in the queue to make room for
+	// the one being logged. Queued records at or above the never-drop level
+	// are kept, and the logging goroutine blocks if there are no others.
+	DropPolicyOldest
+)
+
+// asyncItem is a record waiting to be handled, along with the handler that
+// should handle it.
+type asyncItem struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+	goas    []groupOrAttrs
+}
+
+// asyncQueue is a bounded queue of records shared by an asyncHandler and all
+// handlers derived from it. Records are handled in order on a single
+// background goroutine.
+type asyncQueue struct {
+	size      int
+	policy    DropPolicy
+	neverDrop slog.Level
+	onError   func(err error, r slog.Record)
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []asyncItem
+	closed bool
+
+	// pending is the number of items that are queued or being handled.
+	pending int
+
+	done chan struct{}
+}
+
+func newAsyncQueue(size int, policy DropPolicy, neverDrop slog.Level, onError func(err error, r slog.Record)) *asyncQueue {
+	q := &asyncQueue{
+		size:      size,
+		policy:    policy,
+		neverDrop: neverDrop,
+		onError:   onError,
+		done:      make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	go q.run()
+	return q
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+		for len(q.items) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.items) == 0 {
+			q.mu.Unlock()
+			return
+		}
+
+		item := q.items[0]
+		q.items[0] = asyncItem{}
+		q.items = q.items[1:]
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		q.handle(item)
+
+		q.mu.Lock()
+		q.itemDone()
+		q.mu.Unlock()
+	}
+}
+
+// handle passes an item to its handler, reporting any error.
+func (q *asyncQueue) handle(item asyncItem) {
+	err := item.handler.Handle(item.ctx, item.record)
+	if err != nil && q.onError != nil {
+		q.onError(err, resolveRecord(item.record, item.goas))
+	}
+}
+
+// add queues an item according to the drop policy. Items at or above the
+// never-drop level are always queued, blocking if necessary, and are never
+// dropped to make room for others. Once the queue is closed, items are
+// handled immediately instead.
+func (q *asyncQueue) add(item asyncItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	policy := q.policy
+	if item.record.Level >= q.neverDrop {
+		policy = DropPolicyBlock
+	}
+
+	for len(q.items) >= q.size && !q.closed {
+		switch policy {
+		case DropPolicyNewest:
+			countDrop(dropQueueFull, item.record.Level)
+			return
+		case DropPolicyOldest:
+			if i := q.oldestDroppable(); i >= 0 {
+				countDrop(dropQueueFull, q.items[i].record.Level)
+				q.items = slices.Delete(q.items, i, i+1)
+				q.itemDone()
+				continue
+			}
+		}
+		q.cond.Wait()
+	}
+
+	if q.closed {
+		q.mu.Unlock()
+		q.handle(item)
+		q.mu.Lock()
+		return
+	}
+
+	q.items = append(q.items, item)
+	q.pending++
+	q.cond.Broadcast()
+}
+
+// oldestDroppable returns the index of the oldest queued item below the
+// never-drop level, or -1 if there isn't one. The lock must be held.
+func (q *asyncQueue) oldestDroppable() int {
+	return slices.IndexFunc(q.items, func(item asyncItem) bool {
+		return item.record.Level < q.neverDrop
+	})
+}
+
+// itemDone marks an item as having been handled or dropped. The lock must be
+// held.
+func (q *asyncQueue) itemDone() {
+	q.pending--
+	if q.pending == 0 {
+		q.cond.Broadcast()
+	}
+}
+
+// depth returns the number of records waiting in the queue.
+func (q *asyncQueue) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// flush waits until all queued records have been handled.
+func (q *asyncQueue) flush() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.pending > 0 {
+		q.cond.Wait()
+	}
+	return nil
+}
+
+// close handles any queued records, and then stops the background
+// goroutine.
+func (q *asyncQueue) close() error {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	<-q.done
+	return nil
+}
+
+// asyncHandler is a [log/slog.Handler] that queues records to be handled by
+// the next handler on a background goroutine.
+type asyncHandler struct {
+	next  slog.Handler
+	queue *asyncQueue
+	goas  []groupOrAttrs
+}
+
+func newAsyncHandler(next slog.Handler, queue *asyncQueue) *asyncHandler {
+	return &asyncHandler{next: next, queue: queue}
+}
+
+func (h *asyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *asyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.queue.add(asyncItem{
+		ctx:     context.WithoutCancel(ctx),
+		handler: h.next,
+		record:  r.Clone(),
+		goas:    h.goas,
+	})
+	return nil
+}
+
+func (h *asyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs), groupOrAttrs{attrs: attrs})
+}
+
+func (h *asyncHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name), groupOrAttrs{group: name})
+}
+
+func (h *asyncHandler) with(next slog.Handler, goa groupOrAttrs) *asyncHandler {
+	h2 := *h
+	h2.next = next
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
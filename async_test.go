@@ -0,0 +1,217 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedHandler records messages, blocking on the first record until the gate
+// is opened.
+type gatedHandler struct {
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func newGatedHandler() *gatedHandler {
+	return &gatedHandler{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (h *gatedHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *gatedHandler) Handle(_ context.Context, r slog.Record) error {
+	h.once.Do(func() {
+		close(h.started)
+		<-h.gate
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, r.Message)
+	return nil
+}
+
+func (h *gatedHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *gatedHandler) WithGroup(string) slog.Handler      { return h }
+
+func Test_AsyncWritesRecordsInOrder(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithAsync(10))
+	l.Info("one")
+	l.With("n", 2).Info("two")
+	require.NoError(t, Flush())
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=one\ntime=2026-01-02T03:04:05.000Z level=INFO msg=two n=2\n", w.String())
+}
+
+func Test_AsyncDropPolicies(t *testing.T) {
+	tests := []struct {
+		policy DropPolicy
+		want   []string
+	}{
+		{DropPolicyNewest, []string{"one", "two"}},
+		{DropPolicyOldest, []string{"one", "three"}},
+	}
+
+	for _, tt := range tests {
+		inner := newGatedHandler()
+		queue := newAsyncQueue(1, tt.policy, slog.Level(math.MaxInt), nil)
+		l := slog.New(newAsyncHandler(inner, queue))
+
+		l.Info("one")
+		<-inner.started
+		l.Info("two")
+		l.Info("three")
+		close(inner.gate)
+
+		require.NoError(t, queue.flush())
+		assert.Equal(t, tt.want, inner.messages)
+	}
+}
+
+func Test_AsyncDropOldestKeepsHighSeverityRecords(t *testing.T) {
+	inner := newGatedHandler()
+	queue := newAsyncQueue(2, DropPolicyOldest, slog.LevelError, nil)
+	l := slog.New(newAsyncHandler(inner, queue))
+
+	l.Info("one")
+	<-inner.started
+	l.Error("error")
+	l.Info("two")
+	l.Info("three")
+	close(inner.gate)
+
+	require.NoError(t, queue.flush())
+	assert.Equal(t, []string{"one", "error", "three"}, inner.messages)
+}
+
+func Test_AsyncDropOldestBlocksWhenOnlyHighSeverityRecordsQueued(t *testing.T) {
+	inner := newGatedHandler()
+	queue := newAsyncQueue(1, DropPolicyOldest, slog.LevelError, nil)
+	l := slog.New(newAsyncHandler(inner, queue))
+
+	l.Info("one")
+	<-inner.started
+	l.Error("error")
+
+	done := make(chan struct{})
+	go func() {
+		l.Info("two")
+		l.Info("three")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("records were queued by dropping the error record")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.gate)
+	<-done
+	require.NoError(t, queue.flush())
+	assert.Equal(t, []string{"one", "error"}, inner.messages[:2])
+}
+
+func Test_AsyncReportsErrors(t *testing.T) {
+	var got []string
+	queue := newAsyncQueue(1, DropPolicyBlock, slog.Level(math.MaxInt), func(err error, r slog.Record) {
+		got = append(got, err.Error(), recordAttrs(r)["req"].(map[string]any)["id"].(string))
+	})
+
+	sink := &flakySink{down: true}
+	l := slog.New(newAsyncHandler(newSinkHandler(sink, slog.LevelInfo), queue))
+	l.WithGroup("req").Info("Test", "id", "abc")
+
+	require.NoError(t, queue.flush())
+	assert.Equal(t, []string{"sink is down", "abc"}, got)
+}
+
+func Test_AsyncNeverDropsHighSeverityRecords(t *testing.T) {
+	inner := newGatedHandler()
+	queue := newAsyncQueue(1, DropPolicyNewest, slog.LevelError, nil)
+	l := slog.New(newAsyncHandler(inner, queue))
+
+	l.Info("one")
+	<-inner.started
+	l.Info("two")
+	l.Info("three")
+
+	done := make(chan struct{})
+	go func() {
+		l.Error("four")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("error record was not blocked by full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.gate)
+	<-done
+	require.NoError(t, queue.flush())
+	assert.Equal(t, []string{"one", "two", "four"}, inner.messages)
+}
+
+func Test_AsyncCloseDrainsQueueAndStops(t *testing.T) {
+	inner := newGatedHandler()
+	queue := newAsyncQueue(10, DropPolicyBlock, slog.LevelError, nil)
+	l := slog.New(newAsyncHandler(inner, queue))
+
+	l.Info("one")
+	<-inner.started
+	l.Info("two")
+
+	closed := make(chan struct{})
+	go func() {
+		require.NoError(t, queue.close())
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("close returned before the queue was drained")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.gate)
+	<-closed
+
+	select {
+	case <-queue.done:
+	default:
+		t.Fatal("queue goroutine is still running")
+	}
+
+	l.Info("three")
+	assert.Equal(t, []string{"one", "two", "three"}, inner.messages)
+}
+
+func Test_AsyncQueueClosedWithLogger(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithAsync(10))
+	l.Info("one")
+	require.NoError(t, Close())
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=one\n", w.String())
+}
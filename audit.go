@@ -0,0 +1,211 @@
+package slogflags
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sync"
+)
+
+// Keys added to each record written by an [AuditSink].
+const (
+	auditPrevKey      = "prev_hash"
+	auditHashKey      = "hash"
+	auditSignatureKey = "signature"
+)
+
+// AuditConfig configures an [AuditSink].
+type AuditConfig struct {
+	// Writer is where the audit log is written, normally a file opened for
+	// appending.
+	Writer io.Writer
+
+	// PrivateKey is used to sign the hash of each record, if set. This
+	// allows the log to be verified by anyone holding the public key,
+	// without them being able to forge records.
+	PrivateKey ed25519.PrivateKey
+
+	// PreviousHash is the hash of the last record already in the log, when
+	// appending to an existing log. It can be obtained from
+	// [VerifyAuditLog]. Defaults to empty, for a new log.
+	PreviousHash string
+}
+
+// AuditSink is a [Sink] that writes a tamper-evident audit trail. Each record
+// is written as a line of JSON, with three additional fields:
+//
+//   - prev_hash: the hash of the previous record in the log
+//   - hash: the SHA-256 hash of the record, including prev_hash, encoded
+//     as JSON with sorted keys and without the hash and signature fields
+//   - signature: the Ed25519 signature of the hash, if a private key was
+//     configured
+//
+// Because each record's hash covers the previous hash, any record that is
+// modified, removed or reordered breaks the chain, which is detected by
+// [VerifyAuditLog]. Attributes with these keys are overwritten.
+//
+// Audit records are normally kept separate from other logs, using the
+// logger returned by [AuditSink.Logger], but the sink can also be passed
+// to [WithSink] to keep an audit trail of all records.
+type AuditSink struct {
+	config AuditConfig
+
+	mu   sync.Mutex
+	prev string
+}
+
+// NewAuditSink creates a new [AuditSink] with the given config.
+func NewAuditSink(config AuditConfig) (*AuditSink, error) {
+	if config.Writer == nil {
+		return nil, errors.New("audit: no writer specified")
+	}
+	if config.PrivateKey != nil && len(config.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("audit: invalid private key")
+	}
+	return &AuditSink{config: config, prev: config.PreviousHash}, nil
+}
+
+// Logger returns a logger that writes all records, regardless of level,
+// only to the audit log.
+func (s *AuditSink) Logger() *slog.Logger {
+	return slog.New(newSinkHandler(s, slog.Level(math.MinInt)))
+}
+
+func (s *AuditSink) Write(ctx context.Context, r slog.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := recordJSON(ctx, r)
+	delete(m, auditHashKey)
+	delete(m, auditSignatureKey)
+	m[auditPrevKey] = s.prev
+
+	m, err := auditCanonical(m)
+	if err != nil {
+		return fmt.Errorf("audit: unable to encode record: %w", err)
+	}
+	hash, err := auditHash(m)
+	if err != nil {
+		return fmt.Errorf("audit: unable to encode record: %w", err)
+	}
+	m[auditHashKey] = hash
+	if s.config.PrivateKey != nil {
+		m[auditSignatureKey] = base64.StdEncoding.EncodeToString(ed25519.Sign(s.config.PrivateKey, []byte(hash)))
+	}
+
+	line, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("audit: unable to encode record: %w", err)
+	}
+	if _, err := s.config.Writer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("audit: unable to write record: %w", err)
+	}
+
+	s.prev = hash
+	return nil
+}
+
+// Close does nothing; the writer remains open and must be closed by the
+// caller.
+func (s *AuditSink) Close() error {
+	return nil
+}
+
+// auditCanonical returns m as it will be decoded by [VerifyAuditLog], so that
+// both hash the same encoding. Values such as structs, whose fields are
+// encoded in declaration order, become maps with sorted keys, and numbers
+// keep their encoded form.
+func auditCanonical(m map[string]any) (map[string]any, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var res map[string]any
+	if err := dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// auditHash returns the hex-encoded SHA-256 hash of the JSON encoding of m.
+// Maps are encoded with sorted keys, so the encoding is deterministic.
+func auditHash(m map[string]any) (string, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifyAuditLog reads an audit log written by an [AuditSink], and checks
+// that the hash of every record is correct and that each record refers to
+// the one before it. If a public key is given, every record must also be
+// signed by the matching private key. It returns the hash of the last
+// record, which can be used as [AuditConfig.PreviousHash] to continue the
+// log, or an error describing the first problem found.
+//
+// The first record must be the start of the chain; logs that have been
+// continued across several files should be verified together, e.g. using
+// [io.MultiReader]. Records removed from the end of the log can't be
+// detected, unless a copy of the last hash is kept elsewhere.
+func VerifyAuditLog(r io.Reader, publicKey ed25519.PublicKey) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	prev := ""
+	for line := 1; scanner.Scan(); line++ {
+		dec := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		dec.UseNumber()
+
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			return "", fmt.Errorf("audit: line %d: invalid record: %w", line, err)
+		}
+
+		hash, _ := m[auditHashKey].(string)
+		signature, _ := m[auditSignatureKey].(string)
+		delete(m, auditHashKey)
+		delete(m, auditSignatureKey)
+
+		if p, _ := m[auditPrevKey].(string); p != prev {
+			return "", fmt.Errorf("audit: line %d: previous hash does not match", line)
+		}
+
+		want, err := auditHash(m)
+		if err != nil {
+			return "", fmt.Errorf("audit: line %d: invalid record: %w", line, err)
+		}
+		if hash != want {
+			return "", fmt.Errorf("audit: line %d: hash does not match", line)
+		}
+
+		if publicKey != nil {
+			sig, err := base64.StdEncoding.DecodeString(signature)
+			if err != nil || !ed25519.Verify(publicKey, []byte(hash), sig) {
+				return "", fmt.Errorf("audit: line %d: invalid signature", line)
+			}
+		}
+
+		prev = hash
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("audit: unable to read log: %w", err)
+	}
+	return prev, nil
+}
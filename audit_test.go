@@ -0,0 +1,113 @@
+package slogflags
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAuditLog(t *testing.T, config AuditConfig) *bytes.Buffer {
+	t.Helper()
+	w := new(bytes.Buffer)
+	config.Writer = w
+	sink, err := NewAuditSink(config)
+	require.NoError(t, err)
+
+	l := sink.Logger()
+	l.Info("User created", "user", "alice", "by", "admin")
+	l.Debug("Role granted", "user", "alice", "role", "editor", "attempt", 1.5)
+	l.Warn("User deleted", "user", "bob", "hash", "spoofed")
+	require.NoError(t, sink.Close())
+	return w
+}
+
+func Test_AuditSink_ChainsRecords(t *testing.T) {
+	w := writeAuditLog(t, AuditConfig{})
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"prev_hash":""`)
+	assert.Regexp(t, `"level":"DEBUG".*"msg":"Role granted"`, lines[1])
+	assert.NotContains(t, lines[2], "spoofed")
+	assert.NotContains(t, lines[0], "signature")
+
+	last, err := VerifyAuditLog(bytes.NewReader(w.Bytes()), nil)
+	require.NoError(t, err)
+	assert.Contains(t, lines[2], `"hash":"`+last+`"`)
+}
+
+func Test_AuditSink_VerifiesStructuredValues(t *testing.T) {
+	w := new(bytes.Buffer)
+	sink, err := NewAuditSink(AuditConfig{Writer: w})
+	require.NoError(t, err)
+
+	type inner struct {
+		Z string
+		Y []float64
+	}
+	l := sink.Logger()
+	l.Info("Struct", "s", struct{ B, A int }{1, 2})
+	l.Info("Nested", "n", struct {
+		Outer string
+		Inner inner
+	}{"o", inner{"z", []float64{0.1, 1e21, 3}}}, slog.Group("g", "b", 2, "a", 1))
+	l.Info("Floats", "f", 0.1, "big", 1e21, "third", 1.0/3, "neg", -2.5e-8)
+
+	_, err = VerifyAuditLog(bytes.NewReader(w.Bytes()), nil)
+	assert.NoError(t, err)
+}
+
+func Test_AuditSink_Signed(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	w := writeAuditLog(t, AuditConfig{PrivateKey: priv})
+
+	_, err = VerifyAuditLog(bytes.NewReader(w.Bytes()), pub)
+	assert.NoError(t, err)
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = VerifyAuditLog(bytes.NewReader(w.Bytes()), otherPub)
+	assert.EqualError(t, err, "audit: line 1: invalid signature")
+}
+
+func Test_AuditSink_Continues(t *testing.T) {
+	first := writeAuditLog(t, AuditConfig{})
+	last, err := VerifyAuditLog(bytes.NewReader(first.Bytes()), nil)
+	require.NoError(t, err)
+
+	second := writeAuditLog(t, AuditConfig{PreviousHash: last})
+	_, err = VerifyAuditLog(io.MultiReader(first, second), nil)
+	assert.NoError(t, err)
+}
+
+func Test_VerifyAuditLog_DetectsTampering(t *testing.T) {
+	w := writeAuditLog(t, AuditConfig{})
+	lines := strings.SplitAfter(w.String(), "\n")
+
+	_, err := VerifyAuditLog(strings.NewReader(strings.Replace(w.String(), "alice", "mallory", 1)), nil)
+	assert.EqualError(t, err, "audit: line 1: hash does not match")
+
+	_, err = VerifyAuditLog(strings.NewReader(lines[0]+lines[2]), nil)
+	assert.EqualError(t, err, "audit: line 2: previous hash does not match")
+
+	_, err = VerifyAuditLog(strings.NewReader(lines[1]+lines[2]), nil)
+	assert.EqualError(t, err, "audit: line 1: previous hash does not match")
+
+	_, err = VerifyAuditLog(strings.NewReader("not json\n"), nil)
+	assert.ErrorContains(t, err, "audit: line 1: invalid record")
+}
+
+func Test_NewAuditSink_Validates(t *testing.T) {
+	_, err := NewAuditSink(AuditConfig{})
+	assert.EqualError(t, err, "audit: no writer specified")
+
+	_, err = NewAuditSink(AuditConfig{Writer: io.Discard, PrivateKey: ed25519.PrivateKey{1, 2}})
+	assert.EqualError(t, err, "audit: invalid private key")
+}
@@ -0,0 +1,131 @@
+package slogflags
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/url"
+	"os"
+	"sync"
+)
+
+var (
+	auditOutput    = flag.String("audit.output", "", "Where to send audit records ('stdout', 'stderr', a file path, or a URL such as 'nats://host:4222/subject'); defaults to stdout")
+	auditHashChain = flag.Bool("audit.hash-chain", false, "Write audit records as a tamper-evident hash chain (see AuditSink)")
+
+	auditMu     sync.Mutex
+	auditLogger *slog.Logger
+)
+
+// Audit returns a logger for business and audit events, such as users being
+// created or permissions changing, which is kept separate from operational
+// logs. Its destination is configured by the `audit.output` flag. Audit
+// records are always written as JSON, at every level, and are never sampled,
+// rate limited or dropped. Audit files are synced to disk after every
+// record. If the `audit.hash-chain` flag is set, records are written by an
+// [AuditSink] so that the log can be verified with [VerifyAuditLog]; an
+// existing audit file is verified before records are appended to it.
+//
+// The logger is created the first time Audit is called, so [flag.Parse] must
+// be called first. Its output is closed by [Close].
+func Audit() *slog.Logger {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	if auditLogger != nil {
+		return auditLogger
+	}
+
+	configMu.Lock()
+	requested, hashChain := *auditOutput, *auditHashChain
+	handler, err := newAuditHandler(requested, hashChain)
+	if err != nil {
+		handler, _ = newAuditHandler("stderr", hashChain)
+	}
+	configMu.Unlock()
+	auditLogger = slog.New(handler)
+	registerClose(func() error {
+		auditMu.Lock()
+		defer auditMu.Unlock()
+		auditLogger = nil
+		return nil
+	})
+
+	if err != nil {
+		L().Warn("Unable to configure audit output, using stderr", "requested", requested, "error", err)
+	}
+	return auditLogger
+}
+
+// newAuditHandler creates the handler used by [Audit] for the requested
+// output. Unlike the `log.output` flag, sinks are never queued, so that
+// records can't be dropped.
+func newAuditHandler(requested string, hashChain bool) (slog.Handler, error) {
+	all := slog.Level(math.MinInt)
+
+	var (
+		w    io.Writer
+		prev string
+	)
+	switch requested {
+	case "", "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = stderrFile()
+	default:
+		u, err := url.Parse(requested)
+		if err != nil {
+			return nil, err
+		}
+
+		switch u.Scheme {
+		case "", "file":
+			path := requested
+			if u.Scheme == "file" {
+				path = u.Path
+			}
+			if hashChain {
+				// Continue the chain from the last record in an existing log.
+				if existing, err := os.Open(path); err == nil {
+					prev, err = VerifyAuditLog(existing, nil)
+					_ = existing.Close()
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			f, err := newConfig([]Option{WithFsync(true)}).openFile(path)
+			if err != nil {
+				return nil, err
+			}
+			registerClose(f.Close)
+			w = f
+		default:
+			fn, ok := outputSinks[u.Scheme]
+			if !ok {
+				return nil, fmt.Errorf("unsupported output %q", requested)
+			}
+			sink, err := fn(u)
+			if err != nil {
+				return nil, err
+			}
+			if f, ok := sink.(Flusher); ok {
+				registerFlush(f.Flush)
+			}
+			registerClose(sink.Close)
+			return newSinkHandler(sink, all), nil
+		}
+	}
+
+	if hashChain {
+		sink, err := NewAuditSink(AuditConfig{Writer: w, PreviousHash: prev})
+		if err != nil {
+			return nil, err
+		}
+		return newSinkHandler(sink, all), nil
+	}
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: all}), nil
+}
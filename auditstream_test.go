@@ -0,0 +1,60 @@
+package slogflags
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Audit_WritesJSONToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	_ = flag.Set("audit.output", path)
+	defer flag.Set("audit.output", "")
+
+	Audit().Debug("User created", "user", "alice")
+	Audit().Info("Role granted", "user", "alice", "role", "admin")
+	require.NoError(t, Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "User created", record["msg"])
+	assert.Equal(t, "alice", record["user"])
+}
+
+func Test_Audit_HashChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	_ = flag.Set("audit.output", path)
+	defer flag.Set("audit.output", "")
+	_ = flag.Set("audit.hash-chain", "true")
+	defer flag.Set("audit.hash-chain", "false")
+
+	Audit().Info("First")
+	require.NoError(t, Close())
+
+	// A new logger continues the chain in the existing file.
+	Audit().Info("Second")
+	require.NoError(t, Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = VerifyAuditLog(f, nil)
+	assert.NoError(t, err)
+}
+
+func Test_Audit_InvalidOutput(t *testing.T) {
+	_, err := newAuditHandler("bogus://example", false)
+	assert.EqualError(t, err, `unsupported output "bogus://example"`)
+}
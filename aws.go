@@ -0,0 +1,471 @@
+package slogflags
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AWSCredentials are used to sign requests made to AWS services.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Expires is the time the credentials expire, or the zero time if they
+	// do not expire.
+	Expires time.Time
+}
+
+// AWSCredentialsFunc retrieves credentials used to sign requests made to AWS.
+type AWSCredentialsFunc func(ctx context.Context) (AWSCredentials, error)
+
+// awsRegion returns the region configured in the environment.
+func awsRegion() string {
+	if r := os.Getenv("AWS_REGION"); r != "" {
+		return r
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// awsNegativeCacheDuration is how long a failure to find any credentials is
+// cached for, to avoid probing the metadata endpoints on every request.
+const awsNegativeCacheDuration = 30 * time.Second
+
+// errAWSUnsupportedProfile is returned when the selected profile obtains
+// credentials in a way that isn't supported.
+var errAWSUnsupportedProfile = errors.New("unsupported AWS profile")
+
+// DefaultAWSCredentials returns an [AWSCredentialsFunc] that resolves
+// credentials in the same order as the default chain used by the AWS SDK for
+// Go v2:
+//
+//   - The AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+//     environment variables
+//   - A web identity token specified by AWS_WEB_IDENTITY_TOKEN_FILE and
+//     AWS_ROLE_ARN, as used by EKS
+//   - Static keys in the shared credentials file (~/.aws/credentials, or
+//     AWS_SHARED_CREDENTIALS_FILE) or shared config file (~/.aws/config, or
+//     AWS_CONFIG_FILE), using the profile in AWS_PROFILE
+//   - The ECS container credentials endpoint
+//   - The EC2 instance metadata service
+//
+// As with the SDK, if AWS_PROFILE is set the profile is tried before the
+// environment variables. Profiles that use role_arn, credential_process,
+// web_identity_token_file or SSO are not supported, and result in an error
+// rather than falling back to other sources. Use the AWS SDK to implement an
+// [AWSCredentialsFunc] if you need them.
+//
+// Credentials are cached until shortly before they expire. If no credentials
+// are found, the error is cached for 30 seconds.
+func DefaultAWSCredentials(client *http.Client) AWSCredentialsFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := &awsCredentialCache{client: client}
+	return c.get
+}
+
+type awsCredentialCache struct {
+	client *http.Client
+
+	mu       sync.Mutex
+	creds    *AWSCredentials
+	err      error
+	failedAt time.Time
+}
+
+func (c *awsCredentialCache) get(ctx context.Context) (AWSCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.creds != nil && (c.creds.Expires.IsZero() || time.Until(c.creds.Expires) > 5*time.Minute) {
+		return *c.creds, nil
+	}
+
+	if c.err != nil && time.Since(c.failedAt) < awsNegativeCacheDuration {
+		return AWSCredentials{}, c.err
+	}
+
+	var sources []func(ctx context.Context) (*AWSCredentials, error)
+	if os.Getenv("AWS_PROFILE") != "" {
+		sources = append(sources, c.fromSharedFiles, c.fromEnvironment, c.fromWebIdentity)
+	} else {
+		sources = append(sources, c.fromEnvironment, c.fromWebIdentity, c.fromSharedFiles)
+	}
+	sources = append(sources, c.fromContainer, c.fromInstanceMetadata)
+
+	var errs []error
+	for _, source := range sources {
+		creds, err := source(ctx)
+		if errors.Is(err, errAWSUnsupportedProfile) {
+			errs = []error{err}
+			break
+		} else if err != nil {
+			errs = append(errs, err)
+		} else if creds != nil {
+			c.creds = creds
+			c.err = nil
+			return *creds, nil
+		}
+	}
+
+	c.err = fmt.Errorf("no AWS credentials found: %w", errors.Join(errs...))
+	c.failedAt = time.Now()
+	return AWSCredentials{}, c.err
+}
+
+func (c *awsCredentialCache) fromEnvironment(context.Context) (*AWSCredentials, error) {
+	id := os.Getenv("AWS_ACCESS_KEY_ID")
+	secret := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if id == "" || secret == "" {
+		return nil, nil
+	}
+
+	return &AWSCredentials{
+		AccessKeyID:     id,
+		SecretAccessKey: secret,
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}, nil
+}
+
+func (c *awsCredentialCache) fromWebIdentity(ctx context.Context) (*AWSCredentials, error) {
+	tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	role := os.Getenv("AWS_ROLE_ARN")
+	if tokenFile == "" || role == "" {
+		return nil, nil
+	}
+
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read web identity token: %w", err)
+	}
+
+	session := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if session == "" {
+		session = "slogflags"
+	}
+
+	endpoint := "https://sts.amazonaws.com/"
+	if region := awsRegion(); region != "" {
+		endpoint = fmt.Sprintf("https://sts.%s.amazonaws.com/", region)
+	}
+
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {role},
+		"RoleSessionName":  {session},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+
+	var res struct {
+		Credentials struct {
+			AccessKeyId     string
+			SecretAccessKey string
+			SessionToken    string
+			Expiration      time.Time
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	body, err := c.fetch(ctx, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to assume role with web identity: %w", err)
+	}
+	if err := xml.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("unable to parse web identity credentials: %w", err)
+	}
+
+	return &AWSCredentials{
+		AccessKeyID:     res.Credentials.AccessKeyId,
+		SecretAccessKey: res.Credentials.SecretAccessKey,
+		SessionToken:    res.Credentials.SessionToken,
+		Expires:         res.Credentials.Expiration,
+	}, nil
+}
+
+// fromSharedFiles reads static credentials for the selected profile from the
+// shared credentials file, or failing that the shared config file.
+func (c *awsCredentialCache) fromSharedFiles(context.Context) (*AWSCredentials, error) {
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	// Profiles in the config file are prefixed with "profile", apart from
+	// the default one.
+	configSection := "profile " + profile
+	if profile == "default" {
+		configSection = profile
+	}
+
+	credentials, err := readAWSProfile("AWS_SHARED_CREDENTIALS_FILE", "credentials", profile)
+	if err != nil {
+		return nil, err
+	}
+	config, err := readAWSProfile("AWS_CONFIG_FILE", "config", configSection)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, values := range []map[string]string{credentials, config} {
+		if values["aws_access_key_id"] != "" && values["aws_secret_access_key"] != "" {
+			return &AWSCredentials{
+				AccessKeyID:     values["aws_access_key_id"],
+				SecretAccessKey: values["aws_secret_access_key"],
+				SessionToken:    values["aws_session_token"],
+			}, nil
+		}
+	}
+
+	for _, key := range []string{"role_arn", "credential_process", "web_identity_token_file", "sso_session", "sso_start_url"} {
+		if credentials[key] != "" || config[key] != "" {
+			return nil, fmt.Errorf("%w: profile %q uses %s", errAWSUnsupportedProfile, profile, key)
+		}
+	}
+	return nil, nil
+}
+
+// readAWSProfile returns the values in a section of a shared credentials or
+// config file. The path is taken from the given environment variable, or
+// defaults to the named file in ~/.aws. A missing file or section results in
+// a nil map.
+func readAWSProfile(env, name, section string) (map[string]string, error) {
+	path := os.Getenv(env)
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".aws", name)
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read shared %s file: %w", name, err)
+	}
+	defer f.Close()
+
+	var current string
+	var values map[string]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current != section {
+			continue
+		}
+
+		if values == nil {
+			values = map[string]string{}
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return values, scanner.Err()
+}
+
+func (c *awsCredentialCache) fromContainer(ctx context.Context) (*AWSCredentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		endpoint = "http://169.254.170.2" + rel
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	headers := http.Header{}
+	if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+		headers.Set("Authorization", token)
+	} else if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		token, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read container authorization token: %w", err)
+		}
+		headers.Set("Authorization", strings.TrimSpace(string(token)))
+	}
+
+	body, err := c.fetch(ctx, endpoint, headers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve container credentials: %w", err)
+	}
+	return parseAWSCredentialsJSON(body)
+}
+
+func (c *awsCredentialCache) fromInstanceMetadata(ctx context.Context) (*AWSCredentials, error) {
+	if os.Getenv("AWS_EC2_METADATA_DISABLED") == "true" {
+		return nil, nil
+	}
+
+	const base = "http://169.254.169.254/latest"
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, base+"/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach instance metadata service: %w", err)
+	}
+	token, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("instance metadata service returned status %d", res.StatusCode)
+	}
+
+	headers := http.Header{"X-Aws-Ec2-Metadata-Token": {string(token)}}
+	role, err := c.fetch(ctx, base+"/meta-data/iam/security-credentials/", headers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve instance role: %w", err)
+	}
+
+	body, err := c.fetch(ctx, base+"/meta-data/iam/security-credentials/"+strings.TrimSpace(string(role)), headers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve instance credentials: %w", err)
+	}
+	return parseAWSCredentialsJSON(body)
+}
+
+// fetch performs a GET request and returns the body of the response.
+func (c *awsCredentialCache) fetch(ctx context.Context, u string, headers http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request returned status %d", res.StatusCode)
+	}
+	return body, nil
+}
+
+// parseAWSCredentialsJSON parses credentials in the format returned by the
+// ECS and EC2 metadata endpoints.
+func parseAWSCredentialsJSON(body []byte) (*AWSCredentials, error) {
+	var res struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("unable to parse credentials: %w", err)
+	}
+
+	return &AWSCredentials{
+		AccessKeyID:     res.AccessKeyId,
+		SecretAccessKey: res.SecretAccessKey,
+		SessionToken:    res.Token,
+		Expires:         res.Expiration,
+	}, nil
+}
+
+// signAWSRequest adds AWS Signature Version 4 headers to the request.
+func signAWSRequest(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	date := now.Format("20060102")
+	payloadHash := sha256.Sum256(body)
+
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "content-type" || strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := new(strings.Builder)
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,356 @@
+package slogflags
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SignAWSRequest(t *testing.T) {
+	// Example request from the AWS Signature Version 4 documentation.
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signAWSRequest(req, nil, creds, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", req.Header.Get("Authorization"))
+}
+
+func Test_DefaultAWSCredentials_FromEnvironment(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "token")
+
+	creds, err := DefaultAWSCredentials(nil)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret", SessionToken: "token"}, creds)
+}
+
+func Test_DefaultAWSCredentials_FromSharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(path, []byte("[default]\naws_access_key_id = a\naws_secret_access_key = b\n\n[other]\naws_access_key_id = c\naws_secret_access_key = d\n"), 0600))
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_PROFILE", "other")
+
+	creds, err := DefaultAWSCredentials(nil)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, AWSCredentials{AccessKeyID: "c", SecretAccessKey: "d"}, creds)
+}
+
+func Test_DefaultAWSCredentials_FromConfigFile(t *testing.T) {
+	clearAWSEnvironment(t)
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("[default]\nregion = eu-west-1\n\n[profile other]\naws_access_key_id = c\naws_secret_access_key = d\naws_session_token = e\n"), 0600))
+	t.Setenv("AWS_CONFIG_FILE", path)
+	t.Setenv("AWS_PROFILE", "other")
+
+	creds, err := DefaultAWSCredentials(nil)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, AWSCredentials{AccessKeyID: "c", SecretAccessKey: "d", SessionToken: "e"}, creds)
+}
+
+func Test_DefaultAWSCredentials_UnsupportedProfile(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"role", "[default]\nrole_arn = arn:aws:iam::123456789012:role/logger\nsource_profile = base\n", "role_arn"},
+		{"process", "[default]\ncredential_process = /usr/bin/creds\n", "credential_process"},
+		{"sso", "[default]\nsso_session = corp\nsso_account_id = 123456789012\n", "sso_session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearAWSEnvironment(t)
+			path := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(path, []byte(tt.config), 0600))
+			t.Setenv("AWS_CONFIG_FILE", path)
+			t.Setenv("AWS_EC2_METADATA_DISABLED", "")
+
+			// The instance metadata service shouldn't be used instead of the
+			// profile.
+			client := newAWSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				serveInstanceMetadata(t, w, r)
+			})
+
+			_, err := DefaultAWSCredentials(client)(t.Context())
+			assert.ErrorIs(t, err, errAWSUnsupportedProfile)
+			assert.ErrorContains(t, err, `profile "default" uses `+tt.want)
+		})
+	}
+}
+
+// redirectTransport sends every request to a test server, keeping the
+// original path, query and Host header.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = req.URL.Host
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newAWSTestServer starts a server for the handler, and returns a client
+// that sends all requests to it.
+func newAWSTestServer(t *testing.T, handler http.HandlerFunc) *http.Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	target, _ := url.Parse(srv.URL)
+	return &http.Client{Transport: redirectTransport{target: target}}
+}
+
+// clearAWSEnvironment unsets the environment variables used by each
+// credential source, so that only the ones a test sets are tried.
+func clearAWSEnvironment(t *testing.T) {
+	for _, k := range []string{
+		"AWS_ACCESS_KEY_ID",
+		"AWS_SECRET_ACCESS_KEY",
+		"AWS_SESSION_TOKEN",
+		"AWS_WEB_IDENTITY_TOKEN_FILE",
+		"AWS_ROLE_ARN",
+		"AWS_ROLE_SESSION_NAME",
+		"AWS_REGION",
+		"AWS_DEFAULT_REGION",
+		"AWS_PROFILE",
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+	} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+// serveInstanceMetadata handles the requests made to the EC2 instance
+// metadata service, returning credentials for a role called "web".
+func serveInstanceMetadata(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	assert.Equal(t, "169.254.169.254", r.Host)
+	switch r.URL.Path {
+	case "/latest/api/token":
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "21600", r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+		_, _ = fmt.Fprint(w, "imds-token")
+	case "/latest/meta-data/iam/security-credentials/":
+		assert.Equal(t, "imds-token", r.Header.Get("X-aws-ec2-metadata-token"))
+		_, _ = fmt.Fprint(w, "web\n")
+	case "/latest/meta-data/iam/security-credentials/web":
+		assert.Equal(t, "imds-token", r.Header.Get("X-aws-ec2-metadata-token"))
+		_, _ = fmt.Fprint(w, `{"AccessKeyId":"imds-id","SecretAccessKey":"imds-secret","Token":"imds-session","Expiration":"2030-01-02T03:04:05Z"}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func Test_DefaultAWSCredentials_FromWebIdentity(t *testing.T) {
+	clearAWSEnvironment(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("jwt\n"), 0600))
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/logger")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	client := newAWSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sts.eu-west-1.amazonaws.com", r.Host)
+		assert.Equal(t, url.Values{
+			"Action":           {"AssumeRoleWithWebIdentity"},
+			"Version":          {"2011-06-15"},
+			"RoleArn":          {"arn:aws:iam::123456789012:role/logger"},
+			"RoleSessionName":  {"slogflags"},
+			"WebIdentityToken": {"jwt"},
+		}, r.URL.Query())
+		_, _ = fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>`+
+			`<AccessKeyId>web-id</AccessKeyId><SecretAccessKey>web-secret</SecretAccessKey><SessionToken>web-session</SessionToken>`+
+			`<Expiration>2030-01-02T03:04:05Z</Expiration></Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`)
+	})
+
+	creds, err := DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, AWSCredentials{
+		AccessKeyID:     "web-id",
+		SecretAccessKey: "web-secret",
+		SessionToken:    "web-session",
+		Expires:         time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, creds)
+}
+
+func Test_DefaultAWSCredentials_FromContainer(t *testing.T) {
+	clearAWSEnvironment(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("container-token\n"), 0600))
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/v2/credentials/abc")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE", tokenFile)
+
+	client := newAWSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "169.254.170.2", r.Host)
+		assert.Equal(t, "/v2/credentials/abc", r.URL.Path)
+		assert.Equal(t, "container-token", r.Header.Get("Authorization"))
+		_, _ = fmt.Fprint(w, `{"AccessKeyId":"ecs-id","SecretAccessKey":"ecs-secret","Token":"ecs-session","Expiration":"2030-01-02T03:04:05Z"}`)
+	})
+
+	creds, err := DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, AWSCredentials{
+		AccessKeyID:     "ecs-id",
+		SecretAccessKey: "ecs-secret",
+		SessionToken:    "ecs-session",
+		Expires:         time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, creds)
+}
+
+func Test_DefaultAWSCredentials_FromInstanceMetadata(t *testing.T) {
+	clearAWSEnvironment(t)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "")
+
+	client := newAWSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		serveInstanceMetadata(t, w, r)
+	})
+
+	creds, err := DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, AWSCredentials{
+		AccessKeyID:     "imds-id",
+		SecretAccessKey: "imds-secret",
+		SessionToken:    "imds-session",
+		Expires:         time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, creds)
+}
+
+func Test_DefaultAWSCredentials_Order(t *testing.T) {
+	clearAWSEnvironment(t)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "")
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "http://credentials.test/creds")
+
+	var containerUp bool
+	var requests []string
+	client := newAWSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Host+r.URL.Path)
+		if r.Host == "sts.amazonaws.com" {
+			_, _ = fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>`+
+				`<AccessKeyId>web-id</AccessKeyId><SecretAccessKey>web-secret</SecretAccessKey>`+
+				`</Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`)
+		} else if r.Host != "credentials.test" {
+			serveInstanceMetadata(t, w, r)
+		} else if containerUp {
+			_, _ = fmt.Fprint(w, `{"AccessKeyId":"ecs-id","SecretAccessKey":"ecs-secret"}`)
+		} else {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+		}
+	})
+
+	// The container endpoint fails, so the instance metadata service is used.
+	creds, err := DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "imds-id", creds.AccessKeyID)
+	assert.Equal(t, "credentials.test/creds", requests[0])
+
+	// Each earlier source takes precedence over the later ones.
+	containerUp = true
+	creds, err = DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ecs-id", creds.AccessKeyID)
+
+	path := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(path, []byte("[default]\naws_access_key_id = file-id\naws_secret_access_key = file-secret\n"), 0600))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	creds, err = DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "file-id", creds.AccessKeyID)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("jwt"), 0600))
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/logger")
+	creds, err = DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "web-id", creds.AccessKeyID)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "env-id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	creds, err = DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "env-id", creds.AccessKeyID)
+
+	// An explicitly selected profile takes precedence over everything.
+	t.Setenv("AWS_PROFILE", "default")
+	creds, err = DefaultAWSCredentials(client)(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "file-id", creds.AccessKeyID)
+}
+
+func Test_DefaultAWSCredentials_NoneFound(t *testing.T) {
+	clearAWSEnvironment(t)
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "http://credentials.test/creds")
+
+	var requests int
+	client := newAWSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "unavailable", http.StatusInternalServerError)
+	})
+
+	creds := DefaultAWSCredentials(client)
+	for range 2 {
+		_, err := creds(t.Context())
+		assert.ErrorContains(t, err, "no AWS credentials found: unable to retrieve container credentials: request returned status 500")
+	}
+	assert.Equal(t, 1, requests, "failure should be cached")
+}
+
+func Test_DefaultAWSCredentials_RefreshesExpiringCredentials(t *testing.T) {
+	clearAWSEnvironment(t)
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "http://credentials.test/creds")
+
+	var requests int
+	client := newAWSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		expires := time.Now().Add(time.Hour)
+		if requests == 1 {
+			// Credentials expiring within five minutes are refreshed.
+			expires = time.Now().Add(time.Minute)
+		}
+		_, _ = fmt.Fprintf(w, `{"AccessKeyId":"id-%d","SecretAccessKey":"secret","Expiration":%q}`, requests, expires.Format(time.RFC3339))
+	})
+
+	creds := DefaultAWSCredentials(client)
+	for _, want := range []string{"id-1", "id-2", "id-2"} {
+		got, err := creds(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, want, got.AccessKeyID)
+	}
+}
+
+func Test_ParseAWSCredentialsJSON(t *testing.T) {
+	creds, err := parseAWSCredentialsJSON([]byte(`{"Code":"Success","AccessKeyId":"a","SecretAccessKey":"b","Token":"c","Expiration":"2030-01-02T03:04:05Z"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &AWSCredentials{
+		AccessKeyID:     "a",
+		SecretAccessKey: "b",
+		SessionToken:    "c",
+		Expires:         time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, creds)
+
+	_, err = parseAWSCredentialsJSON([]byte(`<html>`))
+	assert.ErrorContains(t, err, "unable to parse credentials")
+}
@@ -0,0 +1,174 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// azureIMDSURL is the URL of the Azure Instance Metadata Service token
+// endpoint.
+var azureIMDSURL = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// AzureTokenFunc retrieves an access token used to authenticate requests made
+// to Azure APIs.
+type AzureTokenFunc func(ctx context.Context) (string, error)
+
+// DefaultAzureToken returns an [AzureTokenFunc] that obtains tokens for the
+// given scope (e.g. "https://monitor.azure.com/.default"). The following
+// sources are tried in order:
+//
+//   - A client secret specified by AZURE_TENANT_ID, AZURE_CLIENT_ID and
+//     AZURE_CLIENT_SECRET
+//   - A workload identity token specified by AZURE_FEDERATED_TOKEN_FILE, as
+//     used by AKS
+//   - The managed identity endpoint used by App Service and Functions
+//   - The managed identity endpoint of the Instance Metadata Service
+//
+// If AZURE_CLIENT_ID is set it will be used to select a user-assigned managed
+// identity. Tokens are cached until shortly before they expire, and responses
+// without a valid expiry are treated as errors.
+func DefaultAzureToken(client *http.Client, scope string) AzureTokenFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := &azureTokenCache{client: client, scope: scope}
+	return c.get
+}
+
+type azureTokenCache struct {
+	client *http.Client
+	scope  string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+type azureTokenResponse struct {
+	AccessToken string          `json:"access_token"`
+	ExpiresIn   json.RawMessage `json:"expires_in"`
+	ExpiresOn   json.RawMessage `json:"expires_on"`
+}
+
+// expiry returns when the token expires. Managed identity endpoints return
+// expires_in and expires_on as strings, whereas Entra ID returns numbers and
+// omits expires_on.
+func (r azureTokenResponse) expiry() (time.Time, error) {
+	if expiresIn, err := strconv.Atoi(strings.Trim(string(r.ExpiresIn), `"`)); err == nil && expiresIn > 0 {
+		return time.Now().Add(time.Duration(expiresIn) * time.Second), nil
+	}
+	if expiresOn, err := strconv.ParseInt(strings.Trim(string(r.ExpiresOn), `"`), 10, 64); err == nil && expiresOn > 0 {
+		return time.Unix(expiresOn, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("azure token has no valid expiry: expires_in=%s", r.ExpiresIn)
+}
+
+func (c *azureTokenCache) get(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Until(c.expires) > 5*time.Minute {
+		return c.token, nil
+	}
+
+	tenant := os.Getenv("AZURE_TENANT_ID")
+	clientID := os.Getenv("AZURE_CLIENT_ID")
+	resource := strings.TrimSuffix(c.scope, "/.default")
+
+	var req *http.Request
+	var err error
+	switch {
+	case tenant != "" && clientID != "" && os.Getenv("AZURE_CLIENT_SECRET") != "":
+		req, err = c.entraRequest(ctx, tenant, url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientID},
+			"client_secret": {os.Getenv("AZURE_CLIENT_SECRET")},
+			"scope":         {c.scope},
+		})
+	case tenant != "" && clientID != "" && os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != "":
+		var assertion []byte
+		assertion, err = os.ReadFile(os.Getenv("AZURE_FEDERATED_TOKEN_FILE"))
+		if err == nil {
+			req, err = c.entraRequest(ctx, tenant, url.Values{
+				"grant_type":            {"client_credentials"},
+				"client_id":             {clientID},
+				"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+				"client_assertion":      {strings.TrimSpace(string(assertion))},
+				"scope":                 {c.scope},
+			})
+		}
+	case os.Getenv("IDENTITY_ENDPOINT") != "" && os.Getenv("IDENTITY_HEADER") != "":
+		query := url.Values{"api-version": {"2019-08-01"}, "resource": {resource}}
+		if clientID != "" {
+			query.Set("client_id", clientID)
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, os.Getenv("IDENTITY_ENDPOINT")+"?"+query.Encode(), nil)
+		if err == nil {
+			req.Header.Set("X-IDENTITY-HEADER", os.Getenv("IDENTITY_HEADER"))
+		}
+	default:
+		query := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+		if clientID != "" {
+			query.Set("client_id", clientID)
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, azureIMDSURL+"?"+query.Encode(), nil)
+		if err == nil {
+			req.Header.Set("Metadata", "true")
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to create Azure token request: %w", err)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to obtain Azure token: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return "", fmt.Errorf("azure token request returned status %d: %s", res.StatusCode, b)
+	}
+
+	var token azureTokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("unable to parse Azure token: %w", err)
+	}
+
+	// Without an expiry the token can't be cached, and would be requested
+	// again for every call.
+	expires, err := token.expiry()
+	if err != nil {
+		return "", err
+	}
+
+	c.token = token.AccessToken
+	c.expires = expires
+	return c.token, nil
+}
+
+func (c *azureTokenCache) entraRequest(ctx context.Context, tenant string, form url.Values) (*http.Request, error) {
+	authority := os.Getenv("AZURE_AUTHORITY_HOST")
+	if authority == "" {
+		authority = "https://login.microsoftonline.com/"
+	}
+
+	u := strings.TrimSuffix(authority, "/") + "/" + url.PathEscape(tenant) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
@@ -0,0 +1,207 @@
+package slogflags
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearAzureEnvironment unsets the environment variables used by each token
+// source, so that only the ones a test sets are tried.
+func clearAzureEnvironment(t *testing.T) {
+	for _, k := range []string{
+		"AZURE_TENANT_ID",
+		"AZURE_CLIENT_ID",
+		"AZURE_CLIENT_SECRET",
+		"AZURE_FEDERATED_TOKEN_FILE",
+		"AZURE_AUTHORITY_HOST",
+		"IDENTITY_ENDPOINT",
+		"IDENTITY_HEADER",
+	} {
+		t.Setenv(k, "")
+	}
+}
+
+// useAzureIMDSServer points azureIMDSURL at the given server for the
+// duration of the test.
+func useAzureIMDSServer(t *testing.T, srv *httptest.Server) {
+	old := azureIMDSURL
+	azureIMDSURL = srv.URL
+	t.Cleanup(func() { azureIMDSURL = old })
+}
+
+func Test_DefaultAzureToken_FromClientSecret(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/tenant/oauth2/v2.0/token", r.URL.Path)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		assert.Equal(t, "secret", r.PostForm.Get("client_secret"))
+		assert.Equal(t, "https://monitor.azure.com/.default", r.PostForm.Get("scope"))
+		_, _ = fmt.Fprint(w, `{"access_token":"secret-token","expires_in":3599}`)
+	}))
+	defer srv.Close()
+
+	clearAzureEnvironment(t)
+	t.Setenv("AZURE_AUTHORITY_HOST", srv.URL+"/")
+	t.Setenv("AZURE_TENANT_ID", "tenant")
+	t.Setenv("AZURE_CLIENT_ID", "client")
+	t.Setenv("AZURE_CLIENT_SECRET", "secret")
+
+	token := DefaultAzureToken(srv.Client(), "https://monitor.azure.com/.default")
+	for range 2 {
+		got, err := token(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "secret-token", got)
+	}
+	assert.Equal(t, int32(1), requests.Load(), "token should be cached")
+}
+
+func Test_DefaultAzureToken_FromWorkloadIdentity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tenant/oauth2/v2.0/token", r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.PostForm.Get("client_assertion_type"))
+		assert.Equal(t, "federated-jwt", r.PostForm.Get("client_assertion"))
+		assert.Equal(t, "https://monitor.azure.com/.default", r.PostForm.Get("scope"))
+		_, _ = fmt.Fprint(w, `{"access_token":"workload-token","expires_in":3599}`)
+	}))
+	defer srv.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("federated-jwt\n"), 0600))
+
+	clearAzureEnvironment(t)
+	t.Setenv("AZURE_AUTHORITY_HOST", srv.URL)
+	t.Setenv("AZURE_TENANT_ID", "tenant")
+	t.Setenv("AZURE_CLIENT_ID", "client")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+
+	got, err := DefaultAzureToken(srv.Client(), "https://monitor.azure.com/.default")(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "workload-token", got)
+}
+
+func Test_DefaultAzureToken_FromAppService(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/msi/token", r.URL.Path)
+		assert.Equal(t, "identity-header", r.Header.Get("X-IDENTITY-HEADER"))
+		assert.Equal(t, "2019-08-01", r.URL.Query().Get("api-version"))
+		assert.Equal(t, "https://monitor.azure.com", r.URL.Query().Get("resource"))
+		assert.Equal(t, "user-assigned", r.URL.Query().Get("client_id"))
+		_, _ = fmt.Fprint(w, `{"access_token":"app-service-token","expires_in":"3599"}`)
+	}))
+	defer srv.Close()
+
+	clearAzureEnvironment(t)
+	t.Setenv("AZURE_CLIENT_ID", "user-assigned")
+	t.Setenv("IDENTITY_ENDPOINT", srv.URL+"/msi/token")
+	t.Setenv("IDENTITY_HEADER", "identity-header")
+
+	got, err := DefaultAzureToken(srv.Client(), "https://monitor.azure.com/.default")(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "app-service-token", got)
+}
+
+func Test_DefaultAzureToken_FromInstanceMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.Header.Get("Metadata"))
+		assert.Equal(t, "2018-02-01", r.URL.Query().Get("api-version"))
+		assert.Equal(t, "https://monitor.azure.com", r.URL.Query().Get("resource"))
+		_, _ = fmt.Fprint(w, `{"access_token":"mi-token","expires_in":"3599"}`)
+	}))
+	defer srv.Close()
+
+	clearAzureEnvironment(t)
+	useAzureIMDSServer(t, srv)
+
+	got, err := DefaultAzureToken(nil, "https://monitor.azure.com/.default")(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "mi-token", got)
+}
+
+func Test_DefaultAzureToken_Expiry(t *testing.T) {
+	sources := map[string]func(t *testing.T, srv *httptest.Server){
+		"client secret": func(t *testing.T, srv *httptest.Server) {
+			t.Setenv("AZURE_AUTHORITY_HOST", srv.URL)
+			t.Setenv("AZURE_TENANT_ID", "tenant")
+			t.Setenv("AZURE_CLIENT_ID", "client")
+			t.Setenv("AZURE_CLIENT_SECRET", "secret")
+		},
+		"workload identity": func(t *testing.T, srv *httptest.Server) {
+			tokenFile := filepath.Join(t.TempDir(), "token")
+			require.NoError(t, os.WriteFile(tokenFile, []byte("federated-jwt"), 0600))
+			t.Setenv("AZURE_AUTHORITY_HOST", srv.URL)
+			t.Setenv("AZURE_TENANT_ID", "tenant")
+			t.Setenv("AZURE_CLIENT_ID", "client")
+			t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+		},
+		"app service": func(t *testing.T, srv *httptest.Server) {
+			t.Setenv("IDENTITY_ENDPOINT", srv.URL)
+			t.Setenv("IDENTITY_HEADER", "identity-header")
+		},
+		"instance metadata": func(t *testing.T, srv *httptest.Server) {
+			useAzureIMDSServer(t, srv)
+		},
+	}
+
+	expiresOn := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"number", `{"access_token":"token","expires_in":3599}`, false},
+		{"string", `{"access_token":"token","expires_in":"3599"}`, false},
+		{"expires on", `{"access_token":"token","expires_on":"` + expiresOn + `"}`, false},
+		{"missing", `{"access_token":"token"}`, true},
+		{"malformed", `{"access_token":"token","expires_in":"soon"}`, true},
+		{"zero", `{"access_token":"token","expires_in":0}`, true},
+	}
+
+	for source, setup := range sources {
+		for _, tt := range tests {
+			t.Run(source+"/"+tt.name, func(t *testing.T) {
+				var requests atomic.Int32
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					requests.Add(1)
+					_, _ = fmt.Fprint(w, tt.body)
+				}))
+				defer srv.Close()
+
+				clearAzureEnvironment(t)
+				setup(t, srv)
+
+				token := DefaultAzureToken(srv.Client(), "https://monitor.azure.com/.default")
+				for range 2 {
+					got, err := token(t.Context())
+					if tt.wantErr {
+						assert.ErrorContains(t, err, "no valid expiry")
+					} else {
+						require.NoError(t, err)
+						assert.Equal(t, "token", got)
+					}
+				}
+				if !tt.wantErr {
+					assert.Equal(t, int32(1), requests.Load(), "token should be cached")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package slogflags
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// azureMonitorMaxBatchBytes is the maximum size of the body of a single
+// request to the Logs Ingestion API, before compression.
+const azureMonitorMaxBatchBytes = 1000000
+
+// AzureMonitorConfig configures an [AzureMonitorSink].
+type AzureMonitorConfig struct {
+	// Endpoint is the logs ingestion endpoint of the data collection rule or
+	// data collection endpoint, e.g.
+	// "https://my-dcr-abcd.uksouth-1.ingest.monitor.azure.com".
+	Endpoint string
+
+	// RuleID is the immutable ID of the data collection rule, e.g.
+	// "dcr-00000000000000000000000000000000".
+	RuleID string
+
+	// Stream is the name of the stream in the data collection rule, e.g.
+	// "Custom-MyTable_CL".
+	Stream string
+
+	// Token is used to obtain access tokens to authenticate requests.
+	// Defaults to [DefaultAzureToken].
+	Token AzureTokenFunc
+
+	// BatchSize is the maximum number of records that will be buffered before
+	// they are sent. Defaults to 500.
+	BatchSize int
+
+	// FlushInterval is the maximum amount of time records will be buffered
+	// before they are sent. Defaults to 5 seconds.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a request will be retried if it fails
+	// due to a network error or a retryable status. Defaults to 3 if zero; a
+	// negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the time to wait before the first retry. It doubles
+	// after each subsequent attempt. Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// AzureMonitorSink is a [Sink] that writes records to Azure Monitor using the
+// Logs Ingestion API.
+//
+// Each record is sent with the columns TimeGenerated (datetime), Level
+// (string), Message (string) and Attributes (dynamic); the stream declared in
+// the data collection rule should match.
+//
+// Buffered records are only guaranteed to be sent once the sink has been
+// closed.
+type AzureMonitorSink struct {
+	config  AzureMonitorConfig
+	url     string
+	batcher *batcher[[]byte]
+}
+
+// NewAzureMonitorSink creates a new [AzureMonitorSink] with the given config.
+func NewAzureMonitorSink(config AzureMonitorConfig) (*AzureMonitorSink, error) {
+	if config.Endpoint == "" || config.RuleID == "" || config.Stream == "" {
+		return nil, errors.New("azure monitor: endpoint, rule ID and stream must be specified")
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Token == nil {
+		config.Token = DefaultAzureToken(config.Client, "https://monitor.azure.com/.default")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 500
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+
+	s := &AzureMonitorSink{
+		config: config,
+		url: fmt.Sprintf(
+			"%s/dataCollectionRules/%s/streams/%s?api-version=2023-01-01",
+			strings.TrimSuffix(config.Endpoint, "/"),
+			url.PathEscape(config.RuleID),
+			url.PathEscape(config.Stream),
+		),
+	}
+	s.batcher = newBatcher(config.BatchSize, config.FlushInterval, s.send)
+	return s, nil
+}
+
+func (s *AzureMonitorSink) Write(ctx context.Context, r slog.Record) error {
+	row, err := json.Marshal(map[string]any{
+		"TimeGenerated": r.Time.UTC().Format(time.RFC3339Nano),
+		"Level":         levelName(ctx, r.Level),
+		"Message":       r.Message,
+		"Attributes":    recordAttrs(r),
+	})
+	if err != nil {
+		return fmt.Errorf("azure monitor: unable to encode record: %w", err)
+	}
+	return s.batcher.add(row)
+}
+
+func (s *AzureMonitorSink) Flush() error {
+	return s.batcher.sync()
+}
+
+func (s *AzureMonitorSink) Close() error {
+	return s.batcher.close()
+}
+
+func (s *AzureMonitorSink) send(rows [][]byte) error {
+	var errs []error
+	for len(rows) > 0 {
+		size := 2
+		n := 0
+		for n < len(rows) && (n == 0 || size+len(rows[n])+1 <= azureMonitorMaxBatchBytes) {
+			size += len(rows[n]) + 1
+			n++
+		}
+
+		body := append([]byte{'['}, bytes.Join(rows[:n], []byte{','})...)
+		body = append(body, ']')
+
+		err := withRetries(s.config.MaxRetries, s.config.RetryBackoff, func() error {
+			return s.post(body)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("azure monitor: unable to send %d records: %w", n, err))
+		}
+		rows = rows[n:]
+	}
+	return errors.Join(errs...)
+}
+
+func (s *AzureMonitorSink) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	token, err := s.config.Token(ctx)
+	if err != nil {
+		return retryable(err)
+	}
+
+	compressed := new(bytes.Buffer)
+	gw := gzip.NewWriter(compressed)
+	_, _ = gw.Write(body)
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, compressed)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		err = fmt.Errorf("request returned status %d: %s", res.StatusCode, b)
+		if retryableStatus(res.StatusCode) {
+			return retryable(err)
+		}
+		return err
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
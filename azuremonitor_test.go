@@ -0,0 +1,92 @@
+package slogflags
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAzureMonitor struct {
+	mu       sync.Mutex
+	statuses []int
+	paths    []string
+	auth     string
+	rows     [][]map[string]any
+}
+
+func (f *fakeAzureMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := http.StatusNoContent
+	if len(f.paths) < len(f.statuses) {
+		status = f.statuses[len(f.paths)]
+	}
+	f.paths = append(f.paths, r.URL.String())
+	f.auth = r.Header.Get("Authorization")
+
+	if status < 300 {
+		gr, _ := gzip.NewReader(r.Body)
+		var rows []map[string]any
+		_ = json.NewDecoder(gr).Decode(&rows)
+		f.rows = append(f.rows, rows)
+	}
+	w.WriteHeader(status)
+}
+
+func newTestAzureMonitorSink(t *testing.T, f *fakeAzureMonitor) *AzureMonitorSink {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	sink, err := NewAzureMonitorSink(AzureMonitorConfig{
+		Endpoint:     server.URL,
+		RuleID:       "dcr-123",
+		Stream:       "Custom-Logs_CL",
+		RetryBackoff: time.Millisecond,
+		Token: func(context.Context) (string, error) {
+			return "tok", nil
+		},
+	})
+	require.NoError(t, err)
+	return sink
+}
+
+func Test_AzureMonitorSink_WritesRows(t *testing.T) {
+	f := &fakeAzureMonitor{}
+	sink := newTestAzureMonitorSink(t, f)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Error("One", "arg1", "arg2")
+	l.Info("Two")
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []string{"/dataCollectionRules/dcr-123/streams/Custom-Logs_CL?api-version=2023-01-01"}, f.paths)
+	assert.Equal(t, "Bearer tok", f.auth)
+	require.Len(t, f.rows, 1)
+	require.Len(t, f.rows[0], 2)
+	assert.Equal(t, "ERROR", f.rows[0][0]["Level"])
+	assert.Equal(t, "One", f.rows[0][0]["Message"])
+	assert.Equal(t, map[string]any{"arg1": "arg2"}, f.rows[0][0]["Attributes"])
+	assert.Contains(t, f.rows[0][0], "TimeGenerated")
+}
+
+func Test_AzureMonitorSink_RetriesThrottling(t *testing.T) {
+	f := &fakeAzureMonitor{statuses: []int{http.StatusTooManyRequests}}
+	sink := newTestAzureMonitorSink(t, f)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+	require.NoError(t, sink.Close())
+
+	assert.Len(t, f.paths, 2)
+	assert.Len(t, f.rows, 1)
+}
@@ -0,0 +1,131 @@
+package slogflags
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// batcher accumulates items and passes them to a send func once a certain
+// number have been collected, or a certain amount of time has passed. Sends
+// happen on a background goroutine so callers adding items are not blocked.
+//
+// Errors from background sends are retained and returned from the next call
+// to add, wrapped in a [BatchError], or close.
+type batcher[T any] struct {
+	size int
+	send func([]T) error
+
+	mu    sync.Mutex
+	items []T
+	err   error
+
+	sendMu sync.Mutex
+	kick   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func newBatcher[T any](size int, interval time.Duration, send func([]T) error) *batcher[T] {
+	b := &batcher[T]{
+		size: size,
+		send: send,
+		kick: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go b.run(interval)
+	return b
+}
+
+func (b *batcher[T]) run(interval time.Duration) {
+	defer close(b.done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-t.C:
+		case <-b.kick:
+		}
+
+		if err := b.flush(); err != nil {
+			b.mu.Lock()
+			b.err = errors.Join(b.err, err)
+			b.mu.Unlock()
+		}
+	}
+}
+
+// add appends an item to the batch, triggering a send if it is now full.
+func (b *batcher[T]) add(item T) error {
+	b.mu.Lock()
+	b.items = append(b.items, item)
+	full := len(b.items) >= b.size
+	err := b.err
+	b.err = nil
+	b.mu.Unlock()
+
+	if full {
+		b.trigger()
+	}
+	if err != nil {
+		return &BatchError{Err: err}
+	}
+	return nil
+}
+
+// trigger causes pending items to be sent as soon as possible, without
+// waiting for the batch to fill or the interval to elapse.
+func (b *batcher[T]) trigger() {
+	select {
+	case b.kick <- struct{}{}:
+	default:
+	}
+}
+
+// flush sends any pending items immediately, in batches no larger than the
+// configured size.
+func (b *batcher[T]) flush() error {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	b.mu.Lock()
+	items := b.items
+	b.items = nil
+	b.mu.Unlock()
+
+	var errs []error
+	for len(items) > 0 {
+		n := min(len(items), b.size)
+		if err := b.send(items[:n]); err != nil {
+			errs = append(errs, err)
+		}
+		items = items[n:]
+	}
+	return errors.Join(errs...)
+}
+
+// sync sends any pending items immediately, returning any errors from this or
+// previous background sends.
+func (b *batcher[T]) sync() error {
+	err := b.flush()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err = errors.Join(b.err, err)
+	b.err = nil
+	return err
+}
+
+// close stops the timer and sends any pending items.
+func (b *batcher[T]) close() error {
+	close(b.stop)
+	<-b.done
+
+	return b.sync()
+}
@@ -0,0 +1,244 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bridgeLevels maps the level names used by other logging libraries to slog
+// levels.
+var bridgeLevels = map[string]slog.Level{
+	"trace":   slog.LevelDebug - 4,
+	"debug":   slog.LevelDebug,
+	"info":    slog.LevelInfo,
+	"warn":    slog.LevelWarn,
+	"warning": slog.LevelWarn,
+	"error":   slog.LevelError,
+	"dpanic":  slog.LevelError,
+	"panic":   LevelFatal,
+	"fatal":   LevelFatal,
+}
+
+// bridgeFormat describes the keys used in JSON log lines written by another
+// logging library.
+type bridgeFormat struct {
+	levelKey   string
+	messageKey string
+	timeKey    string
+}
+
+// bridgeWriter is an [io.Writer] that parses JSON log lines written by another
+// logging library, and logs each one as a record with the same level,
+// message, time and fields. Lines that aren't JSON objects are logged as the
+// message of a record at info level.
+type bridgeWriter struct {
+	logger *slog.Logger
+	format bridgeFormat
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func newBridgeWriter(logger *slog.Logger, format bridgeFormat) *bridgeWriter {
+	if logger == nil {
+		logger = L()
+	}
+	return &bridgeWriter{logger: logger, format: format}
+}
+
+func (w *bridgeWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// Sync logs any partial line that has been written.
+func (w *bridgeWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.logLine(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// logLine logs a single line.
+func (w *bridgeWriter) logLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	r, err := w.parse(line)
+	if err != nil {
+		r = slog.NewRecord(time.Now(), slog.LevelInfo, string(line), 0)
+	}
+
+	ctx := context.Background()
+	if w.logger.Enabled(ctx, r.Level) {
+		_ = w.logger.Handler().Handle(ctx, r)
+	}
+}
+
+// parse converts a JSON object into a record.
+func (w *bridgeWriter) parse(line []byte) (slog.Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return slog.Record{}, errors.New("not a JSON object")
+	}
+	attrs, err := decodeJSONAttrs(dec)
+	if err != nil {
+		return slog.Record{}, err
+	}
+
+	var (
+		level   = slog.LevelInfo
+		message string
+		when    time.Time
+		fields  []slog.Attr
+	)
+	for _, a := range attrs {
+		switch a.Key {
+		case w.format.levelKey:
+			if l, ok := bridgeLevels[strings.ToLower(a.Value.String())]; ok {
+				level = l
+			}
+		case w.format.messageKey:
+			message = a.Value.String()
+		case w.format.timeKey:
+			when = bridgeTime(a.Value)
+		default:
+			fields = append(fields, a)
+		}
+	}
+	if when.IsZero() {
+		when = time.Now()
+	}
+
+	r := slog.NewRecord(when, level, message, 0)
+	r.AddAttrs(fields...)
+	return r, nil
+}
+
+// bridgeTime parses a timestamp, which may be formatted as RFC 3339 or be a
+// number of seconds since the Unix epoch. It returns the zero time if the
+// value can't be parsed.
+func bridgeTime(v slog.Value) time.Time {
+	switch v.Kind() {
+	case slog.KindString:
+		t, _ := time.Parse(time.RFC3339Nano, v.String())
+		return t
+	case slog.KindInt64:
+		return time.Unix(v.Int64(), 0)
+	case slog.KindFloat64:
+		// Float64 can't represent more than microsecond precision for
+		// current times, so round to avoid spurious nanoseconds.
+		sec, frac := math.Modf(v.Float64())
+		return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3)
+	default:
+		return time.Time{}
+	}
+}
+
+// decodeJSONAttrs decodes the members of a JSON object as attributes, in the
+// order they appear. The opening brace must already have been read.
+func decodeJSONAttrs(dec *json.Decoder) ([]slog.Attr, error) {
+	var attrs []slog.Attr
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, errors.New("invalid object key")
+		}
+
+		value, err := decodeJSONValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: value})
+	}
+
+	// Consume the closing brace.
+	_, err := dec.Token()
+	return attrs, err
+}
+
+// decodeJSONValue decodes a single JSON value. Objects become groups, and
+// integers are kept as integers.
+func decodeJSONValue(dec *json.Decoder) (slog.Value, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+
+	switch v := t.(type) {
+	case json.Delim:
+		if v == '{' {
+			attrs, err := decodeJSONAttrs(dec)
+			return slog.GroupValue(attrs...), err
+		}
+
+		var values []any
+		for dec.More() {
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			values = append(values, value.Any())
+		}
+		_, err := dec.Token()
+		return slog.AnyValue(values), err
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return slog.Int64Value(i), nil
+		}
+		f, err := v.Float64()
+		return slog.Float64Value(f), err
+	default:
+		return slog.AnyValue(v), nil
+	}
+}
+
+// NewZerologWriter returns an [io.Writer] that parses the JSON events written
+// by github.com/rs/zerolog, and logs them through slogflags with the same
+// levels and fields instead of writing them as opaque strings. Records are
+// logged using the given logger, or [L] if it is nil:
+//
+//	logger := zerolog.New(slogflags.NewZerologWriter(nil)).With().Timestamp().Logger()
+//
+// Timestamps may be formatted as RFC 3339, or as Unix times in seconds.
+func NewZerologWriter(logger *slog.Logger) io.Writer {
+	return newBridgeWriter(logger, bridgeFormat{
+		levelKey:   "level",
+		messageKey: "message",
+		timeKey:    "time",
+	})
+}
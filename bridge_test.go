@@ -0,0 +1,64 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BridgeWriter_PartialLines(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	b := newBridgeWriter(LoggerForTest(w), bridgeFormat{levelKey: "level", messageKey: "msg"})
+
+	_, _ = io.WriteString(b, `{"level":"warn","msg":"One"}`+"\n"+`{"level":"info",`)
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=One\n", w.String())
+
+	_, _ = io.WriteString(b, `"msg":"Two"}`+"\nPlain text")
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=One\ntime=2026-01-02T03:04:05.000Z level=INFO msg=Two\n", w.String())
+
+	_ = b.Sync()
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=One\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Two\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"Plain text\"\n", w.String())
+}
+
+func Test_BridgeWriter_PreservesTime(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	b := newBridgeWriter(Logger(WithWriter(w)), bridgeFormat{levelKey: "level", messageKey: "msg", timeKey: "ts"})
+	_, _ = io.WriteString(b, `{"level":"info","msg":"Hi","ts":1700000000.5}`+"\n")
+
+	assert.Contains(t, w.String(), `"time":"`+time.Unix(1700000000, 5e8).Format(time.RFC3339Nano)+`"`)
+}
+
+func Test_BridgeTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	assert.True(t, want.Equal(bridgeTime(slog.StringValue("2024-01-02T03:04:05.600000Z"))))
+	assert.True(t, want.Equal(bridgeTime(slog.Float64Value(float64(want.UnixMilli())/1000))))
+	assert.True(t, want.Truncate(time.Second).Equal(bridgeTime(slog.Int64Value(want.Unix()))))
+	assert.True(t, bridgeTime(slog.StringValue("yesterday")).IsZero())
+}
+
+func Test_ZerologWriter(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	zw := NewZerologWriter(LoggerForTest(w))
+	_, _ = io.WriteString(zw, `{"level":"info","user":{"id":7,"admin":false},"time":1700000000,"message":"Signed in"}`+"\n")
+	_, _ = io.WriteString(zw, `{"level":"trace","message":"Hidden"}`+"\n")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=\"Signed in\" user.id=7 user.admin=false\n", w.String())
+}
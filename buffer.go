@@ -0,0 +1,72 @@
+package slogflags
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+// bufferedWriter buffers data written to it, writing it to the underlying
+// writer when the buffer fills up or at a fixed interval, whichever happens
+// first.
+type bufferedWriter struct {
+	mu  sync.Mutex
+	buf *bufio.Writer
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func newBufferedWriter(w io.Writer, size int, flushInterval time.Duration) *bufferedWriter {
+	b := &bufferedWriter{
+		buf:  bufio.NewWriterSize(w, size),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	if flushInterval > 0 {
+		go b.run(flushInterval)
+	} else {
+		close(b.done)
+	}
+	return b
+}
+
+func (b *bufferedWriter) run(interval time.Duration) {
+	defer close(b.done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-t.C:
+			_ = b.Flush()
+		}
+	}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (b *bufferedWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Flush()
+}
+
+// Close stops the periodic flush and writes any buffered data. It does not
+// close the underlying writer.
+func (b *bufferedWriter) Close() error {
+	b.once.Do(func() { close(b.stop) })
+	<-b.done
+	return b.Flush()
+}
@@ -0,0 +1,80 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BufferedWriter_FlushesWhenFull(t *testing.T) {
+	out := new(bytes.Buffer)
+	w := newBufferedWriter(out, 16, 0)
+
+	_, err := io.WriteString(w, "0123456789")
+	require.NoError(t, err)
+	assert.Empty(t, out.String())
+
+	_, err = io.WriteString(w, "0123456789")
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789012345", out.String())
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, "01234567890123456789", out.String())
+}
+
+func Test_BufferedWriter_FlushesAtInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	w := newBufferedWriter(f, 4096, 10*time.Millisecond)
+	defer w.Close()
+
+	_, err = io.WriteString(w, "Test\n")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		b, _ := os.ReadFile(path)
+		return string(b) == "Test\n"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func Test_Close_WritesBufferedOutputToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.output", path)
+	_ = flag.Set("log.buffer-size", "4096")
+	_ = flag.Set("log.flush-interval", "1h")
+	defer flag.Set("log.output", "")
+	defer flag.Set("log.buffer-size", "0")
+	defer flag.Set("log.flush-interval", "1s")
+
+	l := Logger()
+	l.Info("Test")
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, b)
+
+	require.NoError(t, Flush())
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(b), "level=INFO msg=Test\n"))
+
+	l.Info("Again")
+	require.NoError(t, Close())
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(b), "\n"))
+	assert.Contains(t, string(b), "msg=Again")
+}
@@ -0,0 +1,39 @@
+package slogflags
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"strconv"
+)
+
+// readBuildInfo is replaced in tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// buildInfoAttr returns a "build" group containing the main module's version,
+// and the VCS revision and whether the working tree was dirty when it was
+// built, where known. It reports false if no build information is available.
+func buildInfoAttr() (slog.Attr, bool) {
+	info, ok := readBuildInfo()
+	if !ok {
+		return slog.Attr{}, false
+	}
+
+	var attrs []slog.Attr
+	if info.Main.Version != "" {
+		attrs = append(attrs, slog.String("version", info.Main.Version))
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			attrs = append(attrs, slog.String("revision", s.Value))
+		case "vcs.modified":
+			dirty, _ := strconv.ParseBool(s.Value)
+			attrs = append(attrs, slog.Bool("dirty", dirty))
+		}
+	}
+	if len(attrs) == 0 {
+		return slog.Attr{}, false
+	}
+
+	return slog.Attr{Key: "build", Value: slog.GroupValue(attrs...)}, true
+}
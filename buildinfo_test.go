@@ -0,0 +1,67 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeBuildInfo(t *testing.T, info *debug.BuildInfo) {
+	old := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return info, info != nil
+	}
+	t.Cleanup(func() {
+		readBuildInfo = old
+	})
+}
+
+func Test_WithBuildInfo_LogsStartupRecord(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	fakeBuildInfo(t, &debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app", Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithBuildInfo(true))
+	l.Info("Running")
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"Build information\" build.version=v1.2.3 build.revision=abc123 build.dirty=true\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Running\n", w.String())
+}
+
+func Test_WithBuildInfo_AllRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	fakeBuildInfo(t, &debug.BuildInfo{Main: debug.Module{Path: "example.com/app", Version: "v1.2.3"}})
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithBuildInfo(true), WithBuildInfoOnAllRecords(true))
+	l.Info("Running")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Running build.version=v1.2.3\n", w.String())
+}
+
+func Test_WithBuildInfo_Unavailable(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	fakeBuildInfo(t, nil)
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w, WithBuildInfo(true))
+
+	assert.Empty(t, w.String())
+}
@@ -0,0 +1,82 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// callerSkipHandler is a [log/slog.Handler] that moves the source location of
+// each record up the stack by a number of frames, so that records logged by
+// wrapper functions are attributed to the wrapper's caller. It must be called
+// synchronously by the logging goroutine.
+type callerSkipHandler struct {
+	next slog.Handler
+	skip int
+}
+
+func newCallerSkipHandler(next slog.Handler, skip int) slog.Handler {
+	if h, ok := next.(*callerSkipHandler); ok {
+		next = h.next
+		skip += h.skip
+	}
+	if skip <= 0 {
+		return next
+	}
+	return &callerSkipHandler{next: next, skip: skip}
+}
+
+func (h *callerSkipHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *callerSkipHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.PC != 0 {
+		r.PC = skipCallers(r.PC, h.skip)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *callerSkipHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &callerSkipHandler{next: h.next.WithAttrs(attrs), skip: h.skip}
+}
+
+func (h *callerSkipHandler) WithGroup(name string) slog.Handler {
+	return &callerSkipHandler{next: h.next.WithGroup(name), skip: h.skip}
+}
+
+// skipCallers finds pc on the calling goroutine's stack, and returns the
+// program counter the given number of frames above it. If pc isn't on the
+// stack, or the stack isn't deep enough, pc is returned unchanged.
+func skipCallers(pc uintptr, skip int) uintptr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	for i := range pcs {
+		if pcs[i] == pc {
+			if i+skip < len(pcs) {
+				return pcs[i+skip]
+			}
+			break
+		}
+	}
+	return pc
+}
+
+// SkipCallers returns a child of the given logger whose records have their
+// source location moved up the stack by n frames. This allows packages that
+// wrap a logger to report their caller, rather than themselves, in the
+// source attribute:
+//
+//	var logger = slogflags.SkipCallers(slogflags.L(), 1)
+//
+//	func Audit(user, action string) {
+//		logger.Info("Audit", "user", user, "action", action)
+//	}
+//
+// Skips are cumulative, so a wrapper around another wrapper's logger only
+// needs to skip its own frames. Wrapper functions that are inlined by the
+// compiler don't have their own frame, and should be marked with a
+// `//go:noinline` directive.
+func SkipCallers(logger *slog.Logger, n int) *slog.Logger {
+	return slog.New(newCallerSkipHandler(logger.Handler(), n))
+}
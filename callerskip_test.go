@@ -0,0 +1,86 @@
+package slogflags
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func logViaWrapper(l *slog.Logger) {
+	l.Info("Hello")
+}
+
+//go:noinline
+func logViaWrapperWrapper(l *slog.Logger) {
+	logViaWrapper(l)
+}
+
+// sourceLine returns the line number in the source attribute of a single JSON
+// record.
+func sourceLine(t *testing.T, w *bytes.Buffer) int {
+	t.Helper()
+	var record struct {
+		Source slog.Source `json:"source"`
+	}
+	require.NoError(t, json.Unmarshal(w.Bytes(), &record))
+	return record.Source.Line
+}
+
+func Test_WithCallerSkip(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := Logger(WithWriter(w), WithAddSource(true), WithCallerSkip(1))
+	_, _, line, _ := runtime.Caller(0)
+	logViaWrapper(l)
+
+	assert.Equal(t, line+1, sourceLine(t, w))
+}
+
+func Test_SkipCallers(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := SkipCallers(Logger(WithWriter(w), WithAddSource(true)), 1)
+	_, _, line, _ := runtime.Caller(0)
+	logViaWrapper(l)
+
+	assert.Equal(t, line+1, sourceLine(t, w))
+}
+
+func Test_SkipCallers_Cumulative(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := SkipCallers(Logger(WithWriter(w), WithAddSource(true), WithCallerSkip(1)), 1).With("a", 1)
+	_, _, line, _ := runtime.Caller(0)
+	logViaWrapperWrapper(l)
+
+	assert.Equal(t, line+1, sourceLine(t, w))
+}
+
+func Test_SkipCallers_TooDeep(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := SkipCallers(Logger(WithWriter(w), WithAddSource(true)), 1000)
+	_, _, line, _ := runtime.Caller(0)
+	l.Info("Hello")
+
+	assert.Equal(t, line+1, sourceLine(t, w))
+}
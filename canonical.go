@@ -0,0 +1,202 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// canonicalLineKey is the context key used to store canonical log lines.
+type canonicalLineKey struct{}
+
+// CanonicalLine accumulates attributes throughout a unit of work, such as an
+// HTTP request, so that they can be logged together in a single record once
+// it has completed. This "canonical log line" makes it easy to query and
+// aggregate requests, as everything known about each one is in one place.
+//
+// It's safe to add attributes from multiple goroutines.
+type CanonicalLine struct {
+	mu      sync.Mutex
+	attrs   []slog.Attr
+	capture bool
+	done    bool
+}
+
+// ContextWithCanonicalLine returns a copy of ctx containing a new
+// [CanonicalLine]. Attributes can be added to it using the returned value or
+// [AddCanonicalAttrs].
+func ContextWithCanonicalLine(ctx context.Context) (context.Context, *CanonicalLine) {
+	line := &CanonicalLine{}
+	return context.WithValue(ctx, canonicalLineKey{}, line), line
+}
+
+// AddCanonicalAttrs adds attributes to the [CanonicalLine] stored in ctx, if
+// there is one. The arguments are interpreted as for [log/slog.Logger.With].
+func AddCanonicalAttrs(ctx context.Context, args ...any) {
+	if line, ok := ctx.Value(canonicalLineKey{}).(*CanonicalLine); ok {
+		line.Add(args...)
+	}
+}
+
+// Add adds attributes to the line. The arguments are interpreted as for
+// [log/slog.Logger.With]. If an attribute with the same key has already been
+// added, it is replaced.
+func (l *CanonicalLine) Add(args ...any) {
+	var r slog.Record
+	r.Add(args...)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r.Attrs(func(a slog.Attr) bool {
+		l.add(a)
+		return true
+	})
+}
+
+// add adds or replaces a single attribute. It must be called with the mutex
+// held.
+func (l *CanonicalLine) add(a slog.Attr) {
+	for i := range l.attrs {
+		if l.attrs[i].Key == a.Key {
+			l.attrs[i] = a
+			return
+		}
+	}
+	l.attrs = append(l.attrs, a)
+}
+
+// Attrs returns the attributes added to the line so far.
+func (l *CanonicalLine) Attrs() []slog.Attr {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return slices.Clone(l.attrs)
+}
+
+// Log logs a single record containing the attributes added to the line,
+// using the given logger or [L] if it is nil.
+func (l *CanonicalLine) Log(ctx context.Context, logger *slog.Logger, level slog.Level, msg string) {
+	l.mu.Lock()
+	l.done = true
+	attrs := slices.Clone(l.attrs)
+	l.mu.Unlock()
+
+	if logger == nil {
+		logger = L()
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// captureRecord adds the attributes of a record to the line, if the line is
+// capturing records and hasn't yet been logged. It reports whether the
+// record was captured.
+func (l *CanonicalLine) captureRecord(r slog.Record, groups []string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.capture || l.done {
+		return false
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		for i := len(groups) - 1; i >= 0; i-- {
+			a = slog.Attr{Key: groups[i], Value: slog.GroupValue(a)}
+		}
+		l.add(a)
+		return true
+	})
+	return true
+}
+
+// CanonicalLogConfig configures [CanonicalLogMiddleware].
+type CanonicalLogConfig struct {
+	// Logger is used to log the canonical line. Defaults to [L].
+	Logger *slog.Logger
+
+	// Level is the level the canonical line is logged at. Defaults to
+	// [log/slog.LevelInfo].
+	Level slog.Level
+
+	// Message is the message of the canonical line. Defaults to
+	// "Canonical log line".
+	Message string
+
+	// Capture, if true, stops records logged with the request's context from
+	// being written individually. Their attributes are added to the
+	// canonical line instead, and their messages are discarded.
+	Capture bool
+}
+
+// CanonicalLogMiddleware wraps an HTTP handler so that each request's context
+// contains a [CanonicalLine], and logs it once the request has been handled.
+// The line has the attributes "method", "path", "status" and "duration", in
+// addition to any added by the handler using [AddCanonicalAttrs].
+func CanonicalLogMiddleware(config CanonicalLogConfig, next http.Handler) http.Handler {
+	msg := config.Message
+	if msg == "" {
+		msg = "Canonical log line"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx, line := ContextWithCanonicalLine(r.Context())
+		line.capture = config.Capture
+		line.Add(
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+		)
+
+		rw := &accessLogWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		line.Add(
+			slog.Int("status", rw.status),
+			slog.Duration("duration", time.Since(start)),
+		)
+		line.Log(ctx, config.Logger, config.Level, msg)
+	})
+}
+
+// canonicalHandler is a [log/slog.Handler] that passes records logged with a
+// context containing a capturing [CanonicalLine] to the line, instead of the
+// next handler.
+type canonicalHandler struct {
+	next   slog.Handler
+	groups []string
+}
+
+func newCanonicalHandler(next slog.Handler) *canonicalHandler {
+	return &canonicalHandler{next: next}
+}
+
+func (h *canonicalHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *canonicalHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if line, ok := ctx.Value(canonicalLineKey{}).(*CanonicalLine); ok && line.captureRecord(r, h.groups) {
+			return nil
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *canonicalHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	return &h2
+}
+
+func (h *canonicalHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	if name != "" {
+		h2.groups = append(slices.Clip(h.groups), name)
+	}
+	return &h2
+}
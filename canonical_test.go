@@ -0,0 +1,70 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CanonicalLine_AddReplacesKeys(t *testing.T) {
+	ctx, line := ContextWithCanonicalLine(context.Background())
+	AddCanonicalAttrs(ctx, "user", "bob", "items", 1)
+	line.Add(slog.Int("items", 3))
+
+	assert.Equal(t, []slog.Attr{slog.String("user", "bob"), slog.Int("items", 3)}, line.Attrs())
+}
+
+func Test_AddCanonicalAttrs_WithoutLine(t *testing.T) {
+	assert.NotPanics(t, func() {
+		AddCanonicalAttrs(context.Background(), "user", "bob")
+	})
+}
+
+var durationPattern = regexp.MustCompile(`duration=[^ \n]+`)
+
+func Test_CanonicalLogMiddleware(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+
+	handler := CanonicalLogMiddleware(CanonicalLogConfig{Logger: l}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.InfoContext(r.Context(), "Loading cart", "items", 2)
+		AddCanonicalAttrs(r.Context(), "user", "bob")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cart", nil))
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"Loading cart\" items=2\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"Canonical log line\" method=POST path=/cart user=bob status=201 duration=X\n",
+		durationPattern.ReplaceAllString(w.String(), "duration=X"))
+}
+
+func Test_CanonicalLogMiddleware_Capture(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+
+	handler := CanonicalLogMiddleware(CanonicalLogConfig{Logger: l, Message: "Request", Capture: true}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.InfoContext(r.Context(), "Loading cart", "items", 2)
+		l.WithGroup("db").InfoContext(r.Context(), "Queried", "rows", 5)
+		l.Info("Unrelated")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/cart", nil))
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Unrelated\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Request method=GET path=/cart items=2 db.rows=5 status=200 duration=X\n",
+		durationPattern.ReplaceAllString(w.String(), "duration=X"))
+}
@@ -0,0 +1,175 @@
+package slogflags
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a [CircuitBreakerSink] when a record is
+// dropped because the circuit is open and there is no fallback sink.
+var ErrCircuitOpen = errors.New("circuit breaker: circuit is open")
+
+// CircuitBreakerConfig configures a [CircuitBreakerSink].
+type CircuitBreakerConfig struct {
+	// Fallback receives records while the circuit is open, and any records
+	// the wrapped sink fails to write. If nil, those records are dropped.
+	Fallback Sink
+
+	// Threshold is the number of consecutive failures that cause the circuit
+	// to open. Defaults to 5.
+	Threshold int
+
+	// Backoff is how long the circuit stays open before a record is sent to
+	// the wrapped sink to see if it has recovered. It doubles each time that
+	// attempt fails. Defaults to 10 seconds.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum time the circuit will stay open before trying
+	// the wrapped sink again. Defaults to 5 minutes.
+	MaxBackoff time.Duration
+}
+
+// CircuitBreakerSink wraps another [Sink], and stops sending records to it
+// after it fails repeatedly. While the circuit is open records are routed to
+// a fallback sink instead. Once the backoff has elapsed the next record is
+// sent to the wrapped sink: if it succeeds the circuit closes and a summary
+// record is written describing the outage, otherwise the circuit opens again
+// for twice as long.
+//
+// Records the wrapped sink reports an error for are also sent to the
+// fallback. As sinks that buffer records may report errors for earlier
+// records, this can result in some records being written to both.
+type CircuitBreakerSink struct {
+	sink   Sink
+	config CircuitBreakerConfig
+
+	mu        sync.Mutex
+	failures  int
+	open      bool
+	openedAt  time.Time
+	retryAt   time.Time
+	backoff   time.Duration
+	rerouted  int
+	trialling bool
+}
+
+// NewCircuitBreakerSink creates a new [CircuitBreakerSink] wrapping the given
+// sink.
+func NewCircuitBreakerSink(sink Sink, config CircuitBreakerConfig) *CircuitBreakerSink {
+	if config.Threshold <= 0 {
+		config.Threshold = 5
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 10 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Minute
+	}
+
+	return &CircuitBreakerSink{
+		sink:    sink,
+		config:  config,
+		backoff: config.Backoff,
+	}
+}
+
+func (s *CircuitBreakerSink) Write(ctx context.Context, r slog.Record) error {
+	s.mu.Lock()
+	trial := false
+	if s.open {
+		if s.trialling || time.Now().Before(s.retryAt) {
+			s.rerouted++
+			s.mu.Unlock()
+			if s.config.Fallback == nil {
+				return ErrCircuitOpen
+			}
+			return s.fallback(ctx, r)
+		}
+		// Only one record is used to check whether the sink has recovered;
+		// any others written in the meantime are still rerouted.
+		s.trialling = true
+		trial = true
+	}
+	s.mu.Unlock()
+
+	err := s.sink.Write(ctx, r)
+
+	s.mu.Lock()
+	if err == nil {
+		var summary *slog.Record
+		if trial {
+			summary = s.summary()
+		}
+		s.failures = 0
+		s.mu.Unlock()
+
+		if summary != nil {
+			return s.sink.Write(ctx, *summary)
+		}
+		return nil
+	}
+
+	s.failures++
+	switch {
+	case trial:
+		s.trialling = false
+		s.backoff = min(s.backoff*2, s.config.MaxBackoff)
+		s.retryAt = time.Now().Add(s.backoff)
+	case !s.open && s.failures >= s.config.Threshold:
+		s.open = true
+		s.openedAt = time.Now()
+		s.retryAt = s.openedAt.Add(s.backoff)
+	}
+	s.rerouted++
+	s.mu.Unlock()
+
+	return errors.Join(err, s.fallback(ctx, r))
+}
+
+// summary closes the circuit and returns a record describing the outage. It
+// must be called with the mutex held.
+func (s *CircuitBreakerSink) summary() *slog.Record {
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "Log sink recovered, circuit closed", 0)
+	r.AddAttrs(
+		slog.Duration("open_for", time.Since(s.openedAt)),
+		slog.Int("failures", s.failures),
+		slog.Int("rerouted", s.rerouted),
+	)
+
+	s.open = false
+	s.trialling = false
+	s.backoff = s.config.Backoff
+	s.rerouted = 0
+	return &r
+}
+
+func (s *CircuitBreakerSink) fallback(ctx context.Context, r slog.Record) error {
+	if s.config.Fallback == nil {
+		return nil
+	}
+	return s.config.Fallback.Write(ctx, r)
+}
+
+// Flush flushes the wrapped and fallback sinks, if they implement [Flusher].
+func (s *CircuitBreakerSink) Flush() error {
+	var errs []error
+	if f, ok := s.sink.(Flusher); ok {
+		errs = append(errs, f.Flush())
+	}
+	if f, ok := s.config.Fallback.(Flusher); ok {
+		errs = append(errs, f.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes both the wrapped sink and the fallback sink.
+func (s *CircuitBreakerSink) Close() error {
+	err := s.sink.Close()
+	if s.config.Fallback != nil {
+		err = errors.Join(err, s.config.Fallback.Close())
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CircuitBreakerSink_OpensAfterThreshold(t *testing.T) {
+	inner := &flakySink{down: true}
+	fallback := &flakySink{}
+	sink := NewCircuitBreakerSink(inner, CircuitBreakerConfig{
+		Fallback:  fallback,
+		Threshold: 2,
+		Backoff:   time.Hour,
+	})
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("one")
+	l.Info("two")
+	inner.setDown(false)
+	l.Info("three")
+
+	require.NoError(t, sink.Close())
+	assert.Empty(t, inner.messages())
+	assert.Equal(t, []string{"one", "two", "three"}, fallback.messages())
+}
+
+func Test_CircuitBreakerSink_ClosesWithSummary(t *testing.T) {
+	inner := &flakySink{down: true}
+	fallback := &flakySink{}
+	sink := NewCircuitBreakerSink(inner, CircuitBreakerConfig{
+		Fallback:  fallback,
+		Threshold: 1,
+		Backoff:   50 * time.Millisecond,
+	})
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "one", 0)
+	assert.Error(t, sink.Write(context.Background(), r))
+
+	// The trial after the first backoff fails, doubling the backoff.
+	time.Sleep(75 * time.Millisecond)
+	r.Message = "two"
+	assert.Error(t, sink.Write(context.Background(), r))
+
+	inner.setDown(false)
+	time.Sleep(50 * time.Millisecond)
+	r.Message = "three"
+	require.NoError(t, sink.Write(context.Background(), r))
+
+	time.Sleep(75 * time.Millisecond)
+	r.Message = "four"
+	require.NoError(t, sink.Write(context.Background(), r))
+	r.Message = "five"
+	require.NoError(t, sink.Write(context.Background(), r))
+
+	require.NoError(t, sink.Flush())
+	assert.Equal(t, []string{"one", "two", "three"}, fallback.messages())
+	assert.Equal(t, []string{"four", "Log sink recovered, circuit closed", "five"}, inner.messages())
+}
+
+func Test_CircuitBreakerSink_DropsWithoutFallback(t *testing.T) {
+	sink := NewCircuitBreakerSink(&flakySink{down: true}, CircuitBreakerConfig{Threshold: 1, Backoff: time.Hour})
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "Test", 0)
+	assert.NotErrorIs(t, sink.Write(context.Background(), r), ErrCircuitOpen)
+	assert.ErrorIs(t, sink.Write(context.Background(), r), ErrCircuitOpen)
+}
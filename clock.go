@@ -0,0 +1,35 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// clockHandler is a [log/slog.Handler] that sets the time of each record
+// using a clock func.
+type clockHandler struct {
+	next  slog.Handler
+	clock func() time.Time
+}
+
+func newClockHandler(next slog.Handler, clock func() time.Time) *clockHandler {
+	return &clockHandler{next: next, clock: clock}
+}
+
+func (h *clockHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *clockHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.Time = h.clock()
+	return h.next.Handle(ctx, r)
+}
+
+func (h *clockHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &clockHandler{next: h.next.WithAttrs(attrs), clock: h.clock}
+}
+
+func (h *clockHandler) WithGroup(name string) slog.Handler {
+	return &clockHandler{next: h.next.WithGroup(name), clock: h.clock}
+}
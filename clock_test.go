@@ -0,0 +1,29 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithClock(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	now := time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC)
+	w := new(bytes.Buffer)
+	l := Logger(WithWriter(w), WithClock(func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}))
+	l.Info("One")
+	l.With("a", 1).WithGroup("g").Info("Two")
+
+	assert.Equal(t, ""+
+		`{"time":"2020-02-29T12:00:01Z","level":"INFO","msg":"One"}`+"\n"+
+		`{"time":"2020-02-29T12:00:02Z","level":"INFO","msg":"Two","a":1}`+"\n", w.String())
+}
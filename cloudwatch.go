@@ -0,0 +1,308 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// cloudWatchMaxBatchEvents is the maximum number of events in a single
+	// PutLogEvents request.
+	cloudWatchMaxBatchEvents = 10000
+
+	// cloudWatchMaxBatchBytes is the maximum size of a single PutLogEvents
+	// request, calculated as the sum of all messages plus 26 bytes per event.
+	cloudWatchMaxBatchBytes = 1048576
+
+	// cloudWatchEventOverhead is the number of bytes added to the size of each
+	// event when calculating the batch size.
+	cloudWatchEventOverhead = 26
+
+	// cloudWatchMaxBatchSpan is the maximum time between the first and last
+	// events in a single request.
+	cloudWatchMaxBatchSpan = 24 * time.Hour
+)
+
+// CloudWatchConfig configures a [CloudWatchSink].
+type CloudWatchConfig struct {
+	// LogGroup is the name of the log group to write to. It will be created
+	// if it does not exist.
+	LogGroup string
+
+	// LogStream is the name of the log stream to write to. It will be created
+	// if it does not exist. Defaults to the hostname.
+	LogStream string
+
+	// Region is the AWS region to use. Defaults to the value of the
+	// AWS_REGION or AWS_DEFAULT_REGION environment variables.
+	Region string
+
+	// Endpoint overrides the URL used for CloudWatch Logs API requests.
+	Endpoint string
+
+	// Credentials is used to obtain credentials to sign requests. Defaults to
+	// [DefaultAWSCredentials].
+	Credentials AWSCredentialsFunc
+
+	// FlushInterval is the maximum amount of time records will be buffered
+	// before they are sent. Defaults to 5 seconds.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a request will be retried if it fails
+	// due to a network error or throttling. Defaults to 3 if zero; a negative
+	// value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the time to wait before the first retry. It doubles
+	// after each subsequent attempt. Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// CloudWatchSink is a [Sink] that writes JSON-encoded records to AWS
+// CloudWatch Logs using the PutLogEvents API.
+//
+// Records are batched according to CloudWatch's limits. Buffered records are
+// only guaranteed to be sent once the sink has been closed.
+type CloudWatchSink struct {
+	config  CloudWatchConfig
+	batcher *batcher[cloudWatchEvent]
+
+	// sequenceToken is only accessed from send, which is never called
+	// concurrently.
+	sequenceToken string
+}
+
+type cloudWatchEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+type cloudWatchError struct {
+	Type                  string `json:"__type"`
+	Message               string `json:"message"`
+	ExpectedSequenceToken string `json:"expectedSequenceToken"`
+	status                int
+}
+
+func (e *cloudWatchError) Error() string {
+	return fmt.Sprintf("%s (status %d): %s", e.Type, e.status, e.Message)
+}
+
+// is checks whether the error is of the given type, ignoring any namespace.
+func (e *cloudWatchError) is(t string) bool {
+	return e.Type == t || strings.HasSuffix(e.Type, "#"+t)
+}
+
+// NewCloudWatchSink creates a new [CloudWatchSink] with the given config.
+func NewCloudWatchSink(config CloudWatchConfig) (*CloudWatchSink, error) {
+	if config.LogGroup == "" {
+		return nil, errors.New("cloudwatch: no log group specified")
+	}
+	if config.LogStream == "" {
+		config.LogStream, _ = os.Hostname()
+	}
+	if config.Region == "" {
+		config.Region = awsRegion()
+	}
+	if config.Region == "" && config.Endpoint == "" {
+		return nil, errors.New("cloudwatch: no region specified")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://logs.%s.amazonaws.com/", config.Region)
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Credentials == nil {
+		config.Credentials = DefaultAWSCredentials(config.Client)
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+
+	s := &CloudWatchSink{config: config}
+	s.batcher = newBatcher(cloudWatchMaxBatchEvents, config.FlushInterval, s.send)
+	return s, nil
+}
+
+func (s *CloudWatchSink) Write(ctx context.Context, r slog.Record) error {
+	message, err := json.Marshal(recordJSON(ctx, r))
+	if err != nil {
+		return fmt.Errorf("cloudwatch: unable to encode record: %w", err)
+	}
+
+	return s.batcher.add(cloudWatchEvent{
+		Timestamp: r.Time.UnixMilli(),
+		Message:   string(message),
+	})
+}
+
+func (s *CloudWatchSink) Flush() error {
+	return s.batcher.sync()
+}
+
+func (s *CloudWatchSink) Close() error {
+	return s.batcher.close()
+}
+
+func (s *CloudWatchSink) send(events []cloudWatchEvent) error {
+	// CloudWatch requires events in a batch to be in chronological order.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+
+	var errs []error
+	for len(events) > 0 {
+		n := s.batchLength(events)
+		if err := s.put(events[:n]); err != nil {
+			errs = append(errs, fmt.Errorf("cloudwatch: unable to send %d records: %w", n, err))
+		}
+		events = events[n:]
+	}
+	return errors.Join(errs...)
+}
+
+// batchLength returns the number of events from the start of the slice that
+// can be sent in a single request.
+func (s *CloudWatchSink) batchLength(events []cloudWatchEvent) int {
+	size := 0
+	for i := range events {
+		size += len(events[i].Message) + cloudWatchEventOverhead
+		if i > 0 && (size > cloudWatchMaxBatchBytes || events[i].Timestamp-events[0].Timestamp > cloudWatchMaxBatchSpan.Milliseconds()) {
+			return i
+		}
+	}
+	return len(events)
+}
+
+func (s *CloudWatchSink) put(events []cloudWatchEvent) error {
+	created := false
+	return withRetries(s.config.MaxRetries, s.config.RetryBackoff, func() error {
+		req := map[string]any{
+			"logGroupName":  s.config.LogGroup,
+			"logStreamName": s.config.LogStream,
+			"logEvents":     events,
+		}
+		if s.sequenceToken != "" {
+			req["sequenceToken"] = s.sequenceToken
+		}
+
+		var res struct {
+			NextSequenceToken string `json:"nextSequenceToken"`
+		}
+		err := s.call("PutLogEvents", req, &res)
+
+		var cwErr *cloudWatchError
+		if errors.As(err, &cwErr) {
+			switch {
+			case cwErr.is("ResourceNotFoundException") && !created:
+				created = true
+				if createErr := s.createDestination(); createErr != nil {
+					return createErr
+				}
+				return retryable(err)
+			case cwErr.is("InvalidSequenceTokenException"):
+				s.sequenceToken = cwErr.ExpectedSequenceToken
+				return retryable(err)
+			case cwErr.is("DataAlreadyAcceptedException"):
+				s.sequenceToken = cwErr.ExpectedSequenceToken
+				return nil
+			case cwErr.is("ThrottlingException"), cwErr.is("ServiceUnavailableException"), retryableStatus(cwErr.status):
+				return retryable(err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		s.sequenceToken = res.NextSequenceToken
+		return nil
+	})
+}
+
+// createDestination creates the log group and stream, ignoring errors if they
+// already exist.
+func (s *CloudWatchSink) createDestination() error {
+	var cwErr *cloudWatchError
+
+	err := s.call("CreateLogGroup", map[string]any{"logGroupName": s.config.LogGroup}, nil)
+	if err != nil && !(errors.As(err, &cwErr) && cwErr.is("ResourceAlreadyExistsException")) {
+		return fmt.Errorf("unable to create log group: %w", err)
+	}
+
+	err = s.call("CreateLogStream", map[string]any{"logGroupName": s.config.LogGroup, "logStreamName": s.config.LogStream}, nil)
+	if err != nil && !(errors.As(err, &cwErr) && cwErr.is("ResourceAlreadyExistsException")) {
+		return fmt.Errorf("unable to create log stream: %w", err)
+	}
+
+	return nil
+}
+
+// call makes a request to the CloudWatch Logs API. Errors returned by the API
+// are returned as a *cloudWatchError.
+func (s *CloudWatchSink) call(action string, request any, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	creds, err := s.config.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "Logs_20140328."+action)
+	signAWSRequest(req, body, creds, s.config.Region, "logs", time.Now())
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return retryable(err)
+	}
+
+	if res.StatusCode >= 300 {
+		cwErr := &cloudWatchError{status: res.StatusCode}
+		_ = json.Unmarshal(resBody, cwErr)
+		return cwErr
+	}
+
+	if response != nil {
+		return json.Unmarshal(resBody, response)
+	}
+	return nil
+}
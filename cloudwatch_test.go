@@ -0,0 +1,147 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCloudWatch struct {
+	mu       sync.Mutex
+	exists   bool
+	token    int
+	actions  []string
+	events   [][]cloudWatchEvent
+	unsigned bool
+}
+
+func (f *fakeCloudWatch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=id/") {
+		f.unsigned = true
+	}
+
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "Logs_20140328.")
+	f.actions = append(f.actions, action)
+
+	var req struct {
+		SequenceToken string            `json:"sequenceToken"`
+		LogEvents     []cloudWatchEvent `json:"logEvents"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	switch action {
+	case "CreateLogGroup":
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, `{"__type":"com.amazonaws.logs#ResourceAlreadyExistsException"}`)
+	case "CreateLogStream":
+		f.exists = true
+		_, _ = fmt.Fprint(w, `{}`)
+	case "PutLogEvents":
+		if !f.exists {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"__type":"ResourceNotFoundException","message":"The specified log stream does not exist."}`)
+			return
+		}
+		if f.token > 0 && req.SequenceToken != fmt.Sprint(f.token) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, `{"__type":"InvalidSequenceTokenException","expectedSequenceToken":"%d"}`, f.token)
+			return
+		}
+		f.token++
+		f.events = append(f.events, req.LogEvents)
+		_, _ = fmt.Fprintf(w, `{"nextSequenceToken":"%d"}`, f.token)
+	}
+}
+
+func newTestCloudWatchSink(t *testing.T, cw *fakeCloudWatch) *CloudWatchSink {
+	server := httptest.NewServer(cw)
+	t.Cleanup(server.Close)
+
+	sink, err := NewCloudWatchSink(CloudWatchConfig{
+		LogGroup:     "group",
+		LogStream:    "stream",
+		Region:       "eu-west-2",
+		Endpoint:     server.URL,
+		RetryBackoff: time.Millisecond,
+		Credentials: func(context.Context) (AWSCredentials, error) {
+			return AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret"}, nil
+		},
+	})
+	require.NoError(t, err)
+	return sink
+}
+
+func Test_CloudWatchSink_CreatesStreamAndSendsEvents(t *testing.T) {
+	cw := &fakeCloudWatch{}
+	sink := newTestCloudWatchSink(t, cw)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One", "arg1", "arg2")
+	require.NoError(t, sink.Close())
+
+	assert.False(t, cw.unsigned)
+	assert.Equal(t, []string{"PutLogEvents", "CreateLogGroup", "CreateLogStream", "PutLogEvents"}, cw.actions)
+	require.Len(t, cw.events, 1)
+	require.Len(t, cw.events[0], 1)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(cw.events[0][0].Message), &record))
+	assert.Equal(t, "One", record["msg"])
+	assert.Equal(t, "arg2", record["arg1"])
+}
+
+func Test_CloudWatchSink_HandlesSequenceTokens(t *testing.T) {
+	cw := &fakeCloudWatch{exists: true, token: 5}
+	sink := newTestCloudWatchSink(t, cw)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+	require.NoError(t, sink.batcher.flush())
+	l.Info("Two")
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []string{"PutLogEvents", "PutLogEvents", "PutLogEvents"}, cw.actions)
+	assert.Len(t, cw.events, 2)
+}
+
+func Test_CloudWatchSink_SplitsLargeBatches(t *testing.T) {
+	cw := &fakeCloudWatch{exists: true}
+	sink := newTestCloudWatchSink(t, cw)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	for i := 0; i < 5; i++ {
+		l.Info(strings.Repeat("x", 300*1024))
+	}
+	require.NoError(t, sink.Close())
+
+	require.Len(t, cw.events, 2)
+	assert.Len(t, cw.events[0], 3)
+	assert.Len(t, cw.events[1], 2)
+}
+
+func Test_CloudWatchSink_SortsEvents(t *testing.T) {
+	cw := &fakeCloudWatch{exists: true}
+	sink := newTestCloudWatchSink(t, cw)
+
+	now := time.Now()
+	require.NoError(t, sink.Write(t.Context(), slog.NewRecord(now, slog.LevelInfo, "Second", 0)))
+	require.NoError(t, sink.Write(t.Context(), slog.NewRecord(now.Add(-time.Second), slog.LevelInfo, "First", 0)))
+	require.NoError(t, sink.Close())
+
+	require.Len(t, cw.events, 1)
+	assert.Contains(t, cw.events[0][0].Message, "First")
+	assert.Contains(t, cw.events[0][1].Message, "Second")
+}
@@ -0,0 +1,135 @@
+// Command slogview reads logs written in JSON format by slogflags (or any
+// other [log/slog.JSONHandler]) and re-renders them in the compact,
+// colourised console format, so that production logs can be read easily:
+//
+//	kubectl logs -f deploy/app | slogview -log.level warn -attr path=/widgets
+//
+// Logs are read from the files given as arguments, or from stdin if there
+// are none (or the argument is "-"). Lines that can't be parsed as records,
+// such as those that aren't JSON objects, are passed through unchanged.
+//
+// Records can be filtered by level using the standard `log.level` flag,
+// which shows everything by default, and by attribute using `-attr
+// key=value`, which may be repeated to require several attributes to match.
+// Attributes within groups are specified using dotted paths, e.g.
+// "-attr http.method=POST". The other `log.*` flags provided by slogflags
+// can also be used, e.g. `-log.format fasttext` to change the output format.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/csmith/slogflags"
+)
+
+// maxLineLength is the longest line that will be read.
+const maxLineLength = 1 << 20
+
+// attrFilters holds the values of the `-attr` flags.
+type attrFilters []attrFilter
+
+func (f *attrFilters) String() string {
+	var s []string
+	for _, a := range *f {
+		s = append(s, a.key+"="+a.value)
+	}
+	return strings.Join(s, ",")
+}
+
+func (f *attrFilters) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("attribute filter %q should be in the form key=value", s)
+	}
+	*f = append(*f, attrFilter{key: key, value: value})
+	return nil
+}
+
+var filters attrFilters
+
+func init() {
+	flag.Var(&filters, "attr", "Only show records with an attribute with the given value, as key=value. May be repeated.")
+}
+
+func main() {
+	flag.Parse()
+
+	handler := slogflags.Handler(
+		slogflags.WithConsole(true),
+		slogflags.WithDefaultLogLevel(slog.Level(math.MinInt)),
+		slogflags.WithWriter(os.Stdout),
+	)
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	failed := false
+	for _, path := range paths {
+		if err := viewFile(path, handler, filters, os.Stdout); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "slogview: %v\n", err)
+			failed = true
+		}
+	}
+
+	if err := slogflags.Close(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "slogview: %v\n", err)
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// viewFile renders the logs in the file at path, or stdin if path is "-".
+func viewFile(path string, handler slog.Handler, filters attrFilters, passthrough io.Writer) error {
+	if path == "-" {
+		return view(os.Stdin, handler, filters, passthrough)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return view(f, handler, filters, passthrough)
+}
+
+// view renders each line read from r that can be parsed as a record, and
+// matches all the filters, using handler. Other lines are written to
+// passthrough.
+func view(r io.Reader, handler slog.Handler, filters attrFilters, passthrough io.Writer) error {
+	ctx := context.Background()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	for scanner.Scan() {
+		record, err := parseRecord(scanner.Bytes())
+		if err != nil {
+			if _, err := fmt.Fprintln(passthrough, scanner.Text()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !handler.Enabled(ctx, record.Level) || !filters.match(record) {
+			continue
+		}
+		if err := handler.Handle(ctx, record); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_view(t *testing.T) {
+	input := strings.Join([]string{
+		`{"time":"2026-01-02T03:04:05Z","level":"DEBUG","msg":"Cache miss","path":"/widgets"}`,
+		`Starting up...`,
+		`{"time":"2026-01-02T03:04:06Z","level":"INFO","msg":"Request finished","path":"/widgets"}`,
+		`{"time":"2026-01-02T03:04:07Z","level":"INFO","msg":"Request finished","path":"/gadgets"}`,
+	}, "\n")
+
+	out := new(bytes.Buffer)
+	handler := slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo})
+	filters := attrFilters{{key: "path", value: "/widgets"}}
+	require.NoError(t, view(strings.NewReader(input), handler, filters, out))
+
+	assert.Equal(t, ""+
+		"Starting up...\n"+
+		"time=2026-01-02T03:04:06.000Z level=INFO msg=\"Request finished\" path=/widgets\n", out.String())
+}
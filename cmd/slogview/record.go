@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/csmith/slogflags"
+)
+
+// parseRecord parses a line of JSON output into a record using
+// [slogflags.ParseRecord], and then adjusts its attributes for display. The
+// source location, if present, is replaced by a "caller" attribute in the
+// form "file:line", and string errors are converted to errors so they're
+// highlighted.
+func parseRecord(line []byte) (slog.Record, error) {
+	r, err := slogflags.ParseRecord(line, slogflags.DecoderConfig{})
+	if err != nil {
+		return slog.Record{}, err
+	}
+
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		switch {
+		case a.Key == slog.SourceKey && a.Value.Kind() == slog.KindGroup:
+			a = sourceAttr(a)
+		case (a.Key == "error" || a.Key == "err") && a.Value.Kind() == slog.KindString:
+			a.Value = slog.AnyValue(errors.New(a.Value.String()))
+		}
+		attrs = append(attrs, a)
+		return true
+	})
+
+	res := slog.NewRecord(r.Time, r.Level, r.Message, 0)
+	res.AddAttrs(attrs...)
+	return res, nil
+}
+
+// sourceAttr converts a source location, as written by
+// [log/slog.JSONHandler], into a "caller" attribute in the form "file:line".
+func sourceAttr(a slog.Attr) slog.Attr {
+	var file, line string
+	for _, ga := range a.Value.Group() {
+		switch ga.Key {
+		case "file":
+			file = ga.Value.String()
+		case "line":
+			line = ga.Value.String()
+		}
+	}
+	if file == "" {
+		return a
+	}
+	return slog.String("caller", file+":"+line)
+}
+
+// attrFilter matches records with an attribute with a particular value.
+type attrFilter struct {
+	key   string
+	value string
+}
+
+// match determines whether the record matches all the filters.
+func (f attrFilters) match(r slog.Record) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	for _, filter := range f {
+		v, ok := findAttr(attrs, filter.key)
+		if !ok || v.String() != filter.value {
+			return false
+		}
+	}
+	return true
+}
+
+// findAttr returns the value of the attribute with the given key, which may
+// be a dotted path to an attribute within groups.
+func findAttr(attrs []slog.Attr, key string) (slog.Value, bool) {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+		if a.Value.Kind() == slog.KindGroup && strings.HasPrefix(key, a.Key+".") {
+			if v, ok := findAttr(a.Value.Group(), key[len(a.Key)+1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return slog.Value{}, false
+}
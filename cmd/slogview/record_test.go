@@ -0,0 +1,62 @@
+package main
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordAttrs(r slog.Record) []slog.Attr {
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+func Test_parseRecord(t *testing.T) {
+	r, err := parseRecord([]byte(`{"time":"2026-01-02T03:04:05.123Z","level":"WARN+2","source":{"function":"main.handle","file":"/src/main.go","line":42},"msg":"Request failed","path":"/widgets","http":{"status":503,"retry":true},"duration":1.5,"tags":["a","b"],"error":"timeout"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 123000000, time.UTC), r.Time)
+	assert.Equal(t, slog.LevelWarn+2, r.Level)
+	assert.Equal(t, "Request failed", r.Message)
+
+	attrs := recordAttrs(r)
+	require.Len(t, attrs, 6)
+	assert.Equal(t, slog.String("caller", "/src/main.go:42"), attrs[0])
+	assert.Equal(t, slog.String("path", "/widgets"), attrs[1])
+	assert.Equal(t, slog.Group("http", slog.Int64("status", 503), slog.Bool("retry", true)), attrs[2])
+	assert.Equal(t, slog.Float64("duration", 1.5), attrs[3])
+	assert.Equal(t, []any{"a", "b"}, attrs[4].Value.Any())
+	assert.EqualError(t, attrs[5].Value.Any().(error), "timeout")
+}
+
+func Test_parseRecord_Invalid(t *testing.T) {
+	for _, line := range []string{"", "plain text", `["array"]`, `{"msg":`, `{"level":"NOTICE","msg":"hello"}`} {
+		_, err := parseRecord([]byte(line))
+		assert.Error(t, err, line)
+	}
+}
+
+func Test_attrFilters(t *testing.T) {
+	r, err := parseRecord([]byte(`{"msg":"hello","path":"/widgets","http":{"method":"GET","status":200}}`))
+	require.NoError(t, err)
+
+	var filters attrFilters
+	assert.True(t, filters.match(r))
+
+	require.NoError(t, filters.Set("path=/widgets"))
+	require.NoError(t, filters.Set("http.status=200"))
+	assert.True(t, filters.match(r))
+
+	require.NoError(t, filters.Set("http.method=POST"))
+	assert.False(t, filters.match(r))
+	assert.Equal(t, "path=/widgets,http.status=200,http.method=POST", filters.String())
+
+	assert.Error(t, filters.Set("path"))
+}
@@ -0,0 +1,97 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+// CaptureCommandOutput sets the Stdout and Stderr of cmd so that each line
+// the command writes is logged as a record, instead of raw output being
+// interleaved with the application's logs. Lines written to stdout are logged
+// at [log/slog.LevelInfo], and lines written to stderr at
+// [log/slog.LevelWarn]. Records have a "cmd" attribute containing the base
+// name of the command. They're logged using the given logger, or [L] if it is
+// nil.
+//
+// CaptureCommandOutput must be called before the command is started. The
+// returned func logs any final output that didn't end with a newline, and
+// should be called once the command has finished:
+//
+//	cmd := exec.Command("terraform", "apply", "-auto-approve")
+//	flush := slogflags.CaptureCommandOutput(cmd, nil)
+//	err := cmd.Run()
+//	flush()
+func CaptureCommandOutput(cmd *exec.Cmd, logger *slog.Logger) func() {
+	if logger == nil {
+		logger = L()
+	}
+
+	name := cmd.Path
+	if len(cmd.Args) > 0 {
+		name = cmd.Args[0]
+	}
+	logger = logger.With("cmd", filepath.Base(name))
+
+	stdout := &lineWriter{logger: logger, level: slog.LevelInfo}
+	stderr := &lineWriter{logger: logger, level: slog.LevelWarn}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return func() {
+		stdout.flush()
+		stderr.flush()
+	}
+}
+
+// lineWriter is an [io.Writer] that logs each line written to it as the
+// message of a record.
+type lineWriter struct {
+	logger *slog.Logger
+	level  slog.Level
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// flush logs any partial line that has been written.
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+}
+
+// log logs a single line, ignoring blank lines.
+func (w *lineWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	w.logger.Log(context.Background(), w.level, string(line))
+}
@@ -0,0 +1,52 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CaptureCommandOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+
+	cmd := exec.Command("sh", "-c", `echo "starting up"; echo; echo "disk nearly full" >&2; printf "done"`)
+	flush := CaptureCommandOutput(cmd, l)
+	require.NoError(t, cmd.Run())
+	flush()
+
+	// The command's stdout and stderr are copied concurrently, so lines from
+	// each may be logged in either order.
+	assert.ElementsMatch(t, []string{
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"starting up\" cmd=sh",
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"disk nearly full\" cmd=sh",
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=done cmd=sh",
+	}, strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n"))
+}
+
+func Test_LineWriter_SplitsWrites(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	lw := &lineWriter{logger: LoggerForTest(w), level: LevelFatal}
+	_, _ = lw.Write([]byte("first pa"))
+	_, _ = lw.Write([]byte("rt\r\nsecond\nthi"))
+	lw.flush()
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=FATAL msg=\"first part\"\n"+
+		"time=2026-01-02T03:04:05.000Z level=FATAL msg=second\n"+
+		"time=2026-01-02T03:04:05.000Z level=FATAL msg=thi\n", w.String())
+}
@@ -0,0 +1,189 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiFaint  = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+)
+
+// consoleHandler is a [log/slog.Handler] that writes records in a compact,
+// colourised format intended for humans reading a terminal, e.g.:
+//
+//	15:04:05.000 INFO Server started port=8080
+type consoleHandler struct {
+	w    io.Writer
+	mu   *sync.Mutex
+	opts slog.HandlerOptions
+	goas []groupOrAttrs
+}
+
+func newConsoleHandler(w io.Writer, opts *slog.HandlerOptions) *consoleHandler {
+	return &consoleHandler{w: w, mu: new(sync.Mutex), opts: *opts}
+}
+
+// isTerminal determines whether w is a terminal that supports colour output.
+// The NO_COLOR environment variable can be used to disable colours.
+func isTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := new(bytes.Buffer)
+
+	if !r.Time.IsZero() {
+		if a := h.replace(nil, slog.Time(slog.TimeKey, r.Time)); !a.Equal(slog.Attr{}) {
+			value := a.Value.String()
+			if a.Value.Kind() == slog.KindTime {
+				value = a.Value.Time().Format("15:04:05.000")
+			}
+			buf.WriteString(ansiFaint + value + ansiReset + " ")
+		}
+	}
+
+	level := h.replace(nil, slog.Any(slog.LevelKey, r.Level)).Value.String()
+	_, _ = fmt.Fprintf(buf, "%s%-5s%s ", consoleLevelColour(r.Level), level, ansiReset)
+	buf.WriteString(ansiBold + r.Message + ansiReset)
+
+	if h.opts.AddSource && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		src := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+		if s, ok := h.replace(nil, slog.Any(slog.SourceKey, src)).Value.Any().(*slog.Source); ok {
+			src = s
+		}
+		_, _ = fmt.Fprintf(buf, " %s%s:%d%s", ansiFaint, src.File, src.Line, ansiReset)
+	}
+
+	resolveRecord(r, h.goas).Attrs(func(a slog.Attr) bool {
+		h.appendAttr(buf, nil, a)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *consoleHandler) appendAttr(buf *bytes.Buffer, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		a = h.replace(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(buf, groups, ga)
+		}
+		return
+	}
+
+	key := strings.Join(append(slices.Clip(groups), a.Key), ".")
+	value := a.Value.String()
+	if needsQuoting(value) {
+		value = strconv.Quote(value)
+	}
+
+	colour := ""
+	if _, ok := a.Value.Any().(error); ok {
+		colour = ansiRed
+	}
+	_, _ = fmt.Fprintf(buf, " %s%s=%s%s%s%s", ansiFaint, key, ansiReset, colour, value, ansiReset)
+}
+
+func (h *consoleHandler) replace(groups []string, a slog.Attr) slog.Attr {
+	if h.opts.ReplaceAttr == nil {
+		return a
+	}
+	return h.opts.ReplaceAttr(groups, a)
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *consoleHandler) with(goa groupOrAttrs) *consoleHandler {
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
+
+// consoleLevelColour returns the ANSI colour used to display a level.
+func consoleLevelColour(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return ansiRed
+	case level >= slog.LevelWarn:
+		return ansiYellow
+	case level >= slog.LevelInfo:
+		return ansiGreen
+	default:
+		return ansiBlue
+	}
+}
+
+// needsQuoting reports whether a value must be quoted to be unambiguous.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
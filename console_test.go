@@ -0,0 +1,88 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConsoleHandler_FormatsRecords(t *testing.T) {
+	w := new(bytes.Buffer)
+	var h slog.Handler = newConsoleHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+	h = h.WithAttrs([]slog.Attr{slog.String("user", "bob")}).WithGroup("req")
+
+	r := slog.NewRecord(time.Date(2026, 1, 2, 15, 4, 5, 6e6, time.UTC), slog.LevelWarn, "Slow request", 0)
+	r.AddAttrs(slog.String("path", "/a b"), slog.Any("err", errors.New("timeout")))
+	require.NoError(t, h.Handle(context.Background(), r))
+
+	assert.Equal(t, ""+
+		ansiFaint+"15:04:05.006"+ansiReset+" "+
+		ansiYellow+"WARN "+ansiReset+" "+
+		ansiBold+"Slow request"+ansiReset+
+		" "+ansiFaint+"user="+ansiReset+"bob"+ansiReset+
+		" "+ansiFaint+"req.path="+ansiReset+"\"/a b\""+ansiReset+
+		" "+ansiFaint+"req.err="+ansiReset+ansiRed+"timeout"+ansiReset+"\n",
+		w.String())
+}
+
+func Test_ConsoleHandler_RespectsLevel(t *testing.T) {
+	h := newConsoleHandler(new(bytes.Buffer), &slog.HandlerOptions{Level: slog.LevelWarn})
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+}
+
+func Test_IsTerminal(t *testing.T) {
+	assert.False(t, isTerminal(new(bytes.Buffer)))
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.False(t, isTerminal(f))
+}
+
+func Test_WithDevAndFile(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	path := filepath.Join(t.TempDir(), "debug.log")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithDevAndFile(path))
+	l.Debug("Hidden", "n", 1)
+	l.Info("Shown")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Shown\n", w.String())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "Hidden", record["msg"])
+	assert.Equal(t, float64(1), record["n"])
+	assert.Contains(t, record, "source")
+}
+
+func Test_WithConsole_NotTerminal(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithConsole(true))
+	l.Info("Shown")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Shown\n", w.String())
+}
@@ -0,0 +1,70 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// contextAttrsHandler is a [log/slog.Handler] that adds attributes extracted
+// from each record's context before passing it to the next handler.
+// Attributes are skipped if one with the same key has already been added to
+// the logger using [log/slog.Logger.With], so that values that are also
+// attached to a request-scoped logger aren't duplicated.
+type contextAttrsHandler struct {
+	next slog.Handler
+	fns  []func(ctx context.Context) []slog.Attr
+
+	// keys are the keys of attributes added outside of any group.
+	keys    []string
+	grouped bool
+}
+
+func newContextAttrsHandler(next slog.Handler, fns []func(ctx context.Context) []slog.Attr) *contextAttrsHandler {
+	return &contextAttrsHandler{next: next, fns: fns}
+}
+
+func (h *contextAttrsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextAttrsHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.next.Handle(ctx, r)
+	}
+
+	var attrs []slog.Attr
+	for _, fn := range h.fns {
+		for _, a := range fn(ctx) {
+			if !slices.Contains(h.keys, a.Key) {
+				attrs = append(attrs, a)
+			}
+		}
+	}
+	if len(attrs) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+
+	r = r.Clone()
+	r.AddAttrs(attrs...)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *contextAttrsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	if !h.grouped {
+		h2.keys = slices.Clip(h.keys)
+		for _, a := range attrs {
+			h2.keys = append(h2.keys, a.Key)
+		}
+	}
+	return &h2
+}
+
+func (h *contextAttrsHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.grouped = h.grouped || name != ""
+	return &h2
+}
@@ -0,0 +1,39 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type userIDKey struct{}
+
+func userIDAttrs(ctx context.Context) []slog.Attr {
+	if id, ok := ctx.Value(userIDKey{}).(string); ok {
+		return []slog.Attr{slog.String("user_id", id)}
+	}
+	return nil
+}
+
+func Test_WithContextAttrs(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithContextAttrs(userIDAttrs), WithContextAttrs(func(context.Context) []slog.Attr {
+		return []slog.Attr{slog.String("tenant", "acme")}
+	}))
+
+	ctx := context.WithValue(context.Background(), userIDKey{}, "abc")
+	l.InfoContext(ctx, "With ID", "k", "v")
+	l.Info("Without ID")
+	l.With("a", 1).WithGroup("g").InfoContext(ctx, "Grouped")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=\"With ID\" k=v user_id=abc tenant=acme\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"Without ID\" tenant=acme\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Grouped a=1 g.user_id=abc g.tenant=acme\n", w.String())
+}
@@ -0,0 +1,117 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"path"
+)
+
+// levelKey is the context key used to store a per-context log level.
+type levelKey struct{}
+
+// ContextWithLevel returns a copy of ctx in which records at or above level
+// are logged, even if the level set by the `log.level` flag is higher. This
+// only takes effect for loggers created with [WithContextLevels], and only
+// lowers the level: a level higher than the configured one is ignored.
+func ContextWithLevel(ctx context.Context, level slog.Leveler) context.Context {
+	return context.WithValue(ctx, levelKey{}, level)
+}
+
+// contextLevel returns the lower of level and any level stored in ctx.
+func contextLevel(ctx context.Context, level slog.Level) slog.Level {
+	if ctx == nil {
+		return level
+	}
+	if l, ok := ctx.Value(levelKey{}).(slog.Leveler); ok {
+		return min(level, l.Level())
+	}
+	return level
+}
+
+// contextLevelHandler is a [log/slog.Handler] that is enabled for records at
+// or above a level, or any lower level stored in the context by
+// [ContextWithLevel]. The next handler must be enabled for all levels.
+type contextLevelHandler struct {
+	next  slog.Handler
+	level slog.Leveler
+}
+
+func newContextLevelHandler(next slog.Handler, level slog.Leveler) *contextLevelHandler {
+	return &contextLevelHandler{next: next, level: level}
+}
+
+func (h *contextLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= contextLevel(ctx, h.level.Level()) && h.next.Enabled(ctx, level)
+}
+
+func (h *contextLevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *contextLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	return &h2
+}
+
+func (h *contextLevelHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	return &h2
+}
+
+// DebugConfig configures [DebugMiddleware].
+type DebugConfig struct {
+	// Header is the name of a request header that enables debug logging for
+	// the request, such as "X-Debug-Log". It must have the value given in
+	// Token.
+	Header string
+
+	// Token is the value Header must have. If it is empty, requests can't
+	// enable debug logging using a header, so that clients can't turn it on
+	// at will.
+	Token string
+
+	// Routes are patterns matched against the request path using
+	// [path.Match], such as "/api/orders/*". Debug logging is enabled for
+	// every request to a matching route.
+	Routes []string
+
+	// Level is the level logged for matching requests. Defaults to
+	// [log/slog.LevelDebug].
+	Level slog.Leveler
+}
+
+// DebugMiddleware wraps an HTTP handler so that requests matching the config
+// are logged at a lower level, allowing targeted debugging in production
+// without increasing the volume of logs for every request. The level is
+// stored in the request's context using [ContextWithLevel], so it applies to
+// records logged with that context (e.g. using [log/slog.Logger.DebugContext])
+// by loggers created with [WithContextLevels].
+func DebugMiddleware(config DebugConfig, next http.Handler) http.Handler {
+	level := config.Level
+	if level == nil {
+		level = slog.LevelDebug
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if config.matches(r) {
+			r = r.WithContext(ContextWithLevel(r.Context(), level))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matches reports whether debug logging should be enabled for a request.
+func (c DebugConfig) matches(r *http.Request) bool {
+	if c.Header != "" && c.Token != "" && r.Header.Get(c.Header) == c.Token {
+		return true
+	}
+	for _, route := range c.Routes {
+		if ok, _ := path.Match(route, r.URL.Path); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ContextWithLevel_LowersLevel(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithContextLevels(true))
+
+	l.Debug("Hidden")
+	l.DebugContext(ContextWithLevel(context.Background(), slog.LevelDebug), "Shown")
+	l.DebugContext(ContextWithLevel(context.Background(), slog.LevelWarn), "Also hidden")
+	l.InfoContext(ContextWithLevel(context.Background(), slog.LevelWarn), "Unchanged")
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=DEBUG msg=Shown\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Unchanged\n", w.String())
+}
+
+func Test_ContextWithLevel_IgnoredWhenDisabled(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+
+	l.DebugContext(ContextWithLevel(context.Background(), slog.LevelDebug), "Hidden")
+	assert.Empty(t, w.String())
+}
+
+func Test_ContextWithLevel_FlightRecorder(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithContextLevels(true), WithFlightRecorder(10))
+
+	l.Debug("Retained")
+	l.DebugContext(ContextWithLevel(context.Background(), slog.LevelDebug), "Shown")
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=DEBUG msg=Shown\n", w.String())
+}
+
+func Test_DebugMiddleware(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithContextLevels(true))
+
+	handler := DebugMiddleware(DebugConfig{
+		Header: "X-Debug-Log",
+		Token:  "secret",
+		Routes: []string{"/orders/*"},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.DebugContext(r.Context(), "Debugging", "path", r.URL.Path)
+	}))
+
+	tests := []struct {
+		path  string
+		token string
+		want  bool
+	}{
+		{"/widgets", "", false},
+		{"/widgets", "wrong", false},
+		{"/widgets", "secret", true},
+		{"/orders/123", "", true},
+		{"/orders/123/items", "", false},
+	}
+	for _, tt := range tests {
+		w.Reset()
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("X-Debug-Log", tt.token)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if tt.want {
+			assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=DEBUG msg=Debugging path="+tt.path+"\n", w.String(), tt.path)
+		} else {
+			assert.Empty(t, w.String(), tt.path)
+		}
+	}
+}
+
+func Test_DebugMiddleware_HeaderRequiresToken(t *testing.T) {
+	handler := DebugMiddleware(DebugConfig{Header: "X-Debug-Log"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, slog.LevelInfo, contextLevel(r.Context(), slog.LevelInfo))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Debug-Log", "")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
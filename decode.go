@@ -0,0 +1,115 @@
+package slogflags
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// DecoderConfig configures how records written in the "json" format are
+// decoded by [ParseRecord] and [Decoder].
+type DecoderConfig struct {
+	// CustomLevels are the custom levels that records may use, as passed to
+	// [WithCustomLevels]. Their names are matched case-insensitively.
+	CustomLevels map[string]slog.Level
+}
+
+// ParseRecord parses a single line written in the "json" format (or by any
+// [log/slog.JSONHandler]) back into a record, for example to replay logs into
+// another handler, convert them to another format, or check that records
+// survive a round trip.
+//
+// The time, level and message are taken from the standard keys, and all other
+// keys become attributes in the order they appear, with nested objects
+// becoming groups. Values are decoded as strings, booleans, integers (if they
+// have no fractional part), floats, or slices of values, so types such as
+// durations and errors that were encoded as numbers or strings are decoded
+// as such. The source location, if present, is kept as a "source" group, as
+// records can only refer to their source using a program counter.
+func ParseRecord(line []byte, config DecoderConfig) (slog.Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return slog.Record{}, errors.New("not a JSON object")
+	}
+	attrs, err := decodeJSONAttrs(dec)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	if dec.More() {
+		return slog.Record{}, errors.New("unexpected data after JSON object")
+	}
+
+	var (
+		level   slog.Level
+		message string
+		when    time.Time
+		fields  []slog.Attr
+	)
+	for _, a := range attrs {
+		switch a.Key {
+		case slog.TimeKey:
+			if when, err = time.Parse(time.RFC3339Nano, a.Value.String()); err != nil {
+				return slog.Record{}, fmt.Errorf("invalid time %q", a.Value.String())
+			}
+		case slog.LevelKey:
+			if level, err = ParseLevel(a.Value.String(), config.CustomLevels); err != nil {
+				return slog.Record{}, err
+			}
+		case slog.MessageKey:
+			message = a.Value.String()
+		default:
+			fields = append(fields, a)
+		}
+	}
+
+	r := slog.NewRecord(when, level, message, 0)
+	r.AddAttrs(fields...)
+	return r, nil
+}
+
+// Decoder reads records written in the "json" format from a stream, one per
+// line. See [ParseRecord] for details of how records are decoded.
+type Decoder struct {
+	scanner *bufio.Scanner
+	config  DecoderConfig
+	line    int
+}
+
+// NewDecoder creates a Decoder that reads records from r.
+func NewDecoder(r io.Reader, config DecoderConfig) *Decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	return &Decoder{scanner: scanner, config: config}
+}
+
+// Decode returns the next record, skipping blank lines. It returns [io.EOF]
+// once all records have been read. If a line can't be parsed, the error
+// gives its line number, and the next call to Decode continues with the
+// following line.
+func (d *Decoder) Decode() (slog.Record, error) {
+	for d.scanner.Scan() {
+		d.line++
+		line := bytes.TrimSpace(d.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		r, err := ParseRecord(line, d.config)
+		if err != nil {
+			return slog.Record{}, fmt.Errorf("decode: line %d: %w", d.line, err)
+		}
+		return r, nil
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return slog.Record{}, err
+	}
+	return slog.Record{}, io.EOF
+}
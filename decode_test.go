@@ -0,0 +1,122 @@
+package slogflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseRecord(t *testing.T) {
+	r, err := ParseRecord([]byte(`{"time":"2026-01-02T03:04:05.123456789Z","level":"WARN+2","msg":"Request failed","path":"/widgets","http":{"status":503,"retry":true},"ratio":0.5,"tags":["a","b"],"user":null}`), DecoderConfig{})
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC), r.Time)
+	assert.Equal(t, slog.LevelWarn+2, r.Level)
+	assert.Equal(t, "Request failed", r.Message)
+
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	require.Len(t, attrs, 5)
+	assert.Equal(t, slog.String("path", "/widgets"), attrs[0])
+	assert.Equal(t, slog.Group("http", slog.Int64("status", 503), slog.Bool("retry", true)), attrs[1])
+	assert.Equal(t, slog.Float64("ratio", 0.5), attrs[2])
+	assert.Equal(t, []any{"a", "b"}, attrs[3].Value.Any())
+	assert.Nil(t, attrs[4].Value.Any())
+}
+
+func Test_ParseRecord_CustomLevels(t *testing.T) {
+	r, err := ParseRecord([]byte(`{"level":"NOTICE","msg":"hello"}`), DecoderConfig{CustomLevels: map[string]slog.Level{"notice": 2}})
+	require.NoError(t, err)
+	assert.Equal(t, slog.Level(2), r.Level)
+	assert.True(t, r.Time.IsZero())
+
+	_, err = ParseRecord([]byte(`{"level":"NOTICE","msg":"hello"}`), DecoderConfig{})
+	assert.Error(t, err)
+}
+
+func Test_ParseRecord_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"plain text",
+		`["array"]`,
+		`{"msg":`,
+		`{"msg":"a"} {"msg":"b"}`,
+		`{"time":"yesterday","msg":"a"}`,
+	} {
+		_, err := ParseRecord([]byte(line), DecoderConfig{})
+		assert.Error(t, err, line)
+	}
+}
+
+func Test_ParseRecord_RoundTrip(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "debug")
+	defer flag.Set("log.level", "")
+
+	levels := map[string]slog.Level{"notice": 2}
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithCustomLevels(levels))
+	l.Debug("Cache miss", "key", "widgets", "elapsed", 3*time.Millisecond)
+	l.Log(t.Context(), 2, "Config reloaded", "changed", true)
+	l.WithGroup("request").Error("Request failed", "status", 500, "error", errors.New("boom"))
+
+	replayed := new(bytes.Buffer)
+	h := slog.NewJSONHandler(replayed, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && len(groups) == 0 && a.Value.Any().(slog.Level) == 2 {
+				return slog.String(slog.LevelKey, "NOTICE")
+			}
+			return a
+		},
+	})
+
+	dec := NewDecoder(bytes.NewReader(w.Bytes()), DecoderConfig{CustomLevels: levels})
+	for {
+		r, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, h.Handle(t.Context(), r))
+	}
+
+	assert.Equal(t, w.String(), replayed.String())
+}
+
+func Test_Decoder(t *testing.T) {
+	input := strings.Join([]string{
+		`{"level":"INFO","msg":"first"}`,
+		``,
+		`not json`,
+		`{"level":"ERROR","msg":"second"}`,
+	}, "\n")
+	dec := NewDecoder(strings.NewReader(input), DecoderConfig{})
+
+	r, err := dec.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "first", r.Message)
+
+	_, err = dec.Decode()
+	assert.ErrorContains(t, err, "decode: line 3:")
+
+	r, err = dec.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "second", r.Message)
+	assert.Equal(t, slog.LevelError, r.Level)
+
+	_, err = dec.Decode()
+	assert.ErrorIs(t, err, io.EOF)
+}
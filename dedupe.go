@@ -0,0 +1,202 @@
+package slogflags
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dedupeEntry tracks duplicates of a record that has been handled.
+type dedupeEntry struct {
+	first time.Time
+	count int
+
+	// The most recent duplicate, and the handler it should be passed to.
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// deduper suppresses identical records that occur within a window of each
+// other. When the window ends, a single record is emitted with a "repeated"
+// attribute giving the number of duplicates that were suppressed.
+type deduper struct {
+	window  time.Duration
+	onError func(err error, r slog.Record)
+
+	mu      sync.Mutex
+	entries map[uint64]*dedupeEntry
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func newDeduper(window time.Duration, onError func(err error, r slog.Record)) *deduper {
+	d := &deduper{
+		window:  window,
+		onError: onError,
+		entries: map[uint64]*dedupeEntry{},
+		stop:    make(chan struct{}),
+	}
+
+	go d.run()
+	return d
+}
+
+func (d *deduper) run() {
+	t := time.NewTicker(d.window)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case now := <-t.C:
+			d.emit(d.expired(now))
+		}
+	}
+}
+
+// expired removes and returns all entries whose window ended before now.
+func (d *deduper) expired(now time.Time) []*dedupeEntry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var res []*dedupeEntry
+	for key, entry := range d.entries {
+		if now.Sub(entry.first) >= d.window {
+			delete(d.entries, key)
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+// emit writes a summary record for each entry that had duplicates.
+func (d *deduper) emit(entries []*dedupeEntry) {
+	slices.SortFunc(entries, func(a, b *dedupeEntry) int {
+		return a.record.Time.Compare(b.record.Time)
+	})
+
+	for _, entry := range entries {
+		if entry.count == 0 {
+			continue
+		}
+
+		r := entry.record.Clone()
+		r.AddAttrs(slog.Int("repeated", entry.count))
+		if err := entry.handler.Handle(entry.ctx, r); err != nil && d.onError != nil {
+			d.onError(err, r)
+		}
+	}
+}
+
+// check records that a record with the given hash has been seen, and
+// determines whether it should be passed on. If the record is a duplicate it
+// is retained so a summary can be written later.
+func (d *deduper) check(key uint64, item dedupeEntry) bool {
+	d.mu.Lock()
+	entry, ok := d.entries[key]
+	if ok && time.Since(entry.first) < d.window {
+		entry.count++
+		entry.ctx, entry.handler, entry.record = item.ctx, item.handler, item.record
+		d.mu.Unlock()
+		return false
+	}
+
+	item.first = time.Now()
+	d.entries[key] = &item
+	d.mu.Unlock()
+
+	if ok {
+		d.emit([]*dedupeEntry{entry})
+	}
+	return true
+}
+
+// flush writes summaries for all pending duplicates.
+func (d *deduper) flush() error {
+	d.mu.Lock()
+	var entries []*dedupeEntry
+	for _, entry := range d.entries {
+		entries = append(entries, entry)
+	}
+	clear(d.entries)
+	d.mu.Unlock()
+
+	d.emit(entries)
+	return nil
+}
+
+// close stops the background goroutine and writes any pending summaries.
+func (d *deduper) close() error {
+	d.once.Do(func() { close(d.stop) })
+	return d.flush()
+}
+
+// dedupeHandler is a [log/slog.Handler] that uses a deduper to suppress
+// duplicate records.
+type dedupeHandler struct {
+	next    slog.Handler
+	deduper *deduper
+	goas    []groupOrAttrs
+}
+
+func newDedupeHandler(next slog.Handler, deduper *deduper) *dedupeHandler {
+	return &dedupeHandler{next: next, deduper: deduper}
+}
+
+func (h *dedupeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *dedupeHandler) Handle(ctx context.Context, r slog.Record) error {
+	keep := h.deduper.check(h.hash(r), dedupeEntry{
+		ctx:     context.WithoutCancel(ctx),
+		handler: h.next,
+		record:  r.Clone(),
+	})
+	if !keep {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// hash calculates a hash of the record's level, message and attributes,
+// including those added to the handler.
+func (h *dedupeHandler) hash(r slog.Record) uint64 {
+	hash := fnv.New64a()
+	_, _ = io.WriteString(hash, strconv.Itoa(int(r.Level)))
+	_, _ = io.WriteString(hash, "\x00"+r.Message)
+	resolveRecord(r, h.goas).Attrs(func(a slog.Attr) bool {
+		_, _ = io.WriteString(hash, "\x00"+a.String())
+		return true
+	})
+	return hash.Sum64()
+}
+
+func (h *dedupeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs), groupOrAttrs{attrs: attrs})
+}
+
+func (h *dedupeHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name), groupOrAttrs{group: name})
+}
+
+func (h *dedupeHandler) with(next slog.Handler, goa groupOrAttrs) *dedupeHandler {
+	h2 := *h
+	h2.next = next
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
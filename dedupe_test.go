@@ -0,0 +1,72 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a [bytes.Buffer] that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func Test_Deduplication_CollapsesIdenticalRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithDeduplication(time.Hour))
+	for range 5 {
+		l.Warn("Retrying", "attempt", 1)
+	}
+	l.Warn("Retrying", "attempt", 2)
+	l.With("attempt", 1).Warn("Retrying")
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Retrying attempt=1\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Retrying attempt=2\n", w.String())
+
+	require.NoError(t, Flush())
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Retrying attempt=1\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Retrying attempt=2\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Retrying attempt=1 repeated=5\n", w.String())
+}
+
+func Test_Deduplication_EmitsSummaryAfterWindow(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithDeduplication(20*time.Millisecond))
+	l.Info("Tick")
+	l.Info("Tick")
+	l.Info("Tick")
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(w.String(), "msg=Tick repeated=2\n")
+	}, time.Second, 5*time.Millisecond)
+
+	l.Info("Tick")
+	assert.Equal(t, 2, strings.Count(w.String(), "msg=Tick\n"))
+}
@@ -0,0 +1,76 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// deterministicTime is the time of every record when [WithDeterministic] is
+// used.
+var deterministicTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func deterministicClock() time.Time {
+	return deterministicTime
+}
+
+// sortedHandler is a [log/slog.Handler] that sorts the attributes of each
+// record by key, including those added to the logger and within groups.
+type sortedHandler struct {
+	next slog.Handler
+	goas []groupOrAttrs
+}
+
+func newSortedHandler(next slog.Handler) *sortedHandler {
+	return &sortedHandler{next: next}
+}
+
+func (h *sortedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *sortedHandler) Handle(ctx context.Context, r slog.Record) error {
+	var attrs []slog.Attr
+	resolveRecord(r, h.goas).Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	res := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	res.AddAttrs(sortAttrs(attrs)...)
+	return h.next.Handle(ctx, res)
+}
+
+func (h *sortedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &sortedHandler{next: h.next, goas: append(slices.Clip(h.goas), groupOrAttrs{attrs: attrs})}
+}
+
+func (h *sortedHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &sortedHandler{next: h.next, goas: append(slices.Clip(h.goas), groupOrAttrs{group: name})}
+}
+
+// sortAttrs returns the attributes sorted by key, with the contents of
+// groups sorted recursively. The sort is stable, so attributes with the same
+// key keep their order.
+func sortAttrs(attrs []slog.Attr) []slog.Attr {
+	res := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			a.Value = slog.GroupValue(sortAttrs(a.Value.Group())...)
+		}
+		res[i] = a
+	}
+	slices.SortStableFunc(res, func(a, b slog.Attr) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return res
+}
@@ -0,0 +1,44 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithDeterministic_Text(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := Logger(WithWriter(w), WithAddSource(true), WithDeterministic(true))
+	l.With("zebra", 1, "apple", 2).WithGroup("g").Info("Hello", "y", 3, slog.Group("x", "d", 4, "c", 5))
+
+	assert.Equal(t, "time=2000-01-01T00:00:00.000Z level=INFO msg=Hello apple=2 g.x.c=5 g.x.d=4 g.y=3 zebra=1\n", w.String())
+}
+
+func Test_WithDeterministic_JSON(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := Logger(WithWriter(w), WithDeterministic(true))
+	l.Info("Hello", "b", 1, "a", 2)
+
+	assert.Equal(t, `{"time":"2000-01-01T00:00:00Z","level":"INFO","msg":"Hello","a":2,"b":1}`+"\n", w.String())
+}
+
+func Test_WithDeterministic_Clock(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := Logger(WithWriter(w), WithDeterministic(true), WithClock(testClock))
+	l.Info("Hello")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Hello\n", w.String())
+}
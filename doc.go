@@ -4,19 +4,88 @@ Package slogflags provides flags to configure [log/slog].
 # Basic usage
 
 Simply call [flag.Parse] and then call [Logger] to obtain a configured slog
-instance. Two new flags will be available to users of your app: `--log.level`
-which accepts a textual level ("debug", "info", "warn" or "error") and
-`--log.format` which accepts either "text" or "json".
+instance. The main flags available to users of your app are
+`--log.level` which accepts a textual level ("debug", "info", "warn" or
+"error"), `--log.format` which accepts "text", "json", "fasttext" (the
+same output as "text", but produced with fewer allocations) or "expanded"
+(multiple lines per record, with expanded errors and stack traces), and
+`--log.output` which accepts "stdout", "stderr", a file path, or the URL of a
+supported remote sink (such as "nats://localhost:4222/subject").
 
 	flag.Parse()
 	logger := slogflags.Logger()
 	logger.Warn("This is not a drill", "key", "value", "etc", "etc)
 
+Where output is collected by something that records the time each line was
+received, such as systemd or a container runtime, the `--log.no-time` flag
+or [WithNoTime] omits the time from each record. Otherwise, times are shown
+in the host's local time zone, unless another is chosen using the
+`--log.timezone` flag (e.g. "UTC" or "Europe/London") or [WithTimeZone].
+
+Code that runs before flags are parsed, such as init funcs, can log using
+[Early]. Records are held in memory until [Logger] is called, and then written
+with their original times and levels. Libraries and packages that need a
+logger before the application has configured one can use [L], which always
+forwards records to the logger created by the most recent call to [Logger].
+
+# Host, process and build attributes
+
+Every record can include the application's name, the machine's hostname and
+the process ID, using [WithAppName], [WithHostname] and [WithPID] or the
+`--log.app`, `--log.hostname` and `--log.pid` flags. In Kubernetes,
+[WithKubernetesMetadata] adds the pod, namespace and node names provided by
+the downward API.
+
+[WithBuildInfo] logs the module version and VCS revision of the running
+binary when the logger is created, so logs can be correlated with releases.
+[WithBuildInfoOnAllRecords] adds them to every record instead.
+
+# Per-level formats
+
+[WithLevelFormat] writes records at or above a level in a different format,
+for example keeping routine records on a single line while writing errors in
+the "expanded" format, with each attribute on its own line, the chain of
+wrapped errors, and a stack trace:
+
+	logger := slogflags.Logger(slogflags.WithLevelFormat(slog.LevelError, "expanded"))
+
 # Custom levels
 
 If you define your own log levels, you can pass them to [Logger] using
 [WithCustomLevels]. Users can then specify them in the `log.level` flag.
 
+The flag also accepts offsets from a named level, such as "info+2", and
+numeric levels. [ParseLevel] parses levels in the same way, so that levels
+from other configuration can be validated consistently.
+
+# Validating configuration
+
+Call [Validate] after [flag.Parse] to check the logging flags before creating
+a logger. All problems are returned together, each prefixed with the name of
+the flag responsible, so they can be reported to the user at once. Remote
+sinks are only contacted if [ValidateConfig.CheckRemote] is set.
+
+To change the configuration of a running application, such as after reloading
+a configuration file, call [Reconfigure] with the new options. Loggers that
+have already been created switch to the new configuration atomically, and the
+previous outputs are closed. Invalid configuration is rejected and leaves the
+existing loggers untouched.
+
+# Sharing a single logger
+
+If several packages call [Logger], each call creates a new logger and
+re-applies global settings such as the level used by the [log] package. Pass
+[WithSingleton](true) to make every such call return the same logger instead;
+a warning is logged if the calls disagree about the options.
+
+# Concurrency
+
+[Logger], [Reconfigure], [Validate] and [Audit] may be called from multiple
+goroutines. Loggers are configured one at a time, so process-wide state such
+as the default logger always matches the logger created last. The flags are
+read while holding the same lock, so tests that run in parallel should change
+them using [SetFlag] rather than [flag.Set].
+
 # Setting as the default logger
 
 Pass [WithSetDefault](true) when calling [Logger] to register the new instance
@@ -34,10 +103,326 @@ log level, which you can alter using [WithOldLogLevel]. e.g.:
 	log.Printf("hi")
 	// Prints: time=... level=WARN msg=hi
 
+Components that need their own [log.Logger], such as [net/http.Server], can
+be given one that logs at a specific level using [NewStdLoggerAt], or
+[NewLogBridge] which also tags each record with the name of the subsystem.
+Libraries such as go-retryablehttp that accept a logger with Error, Warn, Info
+and Debug methods can be given a [LeveledLogger].
+
+The output of subprocesses can be logged line by line using
+[CaptureCommandOutput], so it joins the structured log instead of being
+interleaved with it.
+
+# Other logging libraries
+
+Dependencies that use other logging libraries can be bridged to slogflags
+without it depending on them, so that all output obeys the same flags.
+
+For github.com/go-logr/logr, pass the handler from [NewLogrHandler] to
+logr.FromSlogHandler. It maps logr's verbosity levels onto slog levels.
+
+Libraries that can write JSON to an [io.Writer] are bridged by parsing each
+line and logging it again as a record with the same level, message, time and
+fields. [NewZerologWriter] does this for github.com/rs/zerolog.
+
+Code using go.uber.org/zap can use the core from the separate
+github.com/csmith/slogflags/slogflagszap module, which forwards entries and
+their fields to slogflags without encoding them, and lets zap check the level
+set by `--log.level` before creating entries. Similarly, the separate
+github.com/csmith/slogflags/slogflagshclog module provides a
+github.com/hashicorp/go-hclog logger for HashiCorp libraries such as raft,
+and github.com/csmith/slogflags/slogflagslogrus provides a hook and formatter
+that forward github.com/sirupsen/logrus entries with their fields.
+
+Kubernetes' klog can be given a logger using the handler from
+[NewLogrHandler], with [KlogVerbosity] used to set its `-v` flag to match
+`--log.level`. Older versions of klog, and glog, can write their text output
+to [NewKlogWriter] instead.
+
+# Sinks
+
+As well as writing to a local writer, records can be sent to additional
+destinations by passing a [Sink] to [WithSink]. Sinks receive every record at
+or above the configured level and take care of encoding and delivering them.
+The following sinks are provided:
+
+  - [ElasticsearchSink] writes documents using the Elasticsearch bulk API
+  - [GELFSink] sends messages to Graylog over UDP
+  - [KafkaSink] publishes records to a Kafka topic
+  - [NATSSink] publishes records to a NATS subject, optionally in batches or
+    using JetStream
+  - [WebhookSink] posts batches of records to an HTTP endpoint
+  - [CloudWatchSink] writes records to AWS CloudWatch Logs
+  - [GCPLoggingSink] writes records to Google Cloud Logging
+  - [AzureMonitorSink] writes records to Azure Monitor Logs
+  - [OTLPSink] exports records to an OpenTelemetry collector using OTLP
+  - [ObjectStorageSink] archives compressed records to S3 or Cloud Storage
+
+Sinks often buffer records, so make sure to close them before your application
+exits. Sinks that buffer records implement [Flusher], which can be used to
+send them immediately.
+
+Any sink can be wrapped in a [SpillSink], which spills records to disk while
+the sink is failing and replays them once it recovers. Alternatively, a
+[CircuitBreakerSink] stops using a sink after repeated failures, and sends
+records to a fallback sink until it recovers.
+
+To stop a slow sink from slowing down logging, wrap it in a [QueuedSink],
+which writes records from a bounded queue on a background goroutine. When the
+queue is full it can block, drop the lowest-severity records, or spill
+records to disk. Users can enable this for sinks created by the `--log.output`
+flag with `--log.backpressure`.
+
+# Request IDs
+
+Records logged with a context containing a request ID have a "request_id"
+attribute added automatically. IDs can be added to a context using
+[ContextWithRequestID] and generated with [NewRequestID], or
+[RequestIDMiddleware] can be used to do both for incoming HTTP requests.
+
+[LoggerMiddleware] goes further, storing a logger in each request's context
+with attributes describing the request. Handlers can retrieve it using
+[FromContext].
+
+[AccessLogMiddleware] logs a record for each request with its status, size,
+duration and user agent. Users can switch to the Apache Common or Combined Log
+Formats with the `--log.access-format` flag.
+
+[DebugMiddleware] lowers the log level for requests that carry a secret
+header or match a route pattern, enabling targeted debug output in
+production. The level is stored in the context using [ContextWithLevel], and
+is only honoured by loggers created with [WithContextLevels].
+
+[CanonicalLogMiddleware] logs a single "canonical log line" for each request,
+containing attributes added throughout the request using [AddCanonicalAttrs].
+It can optionally capture the records logged with the request's context, so
+that only the canonical line is written.
+
+# RPCs
+
+[StartRPC] logs the start and end of an RPC, with its method, peer, status
+code and duration, and stores a logger describing the RPC in the context.
+It's designed to be called from small gRPC interceptors, so that slogflags
+doesn't need to depend on gRPC.
+
+gRPC's own internal logging can be routed through slogflags by passing a
+[GRPCLogger] to grpclog.SetLoggerV2.
+
+# Trace correlation
+
+[WithTraceCorrelation] adds the IDs of the active trace and span to records
+logged with a context, so logs can be correlated with traces. Attributes can
+be named following OpenTelemetry, Datadog or Google Cloud conventions.
+Services that don't use a tracing library can store the IDs from an incoming
+traceparent header using [ContextWithTraceParent].
+
+[WithService] adds the service's name, version and environment to every
+record, using the standard fields for the same conventions.
+
+# Redaction
+
+[WithRedactKeys] replaces the values of attributes with certain keys, such as
+"password" or "authorization", before records reach any output or sink. This
+applies within groups, and to fields of structs and maps that are logged
+whole. [WithScrubPatterns] masks text matching regular expressions anywhere
+in messages and string values, with presets such as [ScrubEmails] for common
+kinds of personal information. [WithHashKeys] replaces values such as user
+IDs with a keyed digest, so they can still be correlated. Values wrapped
+with [Secret] are always logged as "[REDACTED]", unless [WithRevealSecrets]
+is used while debugging.
+
+For stricter control, [WithAllowedKeys] drops every attribute that hasn't
+been explicitly allowed, so the set of data that can appear in logs is known
+in advance.
+
+[WithSanitize] escapes line breaks and removes terminal escape sequences in
+messages and values, so that user-controlled input can't forge log lines.
+
+# Audit logs
+
+[Audit] returns a separate logger for business and audit events, so they can
+be written somewhere other than operational logs using the `audit.output`
+flag. Audit records are always JSON, and are never sampled or dropped:
+
+	slogflags.Audit().Info("User deleted", "user", id, "by", admin)
+
+An [AuditSink] writes records as a hash chain, where each record includes the
+hash of the one before it and is optionally signed. [VerifyAuditLog] checks
+the chain, proving that records haven't been modified or removed. The
+`audit.hash-chain` flag makes [Audit] use one, or it can be created directly:
+
+	audit, err := slogflags.NewAuditSink(slogflags.AuditConfig{Writer: file, PrivateKey: key})
+	...
+	audit.Logger().Info("User deleted", "user", id, "by", admin)
+
+# Error reporting
+
+Records at or above a certain level can also be sent to Sentry as events,
+using [NewSentry] and [WithSentry]. Events include the record's attributes and
+the stack trace of the logging goroutine.
+
+Similarly, [NewAlerter] and [WithAlerter] can be used to post records to a
+Slack or Discord webhook, subject to a rate limit.
+
+To react to a sudden increase in errors rather than to each one, use
+[WithErrorBurst]. It calls a func, or logs an "Error burst detected" record,
+when more than a threshold of errors are logged within a window.
+
+# Crashes
+
+Deferring [RecoverAndLog] in main logs any panic at [LevelFatal], with its
+stack trace, and closes all outputs before the application crashes.
+Alternatively, [Main] runs a func and does the same if it panics or returns
+an error, so crashes appear in the structured log stream rather than only on
+stderr.
+
+Similarly, [WithGoroutineDump] logs the stack of every goroutine when the
+process receives SIGQUIT (or other signals), to help diagnose stuck processes
+without losing the output on stderr.
+
+Output that bypasses the logger entirely, such as panics in goroutines that
+aren't recovered, println debugging, and messages from C libraries, can be
+captured using [WithStderrCapture]. It redirects the process's stderr through
+a pipe and logs each line written to it, until [Close] is called. This is
+supported on Linux, macOS, and some BSDs.
+
+# Local development
+
+[WithConsole] writes output in a compact, colourised format when it is going
+to a terminal. The slogview command (github.com/csmith/slogflags/cmd/slogview)
+renders JSON logs in the same format, filtering them by level or attribute,
+so that production logs can be read easily.
+
+Records written in the "json" format can be parsed back into
+[log/slog.Record] values using [ParseRecord], or read from a stream using a
+[Decoder], for example to replay them into another handler or to check that
+records survive a round trip in tests.
+
+[WithDevAndFile] configures a logger suited to local development: output is
+colourised when writing to a terminal, and every record (including debug
+records) is written to a file as JSON for later inspection.
+[WithGoroutineID] adds the ID of the logging goroutine to each record, which
+helps when untangling output from concurrent code.
+
+# Asynchronous logging
+
+[WithAsync] moves formatting and writing of records to a background
+goroutine, so logging adds as little latency as possible to the caller. Call
+[Flush] before your application exits to make sure queued records are
+written.
+
+Writes to the output can also be batched by setting the `--log.buffer-size`
+flag, in which case buffered output is written when the buffer is full or
+after `--log.flush-interval` has elapsed (one second by default). Call
+[Close] before exiting to write any remaining output and close any files or
+sinks opened because of the `--log.output` flag. For logs that must survive
+power loss, the `--log.fsync` flag or [WithFsync] syncs files to disk after
+every write (or after every batch, if output is buffered). [Shutdown] does the same,
+but gives up once a context is done so that a slow sink can't stop the
+application from exiting. Simple binaries can use [WithShutdownOnSignal] to
+do this automatically when they receive SIGINT or SIGTERM.
+
+[WithSequenceNumbers] adds an increasing sequence number to every record, so
+that records delivered out of order by asynchronous outputs or collectors can
+be detected and sorted.
+
+# Sampling
+
+[WithSampling] limits the volume of records produced by hot loops: each
+second, the first few records with a given level and message are written,
+and after that only one in every n. Records above the sampling level are
+never sampled. Sampling can also be enabled by users with the
+`--log.sample-first` and `--log.sample-thereafter` flags.
+
+[WithDeduplication] suppresses records that are identical to one logged
+shortly before, writing a single record with a "repeated" attribute once
+the window has passed.
+
+[WithRateLimit] caps the number of records written for each key derived from
+a record (such as a client's IP address) in a window, and logs a summary of
+how many records were suppressed.
+
+[WithErrorSummary] does the same for repeated errors: the first few with the
+same message (or other key) in each interval are written in full, and the
+rest are counted and reported in a single summary record.
+
+To make sure important records are never lost, [WithNeverDrop] exempts
+records at or above a level from sampling and rate limiting, and from being
+dropped when the queue created by [WithAsync] is full.
+
+The number of records dropped for each of these reasons is available from
+[Stats], and can be logged periodically using [WithDropSummary].
+
+# Metrics
+
+[NewMetrics] and [WithMetrics] count the records written to the output and
+each sink, errors writing them, dropped records, and the depth of any queues.
+[Metrics] serves these in the Prometheus text format, so log storms and
+problems shipping logs can be graphed and alerted on. The same metrics can be
+reported as OpenTelemetry instruments using [Metrics.RegisterOTel].
+
+Basic application metrics can also be derived from structured logs using
+[Metrics.AddLogMetric], which counts matching records or records the values
+of a numeric attribute in a histogram, labelled by other attributes.
+
+# Flight recorder
+
+[WithFlightRecorder] keeps the most recent records that are below the log
+level in memory, and writes them out only if an error is logged. This gives
+full context for failures without writing debug logs all the time. Use
+[ContextWithFlightRecorder] to keep a separate recording for each request.
+
+# Debugging running services
+
+[WithRingBuffer] keeps the most recent records in memory regardless of the
+log level. A [RingBuffer] can be served over HTTP, where the records can be
+filtered by level, attribute and time to see what just happened. Adding
+`follow=1` to the URL streams new records as they are logged, as server-sent
+events or over a WebSocket.
+
+# Tests
+
+[WithTB] writes log output to a test's log, so that it's attached to the
+right test and only shown when the test fails:
+
+	func TestSomething(t *testing.T) {
+		logger := slogflags.Logger(slogflags.WithTB(t))
+		...
+	}
+
+[WithClock] replaces the time of every record, so that the output can be
+compared exactly. [WithDeterministic] goes further, also sorting attributes
+and removing source locations and colours. The slogflagstest package provides an Observer that
+captures records in memory, with queries and assertions on what was logged.
+
+[WithSchema] checks that records have the attributes declared for each event,
+so that changes which would break dashboards or downstream consumers of the
+logs are caught in tests.
+
 # Other advanced usage
 
 You can customise other behaviour of the created logger using
-[WithDefaultLogLevel], [WithWriter], [WithAddSource] and [WithReplaceAttr].
+[WithDefaultLogLevel], [WithWriter], [WithFallbackWriter], [WithErrorHandler],
+[WithAddSource], [WithReplaceAttr], [WithContextAttrs], [WithAttrs] and
+[WithGroup].
 See the documentation for those funcs for more details.
+
+[Handler] returns the configured [log/slog.Handler] instead of a logger, so
+that it can be combined with other handlers or passed to frameworks that
+expect a handler.
+
+Attributes that have the same key as a built-in field, such as "level", are
+moved into a "fields" group to avoid duplicate keys; see
+[WithRenameReservedKeys].
+
+Source locations added by [WithAddSource] contain absolute paths from the
+machine the binary was built on. [WithSourceTrimModuleRoot] makes them
+relative to the module root, or [WithSourceTrimPrefix] removes a fixed
+prefix. Packages that wrap the logger can use [WithCallerSkip] or
+[SkipCallers] so that the source location shows their caller.
+
+Records can be modified before they're written using [WithOnLog], for
+example to add attributes derived from the context, and [WithOnLogged] is
+called after each record is written successfully, such as to count them.
 */
 package slogflags
@@ -0,0 +1,122 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// earlyBufferSize is the maximum number of records buffered by [Early]
+// loggers before [Logger] is called.
+const earlyBufferSize = 1000
+
+// early holds records logged by [Early] loggers until [Logger] is called.
+var early = &earlyState{}
+
+// earlyState buffers records until a handler is bound by [Logger], and then
+// forwards them to it.
+type earlyState struct {
+	mu      sync.Mutex
+	items   []earlyItem
+	dropped int
+	handler slog.Handler
+}
+
+// earlyItem is a record waiting to be replayed.
+type earlyItem struct {
+	ctx    context.Context
+	record slog.Record
+	goas   []groupOrAttrs
+}
+
+// Early returns a logger that can be used before flags have been parsed, such
+// as in init funcs or while parsing config. Records are buffered in memory
+// until [Logger] is called, at which point they are replayed through the
+// configured handler with their original times and levels. Records below the
+// configured level are discarded at that point.
+//
+// After [Logger] has been called, the early logger writes directly to the
+// handler created by the most recent call. At most 1000 records are buffered;
+// if more are logged, a warning is written with the number that were dropped.
+func Early() *slog.Logger {
+	return slog.New(&earlyHandler{state: early})
+}
+
+// bind replays any buffered records through the handler, and sends all
+// future records to it.
+func (s *earlyState) bind(handler slog.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handler = handler
+	for _, item := range s.items {
+		if handler.Enabled(item.ctx, item.record.Level) {
+			_ = handler.Handle(item.ctx, resolveRecord(item.record, item.goas))
+		}
+	}
+
+	if s.dropped > 0 && handler.Enabled(context.Background(), slog.LevelWarn) {
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, "Early log records dropped", 0)
+		r.AddAttrs(slog.Int("dropped", s.dropped))
+		_ = handler.Handle(context.Background(), r)
+	}
+
+	s.items = nil
+	s.dropped = 0
+}
+
+// earlyHandler is a [log/slog.Handler] that buffers records in an earlyState.
+type earlyHandler struct {
+	state *earlyState
+	goas  []groupOrAttrs
+}
+
+func (h *earlyHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	h.state.mu.Lock()
+	handler := h.state.handler
+	h.state.mu.Unlock()
+
+	// Until the logger is configured we don't know which levels are enabled,
+	// so buffer everything.
+	return handler == nil || handler.Enabled(ctx, level)
+}
+
+func (h *earlyHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.state.mu.Lock()
+	handler := h.state.handler
+	if handler == nil {
+		if len(h.state.items) < earlyBufferSize {
+			h.state.items = append(h.state.items, earlyItem{ctx: context.WithoutCancel(ctx), record: r.Clone(), goas: h.goas})
+		} else {
+			h.state.dropped++
+		}
+	}
+	h.state.mu.Unlock()
+
+	if handler == nil {
+		return nil
+	}
+	return handler.Handle(ctx, resolveRecord(r, h.goas))
+}
+
+func (h *earlyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *earlyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *earlyHandler) with(goa groupOrAttrs) *earlyHandler {
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
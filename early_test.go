@@ -0,0 +1,62 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Early_ReplaysRecords(t *testing.T) {
+	early = &earlyState{}
+	defer func() { early = &earlyState{} }()
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	e := Early()
+	e.Debug("Too quiet")
+	e.Info("Starting", "version", 1)
+	e.With("component", "config").WithGroup("file").Warn("Missing", "path", "/etc/app")
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w)
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Starting version=1\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Missing component=config file.path=/etc/app\n", w.String())
+
+	w.Reset()
+	e.Info("After")
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=After\n", w.String())
+}
+
+func Test_Early_ForwardsToMostRecentLogger(t *testing.T) {
+	early = &earlyState{}
+	defer func() { early = &earlyState{} }()
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	e := Early()
+	LoggerForTest(new(bytes.Buffer))
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w)
+	e.Info("Latest")
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Latest\n", w.String())
+	assert.False(t, e.Enabled(t.Context(), -8))
+}
+
+func Test_Early_DropsRecordsOverLimit(t *testing.T) {
+	early = &earlyState{}
+	defer func() { early = &earlyState{} }()
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	e := Early()
+	for range earlyBufferSize + 5 {
+		e.Debug("Spam")
+	}
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w)
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=\"Early log records dropped\" dropped=5\n", w.String())
+}
@@ -0,0 +1,257 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ecsVersion is the version of the Elastic Common Schema that documents
+// produced by [ElasticsearchSink] conform to.
+const ecsVersion = "8.11.0"
+
+// ElasticsearchConfig configures an [ElasticsearchSink].
+type ElasticsearchConfig struct {
+	// URL is the base URL of the Elasticsearch cluster, e.g.
+	// "https://localhost:9200".
+	URL string
+
+	// IndexPrefix is combined with the date of each record to form the name
+	// of the index it is written to, e.g. "logs-2025.05.17". Defaults to
+	// "logs".
+	IndexPrefix string
+
+	// Username and Password are used for HTTP basic authentication, if set.
+	Username string
+	Password string
+
+	// APIKey is sent in the Authorization header, if set. It should be the
+	// base64-encoded form returned by the Elasticsearch API.
+	APIKey string
+
+	// BatchSize is the maximum number of records that will be buffered before
+	// they are sent. Defaults to 500.
+	BatchSize int
+
+	// FlushInterval is the maximum amount of time records will be buffered
+	// before they are sent. Defaults to 5 seconds.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times documents that are rejected with a
+	// retryable status (such as 429 Too Many Requests) will be resent.
+	// Defaults to 3 if zero; a negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the time to wait before the first retry. It doubles
+	// after each subsequent attempt. Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// ElasticsearchSink is a [Sink] that writes records to Elasticsearch using
+// the bulk API. Records are written as documents compatible with the Elastic
+// Common Schema into daily indices.
+type ElasticsearchSink struct {
+	config  ElasticsearchConfig
+	batcher *batcher[esDoc]
+}
+
+type esDoc struct {
+	index string
+	body  []byte
+}
+
+type esBulkResponse struct {
+	Errors bool                            `json:"errors"`
+	Items  []map[string]esBulkResponseItem `json:"items"`
+}
+
+type esBulkResponseItem struct {
+	Status int `json:"status"`
+	Error  struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// NewElasticsearchSink creates a new [ElasticsearchSink] with the given
+// config. Records are buffered in memory and written in the background; the
+// sink must be closed to ensure all records are written.
+func NewElasticsearchSink(config ElasticsearchConfig) *ElasticsearchSink {
+	if config.IndexPrefix == "" {
+		config.IndexPrefix = "logs"
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 500
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+
+	s := &ElasticsearchSink{config: config}
+	s.batcher = newBatcher(config.BatchSize, config.FlushInterval, s.send)
+	return s
+}
+
+// builtInKeys returns the keys of the fields added to each document, which
+// attributes mustn't overwrite.
+func (s *ElasticsearchSink) builtInKeys() []string {
+	return []string{"@timestamp", "message", "ecs"}
+}
+
+func (s *ElasticsearchSink) Write(ctx context.Context, r slog.Record) error {
+	doc := recordAttrs(r)
+	doc["@timestamp"] = r.Time.UTC().Format(time.RFC3339Nano)
+	doc["message"] = r.Message
+	doc["ecs"] = map[string]any{"version": ecsVersion}
+	level := strings.ToLower(levelName(ctx, r.Level))
+	if l, ok := doc["log"].(map[string]any); ok {
+		l["level"] = level
+	} else {
+		doc["log"] = map[string]any{"level": level}
+	}
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("elasticsearch: unable to encode record: %w", err)
+	}
+
+	return s.batcher.add(esDoc{
+		index: fmt.Sprintf("%s-%s", s.config.IndexPrefix, r.Time.UTC().Format("2006.01.02")),
+		body:  body,
+	})
+}
+
+func (s *ElasticsearchSink) Flush() error {
+	return s.batcher.sync()
+}
+
+func (s *ElasticsearchSink) Close() error {
+	return s.batcher.close()
+}
+
+func (s *ElasticsearchSink) send(docs []esDoc) error {
+	var errs []error
+	backoff := s.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, retryReason, err := s.bulk(docs)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		if len(retry) == 0 {
+			return errors.Join(errs...)
+		}
+
+		if attempt >= s.config.MaxRetries {
+			errs = append(errs, fmt.Errorf("elasticsearch: giving up on %d documents after %d attempts: %w", len(retry), attempt+1, retryReason))
+			return errors.Join(errs...)
+		}
+
+		docs = retry
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// bulk sends the documents to the bulk API. It returns any documents that
+// should be retried along with the reason, and an error describing any
+// documents that failed permanently.
+func (s *ElasticsearchSink) bulk(docs []esDoc) ([]esDoc, error, error) {
+	body := new(bytes.Buffer)
+	for i := range docs {
+		action, _ := json.Marshal(map[string]any{"index": map[string]any{"_index": docs[i].index}})
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(docs[i].body)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.config.URL+"/_bulk", body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("elasticsearch: unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.config.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.config.APIKey)
+	} else if s.config.Username != "" {
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return docs, fmt.Errorf("elasticsearch: request failed: %w", err), nil
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return docs, fmt.Errorf("elasticsearch: bulk request returned status %d", res.StatusCode), nil
+	}
+
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, nil, fmt.Errorf("elasticsearch: bulk request returned status %d: %s", res.StatusCode, b)
+	}
+
+	var bulkRes esBulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&bulkRes); err != nil {
+		return nil, nil, fmt.Errorf("elasticsearch: unable to decode bulk response: %w", err)
+	}
+
+	if !bulkRes.Errors {
+		return nil, nil, nil
+	}
+
+	var retry []esDoc
+	var retryReason error
+	var failed int
+	var failedReason string
+	for i, item := range bulkRes.Items {
+		if i >= len(docs) {
+			break
+		}
+
+		for _, result := range item {
+			if result.Status < 300 {
+				continue
+			}
+
+			reason := fmt.Sprintf("%d %s: %s", result.Status, result.Error.Type, result.Error.Reason)
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				retry = append(retry, docs[i])
+				retryReason = errors.New(reason)
+			} else {
+				failed++
+				failedReason = reason
+			}
+		}
+	}
+
+	if failed > 0 {
+		return retry, retryReason, fmt.Errorf("elasticsearch: %d documents were rejected, last error: %s", failed, failedReason)
+	}
+	return retry, retryReason, nil
+}
@@ -0,0 +1,151 @@
+package slogflags
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeElasticsearch struct {
+	mu        sync.Mutex
+	requests  int
+	documents []map[string]any
+	indices   []string
+	reject    func(request, item int) int
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var items []string
+	scanner := bufio.NewScanner(r.Body)
+	for i := 0; scanner.Scan(); i++ {
+		var action map[string]map[string]string
+		_ = json.Unmarshal(scanner.Bytes(), &action)
+		scanner.Scan()
+
+		status := 201
+		if f.reject != nil {
+			status = f.reject(f.requests, i)
+		}
+		if status < 300 {
+			var doc map[string]any
+			_ = json.Unmarshal(scanner.Bytes(), &doc)
+			f.documents = append(f.documents, doc)
+			f.indices = append(f.indices, action["index"]["_index"])
+		}
+		items = append(items, fmt.Sprintf(`{"index":{"status":%d,"error":{"type":"t","reason":"r"}}}`, status))
+	}
+	f.requests++
+
+	_, _ = io.WriteString(w, `{"errors":true,"items":[`+strings.Join(items, ",")+`]}`)
+}
+
+func Test_ElasticsearchSink_WritesECSDocuments(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	es := &fakeElasticsearch{}
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	sink := NewElasticsearchSink(ElasticsearchConfig{URL: server.URL, IndexPrefix: "app"})
+	l := LoggerForTest(io.Discard, WithSink(sink))
+	l.With("user", "bob").WithGroup("req").Warn("Test", "id", 4)
+	assert.NoError(t, sink.Close())
+
+	assert.Equal(t, 1, es.requests)
+	assert.Equal(t, []string{"app-2026.01.02"}, es.indices)
+	assert.Equal(t, "Test", es.documents[0]["message"])
+	assert.Equal(t, "bob", es.documents[0]["user"])
+	assert.Equal(t, map[string]any{"id": float64(4)}, es.documents[0]["req"])
+	assert.Equal(t, map[string]any{"level": "warn"}, es.documents[0]["log"])
+	assert.Equal(t, map[string]any{"version": ecsVersion}, es.documents[0]["ecs"])
+	assert.Contains(t, es.documents[0], "@timestamp")
+}
+
+func Test_ElasticsearchSink_BatchesBySize(t *testing.T) {
+	es := &fakeElasticsearch{}
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	sink := NewElasticsearchSink(ElasticsearchConfig{URL: server.URL, BatchSize: 2, FlushInterval: time.Hour})
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+	l.Info("Two")
+
+	assert.Eventually(t, func() bool {
+		es.mu.Lock()
+		defer es.mu.Unlock()
+		return es.requests == 1 && len(es.documents) == 2
+	}, time.Second, time.Millisecond)
+
+	l.Info("Three")
+	assert.NoError(t, sink.Close())
+	assert.Equal(t, 2, es.requests)
+	assert.Len(t, es.documents, 3)
+}
+
+func Test_ElasticsearchSink_RetriesPartialFailures(t *testing.T) {
+	es := &fakeElasticsearch{reject: func(request, item int) int {
+		if request == 0 && item == 1 {
+			return 429
+		}
+		return 201
+	}}
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	sink := NewElasticsearchSink(ElasticsearchConfig{URL: server.URL, RetryBackoff: time.Millisecond})
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+	l.Info("Two")
+	l.Info("Three")
+	assert.NoError(t, sink.Close())
+
+	assert.Equal(t, 2, es.requests)
+	assert.Len(t, es.documents, 3)
+	assert.Equal(t, "Two", es.documents[2]["message"])
+}
+
+func Test_ElasticsearchSink_NegativeMaxRetriesDisablesRetries(t *testing.T) {
+	es := &fakeElasticsearch{reject: func(request, item int) int {
+		return 429
+	}}
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	sink := NewElasticsearchSink(ElasticsearchConfig{URL: server.URL, MaxRetries: -1})
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+
+	assert.ErrorContains(t, sink.Close(), "giving up on 1 documents after 1 attempts")
+	assert.Equal(t, 1, es.requests)
+}
+
+func Test_ElasticsearchSink_ReportsPermanentFailures(t *testing.T) {
+	es := &fakeElasticsearch{reject: func(request, item int) int {
+		return 400
+	}}
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	sink := NewElasticsearchSink(ElasticsearchConfig{URL: server.URL})
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+
+	assert.ErrorContains(t, sink.Close(), "1 documents were rejected")
+	assert.Equal(t, 1, es.requests)
+}
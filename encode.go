@@ -0,0 +1,125 @@
+package slogflags
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+)
+
+// recordJSON returns a map representing r in the same shape as produced by
+// [log/slog.JSONHandler], suitable for encoding as JSON. The level is named
+// using any custom names carried by ctx.
+func recordJSON(ctx context.Context, r slog.Record) map[string]any {
+	res := recordAttrs(r)
+	if !r.Time.IsZero() {
+		res[slog.TimeKey] = r.Time.Format(time.RFC3339Nano)
+	}
+	res[slog.LevelKey] = levelName(ctx, r.Level)
+	res[slog.MessageKey] = r.Message
+	return res
+}
+
+// lookupAttr finds the value with the given key in a map returned by
+// [recordAttrs]. Keys within groups can be found by separating the group
+// names and key with dots, e.g. "request.id".
+func lookupAttr(m map[string]any, key string) (any, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+
+	for i := range key {
+		if key[i] != '.' {
+			continue
+		}
+		if group, ok := m[key[:i]].(map[string]any); ok {
+			if v, ok := lookupAttr(group, key[i+1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// recordAttrs returns the attributes of r as a map suitable for encoding as
+// JSON. Groups are represented as nested maps.
+func recordAttrs(r slog.Record) map[string]any {
+	res := make(map[string]any, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(res, a)
+		return true
+	})
+	return res
+}
+
+// addAttr adds the resolved value of a to m, following the same conventions as
+// [log/slog.JSONHandler]: empty attributes are ignored, and groups with an
+// empty key are inlined.
+func addAttr(m map[string]any, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+
+		target := m
+		if a.Key != "" {
+			target = make(map[string]any, len(attrs))
+			m[a.Key] = target
+		}
+		for _, ga := range attrs {
+			addAttr(target, ga)
+		}
+		return
+	}
+
+	m[a.Key] = jsonValue(a.Value)
+}
+
+// jsonValue converts a resolved, non-group value into something that can be
+// marshalled sensibly by [encoding/json].
+func jsonValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		// JSON can't represent NaN or infinities, so they are written as
+		// strings rather than failing to encode the whole record.
+		if f := v.Float64(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration().Nanoseconds()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	default:
+		switch x := v.Any().(type) {
+		case error:
+			return x.Error()
+		case json.Marshaler:
+			return x
+		case encoding.TextMarshaler:
+			return x
+		default:
+			if _, err := json.Marshal(x); err != nil {
+				return v.String()
+			}
+			return x
+		}
+	}
+}
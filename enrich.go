@@ -0,0 +1,73 @@
+package slogflags
+
+import (
+	"log/slog"
+	"os"
+)
+
+// enrichmentAttrs returns the attributes describing the host, process,
+// service and build that should be added to every record, according to the options and
+// flags.
+func (c *config) enrichmentAttrs() []slog.Attr {
+	var attrs []slog.Attr
+
+	app := c.appName
+	if *logApp != "" {
+		app = *logApp
+	}
+	if app != "" {
+		attrs = append(attrs, slog.String("app", app))
+	}
+
+	if c.hostname || *logHostname {
+		if hostname, err := os.Hostname(); err == nil {
+			attrs = append(attrs, slog.String("hostname", hostname))
+		}
+	}
+
+	if c.pid || *logPID {
+		attrs = append(attrs, slog.Int("pid", os.Getpid()))
+	}
+
+	attrs = append(attrs, c.service.attrs(c.traceFormat)...)
+
+	if c.kubernetes {
+		if k8s, ok := kubernetesAttr(); ok {
+			attrs = append(attrs, k8s)
+		}
+	}
+
+	if c.buildInfoAllRecords {
+		if build, ok := buildInfoAttr(); ok {
+			attrs = append(attrs, build)
+		}
+	}
+
+	return attrs
+}
+
+// kubernetesEnv maps the environment variables conventionally populated
+// using the Kubernetes downward API to attribute keys.
+var kubernetesEnv = []struct {
+	env string
+	key string
+}{
+	{"POD_NAME", "pod"},
+	{"POD_NAMESPACE", "namespace"},
+	{"NODE_NAME", "node"},
+}
+
+// kubernetesAttr returns a "k8s" group containing the pod name, namespace and
+// node name from the environment. It reports false if none are set.
+func kubernetesAttr() (slog.Attr, bool) {
+	var attrs []slog.Attr
+	for _, e := range kubernetesEnv {
+		if v := os.Getenv(e.env); v != "" {
+			attrs = append(attrs, slog.String(e.key, v))
+		}
+	}
+	if len(attrs) == 0 {
+		return slog.Attr{}, false
+	}
+	return slog.Attr{Key: "k8s", Value: slog.GroupValue(attrs...)}, true
+}
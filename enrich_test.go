@@ -0,0 +1,56 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Enrichment_Options(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	hostname, err := os.Hostname()
+	assert.NoError(t, err)
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithAppName("widgets"), WithHostname(true), WithPID(true))
+	l.Info("Started")
+
+	assert.Equal(t, fmt.Sprintf("time=2026-01-02T03:04:05.000Z level=INFO msg=Started app=widgets hostname=%s pid=%d\n", hostname, os.Getpid()), w.String())
+}
+
+func Test_Enrichment_Flags(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.app", "gadgets")
+	_ = flag.Set("log.pid", "true")
+	defer func() {
+		_ = flag.Set("log.app", "")
+		_ = flag.Set("log.pid", "false")
+	}()
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithAppName("widgets"))
+	l.Info("Started")
+
+	assert.Equal(t, fmt.Sprintf("time=2026-01-02T03:04:05.000Z level=INFO msg=Started app=gadgets pid=%d\n", os.Getpid()), w.String())
+}
+
+func Test_WithKubernetesMetadata(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	t.Setenv("POD_NAME", "widgets-7d9f")
+	t.Setenv("POD_NAMESPACE", "prod")
+	t.Setenv("NODE_NAME", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithKubernetesMetadata(true))
+	l.Info("Started")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Started k8s.pod=widgets-7d9f k8s.namespace=prod\n", w.String())
+}
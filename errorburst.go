@@ -0,0 +1,115 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrorBurst describes a burst of records detected by [WithErrorBurst].
+type ErrorBurst struct {
+	// Count is the number of records logged within the window, including
+	// the one that triggered the burst.
+	Count int
+
+	// Start and End are the times of the first and last records in the
+	// window.
+	Start time.Time
+	End   time.Time
+
+	// Message is the message of the record that triggered the burst.
+	Message string
+}
+
+// ErrorBurstConfig configures [WithErrorBurst].
+type ErrorBurstConfig struct {
+	// Threshold is the number of records that may be logged within the
+	// window before it's considered a burst. Defaults to 10.
+	Threshold int
+
+	// Window is the period that records are counted over. Defaults to one
+	// minute.
+	Window time.Duration
+
+	// Level is the minimum level of records that are counted. Defaults to
+	// [log/slog.LevelError].
+	Level slog.Leveler
+
+	// OnBurst is called when a burst is detected. It's called synchronously
+	// by the logging call that triggered the burst, so should return quickly.
+	// If nil, a record with the message "Error burst detected" is logged at
+	// [log/slog.LevelError] instead.
+	OnBurst func(burst ErrorBurst)
+}
+
+// errorBurstMonitor counts records in a sliding window, and reports a burst
+// when there are more than a threshold. Once a burst has been reported, no
+// further bursts are reported until a full window has passed.
+type errorBurstMonitor struct {
+	config  ErrorBurstConfig
+	handler slog.Handler
+
+	mu       sync.Mutex
+	times    []time.Time
+	reported time.Time
+}
+
+func newErrorBurstMonitor(handler slog.Handler, config ErrorBurstConfig) *errorBurstMonitor {
+	if config.Threshold <= 0 {
+		config.Threshold = 10
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Level == nil {
+		config.Level = slog.LevelError
+	}
+	return &errorBurstMonitor{config: config, handler: handler}
+}
+
+// record counts a record, and reports a burst if necessary.
+func (m *errorBurstMonitor) record(r slog.Record) error {
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	m.mu.Lock()
+	cutoff := now.Add(-m.config.Window)
+	i := 0
+	for i < len(m.times) && !m.times[i].After(cutoff) {
+		i++
+	}
+	m.times = append(m.times[i:], now)
+
+	if len(m.times) <= m.config.Threshold || (!m.reported.IsZero() && now.Sub(m.reported) < m.config.Window) {
+		m.mu.Unlock()
+		return nil
+	}
+
+	m.reported = now
+	burst := ErrorBurst{
+		Count:   len(m.times),
+		Start:   m.times[0],
+		End:     now,
+		Message: r.Message,
+	}
+	m.mu.Unlock()
+
+	if m.config.OnBurst != nil {
+		m.config.OnBurst(burst)
+		return nil
+	}
+
+	br := slog.NewRecord(now, slog.LevelError, "Error burst detected", 0)
+	br.AddAttrs(
+		slog.Int("count", burst.Count),
+		slog.Duration("window", m.config.Window),
+		slog.String("last_message", burst.Message),
+	)
+	if !m.handler.Enabled(context.Background(), br.Level) {
+		return nil
+	}
+	return m.handler.Handle(context.Background(), br)
+}
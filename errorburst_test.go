@@ -0,0 +1,86 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithErrorBurst_CallsOnBurst(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	var bursts []ErrorBurst
+	l := LoggerForTest(new(bytes.Buffer), WithErrorBurst(ErrorBurstConfig{
+		Threshold: 3,
+		Window:    time.Minute,
+		OnBurst:   func(b ErrorBurst) { bursts = append(bursts, b) },
+	}))
+
+	for i := range 3 {
+		l.Error(fmt.Sprintf("Failure %d", i))
+	}
+	l.Warn("Not counted")
+	assert.Empty(t, bursts)
+
+	l.Error("Failure 3")
+	l.Error("Failure 4")
+
+	require.Len(t, bursts, 1)
+	assert.Equal(t, ErrorBurst{Count: 4, Start: testTime, End: testTime, Message: "Failure 3"}, bursts[0])
+}
+
+func Test_WithErrorBurst_SlidingWindow(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	now := testTime
+	var bursts []ErrorBurst
+	l := Logger(WithWriter(new(bytes.Buffer)), WithClock(func() time.Time { return now }), WithErrorBurst(ErrorBurstConfig{
+		Threshold: 2,
+		Window:    10 * time.Second,
+		Level:     slog.LevelWarn,
+		OnBurst:   func(b ErrorBurst) { bursts = append(bursts, b) },
+	}))
+
+	l.Warn("one")
+	now = now.Add(6 * time.Second)
+	l.Warn("two")
+	now = now.Add(6 * time.Second)
+	l.Warn("three")
+	assert.Empty(t, bursts, "first record should have left the window")
+
+	l.Warn("four")
+	require.Len(t, bursts, 1)
+	assert.Equal(t, ErrorBurst{Count: 3, Start: testTime.Add(6 * time.Second), End: now, Message: "four"}, bursts[0])
+
+	now = now.Add(5 * time.Second)
+	l.Warn("five")
+	assert.Len(t, bursts, 1, "bursts should not be reported again within the window")
+
+	now = now.Add(5 * time.Second)
+	l.Warn("six")
+	l.Warn("seven")
+	assert.Len(t, bursts, 2)
+}
+
+func Test_WithErrorBurst_LogsRecord(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithErrorBurst(ErrorBurstConfig{Threshold: 1}))
+	l.Error("one")
+	l.Error("two")
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=one\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=\"Error burst detected\" count=2 window=1m0s last_message=two\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=two\n", w.String())
+}
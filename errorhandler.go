@@ -0,0 +1,52 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// errorHandler is a [log/slog.Handler] that calls a func whenever the next
+// handler fails to handle a record.
+type errorHandler struct {
+	next slog.Handler
+	fn   func(err error, r slog.Record)
+	goas []groupOrAttrs
+}
+
+func newErrorHandler(next slog.Handler, fn func(err error, r slog.Record)) *errorHandler {
+	return &errorHandler{next: next, fn: fn}
+}
+
+func (h *errorHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *errorHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.next.Handle(ctx, r.Clone())
+	if err != nil {
+		h.fn(err, resolveRecord(r, h.goas))
+	}
+	return err
+}
+
+func (h *errorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs), groupOrAttrs{attrs: attrs})
+}
+
+func (h *errorHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name), groupOrAttrs{group: name})
+}
+
+func (h *errorHandler) with(next slog.Handler, goa groupOrAttrs) *errorHandler {
+	h2 := *h
+	h2.next = next
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
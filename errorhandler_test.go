@@ -0,0 +1,48 @@
+package slogflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CallsErrorHandlerWhenSinkFails(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	var errs []error
+	var records []slog.Record
+	sink := &flakySink{}
+	l := LoggerForTest(new(bytes.Buffer), WithSink(sink), WithErrorHandler(func(err error, r slog.Record) {
+		errs = append(errs, err)
+		records = append(records, r)
+	}))
+
+	l.Info("one")
+	sink.setDown(true)
+	l.With("user", "bob").WithGroup("req").Info("two", "id", 1)
+
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "sink is down")
+	assert.Equal(t, "two", records[0].Message)
+	assert.Equal(t, map[string]any{"user": "bob", "req": map[string]any{"id": int64(1)}}, recordAttrs(records[0]))
+}
+
+func Test_CallsErrorHandlerWhenOutputFails(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	var errs []error
+	l := LoggerForTest(&failingWriter{err: errors.New("broken pipe")}, WithFallbackWriter(nil), WithErrorHandler(func(err error, r slog.Record) {
+		errs = append(errs, err)
+	}))
+	l.Info("Test")
+
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "broken pipe")
+}
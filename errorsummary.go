@@ -0,0 +1,201 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrorSummaryConfig configures [WithErrorSummary].
+type ErrorSummaryConfig struct {
+	// Interval is how often summaries are logged. Defaults to one minute.
+	Interval time.Duration
+
+	// Keep is the number of records with the same key that are written in
+	// full in each interval, before further records are counted instead.
+	// Defaults to 3.
+	Keep int
+
+	// Level is the minimum level of records that are aggregated. Defaults
+	// to [log/slog.LevelError].
+	Level slog.Leveler
+
+	// Key returns the key that records are aggregated by, such as an error
+	// code. If it returns an empty string, the record is always written.
+	// The record includes any attributes and groups added to the logger.
+	// Defaults to the record's message.
+	Key func(ctx context.Context, r slog.Record) string
+}
+
+// errorSummaryCount holds the records seen for a key in the current
+// interval.
+type errorSummaryCount struct {
+	message string
+	level   slog.Level
+	last    time.Time
+	count   int
+}
+
+// errorSummarizer counts error records for each key in a fixed interval,
+// letting the first few through. At the end of each interval a summary record
+// is written for each key that had records held back.
+type errorSummarizer struct {
+	config  ErrorSummaryConfig
+	handler slog.Handler
+	onError func(err error, r slog.Record)
+
+	mu     sync.Mutex
+	counts map[string]*errorSummaryCount
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func newErrorSummarizer(handler slog.Handler, config ErrorSummaryConfig, onError func(err error, r slog.Record)) *errorSummarizer {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.Keep <= 0 {
+		config.Keep = 3
+	}
+	if config.Level == nil {
+		config.Level = slog.LevelError
+	}
+	if config.Key == nil {
+		config.Key = func(_ context.Context, r slog.Record) string { return r.Message }
+	}
+
+	s := &errorSummarizer{
+		config:  config,
+		handler: handler,
+		onError: onError,
+		counts:  map[string]*errorSummaryCount{},
+		stop:    make(chan struct{}),
+	}
+
+	go s.run()
+	return s
+}
+
+func (s *errorSummarizer) run() {
+	t := time.NewTicker(s.config.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+			_ = s.flush()
+		}
+	}
+}
+
+// allow counts a record with the given key, and determines whether it should
+// be written in full.
+func (s *errorSummarizer) allow(key string, r slog.Record) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counts[key]
+	if !ok {
+		c = &errorSummaryCount{message: r.Message}
+		s.counts[key] = c
+	}
+	c.count++
+	c.level = max(c.level, r.Level)
+	c.last = r.Time
+	return c.count <= s.config.Keep
+}
+
+// flush starts a new interval, writing summaries for any keys that had
+// records held back in the previous one.
+func (s *errorSummarizer) flush() error {
+	s.mu.Lock()
+	counts := s.counts
+	s.counts = map[string]*errorSummaryCount{}
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	for _, key := range slices.Sorted(maps.Keys(counts)) {
+		c := counts[key]
+		if c.count <= s.config.Keep || !s.handler.Enabled(ctx, c.level) {
+			continue
+		}
+
+		when := c.last
+		if when.IsZero() {
+			when = time.Now()
+		}
+		r := slog.NewRecord(when, c.level, "Error occurred repeatedly", 0)
+		r.AddAttrs(
+			slog.String("key", key),
+			slog.String("message", c.message),
+			slog.Int("count", c.count),
+			slog.Int("suppressed", c.count-s.config.Keep),
+			slog.Duration("interval", s.config.Interval),
+		)
+		if err := s.handler.Handle(ctx, r); err != nil && s.onError != nil {
+			s.onError(err, r)
+		}
+	}
+	return nil
+}
+
+// close stops the background goroutine and writes any pending summaries.
+func (s *errorSummarizer) close() error {
+	s.once.Do(func() { close(s.stop) })
+	return s.flush()
+}
+
+// errorSummaryHandler is a [log/slog.Handler] that uses an errorSummarizer to
+// hold back repeated error records.
+type errorSummaryHandler struct {
+	next       slog.Handler
+	summarizer *errorSummarizer
+	goas       []groupOrAttrs
+}
+
+func newErrorSummaryHandler(next slog.Handler, summarizer *errorSummarizer) *errorSummaryHandler {
+	return &errorSummaryHandler{next: next, summarizer: summarizer}
+}
+
+func (h *errorSummaryHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *errorSummaryHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.summarizer.config.Level.Level() {
+		return h.next.Handle(ctx, r)
+	}
+
+	key := h.summarizer.config.Key(ctx, resolveRecord(r, h.goas))
+	if key != "" && !h.summarizer.allow(key, r) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *errorSummaryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs), groupOrAttrs{attrs: attrs})
+}
+
+func (h *errorSummaryHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name), groupOrAttrs{group: name})
+}
+
+func (h *errorSummaryHandler) with(next slog.Handler, goa groupOrAttrs) *errorSummaryHandler {
+	h2 := *h
+	h2.next = next
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
@@ -0,0 +1,84 @@
+package slogflags
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ErrorSummary_AggregatesRepeatedErrors(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	defer func() { _ = Close() }()
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithErrorSummary(ErrorSummaryConfig{Interval: time.Hour, Keep: 2}))
+	for range 5 {
+		l.Error("Database unavailable")
+		l.Warn("Slow request")
+	}
+	l.Error("Disk full")
+	require.NoError(t, Flush())
+
+	assert.Equal(t, 2, strings.Count(w.String(), "level=ERROR msg=\"Database unavailable\""))
+	assert.Equal(t, 5, strings.Count(w.String(), "msg=\"Slow request\""))
+	assert.Equal(t, 1, strings.Count(w.String(), "msg=\"Disk full\""))
+	assert.Contains(t, w.String(), "time=2026-01-02T03:04:05.000Z level=ERROR msg=\"Error occurred repeatedly\" key=\"Database unavailable\" message=\"Database unavailable\" count=5 suppressed=3 interval=1h0m0s\n")
+	assert.Equal(t, 1, strings.Count(w.String(), "Error occurred repeatedly"))
+}
+
+func Test_ErrorSummary_CustomKey(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	defer func() { _ = Close() }()
+
+	codeKey := func(_ context.Context, r slog.Record) string {
+		var code string
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "code" {
+				code = a.Value.String()
+			}
+			return true
+		})
+		return code
+	}
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithErrorSummary(ErrorSummaryConfig{Interval: time.Hour, Keep: 1, Level: slog.LevelWarn, Key: codeKey}))
+	l.Warn("Upload failed for a.txt", "code", "E42")
+	l.Warn("Upload failed for b.txt", "code", "E42")
+	l.With("code", "E42").Warn("Upload failed for c.txt")
+	l.Warn("Unkeyed")
+	l.Warn("Unkeyed")
+	require.NoError(t, Flush())
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"Upload failed for a.txt\" code=E42\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Unkeyed\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Unkeyed\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"Error occurred repeatedly\" key=E42 message=\"Upload failed for a.txt\" count=3 suppressed=2 interval=1h0m0s\n", w.String())
+}
+
+func Test_ErrorSummary_ResetsEachInterval(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	defer func() { _ = Close() }()
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithErrorSummary(ErrorSummaryConfig{Interval: 20 * time.Millisecond, Keep: 1}))
+	l.Error("Failed")
+	l.Error("Failed")
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(w.String(), "Error occurred repeatedly")
+	}, time.Second, 5*time.Millisecond)
+
+	l.Error("Failed")
+	assert.Equal(t, 2, strings.Count(w.String(), "level=ERROR msg=Failed"))
+}
@@ -0,0 +1,204 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// expandedIndent is the indentation used for each level of detail written by
+// an expandedHandler.
+const expandedIndent = "    "
+
+// expandedHandler is a [log/slog.Handler] that writes each record over
+// multiple lines, intended for humans reading records that need
+// investigating. Each attribute is written on its own line, errors are
+// expanded to show the errors they wrap, and the stack trace of the logging
+// goroutine is included, e.g.:
+//
+//	2026-01-02T03:04:05.000Z ERROR Request failed
+//	    path: /widgets
+//	    error: fetch widgets: connection refused
+//	        caused by: connection refused
+//	    stack:
+//	        main.handle
+//	            /src/main.go:42
+type expandedHandler struct {
+	w    io.Writer
+	mu   *sync.Mutex
+	opts slog.HandlerOptions
+	goas []groupOrAttrs
+}
+
+func newExpandedHandler(w io.Writer, opts *slog.HandlerOptions) *expandedHandler {
+	return &expandedHandler{w: w, mu: new(sync.Mutex), opts: *opts}
+}
+
+func (h *expandedHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *expandedHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := new(bytes.Buffer)
+
+	if !r.Time.IsZero() {
+		if a := h.replace(nil, slog.Time(slog.TimeKey, r.Time)); !a.Equal(slog.Attr{}) {
+			value := a.Value.String()
+			if a.Value.Kind() == slog.KindTime {
+				value = a.Value.Time().Format("2006-01-02T15:04:05.000Z07:00")
+			}
+			buf.WriteString(value + " ")
+		}
+	}
+
+	level := h.replace(nil, slog.Any(slog.LevelKey, r.Level)).Value.String()
+	buf.WriteString(level + " " + r.Message + "\n")
+
+	if h.opts.AddSource && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		src := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+		if s, ok := h.replace(nil, slog.Any(slog.SourceKey, src)).Value.Any().(*slog.Source); ok {
+			src = s
+		}
+		_, _ = fmt.Fprintf(buf, "%ssource: %s:%d\n", expandedIndent, src.File, src.Line)
+	}
+
+	resolveRecord(r, h.goas).Attrs(func(a slog.Attr) bool {
+		h.appendAttr(buf, nil, a)
+		return true
+	})
+
+	if pcs := stackFrom(r.PC); len(pcs) > 0 {
+		buf.WriteString(expandedIndent + "stack:\n")
+		frames := runtime.CallersFrames(pcs)
+		for {
+			frame, more := frames.Next()
+			_, _ = fmt.Fprintf(buf, "%[1]s%[1]s%[2]s\n%[1]s%[1]s%[1]s%[3]s:%[4]d\n", expandedIndent, frame.Function, frame.File, frame.Line)
+			if !more {
+				break
+			}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *expandedHandler) appendAttr(buf *bytes.Buffer, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		a = h.replace(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(buf, groups, ga)
+		}
+		return
+	}
+
+	key := strings.Join(append(slices.Clip(groups), a.Key), ".")
+	appendLabelled(buf, expandedIndent, key, a.Value.String())
+
+	if err, ok := a.Value.Any().(error); ok {
+		appendCauses(buf, err, expandedIndent+expandedIndent)
+	}
+}
+
+// appendLabelled writes a labelled value on a single line, or if the value
+// spans multiple lines, writes the label followed by each line of the value
+// indented further.
+func appendLabelled(buf *bytes.Buffer, indent, label, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(indent + label + ": " + value + "\n")
+		return
+	}
+
+	buf.WriteString(indent + label + ":\n")
+	for line := range strings.Lines(value) {
+		buf.WriteString(indent + expandedIndent + strings.TrimSuffix(line, "\n") + "\n")
+	}
+}
+
+// appendCauses writes the errors wrapped by err, and the errors they wrap in
+// turn, each indented further than the error that wraps it.
+func appendCauses(buf *bytes.Buffer, err error, indent string) {
+	var causes []error
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		causes = e.Unwrap()
+	case interface{ Unwrap() error }:
+		causes = []error{e.Unwrap()}
+	}
+
+	for _, cause := range causes {
+		if cause == nil {
+			continue
+		}
+		appendLabelled(buf, indent, "caused by", cause.Error())
+		appendCauses(buf, cause, indent+expandedIndent)
+	}
+}
+
+// stackFrom finds pc on the calling goroutine's stack, and returns the
+// program counters from it to the bottom of the stack. It returns nil if pc
+// isn't on the stack, for example because the record was handled
+// asynchronously.
+func stackFrom(pc uintptr) []uintptr {
+	if pc == 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, 128)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	if i := slices.Index(pcs, pc); i >= 0 {
+		return pcs[i:]
+	}
+	return nil
+}
+
+func (h *expandedHandler) replace(groups []string, a slog.Attr) slog.Attr {
+	if h.opts.ReplaceAttr == nil {
+		return a
+	}
+	return h.opts.ReplaceAttr(groups, a)
+}
+
+func (h *expandedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *expandedHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *expandedHandler) with(goa groupOrAttrs) *expandedHandler {
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
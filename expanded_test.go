@@ -0,0 +1,84 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExpandedHandler(t *testing.T) {
+	w := new(bytes.Buffer)
+	h := newExpandedHandler(w, &slog.HandlerOptions{})
+	l := slog.New(h).With("app", "test").WithGroup("req")
+
+	r := slog.NewRecord(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelWarn, "Request failed", 0)
+	r.AddAttrs(slog.String("path", "/widgets"), slog.String("body", "line one\nline two"))
+	_ = l.Handler().Handle(context.Background(), r)
+
+	assert.Equal(t, ""+
+		"2026-01-02T03:04:05.000Z WARN Request failed\n"+
+		"    app: test\n"+
+		"    req.path: /widgets\n"+
+		"    req.body:\n"+
+		"        line one\n"+
+		"        line two\n", w.String())
+}
+
+func Test_ExpandedHandler_ExpandsErrors(t *testing.T) {
+	w := new(bytes.Buffer)
+	h := newExpandedHandler(w, &slog.HandlerOptions{})
+
+	inner := fmt.Errorf("dial: %w", errors.New("connection refused"))
+	err := fmt.Errorf("fetch widgets: %w", inner)
+	joined := errors.Join(errors.New("first"), errors.New("second"))
+	r := slog.NewRecord(time.Time{}, slog.LevelError, "Failed", 0)
+	r.AddAttrs(slog.Any("error", err), slog.Any("errors", joined))
+	_ = h.Handle(context.Background(), r)
+
+	assert.Equal(t, ""+
+		"ERROR Failed\n"+
+		"    error: fetch widgets: dial: connection refused\n"+
+		"        caused by: dial: connection refused\n"+
+		"            caused by: connection refused\n"+
+		"    errors:\n"+
+		"        first\n"+
+		"        second\n"+
+		"        caused by: first\n"+
+		"        caused by: second\n", w.String())
+}
+
+func Test_ExpandedHandler_Stack(t *testing.T) {
+	w := new(bytes.Buffer)
+	l := slog.New(newExpandedHandler(w, &slog.HandlerOptions{AddSource: true}))
+	l.Error("Failed")
+
+	_, file, _, _ := runtime.Caller(0)
+	assert.Contains(t, w.String(), "    source: "+file+":")
+	assert.Contains(t, w.String(), "    stack:\n        github.com/csmith/slogflags.Test_ExpandedHandler_Stack\n            "+file+":")
+	assert.Contains(t, w.String(), "        testing.tRunner\n")
+}
+
+func Test_ExpandedHandler_NoStackWhenAsync(t *testing.T) {
+	w := new(bytes.Buffer)
+	h := newExpandedHandler(w, &slog.HandlerOptions{})
+
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	r := slog.NewRecord(time.Time{}, slog.LevelError, "Failed", pcs[0])
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = h.Handle(context.Background(), r)
+	}()
+	<-done
+
+	assert.Equal(t, "ERROR Failed\n", w.String())
+}
@@ -0,0 +1,49 @@
+package slogflags
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// fallbackWriter writes to a primary writer, and falls back to a secondary
+// writer for any writes that fail. A diagnostic is written to the fallback
+// each time the primary writer starts failing.
+type fallbackWriter struct {
+	primary  io.Writer
+	fallback io.Writer
+
+	mu      sync.Mutex
+	failing bool
+}
+
+func newFallbackWriter(primary, fallback io.Writer) io.Writer {
+	if fallback == nil {
+		return primary
+	}
+	return &fallbackWriter{primary: primary, fallback: fallback}
+}
+
+func (w *fallbackWriter) Write(p []byte) (int, error) {
+	n, err := w.primary.Write(p)
+	if err == nil {
+		w.mu.Lock()
+		w.failing = false
+		w.mu.Unlock()
+		return n, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.failing {
+		w.failing = true
+		_, _ = fmt.Fprintf(w.fallback, "slogflags: unable to write to log output, using fallback: %v\n", err)
+	}
+
+	if _, fallbackErr := w.fallback.Write(p); fallbackErr != nil {
+		return n, errors.Join(err, fallbackErr)
+	}
+	return len(p), nil
+}
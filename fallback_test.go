@@ -0,0 +1,51 @@
+package slogflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct {
+	err error
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	return len(p), nil
+}
+
+func Test_WritesToFallbackWriterOnError(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	primary := &failingWriter{err: errors.New("broken pipe")}
+	fallback := new(bytes.Buffer)
+	l := LoggerForTest(primary, WithFallbackWriter(fallback))
+	l.Info("one")
+	l.Info("two")
+
+	primary.err = nil
+	l.Info("three")
+
+	primary.err = errors.New("disk full")
+	l.Info("four")
+
+	assert.Equal(t, ""+
+		"slogflags: unable to write to log output, using fallback: broken pipe\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=one\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=two\n"+
+		"slogflags: unable to write to log output, using fallback: disk full\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=four\n",
+		fallback.String())
+}
+
+func Test_FallbackWriterCanBeDisabled(t *testing.T) {
+	primary := new(bytes.Buffer)
+	assert.Same(t, primary, newFallbackWriter(primary, nil))
+}
@@ -0,0 +1,481 @@
+package slogflags
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// fastTextPool holds buffers used to format records. Buffers that grow
+// beyond fastTextMaxBuffer are not returned to the pool, so that one very
+// large record doesn't pin memory forever.
+var fastTextPool = sync.Pool{New: func() any {
+	b := make([]byte, 0, 1024)
+	return &b
+}}
+
+const fastTextMaxBuffer = 64 << 10
+
+// fastTextHandler is a [log/slog.Handler] that writes records in the same
+// format as [log/slog.TextHandler], but is optimised to avoid allocations by
+// using pooled buffers and formatting numbers, times and durations directly
+// into them.
+type fastTextHandler struct {
+	w    io.Writer
+	mu   *sync.Mutex
+	opts slog.HandlerOptions
+
+	// Attributes added with WithAttrs, already formatted.
+	preformatted []byte
+
+	// Groups added with WithGroup, and the corresponding key prefix.
+	groups []string
+	prefix string
+}
+
+func newFastTextHandler(w io.Writer, opts *slog.HandlerOptions) *fastTextHandler {
+	return &fastTextHandler{w: w, mu: new(sync.Mutex), opts: *opts}
+}
+
+func (h *fastTextHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *fastTextHandler) Handle(_ context.Context, r slog.Record) error {
+	bufp := fastTextPool.Get().(*[]byte)
+	buf := (*bufp)[:0]
+
+	rep := h.opts.ReplaceAttr
+
+	if !r.Time.IsZero() {
+		if rep == nil {
+			buf = append(buf, "time="...)
+			buf = appendRFC3339Millis(buf, r.Time)
+		} else {
+			buf, _ = h.appendAttr(buf, nil, "", slog.Time(slog.TimeKey, r.Time.Round(0)))
+		}
+	}
+
+	if rep == nil {
+		buf = appendSep(buf)
+		buf = append(buf, "level="...)
+		buf = appendTextString(buf, r.Level.String())
+	} else {
+		buf, _ = h.appendAttr(buf, nil, "", slog.Any(slog.LevelKey, r.Level))
+	}
+
+	if h.opts.AddSource {
+		src := &slog.Source{}
+		if r.PC != 0 {
+			frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+			src = &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+		}
+		buf, _ = h.appendAttr(buf, nil, "", slog.Any(slog.SourceKey, src))
+	}
+
+	if rep == nil {
+		buf = appendSep(buf)
+		buf = append(buf, "msg="...)
+		buf = appendTextString(buf, r.Message)
+	} else {
+		buf, _ = h.appendAttr(buf, nil, "", slog.String(slog.MessageKey, r.Message))
+	}
+
+	if len(h.preformatted) > 0 {
+		buf = appendSep(buf)
+		buf = append(buf, h.preformatted...)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		buf, _ = h.appendAttr(buf, h.groups, h.prefix, a)
+		return true
+	})
+	buf = append(buf, '\n')
+
+	h.mu.Lock()
+	_, err := h.w.Write(buf)
+	h.mu.Unlock()
+
+	if cap(buf) <= fastTextMaxBuffer {
+		*bufp = buf
+		fastTextPool.Put(bufp)
+	}
+	return err
+}
+
+// appendAttr formats an attribute, calling ReplaceAttr if necessary and
+// flattening groups. It reports whether anything was appended.
+func (h *fastTextHandler) appendAttr(buf []byte, groups []string, prefix string, a slog.Attr) ([]byte, bool) {
+	a.Value = a.Value.Resolve()
+	if rep := h.opts.ReplaceAttr; rep != nil && a.Value.Kind() != slog.KindGroup {
+		a = rep(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return buf, false
+	}
+
+	if a.Value.Kind() == slog.KindAny {
+		if src, ok := a.Value.Any().(*slog.Source); ok {
+			if *src == (slog.Source{}) {
+				return buf, false
+			}
+			buf = appendKey(buf, prefix, a.Key)
+			if needsTextQuoting(src.File) {
+				return strconv.AppendQuote(buf, src.File+":"+strconv.Itoa(src.Line)), true
+			}
+			buf = append(buf, src.File...)
+			buf = append(buf, ':')
+			return strconv.AppendInt(buf, int64(src.Line), 10), true
+		}
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return buf, false
+		}
+
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+			prefix = prefix + a.Key + "."
+		}
+
+		pos := len(buf)
+		appended := false
+		for _, ga := range attrs {
+			var ok bool
+			buf, ok = h.appendAttr(buf, groups, prefix, ga)
+			appended = appended || ok
+		}
+		if !appended {
+			return buf[:pos], false
+		}
+		return buf, true
+	}
+
+	buf = appendKey(buf, prefix, a.Key)
+	return appendTextValue(buf, a.Value), true
+}
+
+func (h *fastTextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	buf := slices.Clone(h.preformatted)
+	for _, a := range attrs {
+		buf, _ = h.appendAttr(buf, h.groups, h.prefix, a)
+	}
+	h2.preformatted = buf
+	return &h2
+}
+
+func (h *fastTextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendSep appends a space if buf isn't empty.
+func appendSep(buf []byte) []byte {
+	if len(buf) > 0 {
+		return append(buf, ' ')
+	}
+	return buf
+}
+
+// appendKey appends a separator, the prefixed key, and an equals sign.
+func appendKey(buf []byte, prefix, key string) []byte {
+	buf = appendSep(buf)
+	switch {
+	case prefix == "":
+		buf = appendTextString(buf, key)
+	case !needsTextQuoting(prefix) && !needsTextQuoting(key):
+		buf = append(buf, prefix...)
+		buf = append(buf, key...)
+	default:
+		buf = strconv.AppendQuote(buf, prefix+key)
+	}
+	return append(buf, '=')
+}
+
+// appendTextValue appends a value in the same format as
+// [log/slog.TextHandler].
+func appendTextValue(buf []byte, v slog.Value) (res []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(v.Any()); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				res = appendTextString(buf, "<nil>")
+			} else {
+				res = appendTextString(buf, fmt.Sprintf("!PANIC: %v", r))
+			}
+		}
+	}()
+
+	switch v.Kind() {
+	case slog.KindString:
+		return appendTextString(buf, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return strconv.AppendFloat(buf, v.Float64(), 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		return appendDuration(buf, v.Duration())
+	case slog.KindTime:
+		return appendRFC3339Millis(buf, v.Time())
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case encoding.TextMarshaler:
+			data, err := x.MarshalText()
+			if err != nil {
+				return appendTextString(buf, fmt.Sprintf("!ERROR:%v", err))
+			}
+			return appendTextString(buf, string(data))
+		case []byte:
+			return strconv.AppendQuote(buf, string(x))
+		}
+	}
+
+	return appendTextString(buf, fmt.Sprintf("%+v", v.Any()))
+}
+
+// appendTextString appends s, quoting it if necessary.
+func appendTextString(buf []byte, s string) []byte {
+	if needsTextQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// needsTextQuoting reports whether s needs to be quoted when written by
+// [log/slog.TextHandler].
+func needsTextQuoting(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			if r < ' ' || r == ' ' || r == '=' || r == '"' {
+				return true
+			}
+			continue
+		}
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendRFC3339Millis appends t in RFC 3339 format with millisecond
+// precision, e.g. "2006-01-02T15:04:05.000Z07:00".
+func appendRFC3339Millis(buf []byte, t time.Time) []byte {
+	_, offset := t.Zone()
+	secs := t.Unix() + int64(offset)
+	days, rem := secs/86400, secs%86400
+	if rem < 0 {
+		days--
+		rem += 86400
+	}
+
+	year, month, day := civilFromDays(days)
+	if year < 0 || year > 9999 {
+		// Let the time package deal with unusual years.
+		n := len(buf)
+		const prefixLen = len("2006-01-02T15:04:05.000")
+		t = t.Truncate(time.Millisecond).Add(time.Millisecond / 10)
+		buf = t.AppendFormat(buf, time.RFC3339Nano)
+		return append(buf[:n+prefixLen], buf[n+prefixLen+1:]...)
+	}
+
+	buf = appendDigits(buf, int(year), 4)
+	buf = append(buf, '-')
+	buf = appendDigits(buf, month, 2)
+	buf = append(buf, '-')
+	buf = appendDigits(buf, day, 2)
+	buf = append(buf, 'T')
+	buf = appendDigits(buf, int(rem/3600), 2)
+	buf = append(buf, ':')
+	buf = appendDigits(buf, int(rem/60%60), 2)
+	buf = append(buf, ':')
+	buf = appendDigits(buf, int(rem%60), 2)
+	buf = append(buf, '.')
+	buf = appendDigits(buf, t.Nanosecond()/int(time.Millisecond), 3)
+
+	if offset == 0 {
+		return append(buf, 'Z')
+	}
+
+	offset /= 60
+	if offset < 0 {
+		buf = append(buf, '-')
+		offset = -offset
+	} else {
+		buf = append(buf, '+')
+	}
+	buf = appendDigits(buf, offset/60, 2)
+	buf = append(buf, ':')
+	return appendDigits(buf, offset%60, 2)
+}
+
+// civilFromDays converts a number of days since 1970-01-01 into a year,
+// month and day in the proleptic Gregorian calendar.
+func civilFromDays(days int64) (year int64, month, day int) {
+	// See https://howardhinnant.github.io/date_algorithms.html#civil_from_days
+	days += 719468
+	era := days / 146097
+	if days < 0 && days%146097 != 0 {
+		era--
+	}
+	doe := days - era*146097
+	yoe := (doe - doe/1460 + doe/36524 - doe/146096) / 365
+	doy := doe - (365*yoe + yoe/4 - yoe/100)
+	mp := (5*doy + 2) / 153
+
+	day = int(doy - (153*mp+2)/5 + 1)
+	month = int(mp + 3)
+	if month > 12 {
+		month -= 12
+	}
+	year = yoe + era*400
+	if month <= 2 {
+		year++
+	}
+	return year, month, day
+}
+
+// appendDigits appends a non-negative integer, zero-padded to width digits.
+func appendDigits(buf []byte, n, width int) []byte {
+	var digits [20]byte
+	i := len(digits)
+	for n > 0 || i > len(digits)-width {
+		i--
+		digits[i] = byte('0' + n%10)
+		n /= 10
+	}
+	return append(buf, digits[i:]...)
+}
+
+// appendDuration appends d in the same format as [time.Duration.String].
+func appendDuration(buf []byte, d time.Duration) []byte {
+	// The longest duration is "-2562047h47m16.854775808s".
+	var arr [32]byte
+	w := len(arr)
+
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+
+	if u < uint64(time.Second) {
+		var prec int
+		w--
+		arr[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			return append(buf, "0s"...)
+		case u < uint64(time.Microsecond):
+			prec = 0
+			arr[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			w-- // 'µ' is two bytes.
+			copy(arr[w:], "µ")
+		default:
+			prec = 6
+			arr[w] = 'm'
+		}
+		w, u = appendDurationFrac(arr[:w], u, prec)
+		w = appendDurationInt(arr[:w], u)
+	} else {
+		w--
+		arr[w] = 's'
+		w, u = appendDurationFrac(arr[:w], u, 9)
+		w = appendDurationInt(arr[:w], u%60)
+		u /= 60
+		if u > 0 {
+			w--
+			arr[w] = 'm'
+			w = appendDurationInt(arr[:w], u%60)
+			u /= 60
+			if u > 0 {
+				w--
+				arr[w] = 'h'
+				w = appendDurationInt(arr[:w], u)
+			}
+		}
+	}
+
+	if neg {
+		w--
+		arr[w] = '-'
+	}
+	return append(buf, arr[w:]...)
+}
+
+// appendDurationFrac formats the fraction of v/10**prec (e.g. ".12345") into
+// the tail of buf, omitting trailing zeros. It returns the index where the
+// output begins, and v/10**prec.
+func appendDurationFrac(buf []byte, v uint64, prec int) (int, uint64) {
+	w := len(buf)
+	printed := false
+	for range prec {
+		digit := v % 10
+		printed = printed || digit != 0
+		if printed {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if printed {
+		w--
+		buf[w] = '.'
+	}
+	return w, v
+}
+
+// appendDurationInt formats v into the tail of buf, returning the index where
+// the output begins.
+func appendDurationInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+		return w
+	}
+	for v > 0 {
+		w--
+		buf[w] = byte(v%10) + '0'
+		v /= 10
+	}
+	return w
+}
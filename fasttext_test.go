@@ -0,0 +1,142 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"math"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedTimeHandler sets the time of all records to a fixed value, so that
+// output from different handlers can be compared.
+type fixedTimeHandler struct {
+	slog.Handler
+}
+
+func (h fixedTimeHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.Time = time.Date(2025, 5, 17, 12, 34, 56, 789000000, time.Local)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h fixedTimeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return fixedTimeHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h fixedTimeHandler) WithGroup(name string) slog.Handler {
+	return fixedTimeHandler{h.Handler.WithGroup(name)}
+}
+
+// logFastTextCases logs a variety of records to a logger using the given
+// handler.
+func logFastTextCases(h slog.Handler) {
+	l := slog.New(fixedTimeHandler{h})
+	l.Info("Simple")
+	l.Warn("Needs quoting", "key", "value with spaces", "empty", "", "eq", "a=b", "quote", `"`)
+	l.Error("Numbers", "int", -42, "uint", uint64(math.MaxUint64), "float", 3.25, "big", 1e21, "nan", math.NaN(), "bool", true)
+	l.Info("Durations", "zero", time.Duration(0), "ns", 5*time.Nanosecond, "us", 1500*time.Nanosecond,
+		"ms", 2500*time.Microsecond, "s", 3*time.Second, "long", -(26*time.Hour + 3*time.Minute + 4*time.Millisecond))
+	l.Info("Times",
+		"utc", time.Date(2025, 5, 17, 9, 8, 7, 654321000, time.UTC),
+		"offset", time.Date(1999, 12, 31, 23, 59, 59, 0, time.FixedZone("", -(5*3600+30*60))),
+		"future", time.Date(12345, 1, 1, 0, 0, 0, 0, time.UTC))
+	l.Info("Any", "err", errors.New("it broke"), "addr", netip.MustParseAddr("192.0.2.1"),
+		"bytes", []byte("hi there"), "slice", []int{1, 2}, "nil", nil, "nilptr", (*netip.Addr)(nil))
+	l.Info("Unicode", "key with space", "naïve", "tab", "a\tb", "invalid", "\xff")
+	l.Info("Groups", slog.Group("g", "a", 1, slog.Group("h", "b", 2)), slog.Group("empty"), slog.Group("", "inline", 3))
+	l.With("w", 1).WithGroup("req").With("id", "abc").WithGroup("inner").Info("Nested", "x", 1)
+	l.WithGroup("unused").Info("No attrs")
+	l.Log(context.Background(), slog.LevelInfo+2, "Odd level")
+}
+
+func Test_FastTextHandler_MatchesTextHandler(t *testing.T) {
+	replacers := map[string]func([]string, slog.Attr) slog.Attr{
+		"none": nil,
+		"identity": func(_ []string, a slog.Attr) slog.Attr {
+			return a
+		},
+		"rewrite": func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "a" && len(groups) > 0 {
+				return slog.String("a", groups[len(groups)-1])
+			}
+			if a.Key == "empty" || a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}
+
+	for name, rep := range replacers {
+		for _, addSource := range []bool{false, true} {
+			opts := &slog.HandlerOptions{AddSource: addSource, Level: slog.LevelDebug, ReplaceAttr: rep}
+
+			want := new(bytes.Buffer)
+			logFastTextCases(slog.NewTextHandler(want, opts))
+
+			got := new(bytes.Buffer)
+			logFastTextCases(newFastTextHandler(got, opts))
+
+			assert.Equal(t, want.String(), got.String(), "replacer %s, source %t", name, addSource)
+		}
+	}
+}
+
+func Test_CivilFromDays(t *testing.T) {
+	for days := int64(-800000); days < 3000000; days += 997 {
+		year, month, day := time.Unix(days*86400, 0).UTC().Date()
+		gotYear, gotMonth, gotDay := civilFromDays(days)
+		assert.Equal(t, []int{year, int(month), day}, []int{int(gotYear), gotMonth, gotDay}, "days = %d", days)
+	}
+}
+
+func Test_FastTextHandler_DoesNotAllocate(t *testing.T) {
+	l := slog.New(newFastTextHandler(io.Discard, &slog.HandlerOptions{})).With("service", "api")
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Info("Request handled", "status", 200, "duration", 150*time.Millisecond, "path", "/index.html")
+	})
+	assert.Zero(t, allocs)
+}
+
+func Test_FastTextFormatFlag(t *testing.T) {
+	_ = flag.Set("log.format", "fasttext")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.Info("Test", "key", "value")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Test key=value\n", w.String())
+}
+
+func benchmarkHandler(b *testing.B, h slog.Handler) {
+	h = h.WithAttrs([]slog.Attr{slog.String("service", "api")})
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "Request handled", 0)
+	r.AddAttrs(
+		slog.Int("status", 200),
+		slog.Duration("duration", 150*time.Millisecond),
+		slog.String("path", "/index.html"),
+		slog.Bool("ok", true),
+	)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = h.Handle(ctx, r)
+	}
+}
+
+func Benchmark_TextHandler(b *testing.B) {
+	benchmarkHandler(b, slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+}
+
+func Benchmark_FastTextHandler(b *testing.B) {
+	benchmarkHandler(b, newFastTextHandler(io.Discard, &slog.HandlerOptions{}))
+}
@@ -0,0 +1,157 @@
+package slogflags
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// openFile opens the file at path for appending, creating it if necessary.
+// If gzip compression has been enabled, the returned writer compresses
+// output on the fly. If file encryption has been configured, output is
+// encrypted.
+func (c *config) openFile(path string) (io.WriteCloser, error) {
+	if c.fileEncryption != nil {
+		// An encrypted file can't be appended to, so move any existing file out of
+		// the way.
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			if err := os.Rename(path, fmt.Sprintf("%s.%d", path, info.ModTime().Unix())); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	var w io.WriteCloser = f
+	if c.fsync || *logFsync {
+		w = syncWriter{f}
+	}
+
+	if c.fileEncryption != nil {
+		fw := w
+		ew, err := c.fileEncryption(fw)
+		if err != nil {
+			_ = fw.Close()
+			return nil, err
+		}
+		w = &closeFuncWriter{Writer: ew, close: func() error {
+			return errors.Join(ew.Close(), fw.Close())
+		}}
+	}
+
+	if c.gzipFlushInterval > 0 {
+		return newGzipWriter(w, c.gzipFlushInterval), nil
+	}
+	return w, nil
+}
+
+// syncWriter is an [io.WriteCloser] that syncs a file to disk after every
+// write.
+type syncWriter struct {
+	f *os.File
+}
+
+func (w syncWriter) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, w.f.Sync()
+}
+
+func (w syncWriter) Close() error {
+	return w.f.Close()
+}
+
+// closeFuncWriter is an [io.WriteCloser] that calls a func when closed.
+type closeFuncWriter struct {
+	io.Writer
+	close func() error
+}
+
+func (w *closeFuncWriter) Close() error {
+	return w.close()
+}
+
+// gzipWriter compresses data written to it, periodically flushing the
+// compressor so that everything written so far can be decompressed even if
+// the writer is never closed.
+//
+// Each gzipWriter starts a new gzip member, so appending to an existing
+// compressed file produces a valid multi-member gzip file.
+type gzipWriter struct {
+	w io.WriteCloser
+
+	mu    sync.Mutex
+	gz    *gzip.Writer
+	dirty bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newGzipWriter(w io.WriteCloser, flushInterval time.Duration) *gzipWriter {
+	g := &gzipWriter{
+		w:    w,
+		gz:   gzip.NewWriter(w),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go g.run(flushInterval)
+	return g
+}
+
+func (g *gzipWriter) run(interval time.Duration) {
+	defer close(g.done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-t.C:
+			_ = g.Flush()
+		}
+	}
+}
+
+func (g *gzipWriter) Write(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.dirty = true
+	return g.gz.Write(p)
+}
+
+// Flush writes any pending compressed data to the underlying writer.
+func (g *gzipWriter) Flush() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.dirty {
+		return nil
+	}
+	g.dirty = false
+	return g.gz.Flush()
+}
+
+// Close writes the gzip footer and closes the underlying writer.
+func (g *gzipWriter) Close() error {
+	close(g.stop)
+	<-g.done
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.gz.Close(), g.w.Close())
+}
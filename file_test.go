@@ -0,0 +1,156 @@
+package slogflags
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WritesToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.output", "file://"+path)
+	defer flag.Set("log.output", "")
+
+	l := LoggerForTest(new(bytes.Buffer))
+	l.Info("Test")
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "level=INFO msg=Test\n")
+}
+
+func Test_WritesGzipFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.output", path)
+	defer flag.Set("log.output", "")
+
+	l := LoggerForTest(new(bytes.Buffer), WithGzipFileOutput(10*time.Millisecond))
+	l.Info("Test")
+
+	// The file won't have a gzip footer until it's closed, but everything up
+	// to the last flush point should be readable.
+	assert.Eventually(t, func() bool {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return false
+		}
+		b, _ := io.ReadAll(gz)
+		return bytes.Contains(b, []byte("level=INFO msg=Test\n"))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_GzipWriter_AppendsMembers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	c := &config{gzipFlushInterval: time.Hour}
+
+	for _, line := range []string{"one\n", "two\n"} {
+		w, err := c.openFile(path)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, line)
+		require.NoError(t, err)
+		require.NoError(t, w.(*gzipWriter).Close())
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	b, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(b))
+}
+
+func Test_WritesEncryptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.b64")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+
+	// Base64 stands in for encryption: it transforms the output, and closing
+	// the encoder finishes the stream without closing the file.
+	c := &config{fileEncryption: func(w io.Writer) (io.WriteCloser, error) {
+		return base64.NewEncoder(base64.StdEncoding, w), nil
+	}}
+	w, err := c.openFile(path)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "Test\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("Test\n")), string(b))
+
+	matches, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+}
+
+func Test_FallsBackIfEncryptionFails(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.output", filepath.Join(t.TempDir(), "app.log.age"))
+	defer flag.Set("log.output", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithFileEncryption(func(io.Writer) (io.WriteCloser, error) {
+		return nil, errors.New("no recipients")
+	}))
+	l.Info("Test")
+
+	assert.Contains(t, w.String(), "Unable to configure log output")
+	assert.Contains(t, w.String(), "no recipients")
+	assert.Contains(t, w.String(), "level=INFO msg=Test\n")
+}
+
+func Test_Fsync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	_ = flag.Set("log.fsync", "true")
+	defer flag.Set("log.fsync", "false")
+
+	c := &config{}
+	w, err := c.openFile(path)
+	require.NoError(t, err)
+	assert.IsType(t, syncWriter{}, w)
+
+	_, err = w.Write([]byte("durable\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "durable\n", string(b))
+}
+
+func Test_SyncWriter_ReturnsSyncErrors(t *testing.T) {
+	// Pipes can be written to but not synced.
+	r, pw, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	w := syncWriter{pw}
+	defer w.Close()
+
+	go io.Copy(io.Discard, r)
+	n, err := w.Write([]byte("hi"))
+	assert.Equal(t, 2, n)
+	assert.Error(t, err)
+}
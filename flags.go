@@ -0,0 +1,23 @@
+package slogflags
+
+import (
+	"flag"
+	"sync"
+)
+
+// configMu guards the flags defined by this package, and ensures that only
+// one logger is configured at a time so that process-wide state such as the
+// default logger and the level used by the [log] package is left consistent
+// with the most recent logger.
+var configMu sync.Mutex
+
+// SetFlag sets the value of one of the flags defined by this package, or any
+// other flag registered with the [flag] package, as [flag.Set] does. Unlike
+// calling flag.Set directly, it's safe to call concurrently with [Logger],
+// [Reconfigure], [Validate] and [Audit], which read the flags while holding
+// the same lock. This is mostly useful in tests that run in parallel.
+func SetFlag(name, value string) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return flag.Set(name, value)
+}
@@ -0,0 +1,50 @@
+package slogflags
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SetFlag(t *testing.T) {
+	require.NoError(t, SetFlag("log.level", "warn"))
+	defer SetFlag("log.level", "")
+
+	assert.Equal(t, "warn", *logLevel)
+	assert.Error(t, SetFlag("log.nonexistent", "true"))
+}
+
+func Test_Logger_ConcurrentConfiguration(t *testing.T) {
+	defer SetFlag("log.level", "")
+	defer SetFlag("log.format", "")
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			l := Logger(WithWriter(new(bytes.Buffer)), WithSetDefault(true))
+			l.Info("Configured", "i", i)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = SetFlag("log.level", []string{"debug", "info"}[i%2])
+			_ = SetFlag("log.format", []string{"json", "text"}[i%2])
+		}()
+		go func() {
+			defer wg.Done()
+			_ = Validate(ValidateConfig{})
+			_ = Reconfigure(WithWriter(new(bytes.Buffer)))
+		}()
+	}
+	wg.Wait()
+
+	// The default logger must be the one created last.
+	handler, ok := slog.Default().Handler().(*proxyHandler)
+	require.True(t, ok)
+	assert.Same(t, currentSlot.Load(), handler.current)
+}
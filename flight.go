@@ -0,0 +1,133 @@
+package slogflags
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// flightRecorderKey is the context key used to store a request-scoped
+// flightRing.
+type flightRecorderKey struct{}
+
+// ContextWithFlightRecorder returns a copy of ctx that records its own flight
+// recording, for use with [WithFlightRecorder]. Records logged with the
+// returned context (e.g. using [log/slog.Logger.InfoContext]) that are below
+// the output level are retained separately from other records, and only
+// they are written if an error is subsequently logged with the same context.
+//
+// This is typically used to give each incoming request its own recording, so
+// that a failure in one request doesn't dump unrelated records from others.
+func ContextWithFlightRecorder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, flightRecorderKey{}, &flightRing{})
+}
+
+// flightItem is a record retained by a flight recorder, along with the
+// handler that should handle it if it is written.
+type flightItem struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// flightRing is a ring buffer of the most recent records.
+type flightRing struct {
+	mu    sync.Mutex
+	items []flightItem
+	next  int
+}
+
+// add adds an item to the ring, overwriting the oldest item if the ring
+// already contains size items.
+func (f *flightRing) add(item flightItem, size int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.items) < size {
+		f.items = append(f.items, item)
+		return
+	}
+	f.items[f.next] = item
+	f.next = (f.next + 1) % len(f.items)
+}
+
+// drain removes and returns all items in the ring, oldest first.
+func (f *flightRing) drain() []flightItem {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	res := slices.Concat(f.items[f.next:], f.items[:f.next])
+	f.items = nil
+	f.next = 0
+	return res
+}
+
+// flightRecorderHandler is a [log/slog.Handler] that retains records below a
+// level (or any lower level stored in the context) in a ring buffer instead
+// of passing them to the next handler. When a record at error level or above
+// is handled, the retained records are passed to the next handler first. The next handler must be enabled for all levels.
+type flightRecorderHandler struct {
+	next  slog.Handler
+	level slog.Leveler
+	size  int
+	ring  *flightRing
+}
+
+func newFlightRecorderHandler(next slog.Handler, level slog.Leveler, size int) *flightRecorderHandler {
+	return &flightRecorderHandler{next: next, level: level, size: size, ring: &flightRing{}}
+}
+
+// ringFor returns the ring associated with ctx, or the process-wide ring if
+// there isn't one.
+func (h *flightRecorderHandler) ringFor(ctx context.Context) *flightRing {
+	if ring, ok := ctx.Value(flightRecorderKey{}).(*flightRing); ok {
+		return ring
+	}
+	return h.ring
+}
+
+func (h *flightRecorderHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *flightRecorderHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < contextLevel(ctx, h.level.Level()) {
+		h.ringFor(ctx).add(flightItem{
+			ctx:     context.WithoutCancel(ctx),
+			handler: h.next,
+			record:  r.Clone(),
+		}, h.size)
+		return nil
+	}
+
+	var errs []error
+	if r.Level >= slog.LevelError {
+		for _, item := range h.ringFor(ctx).drain() {
+			errs = append(errs, item.handler.Handle(item.ctx, item.record))
+		}
+	}
+	errs = append(errs, h.next.Handle(ctx, r))
+	return errors.Join(errs...)
+}
+
+func (h *flightRecorderHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs))
+}
+
+func (h *flightRecorderHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name))
+}
+
+func (h *flightRecorderHandler) with(next slog.Handler) *flightRecorderHandler {
+	h2 := *h
+	h2.next = next
+	return &h2
+}
@@ -0,0 +1,76 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FlightRing_KeepsMostRecent(t *testing.T) {
+	ring := &flightRing{}
+	for i := range 5 {
+		ring.add(flightItem{record: slog.NewRecord(time.Now(), slog.LevelDebug, strconv.Itoa(i), 0)}, 3)
+	}
+
+	var messages []string
+	for _, item := range ring.drain() {
+		messages = append(messages, item.record.Message)
+	}
+	assert.Equal(t, []string{"2", "3", "4"}, messages)
+	assert.Empty(t, ring.drain())
+}
+
+func Test_FlightRecorder_WritesRetainedRecordsOnError(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithFlightRecorder(2))
+	l.Debug("One")
+	l.With("key", "value").Debug("Two")
+	l.Debug("Three")
+	l.Info("Four")
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Four\n", w.String())
+
+	l.Error("Five")
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Four\n"+
+		"time=2026-01-02T03:04:05.000Z level=DEBUG msg=Two key=value\n"+
+		"time=2026-01-02T03:04:05.000Z level=DEBUG msg=Three\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=Five\n", w.String())
+
+	w.Reset()
+	l.Error("Six")
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=ERROR msg=Six\n", w.String())
+}
+
+func Test_FlightRecorder_UsesContextRecordings(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithFlightRecorder(10))
+
+	ctx1 := ContextWithFlightRecorder(context.Background())
+	ctx2 := ContextWithFlightRecorder(context.Background())
+	l.DebugContext(ctx1, "Request one")
+	l.DebugContext(ctx2, "Request two")
+	l.Debug("Background")
+
+	l.ErrorContext(ctx2, "Failed")
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=DEBUG msg=\"Request two\"\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=Failed\n", w.String())
+
+	w.Reset()
+	l.Error("Failed")
+	assert.True(t, strings.HasPrefix(w.String(), "time=2026-01-02T03:04:05.000Z level=DEBUG msg=Background\n"))
+	assert.NotContains(t, w.String(), "Request one")
+}
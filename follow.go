@@ -0,0 +1,249 @@
+package slogflags
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// websocketGUID is appended to the client's key when computing the
+// Sec-WebSocket-Accept header, as defined in RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used when streaming records.
+const (
+	websocketText  = 0x1
+	websocketClose = 0x8
+)
+
+// follow streams matching records to the client, starting with those already
+// in the buffer, until the client disconnects.
+func (b *RingBuffer) follow(w http.ResponseWriter, req *http.Request, filter recordFilter) {
+	if isWebSocketUpgrade(req) {
+		b.followWebSocket(w, req, filter)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	records, ch, cancel := b.subscribe()
+	defer cancel()
+
+	format := req.URL.Query().Get("format")
+	send := func(r slog.Record) error {
+		if !filter.match(r) {
+			return nil
+		}
+		if _, err := io.WriteString(w, "data: "+renderRecord(r, format)+"\n\n"); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	for _, r := range records {
+		if send(r) != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case r := <-ch:
+			if send(r) != nil {
+				return
+			}
+		}
+	}
+}
+
+// followWebSocket upgrades the connection to a WebSocket and sends each
+// matching record as a text message, until the client closes the connection.
+//
+// Browsers don't apply CORS to WebSockets, so upgrades from pages on other
+// origins are rejected to stop them reading the log stream.
+func (b *RingBuffer) followWebSocket(w http.ResponseWriter, req *http.Request, filter recordFilter) {
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return
+	}
+
+	if !isSameOrigin(req) {
+		http.Error(w, "cross-origin WebSocket requests are not allowed", http.StatusForbidden)
+		return
+	}
+
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key header", http.StatusBadRequest)
+		return
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "unable to upgrade connection", http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n")
+	if rw.Flush() != nil {
+		return
+	}
+
+	// Messages from the client are ignored, but reading them is the only way
+	// to notice that it has gone away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			opcode, err := readWebSocketFrame(rw.Reader)
+			if err != nil || opcode == websocketClose {
+				return
+			}
+		}
+	}()
+
+	records, ch, cancel := b.subscribe()
+	defer cancel()
+
+	format := req.URL.Query().Get("format")
+	send := func(r slog.Record) error {
+		if !filter.match(r) {
+			return nil
+		}
+		if err := writeWebSocketFrame(rw.Writer, websocketText, []byte(renderRecord(r, format))); err != nil {
+			return err
+		}
+		return rw.Flush()
+	}
+
+	for _, r := range records {
+		if send(r) != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case <-closed:
+			_ = writeWebSocketFrame(rw.Writer, websocketClose, nil)
+			_ = rw.Flush()
+			return
+		case r := <-ch:
+			if send(r) != nil {
+				return
+			}
+		}
+	}
+}
+
+// renderRecord formats a record as a single line of text or JSON, without a
+// trailing newline.
+func renderRecord(r slog.Record, format string) string {
+	buf := new(bytes.Buffer)
+	_ = recordViewHandler(buf, format).Handle(context.Background(), r)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// isWebSocketUpgrade reports whether the request asks to upgrade the
+// connection to a WebSocket.
+func isWebSocketUpgrade(req *http.Request) bool {
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, v := range strings.Split(req.Header.Get("Connection"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), "upgrade") {
+			return true
+		}
+	}
+	return false
+}
+
+// isSameOrigin reports whether the request's Origin header, if any, matches
+// the host it was sent to. Clients other than browsers don't normally send an
+// Origin header, so requests without one are allowed.
+func isSameOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, req.Host)
+}
+
+// websocketAccept computes the Sec-WebSocket-Accept header for a key.
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// writeWebSocketFrame writes a single, unmasked, frame.
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xffff:
+		header = binary.BigEndian.AppendUint16(append(header, 126), uint16(n))
+	default:
+		header = binary.BigEndian.AppendUint64(append(header, 127), uint64(n))
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readWebSocketFrame reads and discards a single frame from the client,
+// returning its opcode.
+func readWebSocketFrame(r *bufio.Reader) (byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if header[1]&0x80 != 0 {
+		// Skip the masking key.
+		length += 4
+	}
+
+	_, err := io.CopyN(io.Discard, r, int64(length))
+	return header[0] & 0x0f, err
+}
@@ -0,0 +1,154 @@
+package slogflags
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RingBuffer_FollowSSE(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	l := slog.New(newTapHandler(slog.DiscardHandler, slog.Level(-100), buffer.add))
+	l.Warn("Before")
+	l.Info("Filtered")
+
+	server := httptest.NewServer(buffer)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?follow=1&level=warn&format=json", nil)
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	events := bufio.NewReader(res.Body)
+	readEvent := func() string {
+		line, err := events.ReadString('\n')
+		require.NoError(t, err)
+		blank, err := events.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "\n", blank)
+		return line
+	}
+
+	assert.Contains(t, readEvent(), `"msg":"Before"`)
+
+	l.Info("Also filtered")
+	l.Error("After", "k", "v")
+	event := readEvent()
+	assert.True(t, strings.HasPrefix(event, "data: {"), event)
+	assert.Contains(t, event, `"level":"ERROR","msg":"After","k":"v"}`)
+}
+
+func Test_RingBuffer_FollowWebSocket(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	l := slog.New(newTapHandler(slog.DiscardHandler, slog.Level(-100), buffer.add))
+	l.Info("Before")
+
+	server := httptest.NewServer(buffer)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err = conn.Write([]byte("GET /?follow=1 HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Origin: http://localhost\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"))
+	require.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	res, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", res.Header.Get("Sec-WebSocket-Accept"))
+
+	readMessage := func() string {
+		header := make([]byte, 2)
+		_, err := io.ReadFull(r, header)
+		require.NoError(t, err)
+		assert.Equal(t, byte(0x81), header[0])
+		payload := make([]byte, header[1])
+		_, err = io.ReadFull(r, payload)
+		require.NoError(t, err)
+		return string(payload)
+	}
+
+	assert.Contains(t, readMessage(), "level=INFO msg=Before")
+	l.Warn("After")
+	assert.Contains(t, readMessage(), "level=WARN msg=After")
+
+	// A masked close frame with no payload.
+	_, err = conn.Write([]byte{0x88, 0x80, 1, 2, 3, 4})
+	require.NoError(t, err)
+	header := make([]byte, 2)
+	_, err = io.ReadFull(r, header)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x88, 0}, header)
+}
+
+func Test_RingBuffer_FollowWebSocket_RejectsInvalidUpgrades(t *testing.T) {
+	buffer := NewRingBuffer(10)
+
+	tests := []struct {
+		name    string
+		origin  string
+		version string
+		status  int
+	}{
+		{"cross origin", "https://evil.example", "13", http.StatusForbidden},
+		{"different port", "http://logs.internal:8080", "13", http.StatusForbidden},
+		{"invalid origin", "://", "13", http.StatusForbidden},
+		{"missing version", "", "", http.StatusUpgradeRequired},
+		{"unsupported version", "http://logs.internal", "8", http.StatusUpgradeRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://logs.internal/?follow=1", nil)
+			req.Header.Set("Upgrade", "websocket")
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.version != "" {
+				req.Header.Set("Sec-WebSocket-Version", tt.version)
+			}
+
+			res := httptest.NewRecorder()
+			buffer.ServeHTTP(res, req)
+			assert.Equal(t, tt.status, res.Code)
+		})
+	}
+}
+
+func Test_WriteWebSocketFrame_Lengths(t *testing.T) {
+	for _, n := range []int{0, 125, 126, 65535, 65536} {
+		buf := new(bytes.Buffer)
+		require.NoError(t, writeWebSocketFrame(buf, websocketText, make([]byte, n)))
+
+		opcode, err := readWebSocketFrame(bufio.NewReader(buf))
+		require.NoError(t, err, "length %d", n)
+		assert.Equal(t, byte(websocketText), opcode)
+		assert.Zero(t, buf.Len(), "length %d", n)
+	}
+}
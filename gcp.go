@@ -0,0 +1,303 @@
+package slogflags
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gcpMetadataURL is the base URL of the GCP metadata server.
+var gcpMetadataURL = "http://metadata.google.internal/computeMetadata/v1"
+
+// GCPTokenFunc retrieves an OAuth2 access token used to authenticate requests
+// made to Google Cloud APIs.
+type GCPTokenFunc func(ctx context.Context) (string, error)
+
+// DefaultGCPToken returns a [GCPTokenFunc] that obtains tokens using the
+// service account key file named in the GOOGLE_APPLICATION_CREDENTIALS
+// environment variable if set, or from the metadata server otherwise.
+//
+// Tokens are cached until shortly before they expire.
+func DefaultGCPToken(client *http.Client, scopes ...string) GCPTokenFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := &gcpTokenCache{client: client, scopes: scopes}
+	return c.get
+}
+
+type gcpTokenCache struct {
+	client *http.Client
+	scopes []string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+type gcpTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func (c *gcpTokenCache) get(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Until(c.expires) > 5*time.Minute {
+		return c.token, nil
+	}
+
+	var res *gcpTokenResponse
+	var err error
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		res, err = c.fromServiceAccount(ctx, path)
+	} else {
+		res, err = c.fromMetadata(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	c.token = res.AccessToken
+	c.expires = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+	return c.token, nil
+}
+
+func (c *gcpTokenCache) fromMetadata(ctx context.Context) (*gcpTokenResponse, error) {
+	path := "instance/service-accounts/default/token"
+	if len(c.scopes) > 0 {
+		path += "?scopes=" + url.QueryEscape(strings.Join(c.scopes, ","))
+	}
+
+	body, err := gcpMetadata(ctx, c.client, path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to obtain token from metadata server: %w", err)
+	}
+
+	res := &gcpTokenResponse{}
+	if err := json.Unmarshal([]byte(body), res); err != nil {
+		return nil, fmt.Errorf("unable to parse token from metadata server: %w", err)
+	}
+	return res, nil
+}
+
+func (c *gcpTokenCache) fromServiceAccount(ctx context.Context, path string) (*gcpTokenResponse, error) {
+	key, err := readGCPServiceAccount(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errors.New("unable to decode service account private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse service account private key: %w", err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("service account private key is not an RSA key")
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": key.PrivateKeyID})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   key.ClientEmail,
+		"scope": strings.Join(c.scopes, " "),
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpRes, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to obtain token for service account: %w", err)
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(httpRes.Body, 1024))
+		return nil, fmt.Errorf("token request returned status %d: %s", httpRes.StatusCode, b)
+	}
+
+	res := &gcpTokenResponse{}
+	if err := json.NewDecoder(httpRes.Body).Decode(res); err != nil {
+		return nil, fmt.Errorf("unable to parse service account token: %w", err)
+	}
+	return res, nil
+}
+
+type gcpServiceAccount struct {
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+func readGCPServiceAccount(path string) (*gcpServiceAccount, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read service account key: %w", err)
+	}
+
+	key := &gcpServiceAccount{}
+	if err := json.Unmarshal(b, key); err != nil {
+		return nil, fmt.Errorf("unable to parse service account key: %w", err)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return key, nil
+}
+
+// gcpMetadata retrieves a value from the metadata server.
+func gcpMetadata(ctx context.Context, client *http.Client, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataURL+"/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned status %d", res.StatusCode)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// gcpProjectID determines the current project from the environment, the
+// service account key, or the metadata server.
+func gcpProjectID(ctx context.Context, client *http.Client) string {
+	if p := os.Getenv("GOOGLE_CLOUD_PROJECT"); p != "" {
+		return p
+	}
+
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		if key, err := readGCPServiceAccount(path); err == nil && key.ProjectID != "" {
+			return key.ProjectID
+		}
+	}
+
+	p, _ := gcpMetadata(ctx, client, "project/project-id")
+	return p
+}
+
+// GCPResource describes the monitored resource that log entries originate
+// from.
+type GCPResource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// DetectGCPResource determines the monitored resource the current process is
+// running on. Cloud Run, GKE and GCE are detected using environment variables
+// and the metadata server; if none are detected a "global" resource is
+// returned.
+func DetectGCPResource(ctx context.Context, client *http.Client, projectID string) GCPResource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	metadata := func(path string) string {
+		v, _ := gcpMetadata(ctx, client, path)
+		return v
+	}
+
+	if service := os.Getenv("K_SERVICE"); service != "" {
+		region := metadata("instance/region")
+		return GCPResource{
+			Type: "cloud_run_revision",
+			Labels: map[string]string{
+				"project_id":         projectID,
+				"service_name":       service,
+				"revision_name":      os.Getenv("K_REVISION"),
+				"configuration_name": os.Getenv("K_CONFIGURATION"),
+				"location":           region[strings.LastIndex(region, "/")+1:],
+			},
+		}
+	}
+
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		namespace := os.Getenv("POD_NAMESPACE")
+		if namespace == "" {
+			b, _ := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+			namespace = strings.TrimSpace(string(b))
+		}
+		pod := os.Getenv("POD_NAME")
+		if pod == "" {
+			pod, _ = os.Hostname()
+		}
+		return GCPResource{
+			Type: "k8s_container",
+			Labels: map[string]string{
+				"project_id":     projectID,
+				"location":       metadata("instance/attributes/cluster-location"),
+				"cluster_name":   metadata("instance/attributes/cluster-name"),
+				"namespace_name": namespace,
+				"pod_name":       pod,
+				"container_name": os.Getenv("CONTAINER_NAME"),
+			},
+		}
+	}
+
+	if id := metadata("instance/id"); id != "" {
+		zone := metadata("instance/zone")
+		return GCPResource{
+			Type: "gce_instance",
+			Labels: map[string]string{
+				"project_id":  projectID,
+				"instance_id": id,
+				"zone":        zone[strings.LastIndex(zone, "/")+1:],
+			},
+		}
+	}
+
+	return GCPResource{Type: "global", Labels: map[string]string{"project_id": projectID}}
+}
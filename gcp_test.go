@@ -0,0 +1,228 @@
+package slogflags
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useGCPMetadataServer points gcpMetadataURL at the given server for the
+// duration of the test.
+func useGCPMetadataServer(t *testing.T, srv *httptest.Server) {
+	old := gcpMetadataURL
+	gcpMetadataURL = srv.URL
+	t.Cleanup(func() { gcpMetadataURL = old })
+}
+
+// writeGCPServiceAccount writes a service account key file using the given
+// private key, and returns its path.
+func writeGCPServiceAccount(t *testing.T, key *rsa.PrivateKey, tokenURI string) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	b, err := json.Marshal(map[string]string{
+		"project_id":     "key-project",
+		"private_key_id": "key-id",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "logger@key-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURI,
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, b, 0600))
+	return path
+}
+
+// decodeJWTPart decodes one base64url-encoded JSON part of a JWT.
+func decodeJWTPart(t *testing.T, part string) map[string]any {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	require.NoError(t, err)
+
+	m := map[string]any{}
+	require.NoError(t, json.Unmarshal(b, &m))
+	return m
+}
+
+func Test_DefaultGCPToken_FromServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var (
+		requests  atomic.Int32
+		assertion string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+		assertion = r.PostForm.Get("assertion")
+		_, _ = fmt.Fprint(w, `{"access_token":"sa-token","expires_in":3600}`)
+	}))
+	defer srv.Close()
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", writeGCPServiceAccount(t, key, srv.URL))
+
+	before := time.Now().Unix()
+	token := DefaultGCPToken(srv.Client(), "scope-a", "scope-b")
+	got, err := token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "sa-token", got)
+
+	parts := strings.Split(assertion, ".")
+	require.Len(t, parts, 3)
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], sig))
+
+	header := decodeJWTPart(t, parts[0])
+	claims := decodeJWTPart(t, parts[1])
+	assert.Equal(t, map[string]any{"alg": "RS256", "typ": "JWT", "kid": "key-id"}, header)
+	assert.Equal(t, "logger@key-project.iam.gserviceaccount.com", claims["iss"])
+	assert.Equal(t, "scope-a scope-b", claims["scope"])
+	assert.Equal(t, srv.URL, claims["aud"])
+	iat := int64(claims["iat"].(float64))
+	assert.GreaterOrEqual(t, iat, before)
+	assert.LessOrEqual(t, iat, time.Now().Unix())
+	assert.Equal(t, iat+3600, int64(claims["exp"].(float64)))
+
+	got, err = token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "sa-token", got)
+	assert.Equal(t, int32(1), requests.Load(), "token should be cached")
+}
+
+func Test_DefaultGCPToken_ServiceAccountErrors(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_grant", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", writeGCPServiceAccount(t, key, srv.URL))
+	_, err = DefaultGCPToken(srv.Client())(t.Context())
+	assert.ErrorContains(t, err, "token request returned status 400: invalid_grant")
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"private_key":"not a key"}`), 0600))
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+	_, err = DefaultGCPToken(srv.Client())(t.Context())
+	assert.ErrorContains(t, err, "unable to decode service account private key")
+}
+
+func Test_DefaultGCPToken_FromMetadata(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		assert.Equal(t, "/instance/service-accounts/default/token", r.URL.Path)
+		assert.Equal(t, "scope-a,scope-b", r.URL.Query().Get("scopes"))
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600}`, requests.Load())
+	}))
+	defer srv.Close()
+	useGCPMetadataServer(t, srv)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+
+	token := DefaultGCPToken(srv.Client(), "scope-a", "scope-b")
+	for range 3 {
+		got, err := token(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", got)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func Test_DefaultGCPToken_RefreshesExpiringToken(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// Tokens expiring within five minutes are refreshed before use.
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":60}`, requests.Load())
+	}))
+	defer srv.Close()
+	useGCPMetadataServer(t, srv)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+
+	token := DefaultGCPToken(srv.Client())
+	got, err := token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", got)
+
+	got, err = token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", got)
+}
+
+func Test_DefaultGCPToken_MetadataErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	useGCPMetadataServer(t, srv)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+
+	_, err := DefaultGCPToken(srv.Client())(t.Context())
+	assert.ErrorContains(t, err, "unable to obtain token from metadata server: metadata server returned status 404")
+}
+
+func Test_ReadGCPServiceAccount(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"project_id":"p","client_email":"e"}`), 0600))
+
+	key, err := readGCPServiceAccount(path)
+	require.NoError(t, err)
+	assert.Equal(t, &gcpServiceAccount{ProjectID: "p", ClientEmail: "e", TokenURI: "https://oauth2.googleapis.com/token"}, key)
+
+	_, err = readGCPServiceAccount(filepath.Join(dir, "missing.json"))
+	assert.ErrorContains(t, err, "unable to read service account key")
+
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0600))
+	_, err = readGCPServiceAccount(path)
+	assert.ErrorContains(t, err, "unable to parse service account key")
+}
+
+func Test_GCPProjectID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/project/project-id", r.URL.Path)
+		_, _ = fmt.Fprint(w, "metadata-project\n")
+	}))
+	defer srv.Close()
+	useGCPMetadataServer(t, srv)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPath := writeGCPServiceAccount(t, key, srv.URL)
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "env-project")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", keyPath)
+	assert.Equal(t, "env-project", gcpProjectID(t.Context(), srv.Client()))
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	assert.Equal(t, "key-project", gcpProjectID(t.Context(), srv.Client()))
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	assert.Equal(t, "metadata-project", gcpProjectID(t.Context(), srv.Client()))
+}
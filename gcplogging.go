@@ -0,0 +1,229 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// GCPLoggingConfig configures a [GCPLoggingSink].
+type GCPLoggingConfig struct {
+	// ProjectID is the Google Cloud project to write logs to. Defaults to the
+	// value of GOOGLE_CLOUD_PROJECT, the project of the service account key,
+	// or the project reported by the metadata server.
+	ProjectID string
+
+	// LogID is the name of the log entries are written to. Defaults to
+	// "slogflags".
+	LogID string
+
+	// Resource is the monitored resource entries are attributed to. Defaults
+	// to the result of [DetectGCPResource].
+	Resource *GCPResource
+
+	// Labels are added to every entry.
+	Labels map[string]string
+
+	// Endpoint overrides the URL used for Cloud Logging API requests.
+	Endpoint string
+
+	// Token is used to obtain access tokens to authenticate requests.
+	// Defaults to [DefaultGCPToken].
+	Token GCPTokenFunc
+
+	// BatchSize is the maximum number of records that will be buffered before
+	// they are sent. Defaults to 500.
+	BatchSize int
+
+	// FlushInterval is the maximum amount of time records will be buffered
+	// before they are sent. Defaults to 5 seconds.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a request will be retried if it fails
+	// due to a network error or a retryable status. Defaults to 3 if zero; a
+	// negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the time to wait before the first retry. It doubles
+	// after each subsequent attempt. Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// GCPLoggingSink is a [Sink] that writes records to Google Cloud Logging using
+// the entries.write API. Attributes are written as the entry's JSON payload,
+// and levels are mapped to the equivalent Cloud Logging severity.
+//
+// Buffered records are only guaranteed to be sent once the sink has been
+// closed.
+type GCPLoggingSink struct {
+	config  GCPLoggingConfig
+	logName string
+	batcher *batcher[json.RawMessage]
+}
+
+type gcpLogEntry struct {
+	Severity    string         `json:"severity"`
+	Timestamp   string         `json:"timestamp"`
+	JSONPayload map[string]any `json:"jsonPayload"`
+}
+
+// NewGCPLoggingSink creates a new [GCPLoggingSink] with the given config. If
+// the project or resource are not specified they will be detected, which may
+// involve requests to the metadata server.
+func NewGCPLoggingSink(config GCPLoggingConfig) (*GCPLoggingSink, error) {
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.ProjectID == "" {
+		config.ProjectID = gcpProjectID(context.Background(), config.Client)
+	}
+	if config.ProjectID == "" {
+		return nil, errors.New("gcp logging: unable to determine project ID")
+	}
+	if config.LogID == "" {
+		config.LogID = "slogflags"
+	}
+	if config.Resource == nil {
+		r := DetectGCPResource(context.Background(), config.Client, config.ProjectID)
+		config.Resource = &r
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://logging.googleapis.com/v2/entries:write"
+	}
+	if config.Token == nil {
+		config.Token = DefaultGCPToken(config.Client, "https://www.googleapis.com/auth/logging.write")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 500
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+
+	s := &GCPLoggingSink{
+		config:  config,
+		logName: fmt.Sprintf("projects/%s/logs/%s", config.ProjectID, url.PathEscape(config.LogID)),
+	}
+	s.batcher = newBatcher(config.BatchSize, config.FlushInterval, s.send)
+	return s, nil
+}
+
+// builtInKeys returns the keys of the fields added to each payload, which
+// attributes mustn't overwrite.
+func (s *GCPLoggingSink) builtInKeys() []string {
+	return []string{"message"}
+}
+
+func (s *GCPLoggingSink) Write(_ context.Context, r slog.Record) error {
+	payload := recordAttrs(r)
+	payload["message"] = r.Message
+
+	entry, err := json.Marshal(gcpLogEntry{
+		Severity:    gcpSeverity(r.Level),
+		Timestamp:   r.Time.UTC().Format(time.RFC3339Nano),
+		JSONPayload: payload,
+	})
+	if err != nil {
+		return fmt.Errorf("gcp logging: unable to encode record: %w", err)
+	}
+
+	return s.batcher.add(entry)
+}
+
+func (s *GCPLoggingSink) Flush() error {
+	return s.batcher.sync()
+}
+
+func (s *GCPLoggingSink) Close() error {
+	return s.batcher.close()
+}
+
+func (s *GCPLoggingSink) send(entries []json.RawMessage) error {
+	body, err := json.Marshal(map[string]any{
+		"logName":  s.logName,
+		"resource": s.config.Resource,
+		"labels":   s.config.Labels,
+		"entries":  entries,
+	})
+	if err != nil {
+		return fmt.Errorf("gcp logging: unable to encode entries: %w", err)
+	}
+
+	err = withRetries(s.config.MaxRetries, s.config.RetryBackoff, func() error {
+		return s.post(body)
+	})
+	if err != nil {
+		return fmt.Errorf("gcp logging: unable to send %d records: %w", len(entries), err)
+	}
+	return nil
+}
+
+func (s *GCPLoggingSink) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	token, err := s.config.Token(ctx)
+	if err != nil {
+		return retryable(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		err = fmt.Errorf("request returned status %d: %s", res.StatusCode, b)
+		if retryableStatus(res.StatusCode) {
+			return retryable(err)
+		}
+		return err
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// gcpSeverity maps a slog level to the closest Cloud Logging severity.
+func gcpSeverity(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError+4:
+		return "CRITICAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
@@ -0,0 +1,166 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCloudLogging struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []map[string]any
+	auth     string
+}
+
+func (f *fakeCloudLogging) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.auth = r.Header.Get("Authorization")
+
+	status := http.StatusOK
+	if len(f.requests) < len(f.statuses) {
+		status = f.statuses[len(f.requests)]
+	}
+	f.requests = append(f.requests, body)
+	w.WriteHeader(status)
+	_, _ = fmt.Fprint(w, "{}")
+}
+
+func newTestGCPLoggingSink(t *testing.T, f *fakeCloudLogging) *GCPLoggingSink {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	sink, err := NewGCPLoggingSink(GCPLoggingConfig{
+		ProjectID:    "proj",
+		LogID:        "app",
+		Resource:     &GCPResource{Type: "global", Labels: map[string]string{"project_id": "proj"}},
+		Endpoint:     server.URL,
+		RetryBackoff: time.Millisecond,
+		Token: func(context.Context) (string, error) {
+			return "tok", nil
+		},
+	})
+	require.NoError(t, err)
+	return sink
+}
+
+func Test_GCPLoggingSink_WritesEntries(t *testing.T) {
+	f := &fakeCloudLogging{}
+	sink := newTestGCPLoggingSink(t, f)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelDebug))
+	l.Warn("One", "arg1", "arg2")
+	l.Debug("Two")
+	require.NoError(t, sink.Close())
+
+	require.Len(t, f.requests, 1)
+	assert.Equal(t, "Bearer tok", f.auth)
+	assert.Equal(t, "projects/proj/logs/app", f.requests[0]["logName"])
+	assert.Equal(t, map[string]any{"type": "global", "labels": map[string]any{"project_id": "proj"}}, f.requests[0]["resource"])
+
+	entries := f.requests[0]["entries"].([]any)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "WARNING", entries[0].(map[string]any)["severity"])
+	assert.Equal(t, map[string]any{"message": "One", "arg1": "arg2"}, entries[0].(map[string]any)["jsonPayload"])
+	assert.Equal(t, "DEBUG", entries[1].(map[string]any)["severity"])
+}
+
+func Test_GCPLoggingSink_RetriesFailures(t *testing.T) {
+	f := &fakeCloudLogging{statuses: []int{http.StatusServiceUnavailable}}
+	sink := newTestGCPLoggingSink(t, f)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+	require.NoError(t, sink.Close())
+
+	assert.Len(t, f.requests, 2)
+}
+
+// unencodable is a value that always fails to marshal to JSON.
+type unencodable struct{}
+
+func (unencodable) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("unencodable")
+}
+
+func Test_GCPLoggingSink_RejectsUnencodableRecord(t *testing.T) {
+	f := &fakeCloudLogging{}
+	sink := newTestGCPLoggingSink(t, f)
+
+	ctx := context.Background()
+	bad := slog.NewRecord(time.Now(), slog.LevelInfo, "Bad", 0)
+	bad.AddAttrs(slog.Any("ratio", unencodable{}))
+	assert.ErrorContains(t, sink.Write(ctx, bad), "gcp logging: unable to encode record")
+	require.NoError(t, sink.Write(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "Good", 0)))
+	require.NoError(t, sink.Close())
+
+	require.Len(t, f.requests, 1)
+	entries := f.requests[0]["entries"].([]any)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Good", entries[0].(map[string]any)["jsonPayload"].(map[string]any)["message"])
+}
+
+func Test_DetectGCPResource(t *testing.T) {
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/instance/region":
+			_, _ = fmt.Fprint(w, "projects/123/regions/europe-west2")
+		case "/instance/id":
+			_, _ = fmt.Fprint(w, "456")
+		case "/instance/zone":
+			_, _ = fmt.Fprint(w, "projects/123/zones/europe-west2-a")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer metadata.Close()
+
+	oldURL := gcpMetadataURL
+	gcpMetadataURL = metadata.URL
+	defer func() { gcpMetadataURL = oldURL }()
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("K_SERVICE", "svc")
+	t.Setenv("K_REVISION", "svc-001")
+	t.Setenv("K_CONFIGURATION", "svc")
+	assert.Equal(t, GCPResource{Type: "cloud_run_revision", Labels: map[string]string{
+		"project_id":         "proj",
+		"service_name":       "svc",
+		"revision_name":      "svc-001",
+		"configuration_name": "svc",
+		"location":           "europe-west2",
+	}}, DetectGCPResource(t.Context(), nil, "proj"))
+
+	t.Setenv("K_SERVICE", "")
+	assert.Equal(t, GCPResource{Type: "gce_instance", Labels: map[string]string{
+		"project_id":  "proj",
+		"instance_id": "456",
+		"zone":        "europe-west2-a",
+	}}, DetectGCPResource(t.Context(), nil, "proj"))
+}
+
+func Test_GCPSeverity(t *testing.T) {
+	assert.Equal(t, "DEBUG", gcpSeverity(slog.LevelDebug))
+	assert.Equal(t, "INFO", gcpSeverity(slog.LevelInfo))
+	assert.Equal(t, "WARNING", gcpSeverity(slog.LevelWarn))
+	assert.Equal(t, "ERROR", gcpSeverity(slog.LevelError))
+	assert.Equal(t, "CRITICAL", gcpSeverity(slog.LevelError+4))
+}
@@ -0,0 +1,119 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GCSConfig configures a [GCSStore].
+type GCSConfig struct {
+	// Bucket is the name of the bucket to upload objects to.
+	Bucket string
+
+	// Endpoint overrides the base URL used for Cloud Storage requests.
+	Endpoint string
+
+	// Token is used to obtain access tokens to authenticate requests.
+	// Defaults to [DefaultGCPToken].
+	Token GCPTokenFunc
+
+	// MaxRetries is the number of times a request will be retried if it fails
+	// due to a network error or a retryable status. Defaults to 3 if zero; a
+	// negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the time to wait before the first retry. It doubles
+	// after each subsequent attempt. Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// GCSStore is an [ObjectStore] that uploads objects to a Google Cloud Storage
+// bucket using the JSON API's simple upload method.
+type GCSStore struct {
+	config GCSConfig
+}
+
+// NewGCSStore creates a new [GCSStore] with the given config.
+func NewGCSStore(config GCSConfig) (*GCSStore, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("gcs: no bucket specified")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://storage.googleapis.com"
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Token == nil {
+		config.Token = DefaultGCPToken(config.Client, "https://www.googleapis.com/auth/devstorage.read_write")
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+
+	return &GCSStore{config: config}, nil
+}
+
+func (s *GCSStore) Put(ctx context.Context, key string, contentType string, body []byte) error {
+	err := withRetries(s.config.MaxRetries, s.config.RetryBackoff, func() error {
+		return s.put(ctx, key, contentType, body)
+	})
+	if err != nil {
+		return fmt.Errorf("gcs: unable to upload %q: %w", key, err)
+	}
+	return nil
+}
+
+func (s *GCSStore) put(ctx context.Context, key string, contentType string, body []byte) error {
+	token, err := s.config.Token(ctx)
+	if err != nil {
+		return retryable(err)
+	}
+
+	u := fmt.Sprintf(
+		"%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		strings.TrimSuffix(s.config.Endpoint, "/"),
+		url.PathEscape(s.config.Bucket),
+		url.QueryEscape(key),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		err = fmt.Errorf("request returned status %d: %s", res.StatusCode, b)
+		if retryableStatus(res.StatusCode) {
+			return retryable(err)
+		}
+		return err
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
@@ -0,0 +1,191 @@
+package slogflags
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"os"
+	"sync"
+)
+
+const (
+	// gelfMaxChunks is the maximum number of chunks a GELF message may be
+	// split into.
+	gelfMaxChunks = 128
+
+	// gelfChunkHeaderSize is the size of the header prepended to each chunk:
+	// two magic bytes, an eight byte message ID, the sequence number and the
+	// sequence count.
+	gelfChunkHeaderSize = 12
+)
+
+// GELFConfig configures a [GELFSink].
+type GELFConfig struct {
+	// Address is the host and port of the Graylog GELF UDP input, e.g.
+	// "graylog.example.com:12201".
+	Address string
+
+	// Host is the name of the host sending the messages. Defaults to the
+	// value returned by [os.Hostname].
+	Host string
+
+	// ChunkSize is the maximum size of each UDP datagram. Messages larger
+	// than this are split into multiple chunks. Defaults to 1420, which is
+	// safe for most networks; 8154 may be used on local networks.
+	ChunkSize int
+
+	// Compress controls whether messages are compressed with zlib before
+	// being sent.
+	Compress bool
+}
+
+// GELFSink is a [Sink] that sends records to Graylog, or other services that
+// accept the Graylog Extended Log Format, over UDP. Messages that do not fit
+// in a single datagram are chunked.
+type GELFSink struct {
+	config GELFConfig
+	mu     sync.Mutex
+	conn   net.Conn
+}
+
+// NewGELFSink creates a new [GELFSink] with the given config.
+func NewGELFSink(config GELFConfig) (*GELFSink, error) {
+	if config.Host == "" {
+		config.Host, _ = os.Hostname()
+	}
+	if config.ChunkSize <= gelfChunkHeaderSize {
+		config.ChunkSize = 1420
+	}
+
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("gelf: unable to connect: %w", err)
+	}
+
+	return &GELFSink{config: config, conn: conn}, nil
+}
+
+func (s *GELFSink) Write(_ context.Context, r slog.Record) error {
+	msg, err := gelfMessage(r, s.config.Host)
+	if err != nil {
+		return err
+	}
+
+	if s.config.Compress {
+		buf := new(bytes.Buffer)
+		zw := zlib.NewWriter(buf)
+		_, _ = zw.Write(msg)
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("gelf: unable to compress message: %w", err)
+		}
+		msg = buf.Bytes()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(msg) <= s.config.ChunkSize {
+		_, err = s.conn.Write(msg)
+		return err
+	}
+
+	return s.writeChunked(msg)
+}
+
+func (s *GELFSink) writeChunked(msg []byte) error {
+	dataSize := s.config.ChunkSize - gelfChunkHeaderSize
+	count := (len(msg) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return fmt.Errorf("gelf: message of %d bytes exceeds the maximum of %d chunks", len(msg), gelfMaxChunks)
+	}
+
+	chunk := make([]byte, 0, s.config.ChunkSize)
+	id := rand.Uint64()
+	for i := 0; i < count; i++ {
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = binary.BigEndian.AppendUint64(chunk, id)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*dataSize:min((i+1)*dataSize, len(msg))]...)
+		if _, err := s.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GELFSink) Close() error {
+	return s.conn.Close()
+}
+
+// gelfMessage encodes a record as a GELF 1.1 message.
+func gelfMessage(r slog.Record, host string) ([]byte, error) {
+	msg := map[string]any{
+		"version":       "1.1",
+		"host":          host,
+		"short_message": r.Message,
+		"timestamp":     float64(r.Time.UnixMicro()) / 1e6,
+		"level":         syslogSeverity(r.Level),
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		addGELFField(msg, "", a)
+		return true
+	})
+
+	res, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("gelf: unable to encode record: %w", err)
+	}
+	return res, nil
+}
+
+// addGELFField adds a as an additional field to msg. GELF does not support
+// nested fields, so groups are flattened using underscores.
+func addGELFField(msg map[string]any, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	key := a.Key
+	if prefix != "" && key != "" {
+		key = prefix + "_" + key
+	} else if key == "" {
+		key = prefix
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			addGELFField(msg, key, ga)
+		}
+		return
+	}
+
+	if key == "id" {
+		// The "_id" field is reserved by Graylog.
+		key = "id_"
+	}
+	msg["_"+key] = jsonValue(a.Value)
+}
+
+// syslogSeverity maps a slog level to the closest syslog severity.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError+4:
+		return 2
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 4
+	case level >= slog.LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
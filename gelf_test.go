@@ -0,0 +1,119 @@
+package slogflags
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveGELF(t *testing.T, conn net.PacketConn) map[string]any {
+	chunks := map[byte][]byte{}
+	buf := make([]byte, 65536)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+
+		data := buf[:n]
+		if n < 2 || data[0] != 0x1e || data[1] != 0x0f {
+			return decodeGELF(t, data)
+		}
+
+		chunks[data[10]] = bytes.Clone(data[12:])
+		if len(chunks) == int(data[11]) {
+			var msg []byte
+			for i := 0; i < len(chunks); i++ {
+				msg = append(msg, chunks[byte(i)]...)
+			}
+			return decodeGELF(t, msg)
+		}
+	}
+}
+
+func decodeGELF(t *testing.T, data []byte) map[string]any {
+	if data[0] == 0x78 {
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		data, err = io.ReadAll(zr)
+		require.NoError(t, err)
+	}
+
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(data, &res))
+	return res
+}
+
+func Test_GELFSink_SendsMessage(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewGELFSink(GELFConfig{Address: conn.LocalAddr().String(), Host: "test"})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.WithGroup("req").Error("Test", "id", 4, "path", "/")
+
+	msg := receiveGELF(t, conn)
+	assert.Equal(t, "1.1", msg["version"])
+	assert.Equal(t, "test", msg["host"])
+	assert.Equal(t, "Test", msg["short_message"])
+	assert.Equal(t, float64(3), msg["level"])
+	assert.Equal(t, float64(4), msg["_req_id"])
+	assert.Equal(t, "/", msg["_req_path"])
+}
+
+func Test_GELFSink_ChunksLargeMessages(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewGELFSink(GELFConfig{Address: conn.LocalAddr().String(), ChunkSize: 100})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("Test", "big", strings.Repeat("x", 1000))
+
+	msg := receiveGELF(t, conn)
+	assert.Equal(t, strings.Repeat("x", 1000), msg["_big"])
+}
+
+func Test_GELFSink_CompressesMessages(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewGELFSink(GELFConfig{Address: conn.LocalAddr().String(), ChunkSize: 100, Compress: true})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("Test", "big", strings.Repeat("x", 1000))
+
+	msg := receiveGELF(t, conn)
+	assert.Equal(t, strings.Repeat("x", 1000), msg["_big"])
+}
+
+func Test_GELFSink_RejectsOversizedMessages(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewGELFSink(GELFConfig{Address: conn.LocalAddr().String(), ChunkSize: 20})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	assert.ErrorContains(t, l.Handler().Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, strings.Repeat("x", 2000), 0)), "maximum of 128 chunks")
+}
@@ -0,0 +1,73 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// goroutineDumpOnce ensures signals are only hooked once, regardless of how
+// many loggers are created.
+var goroutineDumpOnce sync.Once
+
+// hookGoroutineDump starts a goroutine that logs the stacks of all goroutines
+// whenever the process receives one of the signals.
+func hookGoroutineDump(level slog.Level, signals []os.Signal) {
+	goroutineDumpOnce.Do(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, signals...)
+		go func() {
+			for range ch {
+				dumpGoroutines(currentLogger(), level)
+			}
+		}()
+	})
+}
+
+// dumpGoroutines logs a record with the number of goroutines, followed by a
+// record for each goroutine containing its stack.
+func dumpGoroutines(logger *slog.Logger, level slog.Level) {
+	goroutines := strings.Split(strings.TrimSpace(string(allStacks())), "\n\n")
+
+	ctx := context.Background()
+	logger.Log(ctx, level, "Goroutine dump", "goroutines", len(goroutines))
+	for _, g := range goroutines {
+		header, stack, _ := strings.Cut(g, "\n")
+		id, state := parseGoroutineHeader(header)
+		logger.Log(ctx, level, "Goroutine", "id", id, "state", state, "stack", stack)
+	}
+}
+
+// allStacks returns the stack traces of all goroutines, growing the buffer
+// until they fit.
+func allStacks() []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// parseGoroutineHeader parses the first line of a goroutine's stack trace,
+// such as "goroutine 7 [chan receive, 5 minutes]:", returning the ID and the
+// state.
+func parseGoroutineHeader(header string) (int, string) {
+	rest, ok := strings.CutPrefix(header, "goroutine ")
+	if !ok {
+		return 0, header
+	}
+
+	idText, state, _ := strings.Cut(rest, " ")
+	id, _ := strconv.Atoi(idText)
+	state = strings.TrimSuffix(state, ":")
+	state = strings.TrimSuffix(strings.TrimPrefix(state, "["), "]")
+	return id, state
+}
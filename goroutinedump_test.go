@@ -0,0 +1,37 @@
+package slogflags
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DumpGoroutines(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	go func() {
+		<-block
+	}()
+
+	w := new(bytes.Buffer)
+	dumpGoroutines(slog.New(slog.NewJSONHandler(w, nil)), slog.LevelWarn)
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	assert.Regexp(t, `"level":"WARN","msg":"Goroutine dump","goroutines":\d+}$`, lines[0])
+	assert.Greater(t, len(lines), 2)
+	assert.Regexp(t, `"msg":"Goroutine","id":\d+,"state":"running","stack":"github.com/csmith/slogflags.allStacks`, lines[1])
+	assert.Contains(t, w.String(), `"stack":"github.com/csmith/slogflags.Test_DumpGoroutines.func1()`)
+}
+
+func Test_ParseGoroutineHeader(t *testing.T) {
+	id, state := parseGoroutineHeader("goroutine 7 [chan receive, 5 minutes]:")
+	assert.Equal(t, 7, id)
+	assert.Equal(t, "chan receive, 5 minutes", state)
+
+	id, state = parseGoroutineHeader("something else")
+	assert.Equal(t, 0, id)
+	assert.Equal(t, "something else", state)
+}
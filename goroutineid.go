@@ -0,0 +1,16 @@
+package slogflags
+
+import (
+	"runtime"
+	"strings"
+)
+
+// goroutineID returns the ID of the calling goroutine, by parsing the header
+// of its stack trace.
+func goroutineID() int {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	header, _, _ := strings.Cut(string(buf[:n]), "\n")
+	id, _ := parseGoroutineHeader(header)
+	return id
+}
@@ -0,0 +1,41 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GoroutineID(t *testing.T) {
+	ids := make(chan int, 2)
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- goroutineID()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	a, b := <-ids, <-ids
+	assert.NotZero(t, a)
+	assert.NotZero(t, b)
+	assert.NotEqual(t, a, b)
+}
+
+func Test_WithGoroutineID(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithGoroutineID(true))
+	l.With("key", "value").Info("Hello")
+
+	assert.Equal(t, fmt.Sprintf("time=2026-01-02T03:04:05.000Z level=INFO msg=Hello key=value goroutine=%d\n", goroutineID()), w.String())
+}
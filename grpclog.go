@@ -0,0 +1,156 @@
+package slogflags
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// GRPCLoggerConfig configures a [GRPCLogger].
+type GRPCLoggerConfig struct {
+	// Logger is used to log messages from gRPC. Defaults to [L].
+	Logger *slog.Logger
+
+	// InfoLevel is the level that gRPC's informational messages are logged
+	// at. These are mostly useful when debugging gRPC itself, so this
+	// defaults to [log/slog.LevelDebug].
+	InfoLevel slog.Leveler
+
+	// Verbosity is the verbosity level reported to gRPC, which controls
+	// whether it logs additional detail. Defaults to 0.
+	Verbosity int
+}
+
+// GRPCLogger routes gRPC's internal logging through a [log/slog.Logger], so
+// that it's written in the format configured by flags rather than as
+// unformatted lines on stderr. Warnings and errors are logged at
+// [log/slog.LevelWarn] and [log/slog.LevelError], and fatal messages at
+// [LevelFatal] before all outputs are closed and the process exits.
+//
+// GRPCLogger implements grpclog.LoggerV2 and grpclog.DepthLoggerV2 from
+// google.golang.org/grpc/grpclog, without slogflags depending on gRPC:
+//
+//	grpclog.SetLoggerV2(slogflags.NewGRPCLogger(slogflags.GRPCLoggerConfig{}))
+type GRPCLogger struct {
+	logger    *slog.Logger
+	infoLevel slog.Leveler
+	verbosity int
+	exit      func(int)
+}
+
+// NewGRPCLogger creates a new [GRPCLogger].
+func NewGRPCLogger(config GRPCLoggerConfig) *GRPCLogger {
+	g := &GRPCLogger{
+		logger:    config.Logger,
+		infoLevel: config.InfoLevel,
+		verbosity: config.Verbosity,
+		exit:      os.Exit,
+	}
+	if g.logger == nil {
+		g.logger = L()
+	}
+	if g.infoLevel == nil {
+		g.infoLevel = slog.LevelDebug
+	}
+	return g
+}
+
+// log logs a message, attributing it to the caller depth frames above the
+// caller of the exported method.
+func (g *GRPCLogger) log(depth int, level slog.Level, msg string) {
+	ctx := context.Background()
+	if !g.logger.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	// Skip runtime.Callers, log, and the exported method.
+	runtime.Callers(depth+3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	_ = g.logger.Handler().Handle(ctx, r)
+}
+
+// fatal logs a message at [LevelFatal], closes all outputs, and exits.
+func (g *GRPCLogger) fatal(depth int, msg string) {
+	g.log(depth+1, LevelFatal, msg)
+	_ = Close()
+	g.exit(1)
+}
+
+// sprintln formats args as [fmt.Sprintln] does, without the trailing newline.
+func sprintln(args []any) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+func (g *GRPCLogger) Info(args ...any) {
+	g.log(0, g.infoLevel.Level(), fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Infoln(args ...any) {
+	g.log(0, g.infoLevel.Level(), sprintln(args))
+}
+
+func (g *GRPCLogger) Infof(format string, args ...any) {
+	g.log(0, g.infoLevel.Level(), fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) InfoDepth(depth int, args ...any) {
+	g.log(depth, g.infoLevel.Level(), fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Warning(args ...any) {
+	g.log(0, slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Warningln(args ...any) {
+	g.log(0, slog.LevelWarn, sprintln(args))
+}
+
+func (g *GRPCLogger) Warningf(format string, args ...any) {
+	g.log(0, slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) WarningDepth(depth int, args ...any) {
+	g.log(depth, slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Error(args ...any) {
+	g.log(0, slog.LevelError, fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Errorln(args ...any) {
+	g.log(0, slog.LevelError, sprintln(args))
+}
+
+func (g *GRPCLogger) Errorf(format string, args ...any) {
+	g.log(0, slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) ErrorDepth(depth int, args ...any) {
+	g.log(depth, slog.LevelError, fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Fatal(args ...any) {
+	g.fatal(0, fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Fatalln(args ...any) {
+	g.fatal(0, sprintln(args))
+}
+
+func (g *GRPCLogger) Fatalf(format string, args ...any) {
+	g.fatal(0, fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) FatalDepth(depth int, args ...any) {
+	g.fatal(depth, fmt.Sprint(args...))
+}
+
+// V reports whether the verbosity level l is enabled.
+func (g *GRPCLogger) V(l int) bool {
+	return l <= g.verbosity
+}
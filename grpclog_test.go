@@ -0,0 +1,96 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// grpcLoggerV2 mirrors the grpclog.LoggerV2 and grpclog.DepthLoggerV2
+// interfaces.
+type grpcLoggerV2 interface {
+	Info(args ...any)
+	Infoln(args ...any)
+	Infof(format string, args ...any)
+	Warning(args ...any)
+	Warningln(args ...any)
+	Warningf(format string, args ...any)
+	Error(args ...any)
+	Errorln(args ...any)
+	Errorf(format string, args ...any)
+	Fatal(args ...any)
+	Fatalln(args ...any)
+	Fatalf(format string, args ...any)
+	V(l int) bool
+
+	InfoDepth(depth int, args ...any)
+	WarningDepth(depth int, args ...any)
+	ErrorDepth(depth int, args ...any)
+	FatalDepth(depth int, args ...any)
+}
+
+var _ grpcLoggerV2 = (*GRPCLogger)(nil)
+
+func Test_GRPCLogger_MapsLevels(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "debug")
+	defer flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	g := NewGRPCLogger(GRPCLoggerConfig{Logger: LoggerForTest(w)})
+	g.Info("channel ", 1, " created")
+	g.Warningln("connection", "lost")
+	g.Errorf("dial failed: %d", 3)
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=DEBUG msg=\"channel 1 created\"\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"connection lost\"\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=\"dial failed: 3\"\n", w.String())
+}
+
+func Test_GRPCLogger_InfoLevel(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	g := NewGRPCLogger(GRPCLoggerConfig{Logger: LoggerForTest(w), InfoLevel: slog.LevelInfo})
+	g.Infof("server listening on %s", ":8080")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=\"server listening on :8080\"\n", w.String())
+}
+
+func Test_GRPCLogger_Fatal(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	g := NewGRPCLogger(GRPCLoggerConfig{Logger: LoggerForTest(w)})
+	var code int
+	g.exit = func(c int) { code = c }
+	g.Fatal("unrecoverable")
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=FATAL msg=unrecoverable\n", w.String())
+}
+
+func Test_GRPCLogger_Verbosity(t *testing.T) {
+	g := NewGRPCLogger(GRPCLoggerConfig{Logger: slog.New(slog.DiscardHandler), Verbosity: 2})
+	assert.True(t, g.V(0))
+	assert.True(t, g.V(2))
+	assert.False(t, g.V(3))
+}
+
+func Test_GRPCLogger_Source(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	g := NewGRPCLogger(GRPCLoggerConfig{Logger: Logger(WithWriter(w), WithAddSource(true))})
+	g.Error("failed")
+
+	assert.Contains(t, w.String(), "grpclog_test.go")
+}
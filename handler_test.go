@@ -0,0 +1,77 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// teeHandler is a minimal [log/slog.Handler] that passes records to two
+// handlers.
+type teeHandler struct {
+	a, b slog.Handler
+}
+
+func (h teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.a.Enabled(ctx, level) || h.b.Enabled(ctx, level)
+}
+
+func (h teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.a.Enabled(ctx, r.Level) {
+		_ = h.a.Handle(ctx, r.Clone())
+	}
+	if h.b.Enabled(ctx, r.Level) {
+		_ = h.b.Handle(ctx, r.Clone())
+	}
+	return nil
+}
+
+func (h teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return teeHandler{h.a.WithAttrs(attrs), h.b.WithAttrs(attrs)}
+}
+
+func (h teeHandler) WithGroup(name string) slog.Handler {
+	return teeHandler{h.a.WithGroup(name), h.b.WithGroup(name)}
+}
+
+func Test_Handler_Composes(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	ours := new(bytes.Buffer)
+	theirs := new(bytes.Buffer)
+	h := Handler(WithWriter(ours), WithClock(testClock))
+	l := slog.New(teeHandler{h, slog.NewJSONHandler(theirs, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})})
+	l.With("k", "v").Info("Hello")
+	l.Debug("Hidden")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Hello k=v\n", ours.String())
+	assert.Equal(t, `{"level":"INFO","msg":"Hello","k":"v"}`+"\n", theirs.String())
+}
+
+func Test_Handler_Reconfigure(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	first := new(bytes.Buffer)
+	h := Handler(WithWriter(first), WithClock(testClock))
+
+	second := new(bytes.Buffer)
+	require.NoError(t, Reconfigure(WithWriter(second), WithClock(testClock)))
+	slog.New(h).Info("Hello")
+
+	assert.Empty(t, first.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Hello\n", second.String())
+}
@@ -0,0 +1,18 @@
+package slogflags
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+)
+
+// hashValue returns a replacement func that replaces values with a truncated
+// HMAC-SHA256 digest of their string form, using the given secret.
+func hashValue(secret []byte) func(slog.Value) slog.Value {
+	return func(v slog.Value) slog.Value {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(v.String()))
+		return slog.StringValue(hex.EncodeToString(mac.Sum(nil)[:16]))
+	}
+}
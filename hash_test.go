@@ -0,0 +1,48 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithHashKeys(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithHashKeys([]byte("secret"), "user_id", "email"))
+	l.Info("Login", "user_id", 42, slog.Group("contact", "email", "alice@example.com"), "method", "password")
+	l.Info("Logout", "user_id", 42)
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Login user_id=93c121e7aa437a1e01e3c512c6f0ce3c contact.email=a398d49ce1980b3642bc4dbd110121e3 method=password\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Logout user_id=93c121e7aa437a1e01e3c512c6f0ce3c\n", w.String())
+}
+
+func Test_WithHashKeys_Secret(t *testing.T) {
+	a := hashValue([]byte("one"))(slog.StringValue("alice"))
+	b := hashValue([]byte("two"))(slog.StringValue("alice"))
+	assert.NotEqual(t, a.String(), b.String())
+	assert.Len(t, a.String(), 32)
+}
+
+func Test_WithHashKeys_Structs(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	type user struct {
+		ID   int    `json:"user_id"`
+		Name string `json:"name"`
+	}
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithHashKeys([]byte("secret"), "user_id"))
+	l.Info("Created", "user", user{ID: 42, Name: "Alice"})
+
+	assert.Equal(t, `{"time":"2026-01-02T03:04:05Z","level":"INFO","msg":"Created","user":{"name":"Alice","user_id":"93c121e7aa437a1e01e3c512c6f0ce3c"}}`+"\n", w.String())
+}
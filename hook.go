@@ -0,0 +1,69 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// hookHandler is a [log/slog.Handler] that calls funcs before each record is
+// handled, allowing them to modify it, and after it has been handled
+// successfully.
+type hookHandler struct {
+	next   slog.Handler
+	before []func(ctx context.Context, r *slog.Record)
+	after  []func(ctx context.Context, r slog.Record)
+	goas   []groupOrAttrs
+}
+
+func newHookHandler(next slog.Handler, before []func(context.Context, *slog.Record), after []func(context.Context, slog.Record)) *hookHandler {
+	return &hookHandler{next: next, before: before, after: after}
+}
+
+func (h *hookHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *hookHandler) Handle(ctx context.Context, r slog.Record) error {
+	if len(h.before) > 0 {
+		// The record may share attributes with the caller's copy, so it
+		// must be cloned before the hooks can safely modify it.
+		r = r.Clone()
+		for _, fn := range h.before {
+			fn(ctx, &r)
+		}
+	}
+
+	if err := h.next.Handle(ctx, r.Clone()); err != nil {
+		return err
+	}
+
+	if len(h.after) > 0 {
+		resolved := resolveRecord(r, h.goas)
+		for _, fn := range h.after {
+			fn(ctx, resolved)
+		}
+	}
+	return nil
+}
+
+func (h *hookHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs), groupOrAttrs{attrs: attrs})
+}
+
+func (h *hookHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name), groupOrAttrs{group: name})
+}
+
+func (h *hookHandler) with(next slog.Handler, goa groupOrAttrs) *hookHandler {
+	h2 := *h
+	h2.next = next
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
@@ -0,0 +1,75 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookKey struct{}
+
+func Test_WithOnLog_ModifiesRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w,
+		WithOnLog(func(ctx context.Context, r *slog.Record) {
+			if tenant, ok := ctx.Value(hookKey{}).(string); ok {
+				r.AddAttrs(slog.String("tenant", tenant))
+			}
+		}),
+		WithOnLog(func(_ context.Context, r *slog.Record) {
+			r.Message = "[app] " + r.Message
+		}),
+	)
+
+	ctx := context.WithValue(context.Background(), hookKey{}, "acme")
+	l.With("k", "v").InfoContext(ctx, "Hello")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=\"[app] Hello\" k=v tenant=acme\n", w.String())
+}
+
+func Test_WithOnLogged_CalledAfterSuccess(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	var records []slog.Record
+	l := LoggerForTest(new(bytes.Buffer),
+		WithOnLog(func(_ context.Context, r *slog.Record) {
+			r.AddAttrs(slog.Bool("hooked", true))
+		}),
+		WithOnLogged(func(_ context.Context, r slog.Record) {
+			records = append(records, r)
+		}),
+	)
+
+	l.With("user", "bob").WithGroup("req").Warn("Hello", "id", 1)
+	l.Debug("Hidden")
+
+	require.Len(t, records, 1)
+	assert.Equal(t, "Hello", records[0].Message)
+	assert.Equal(t, map[string]any{"user": "bob", "req": map[string]any{"id": int64(1), "hooked": true}}, recordAttrs(records[0]))
+}
+
+func Test_WithOnLogged_NotCalledOnFailure(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	var messages []string
+	sink := &flakySink{}
+	l := LoggerForTest(new(bytes.Buffer), WithSink(sink), WithOnLogged(func(_ context.Context, r slog.Record) {
+		messages = append(messages, r.Message)
+	}))
+
+	l.Info("one")
+	sink.setDown(true)
+	l.Info("two")
+
+	assert.Equal(t, []string{"one"}, messages)
+}
@@ -0,0 +1,135 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// KafkaMessage is a single message to be published to Kafka.
+type KafkaMessage struct {
+	Key   []byte
+	Value []byte
+	Time  time.Time
+}
+
+// KafkaProducer publishes messages to a Kafka topic.
+//
+// slogflags does not depend on a particular Kafka client; instead this
+// interface should be implemented using a small adapter around your client
+// of choice. For example, using github.com/twmb/franz-go:
+//
+//	type franzProducer struct{ client *kgo.Client }
+//
+//	func (p franzProducer) Produce(ctx context.Context, topic string, msgs []slogflags.KafkaMessage) error {
+//		records := make([]*kgo.Record, len(msgs))
+//		for i, m := range msgs {
+//			records[i] = &kgo.Record{Topic: topic, Key: m.Key, Value: m.Value, Timestamp: m.Time}
+//		}
+//		return p.client.ProduceSync(ctx, records...).FirstErr()
+//	}
+type KafkaProducer interface {
+	// Produce synchronously publishes the given messages to the topic,
+	// returning once they have been acknowledged.
+	Produce(ctx context.Context, topic string, messages []KafkaMessage) error
+}
+
+// KafkaConfig configures a [KafkaSink].
+type KafkaConfig struct {
+	// Producer is used to publish messages. It is required.
+	Producer KafkaProducer
+
+	// Topic is the name of the topic records are published to.
+	Topic string
+
+	// KeyAttr is the name of the attribute used as the message key. Keys
+	// within groups can be specified by separating the group names and key
+	// with dots, e.g. "request.id". If empty, or if a record does not have
+	// the attribute, messages are published without a key.
+	KeyAttr string
+
+	// BatchSize is the maximum number of records that will be buffered before
+	// they are published. Defaults to 100.
+	BatchSize int
+
+	// FlushInterval is the maximum amount of time records will be buffered
+	// before they are published. Defaults to 1 second.
+	FlushInterval time.Duration
+
+	// Timeout is the maximum time allowed for publishing each batch.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// KafkaSink is a [Sink] that publishes JSON-encoded records to a Kafka topic.
+// Records are batched and published in the background; all buffered records
+// are published before [KafkaSink.Close] returns.
+type KafkaSink struct {
+	config  KafkaConfig
+	batcher *batcher[KafkaMessage]
+}
+
+// NewKafkaSink creates a new [KafkaSink] with the given config.
+func NewKafkaSink(config KafkaConfig) (*KafkaSink, error) {
+	if config.Producer == nil {
+		return nil, errors.New("kafka: no producer specified")
+	}
+	if config.Topic == "" {
+		return nil, errors.New("kafka: no topic specified")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	s := &KafkaSink{config: config}
+	s.batcher = newBatcher(config.BatchSize, config.FlushInterval, s.send)
+	return s, nil
+}
+
+func (s *KafkaSink) Write(ctx context.Context, r slog.Record) error {
+	doc := recordJSON(ctx, r)
+
+	var key []byte
+	if s.config.KeyAttr != "" {
+		if v, ok := lookupAttr(doc, s.config.KeyAttr); ok {
+			key = fmt.Append(nil, v)
+		}
+	}
+
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("kafka: unable to encode record: %w", err)
+	}
+
+	return s.batcher.add(KafkaMessage{Key: key, Value: value, Time: r.Time})
+}
+
+// Flush sends any batched records immediately.
+func (s *KafkaSink) Flush() error {
+	return s.batcher.sync()
+}
+
+// Close publishes any buffered records, and then stops the background
+// publishing. It does not close the underlying producer.
+func (s *KafkaSink) Close() error {
+	return s.batcher.close()
+}
+
+func (s *KafkaSink) send(messages []KafkaMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	if err := s.config.Producer.Produce(ctx, s.config.Topic, messages); err != nil {
+		return fmt.Errorf("kafka: unable to publish %d records: %w", len(messages), err)
+	}
+	return nil
+}
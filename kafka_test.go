@@ -0,0 +1,84 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKafkaProducer struct {
+	mu      sync.Mutex
+	batches [][]KafkaMessage
+	topic   string
+	err     error
+}
+
+func (f *fakeKafkaProducer) Produce(_ context.Context, topic string, messages []KafkaMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.topic = topic
+	f.batches = append(f.batches, messages)
+	return f.err
+}
+
+func Test_KafkaSink_PublishesOnClose(t *testing.T) {
+	producer := &fakeKafkaProducer{}
+	sink, err := NewKafkaSink(KafkaConfig{Producer: producer, Topic: "logs", KeyAttr: "req.user", FlushInterval: time.Hour})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.WithGroup("req").Info("One", "user", "bob")
+	l.Info("Two")
+
+	producer.mu.Lock()
+	assert.Empty(t, producer.batches)
+	producer.mu.Unlock()
+
+	require.NoError(t, sink.Close())
+	require.Len(t, producer.batches, 1)
+	require.Len(t, producer.batches[0], 2)
+	assert.Equal(t, "logs", producer.topic)
+	assert.Equal(t, []byte("bob"), producer.batches[0][0].Key)
+	assert.Nil(t, producer.batches[0][1].Key)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(producer.batches[0][0].Value, &doc))
+	assert.Equal(t, "One", doc["msg"])
+	assert.Equal(t, "INFO", doc["level"])
+	assert.Equal(t, map[string]any{"user": "bob"}, doc["req"])
+}
+
+func Test_KafkaSink_PublishesFullBatches(t *testing.T) {
+	producer := &fakeKafkaProducer{}
+	sink, err := NewKafkaSink(KafkaConfig{Producer: producer, Topic: "logs", BatchSize: 2, FlushInterval: time.Hour})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+	l.Info("Two")
+
+	assert.Eventually(t, func() bool {
+		producer.mu.Lock()
+		defer producer.mu.Unlock()
+		return len(producer.batches) == 1 && len(producer.batches[0]) == 2
+	}, time.Second, time.Millisecond)
+}
+
+func Test_KafkaSink_ReturnsErrorsOnClose(t *testing.T) {
+	producer := &fakeKafkaProducer{err: errors.New("broker unavailable")}
+	sink, err := NewKafkaSink(KafkaConfig{Producer: producer, Topic: "logs"})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+
+	assert.ErrorContains(t, sink.Close(), "broker unavailable")
+}
@@ -0,0 +1,132 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxKlogVerbosity is the highest verbosity level considered by
+// [KlogVerbosity]. Kubernetes components rarely log beyond V(10).
+const maxKlogVerbosity = 10
+
+// KlogVerbosity returns the highest klog (or glog) verbosity level whose
+// records would be written by the logger created by the most recent call to
+// [Logger], when levels are mapped using [DefaultLogrLevel]. klog discards
+// records above its own `-v` flag before they reach slogflags, so this can be
+// used to keep the two in sync.
+//
+// klog can be configured to log through slogflags as follows:
+//
+//	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+//	klog.InitFlags(fs)
+//	_ = fs.Set("v", strconv.Itoa(slogflags.KlogVerbosity()))
+//	klog.SetSlogLogger(slog.New(slogflags.NewLogrHandler(slogflags.LogrConfig{})))
+//
+// Older versions of klog, and glog, write text rather than passing records
+// to a logger. See [NewKlogWriter] for those.
+func KlogVerbosity() int {
+	logger := L()
+	ctx := context.Background()
+	v := 0
+	for v < maxKlogVerbosity && logger.Enabled(ctx, DefaultLogrLevel(v+1)) {
+		v++
+	}
+	return v
+}
+
+// klogHeader matches the header at the start of each line written by klog
+// and glog, e.g. "I1016 12:34:56.789012   1234 main.go:42] ".
+var klogHeader = regexp.MustCompile(`^([IWEF])(\d{2})(\d{2}) (\d{2}):(\d{2}):(\d{2})\.(\d{6})\s+\d+ ([^ \]]+)\] ?`)
+
+// klogLevels maps klog and glog severities to slog levels.
+var klogLevels = map[byte]slog.Level{
+	'I': slog.LevelInfo,
+	'W': slog.LevelWarn,
+	'E': slog.LevelError,
+	'F': LevelFatal,
+}
+
+// klogWriter is an [io.Writer] that parses lines in the klog and glog text
+// format, and logs each as a record.
+type klogWriter struct {
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	buf   []byte
+	level slog.Level
+}
+
+// NewKlogWriter returns an [io.Writer] that parses lines written in the text
+// format used by klog and glog, and logs each as a record with the matching
+// level, time and message. The source location is added as a "caller"
+// attribute. Lines without a header, such as stack traces, are logged at the
+// level of the preceding line. Records are logged using the given logger, or
+// [L] if it is nil:
+//
+//	klog.LogToStderr(false)
+//	klog.SetOutput(slogflags.NewKlogWriter(nil))
+//
+// Verbosity isn't included in the text format, so all records are logged at
+// info level or above; use [KlogVerbosity] to control which are written.
+func NewKlogWriter(logger *slog.Logger) io.Writer {
+	if logger == nil {
+		logger = L()
+	}
+	return &klogWriter{logger: logger}
+}
+
+func (w *klogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// logLine logs a single line. Lines without a header are logged at the level
+// of the previous line.
+func (w *klogWriter) logLine(line string) {
+	if line == "" {
+		return
+	}
+
+	r := slog.NewRecord(time.Now(), w.level, line, 0)
+	if m := klogHeader.FindStringSubmatch(line); m != nil {
+		r = slog.NewRecord(klogTime(m[2:8], time.Now()), klogLevels[m[1][0]], line[len(m[0]):], 0)
+		r.AddAttrs(slog.String("caller", m[8]))
+		w.level = r.Level
+	}
+
+	ctx := context.Background()
+	if w.logger.Enabled(ctx, r.Level) {
+		_ = w.logger.Handler().Handle(ctx, r)
+	}
+}
+
+// klogTime converts the month, day, hour, minute, second and microsecond
+// fields of a header to a time. The header doesn't include a year, so the
+// current year is assumed.
+func klogTime(fields []string, now time.Time) time.Time {
+	var v [6]int
+	for i, f := range fields {
+		v[i], _ = strconv.Atoi(f)
+	}
+	return time.Date(now.Year(), time.Month(v[0]), v[1], v[2], v[3], v[4], v[5]*1000, now.Location())
+}
@@ -0,0 +1,49 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_KlogVerbosity(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	defer flag.Set("log.level", "")
+
+	_ = flag.Set("log.level", "info")
+	Logger(WithWriter(io.Discard))
+	assert.Equal(t, 0, KlogVerbosity())
+
+	_ = flag.Set("log.level", "debug")
+	Logger(WithWriter(io.Discard))
+	assert.Equal(t, 1, KlogVerbosity())
+
+	_ = flag.Set("log.level", "error")
+	Logger(WithWriter(io.Discard))
+	assert.Equal(t, 0, KlogVerbosity())
+}
+
+func Test_KlogWriter(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	kw := NewKlogWriter(LoggerForTest(w))
+	_, _ = io.WriteString(kw, "W1016 12:34:56.789012    1234 reflector.go:42] Watch closed\n")
+	_, _ = io.WriteString(kw, "E1016 12:34:57.000000    1234 main.go:7] Failed\n")
+	_, _ = io.WriteString(kw, "goroutine 1 [running]:\n")
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"Watch closed\" caller=reflector.go:42\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=Failed caller=main.go:7\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=\"goroutine 1 [running]:\"\n", w.String())
+}
+
+func Test_KlogTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 16, 12, 34, 56, 789012000, time.UTC), klogTime([]string{"10", "16", "12", "34", "56", "789012"}, now))
+}
@@ -0,0 +1,104 @@
+package slogflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// levelAliases are alternative names for levels accepted by [ParseLevel], in
+// addition to the defaults.
+var levelAliases = map[string]slog.Level{
+	"trace":   slog.LevelDebug - 4,
+	"warning": slog.LevelWarn,
+	"fatal":   LevelFatal,
+}
+
+// ParseLevel parses a level in the same way as the `log.level` flag, so that
+// levels from other sources of configuration are handled consistently. The
+// grammar is:
+//
+//	level  = name [ offset ] | number
+//	offset = ( "+" | "-" ) digits
+//	number = [ "+" | "-" ] digits
+//
+// Names are matched case-insensitively, and may be "debug", "info", "warn"
+// or "error", the aliases "trace" (debug-4), "warning" and "fatal" (see
+// [LevelFatal]), or a key in custom (see [WithCustomLevels]). An offset is
+// added to the named level, so "info+2" is between info and warn. Numbers
+// are the numeric value of a [log/slog.Level], e.g. "-4" for debug.
+func ParseLevel(s string, custom map[string]slog.Level) (slog.Level, error) {
+	if s == "" {
+		return 0, errors.New("empty log level")
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		return slog.Level(n), nil
+	}
+
+	if level, ok := lookupLevel(s, custom); ok {
+		return level, nil
+	}
+
+	name, offset := s, 0
+	if i := strings.LastIndexAny(s, "+-"); i > 0 {
+		n, err := strconv.Atoi(s[i:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset in log level %q", s)
+		}
+		name, offset = s[:i], n
+	}
+
+	level, ok := lookupLevel(name, custom)
+	if !ok {
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+	return level + slog.Level(offset), nil
+}
+
+// lookupLevel finds the level with the given name, ignoring case.
+func lookupLevel(name string, custom map[string]slog.Level) (slog.Level, bool) {
+	name = strings.ToLower(name)
+	if l, ok := defaultLevels[name]; ok {
+		return l, true
+	}
+	if l, ok := levelAliases[name]; ok {
+		return l, true
+	}
+	for k, l := range custom {
+		if strings.ToLower(k) == name {
+			return l, true
+		}
+	}
+	return 0, false
+}
+
+// levelNames maps levels to the names they are written with, for levels
+// that have a custom name (see [WithCustomLevels]).
+type levelNames map[slog.Level]string
+
+// name returns the name of the level.
+func (n levelNames) name(level slog.Level) string {
+	if name, ok := n[level]; ok {
+		return name
+	}
+	return level.String()
+}
+
+type levelNamesKey struct{}
+
+// withLevelNames returns a context carrying the names sinks should use for
+// levels.
+func withLevelNames(ctx context.Context, names levelNames) context.Context {
+	return context.WithValue(ctx, levelNamesKey{}, names)
+}
+
+// levelName returns the name of the level, using any custom names carried by
+// the context.
+func levelName(ctx context.Context, level slog.Level) string {
+	names, _ := ctx.Value(levelNamesKey{}).(levelNames)
+	return names.name(level)
+}
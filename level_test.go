@@ -0,0 +1,67 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseLevel(t *testing.T) {
+	custom := map[string]slog.Level{"Verbose": -8, "very-quiet": 12}
+	tests := []struct {
+		input string
+		want  slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"Warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{"fatal", LevelFatal},
+		{"trace", slog.LevelDebug - 4},
+		{"verbose", -8},
+		{"very-quiet", 12},
+		{"info+2", slog.LevelInfo + 2},
+		{"ERROR-1", slog.LevelError - 1},
+		{"verbose+1", -7},
+		{"-4", slog.LevelDebug},
+		{"+8", slog.LevelError},
+		{"3", 3},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input, custom)
+		if assert.NoError(t, err, tt.input) {
+			assert.Equal(t, tt.want, got, tt.input)
+		}
+	}
+}
+
+func Test_ParseLevel_Errors(t *testing.T) {
+	tests := map[string]string{
+		"":          "empty log level",
+		"loud":      `unknown log level "loud"`,
+		"loud+1":    `unknown log level "loud"`,
+		"info+x":    `invalid offset in log level "info+x"`,
+		"very-loud": `invalid offset in log level "very-loud"`,
+	}
+	for input, want := range tests {
+		_, err := ParseLevel(input, nil)
+		assert.EqualError(t, err, want, input)
+	}
+}
+
+func Test_LevelFlag_AcceptsOffsets(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "info+1")
+	defer flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.Info("Hidden")
+	l.Log(t.Context(), slog.LevelInfo+1, "Shown")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO+1 msg=Shown\n", w.String())
+}
@@ -0,0 +1,90 @@
+package slogflags
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// LeveledLogger implements the LeveledLogger interface used by
+// github.com/hashicorp/go-retryablehttp and similar libraries, logging
+// through a [log/slog.Logger]:
+//
+//	client := retryablehttp.NewClient()
+//	client.Logger = slogflags.NewLeveledLogger(nil)
+//
+// Key/value pairs are converted to attributes. Unlike passing them directly
+// to [log/slog.Logger], keys that aren't strings are formatted using
+// [fmt.Sprint] rather than being reported as "!BADKEY", and a trailing key
+// without a value is given a nil value.
+type LeveledLogger struct {
+	logger *slog.Logger
+}
+
+// NewLeveledLogger creates a new [LeveledLogger] that logs using the given
+// logger, or [L] if it is nil.
+func NewLeveledLogger(logger *slog.Logger) *LeveledLogger {
+	if logger == nil {
+		logger = L()
+	}
+	return &LeveledLogger{logger: logger}
+}
+
+// log logs a message with the given key/value pairs, attributing it to the
+// caller of the exported method.
+func (l *LeveledLogger) log(level slog.Level, msg string, keysAndValues []any) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	// Skip runtime.Callers, log, and the exported method.
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(keyValueAttrs(keysAndValues)...)
+	_ = l.logger.Handler().Handle(ctx, r)
+}
+
+// keyValueAttrs converts alternating keys and values into attributes.
+// Existing attributes are passed through unchanged.
+func keyValueAttrs(keysAndValues []any) []slog.Attr {
+	var attrs []slog.Attr
+	for i := 0; i < len(keysAndValues); i++ {
+		if a, ok := keysAndValues[i].(slog.Attr); ok {
+			attrs = append(attrs, a)
+			continue
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+
+		var value any
+		if i+1 < len(keysAndValues) {
+			i++
+			value = keysAndValues[i]
+		}
+		attrs = append(attrs, slog.Any(key, value))
+	}
+	return attrs
+}
+
+func (l *LeveledLogger) Error(msg string, keysAndValues ...any) {
+	l.log(slog.LevelError, msg, keysAndValues)
+}
+
+func (l *LeveledLogger) Warn(msg string, keysAndValues ...any) {
+	l.log(slog.LevelWarn, msg, keysAndValues)
+}
+
+func (l *LeveledLogger) Info(msg string, keysAndValues ...any) {
+	l.log(slog.LevelInfo, msg, keysAndValues)
+}
+
+func (l *LeveledLogger) Debug(msg string, keysAndValues ...any) {
+	l.log(slog.LevelDebug, msg, keysAndValues)
+}
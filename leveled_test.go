@@ -0,0 +1,36 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// retryableLeveledLogger mirrors the retryablehttp.LeveledLogger interface.
+type retryableLeveledLogger interface {
+	Error(msg string, keysAndValues ...any)
+	Info(msg string, keysAndValues ...any)
+	Debug(msg string, keysAndValues ...any)
+	Warn(msg string, keysAndValues ...any)
+}
+
+var _ retryableLeveledLogger = (*LeveledLogger)(nil)
+
+func Test_LeveledLogger(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := NewLeveledLogger(LoggerForTest(w))
+	l.Debug("performing request", "method", "GET")
+	l.Info("performing request", "method", "GET", "url", "http://example.com")
+	l.Warn("retrying", 1, "attempt", "remaining")
+	l.Error("request failed", "error", "timeout")
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"performing request\" method=GET url=http://example.com\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=retrying 1=attempt remaining=<nil>\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=\"request failed\" error=timeout\n", w.String())
+}
@@ -0,0 +1,101 @@
+package slogflags
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// formatHandler creates a handler that writes records to w in the given
+// format, as accepted by the `log.format` flag. If console is true, the
+// "text" format is replaced by the colourised console format.
+func formatHandler(format string, w io.Writer, opts *slog.HandlerOptions, console bool) slog.Handler {
+	switch {
+	case format == "json":
+		return slog.NewJSONHandler(w, opts)
+	case format == "fasttext":
+		return newFastTextHandler(w, opts)
+	case format == "expanded":
+		return newExpandedHandler(w, opts)
+	case console:
+		return newConsoleHandler(w, opts)
+	default:
+		return slog.NewTextHandler(w, opts)
+	}
+}
+
+// levelFormat is a handler used for records at or above a level.
+type levelFormat struct {
+	level   slog.Level
+	handler slog.Handler
+}
+
+// levelFormatHandler is a [log/slog.Handler] that passes each record to the
+// handler for the highest level it reaches, or to a default handler if it
+// doesn't reach any of them.
+type levelFormatHandler struct {
+	formats  []levelFormat
+	fallback slog.Handler
+}
+
+// newLevelFormatHandler creates a levelFormatHandler using a handler for each
+// of the given formats.
+func newLevelFormatHandler(fallback slog.Handler, formats map[slog.Level]string, w io.Writer, opts *slog.HandlerOptions, console bool) *levelFormatHandler {
+	h := &levelFormatHandler{fallback: fallback}
+	for level, format := range formats {
+		h.formats = append(h.formats, levelFormat{level: level, handler: formatHandler(format, w, opts, console)})
+	}
+	slices.SortFunc(h.formats, func(a, b levelFormat) int {
+		return int(b.level) - int(a.level)
+	})
+	return h
+}
+
+// handler returns the handler used for records at the given level.
+func (h *levelFormatHandler) handler(level slog.Level) slog.Handler {
+	for _, f := range h.formats {
+		if level >= f.level {
+			return f.handler
+		}
+	}
+	return h.fallback
+}
+
+func (h *levelFormatHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler(level).Enabled(ctx, level)
+}
+
+func (h *levelFormatHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler(r.Level).Handle(ctx, r)
+}
+
+func (h *levelFormatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.apply(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *levelFormatHandler) WithGroup(name string) slog.Handler {
+	return h.apply(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *levelFormatHandler) apply(fn func(slog.Handler) slog.Handler) *levelFormatHandler {
+	h2 := &levelFormatHandler{fallback: fn(h.fallback)}
+	for _, f := range h.formats {
+		h2.formats = append(h2.formats, levelFormat{level: f.level, handler: fn(f.handler)})
+	}
+	return h2
+}
+
+// lockedWriter serialises writes to a writer that is shared by several
+// handlers, each of which only serialises its own writes.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
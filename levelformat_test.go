@@ -0,0 +1,34 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithLevelFormat(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "debug")
+	defer flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithLevelFormat(slog.LevelWarn, "json"), WithLevelFormat(slog.LevelError, "expanded")).With("k", "v")
+	l.Debug("Debugging")
+	l.Warn("Warning")
+	l.Error("Failed")
+
+	assert.Regexp(t, "^"+
+		"time=2026-01-02T03:04:05.000Z level=DEBUG msg=Debugging k=v\n"+
+		`\{"time":"2026-01-02T03:04:05Z","level":"WARN","msg":"Warning","k":"v"\}`+"\n"+
+		"2026-01-02T03:04:05.000Z ERROR Failed\n"+
+		"    k: v\n"+
+		"    stack:\n", w.String())
+}
+
+func Test_WithLevelFormat_Validate(t *testing.T) {
+	err := Validate(ValidateConfig{Options: []Option{WithLevelFormat(slog.LevelError, "xml")}})
+	assert.EqualError(t, err, `level format for ERROR: unknown format "xml"`)
+}
@@ -0,0 +1,120 @@
+package slogflags
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	lifecycleMu sync.Mutex
+	flushFuncs  []lifecycleFunc
+	closeFuncs  []lifecycleFunc
+)
+
+// lifecycleFunc is a func registered to be called by [Flush] or [Close],
+// along with the logger that it belongs to, if any.
+type lifecycleFunc struct {
+	owner *atomic.Pointer[slog.Handler]
+	fn    func() error
+}
+
+// registerFlush adds a func that will be called by [Flush]. Funcs are called
+// in the reverse order to which they were registered, so that anything
+// wrapping an output is flushed before the output itself.
+func registerFlush(fn func() error) {
+	registerOwnedFlush(nil, fn)
+}
+
+// registerClose adds a func that will be called by [Close], after all
+// registered flush funcs. As with flushing, funcs are called in the reverse
+// order to which they were registered.
+func registerClose(fn func() error) {
+	registerOwnedClose(nil, fn)
+}
+
+// registerOwnedFlush adds a func that will be called by [Flush], or by
+// [Reconfigure] when the owning logger's handler is replaced.
+func registerOwnedFlush(owner *atomic.Pointer[slog.Handler], fn func() error) {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+	flushFuncs = append(flushFuncs, lifecycleFunc{owner: owner, fn: fn})
+}
+
+// registerOwnedClose adds a func that will be called by [Close], or by
+// [Reconfigure] when the owning logger's handler is replaced.
+func registerOwnedClose(owner *atomic.Pointer[slog.Handler], fn func() error) {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+	closeFuncs = append(closeFuncs, lifecycleFunc{owner: owner, fn: fn})
+}
+
+// registerFlush registers a flush func owned by the logger being configured.
+func (c *config) registerFlush(fn func() error) {
+	registerOwnedFlush(c.owner, fn)
+}
+
+// registerClose registers a close func owned by the logger being configured.
+func (c *config) registerClose(fn func() error) {
+	registerOwnedClose(c.owner, fn)
+}
+
+// takeOwned removes and returns the flush and close funcs registered by the
+// given owner.
+func takeOwned(owner *atomic.Pointer[slog.Handler]) (flushes, closes []lifecycleFunc) {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+
+	owned := func(f lifecycleFunc) bool { return f.owner == owner }
+	for _, f := range flushFuncs {
+		if owned(f) {
+			flushes = append(flushes, f)
+		}
+	}
+	for _, f := range closeFuncs {
+		if owned(f) {
+			closes = append(closes, f)
+		}
+	}
+	flushFuncs = slices.DeleteFunc(flushFuncs, owned)
+	closeFuncs = slices.DeleteFunc(closeFuncs, owned)
+	return flushes, closes
+}
+
+// Flush blocks until all records logged so far by loggers created with
+// [Logger] have been written, for example by waiting for queues created by
+// [WithAsync] to drain and writing any output buffered because of the
+// `log.buffer-size` flag. It should be called before the application exits.
+func Flush() error {
+	lifecycleMu.Lock()
+	fns := slices.Clone(flushFuncs)
+	lifecycleMu.Unlock()
+
+	return callReversed(fns)
+}
+
+// Close flushes all records as per [Flush], and then closes any files or
+// sinks that were opened by [Logger] because of the `log.output` flag or
+// options such as [WithDevAndFile]. Sinks passed to [WithSink] are not
+// closed.
+//
+// Loggers created before Close is called should not be used afterwards.
+func Close() error {
+	lifecycleMu.Lock()
+	flushes, closes := flushFuncs, closeFuncs
+	flushFuncs, closeFuncs = nil, nil
+	lifecycleMu.Unlock()
+
+	return errors.Join(callReversed(flushes), callReversed(closes))
+}
+
+// callReversed calls each func in reverse order, returning any errors.
+func callReversed(fns []lifecycleFunc) error {
+	var errs []error
+	for _, f := range slices.Backward(fns) {
+		errs = append(errs, f.fn())
+	}
+	return errors.Join(errs...)
+}
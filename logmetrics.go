@@ -0,0 +1,246 @@
+package slogflags
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// metricKindHistogram is the kind of metrics derived from the values of
+// attributes. It isn't exported, as histograms are reported to OpenTelemetry
+// as a pair of counters.
+const metricKindHistogram = MetricKindGauge + 1
+
+// DefaultLogMetricBuckets are the histogram buckets used by a [LogMetric] if
+// none are given. They're the same as the default Prometheus buckets, and
+// suit durations measured in seconds.
+var DefaultLogMetricBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// LogMetric defines a metric that is derived from log records, so that basic
+// metrics can be obtained from structured logs without separate
+// instrumentation. See [Metrics.AddLogMetric].
+type LogMetric struct {
+	// Name is the name of the metric, such as "http_requests_total". It
+	// should follow the Prometheus naming conventions, and is used for both
+	// Prometheus and OpenTelemetry.
+	Name string
+
+	// Help describes the metric.
+	Help string
+
+	// Message restricts the metric to records with the given message, such
+	// as "request.done". If empty, all records are included.
+	Message string
+
+	// Attr is the key of a numeric attribute whose values are recorded in a
+	// histogram, such as "duration_ms". Attributes in groups are specified
+	// using dotted paths, e.g. "http.duration". Durations are recorded in
+	// seconds. Records without the attribute aren't counted. If empty, the
+	// metric is a counter of matching records.
+	Attr string
+
+	// Buckets are the upper bounds of the histogram buckets, in increasing
+	// order. Only used if Attr is set. Defaults to
+	// [DefaultLogMetricBuckets].
+	Buckets []float64
+
+	// Labels are the keys of attributes whose values are used as labels, such
+	// as "method" or "status". Records without one of the attributes have an
+	// empty value for that label.
+	Labels []string
+}
+
+// logMetric holds the definition and current values of a LogMetric.
+type logMetric struct {
+	def    LogMetric
+	points map[string]*logMetricPoint
+}
+
+// logMetricPoint holds the values of a LogMetric for one set of labels.
+type logMetricPoint struct {
+	labels  []string
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// AddLogMetric adds a metric derived from the records logged by loggers
+// using these metrics. For example, to record a histogram of request
+// durations by status from records logged as
+// `logger.Info("request.done", "status", 200, "duration_ms", 12)`:
+//
+//	metrics.AddLogMetric(slogflags.LogMetric{
+//		Name:    "http_request_duration_milliseconds",
+//		Help:    "Time taken to handle HTTP requests.",
+//		Message: "request.done",
+//		Attr:    "duration_ms",
+//		Buckets: []float64{5, 10, 50, 100, 500, 1000},
+//		Labels:  []string{"status"},
+//	})
+//
+// Log metrics must be added before [Logger] is called with [WithMetrics], and
+// before [Metrics.RegisterOTel] is called. Only records at or above the
+// logger's level are included. Histograms are reported to OpenTelemetry as
+// two counters, with ".count" and ".sum" appended to the name; the sum is
+// rounded to the nearest integer.
+func (m *Metrics) AddLogMetric(metric LogMetric) error {
+	if metric.Name == "" {
+		return errors.New("metrics: log metric has no name")
+	}
+	if metric.Attr != "" && metric.Buckets == nil {
+		metric.Buckets = DefaultLogMetricBuckets
+	}
+	if !slices.IsSorted(metric.Buckets) {
+		return fmt.Errorf("metrics: buckets for log metric %s are not in increasing order", metric.Name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if slices.ContainsFunc(m.logMetrics, func(lm *logMetric) bool { return lm.def.Name == metric.Name }) {
+		return fmt.Errorf("metrics: duplicate log metric %s", metric.Name)
+	}
+	m.logMetrics = append(m.logMetrics, &logMetric{def: metric, points: map[string]*logMetricPoint{}})
+	return nil
+}
+
+// hasLogMetrics determines whether any log metrics have been added.
+func (m *Metrics) hasLogMetrics() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.logMetrics) > 0
+}
+
+// observe updates the log metrics that match a fully resolved record.
+func (m *Metrics) observe(r slog.Record) error {
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, lm := range m.logMetrics {
+		if lm.def.Message != "" && lm.def.Message != r.Message {
+			continue
+		}
+
+		var value float64
+		if lm.def.Attr != "" {
+			v, ok := findAttr(attrs, lm.def.Attr)
+			if !ok {
+				continue
+			}
+			if value, ok = numericValue(v); !ok {
+				continue
+			}
+		}
+
+		labels := make([]string, len(lm.def.Labels))
+		for i, key := range lm.def.Labels {
+			if v, ok := findAttr(attrs, key); ok {
+				labels[i] = v.String()
+			}
+		}
+
+		key := strings.Join(labels, "\x00")
+		p, ok := lm.points[key]
+		if !ok {
+			p = &logMetricPoint{labels: labels, buckets: make([]uint64, len(lm.def.Buckets))}
+			lm.points[key] = p
+		}
+		p.count++
+		if lm.def.Attr != "" {
+			p.sum += value
+			if i, _ := slices.BinarySearch(lm.def.Buckets, value); i < len(p.buckets) {
+				p.buckets[i]++
+			}
+		}
+	}
+	return nil
+}
+
+// collectLogMetrics returns the current values of the log metrics. m.mu must
+// be held.
+func (m *Metrics) collectLogMetrics() []metricFamily {
+	var families []metricFamily
+	for _, lm := range m.logMetrics {
+		f := metricFamily{
+			promName: lm.def.Name,
+			otelName: lm.def.Name,
+			help:     lm.def.Help,
+			unit:     "{record}",
+			kind:     MetricKindCounter,
+		}
+		if lm.def.Attr != "" {
+			f.unit = "1"
+			f.kind = metricKindHistogram
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(lm.points)) {
+			p := lm.points[key]
+			point := metricPoint{value: int64(p.count)}
+			for i, name := range lm.def.Labels {
+				point.labels = append(point.labels, metricLabel{name, p.labels[i]})
+			}
+			if lm.def.Attr != "" {
+				point.histogram = &histogramValue{
+					bounds: lm.def.Buckets,
+					counts: slices.Clone(p.buckets),
+					sum:    p.sum,
+				}
+			}
+			f.points = append(f.points, point)
+		}
+		families = append(families, f)
+	}
+	return families
+}
+
+// histogramValue is the value of a histogram for one set of labels. The
+// count of records is held in the metricPoint's value.
+type histogramValue struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+}
+
+// findAttr returns the value of the attribute with the given key, which may
+// be a dotted path to an attribute within groups.
+func findAttr(attrs []slog.Attr, key string) (slog.Value, bool) {
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if a.Key == key {
+			return v, true
+		}
+		if v.Kind() == slog.KindGroup && strings.HasPrefix(key, a.Key+".") {
+			if v, ok := findAttr(v.Group(), key[len(a.Key)+1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return slog.Value{}, false
+}
+
+// numericValue converts a numeric value to a float64. Durations are
+// converted to seconds.
+func numericValue(v slog.Value) (float64, bool) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return float64(v.Int64()), true
+	case slog.KindUint64:
+		return float64(v.Uint64()), true
+	case slog.KindFloat64:
+		return v.Float64(), true
+	case slog.KindDuration:
+		return v.Duration().Seconds(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,107 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LogMetrics_Prometheus(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	metrics := NewMetrics()
+	require.NoError(t, metrics.AddLogMetric(LogMetric{
+		Name:    "requests_total",
+		Help:    "Requests handled.",
+		Message: "request.done",
+		Labels:  []string{"method"},
+	}))
+	require.NoError(t, metrics.AddLogMetric(LogMetric{
+		Name:    "request_duration_milliseconds",
+		Help:    "Time taken to handle requests.",
+		Message: "request.done",
+		Attr:    "http.duration_ms",
+		Buckets: []float64{10, 100},
+	}))
+
+	l := LoggerForTest(new(bytes.Buffer), WithMetrics(metrics))
+	l.Info("request.done", "method", "GET", slog.Group("http", "duration_ms", 5))
+	l.With("method", "POST").Info("request.done", slog.Group("http", "duration_ms", 50.5))
+	l.Info("request.done", "method", "GET", slog.Group("http", "duration_ms", 500))
+	l.Info("request.done", "method", "GET")
+	l.Info("other", "method", "GET", slog.Group("http", "duration_ms", 1))
+	l.Debug("request.done", "method", "GET")
+
+	out := new(bytes.Buffer)
+	require.NoError(t, metrics.WritePrometheus(out))
+
+	assert.Contains(t, out.String(), ""+
+		"# HELP requests_total Requests handled.\n"+
+		"# TYPE requests_total counter\n"+
+		`requests_total{method="GET"} 3`+"\n"+
+		`requests_total{method="POST"} 1`+"\n"+
+		"# HELP request_duration_milliseconds Time taken to handle requests.\n"+
+		"# TYPE request_duration_milliseconds histogram\n"+
+		`request_duration_milliseconds_bucket{le="10"} 1`+"\n"+
+		`request_duration_milliseconds_bucket{le="100"} 2`+"\n"+
+		`request_duration_milliseconds_bucket{le="+Inf"} 3`+"\n"+
+		`request_duration_milliseconds_sum{} 555.5`+"\n"+
+		`request_duration_milliseconds_count{} 3`+"\n")
+}
+
+func Test_LogMetrics_Durations(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	metrics := NewMetrics()
+	require.NoError(t, metrics.AddLogMetric(LogMetric{Name: "job_seconds", Attr: "took", Labels: []string{"job"}}))
+
+	l := LoggerForTest(new(bytes.Buffer), WithMetrics(metrics))
+	l.Info("Finished", "job", "backup", "took", 750*time.Millisecond)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, metrics.WritePrometheus(out))
+
+	assert.Contains(t, out.String(), ""+
+		`job_seconds_bucket{job="backup",le="0.5"} 0`+"\n"+
+		`job_seconds_bucket{job="backup",le="1"} 1`+"\n")
+	assert.Contains(t, out.String(), `job_seconds_sum{job="backup"} 0.75`+"\n")
+}
+
+func Test_LogMetrics_OTel(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	metrics := NewMetrics()
+	require.NoError(t, metrics.AddLogMetric(LogMetric{Name: "requests_total", Labels: []string{"status"}}))
+	require.NoError(t, metrics.AddLogMetric(LogMetric{Name: "request_bytes", Attr: "bytes", Buckets: []float64{1024}, Labels: []string{"status"}}))
+
+	meter := newFakeMeter()
+	require.NoError(t, metrics.RegisterOTel(meter))
+
+	l := LoggerForTest(new(bytes.Buffer), WithMetrics(metrics))
+	l.Info("Request", "status", 200, "bytes", 100)
+	l.Info("Request", "status", 200, "bytes", 2000.6)
+	l.Info("Request", "status", 404)
+
+	assert.Equal(t, MetricKindCounter, meter.kinds["requests_total"])
+	assert.Equal(t, MetricKindCounter, meter.kinds["request_bytes.count"])
+	assert.Equal(t, MetricKindCounter, meter.kinds["request_bytes.sum"])
+	assert.Equal(t, map[string]int64{"200": 2, "404": 1}, meter.observe("requests_total", "status"))
+	assert.Equal(t, map[string]int64{"200": 2}, meter.observe("request_bytes.count", "status"))
+	assert.Equal(t, map[string]int64{"200": 2101}, meter.observe("request_bytes.sum", "status"))
+}
+
+func Test_LogMetrics_InvalidDefinitions(t *testing.T) {
+	metrics := NewMetrics()
+	assert.EqualError(t, metrics.AddLogMetric(LogMetric{}), "metrics: log metric has no name")
+	assert.EqualError(t, metrics.AddLogMetric(LogMetric{Name: "x", Attr: "y", Buckets: []float64{2, 1}}), "metrics: buckets for log metric x are not in increasing order")
+	require.NoError(t, metrics.AddLogMetric(LogMetric{Name: "x"}))
+	assert.EqualError(t, metrics.AddLogMetric(LogMetric{Name: "x"}), "metrics: duplicate log metric x")
+}
@@ -0,0 +1,88 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogrConfig configures the handler returned by [NewLogrHandler].
+type LogrConfig struct {
+	// Handler is the handler records are passed to. Defaults to the handler
+	// of [L].
+	Handler slog.Handler
+
+	// Level maps a logr verbosity level to a [log/slog.Level]. Defaults to
+	// [DefaultLogrLevel].
+	Level func(v int) slog.Level
+}
+
+// DefaultLogrLevel maps logr's V(0) to [log/slog.LevelInfo], V(1) to
+// [log/slog.LevelDebug], and each further verbosity level to one level below
+// that. This means that `--log.level=debug` shows V(1) records, which is
+// conventionally used for debugging output.
+func DefaultLogrLevel(v int) slog.Level {
+	if v <= 0 {
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug - slog.Level(v-1)
+}
+
+// NewLogrHandler returns a [log/slog.Handler] for use with
+// github.com/go-logr/logr, so that libraries such as controller-runtime and
+// the Kubernetes client log through slogflags. Pass it to
+// logr.FromSlogHandler:
+//
+//	logger := logr.FromSlogHandler(slogflags.NewLogrHandler(slogflags.LogrConfig{}))
+//	ctrl.SetLogger(logger)
+//
+// logr represents verbosity V(n) as the slog level -n, which doesn't line up
+// with slog's own levels: V(1) would be just below [log/slog.LevelInfo] and
+// never shown at debug level. The handler converts these levels using
+// [LogrConfig.Level]. Errors, and records logged at slog's own levels above
+// [log/slog.LevelInfo], are passed through unchanged.
+func NewLogrHandler(config LogrConfig) slog.Handler {
+	h := &logrHandler{next: config.Handler, level: config.Level}
+	if h.next == nil {
+		h.next = L().Handler()
+	}
+	if h.level == nil {
+		h.level = DefaultLogrLevel
+	}
+	return h
+}
+
+// logrHandler is a [log/slog.Handler] that converts logr verbosity levels to
+// slog levels before passing records to the next handler.
+type logrHandler struct {
+	next  slog.Handler
+	level func(v int) slog.Level
+}
+
+// convert returns the slog level for a level used by logr.
+func (h *logrHandler) convert(level slog.Level) slog.Level {
+	if level > slog.LevelInfo {
+		return level
+	}
+	return h.level(-int(level))
+}
+
+func (h *logrHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, h.convert(level))
+}
+
+func (h *logrHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.Level = h.convert(r.Level)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *logrHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	return &h2
+}
+
+func (h *logrHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	return &h2
+}
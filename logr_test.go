@@ -0,0 +1,58 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DefaultLogrLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelInfo, DefaultLogrLevel(0))
+	assert.Equal(t, slog.LevelDebug, DefaultLogrLevel(1))
+	assert.Equal(t, slog.LevelDebug-1, DefaultLogrLevel(2))
+}
+
+func Test_LogrHandler_ConvertsLevels(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "debug")
+	defer flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	h := NewLogrHandler(LogrConfig{Handler: LoggerForTest(w).Handler()})
+	l := slog.New(h)
+
+	// These are the levels logr uses for V(0), V(1), V(2) and errors.
+	ctx := context.Background()
+	assert.True(t, h.Enabled(ctx, 0))
+	assert.True(t, h.Enabled(ctx, -1))
+	assert.False(t, h.Enabled(ctx, -2))
+
+	l.Log(ctx, 0, "Reconciling", "logger", "controller")
+	l.Log(ctx, -1, "Fetched object")
+	l.Log(ctx, slog.LevelError, "Reconcile failed")
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Reconciling logger=controller\n"+
+		"time=2026-01-02T03:04:05.000Z level=DEBUG msg=\"Fetched object\"\n"+
+		"time=2026-01-02T03:04:05.000Z level=ERROR msg=\"Reconcile failed\"\n", w.String())
+}
+
+func Test_LogrHandler_CustomLevels(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	h := NewLogrHandler(LogrConfig{
+		Handler: LoggerForTest(w).Handler(),
+		Level: func(v int) slog.Level {
+			return slog.LevelInfo
+		},
+	})
+	slog.New(h).Log(context.Background(), -3, "Very verbose")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=\"Very verbose\"\n", w.String())
+}
@@ -0,0 +1,324 @@
+package slogflags
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metrics counts the records written by loggers, and exposes the counts in the
+// Prometheus text format. Pass it to [Logger] using [WithMetrics], and then
+// serve it alongside your application's other metrics:
+//
+//	metrics := slogflags.NewMetrics()
+//	logger := slogflags.Logger(slogflags.WithMetrics(metrics))
+//	http.Handle("/metrics/logs", metrics)
+//
+// The following metrics are exported:
+//
+//   - slogflags_records_total: records written, by level and destination
+//   - slogflags_sink_errors_total: errors returned when writing records, by
+//     destination
+//   - slogflags_records_dropped_total: records dropped by sampling, rate
+//     limiting or full queues, by reason and level (see [Stats])
+//   - slogflags_queue_depth: records waiting in the queue created by
+//     [WithAsync], and in any [QueuedSink]
+//   - slogflags_attrs_dropped_total: attributes dropped because they weren't
+//     allowed by [WithAllowedKeys], by key
+//
+// Metrics derived from the records themselves can be added using
+// [Metrics.AddLogMetric].
+//
+// Destinations are named "output" for the writer selected by the `log.output`
+// flag, or after the type of the sink otherwise (e.g. "NATSSink").
+type Metrics struct {
+	mu           sync.Mutex
+	records      map[recordsKey]uint64
+	errors       map[string]uint64
+	queues       map[string]func() int
+	droppedAttrs map[string]uint64
+	logMetrics   []*logMetric
+}
+
+// recordsKey identifies a counter of records written to a destination.
+type recordsKey struct {
+	sink  string
+	level slog.Level
+}
+
+// NewMetrics creates a new, empty, [Metrics].
+func NewMetrics() *Metrics {
+	return &Metrics{
+		records:      map[recordsKey]uint64{},
+		errors:       map[string]uint64{},
+		queues:       map[string]func() int{},
+		droppedAttrs: map[string]uint64{},
+	}
+}
+
+// count records that a record was written to the named destination.
+func (m *Metrics) count(sink string, level slog.Level, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[recordsKey{sink: sink, level: level}]++
+	if err != nil {
+		m.errors[sink]++
+	}
+}
+
+// countDroppedAttr records that an attribute with the given key was dropped.
+func (m *Metrics) countDroppedAttr(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.droppedAttrs[key]++
+}
+
+// addQueue registers a function that returns the depth of a queue.
+func (m *Metrics) addQueue(name string, depth func() int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queues[name] = depth
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+// WritePrometheus writes the metrics to w in the Prometheus text format. This
+// can be used to append them to the output of another exporter.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for _, f := range m.collect() {
+		kind := "counter"
+		switch f.kind {
+		case MetricKindGauge:
+			kind = "gauge"
+		case metricKindHistogram:
+			kind = "histogram"
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", f.promName, f.help, f.promName, kind)
+
+		for _, p := range f.points {
+			labels := make([]string, len(p.labels))
+			for i, l := range p.labels {
+				labels[i] = l.name + "=" + promLabel(l.value)
+			}
+			if p.histogram != nil {
+				writePrometheusHistogram(bw, f.promName, labels, p)
+				continue
+			}
+			fmt.Fprintf(bw, "%s{%s} %d\n", f.promName, strings.Join(labels, ","), p.value)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writePrometheusHistogram writes the buckets, sum and count of a histogram
+// in the Prometheus text format.
+func writePrometheusHistogram(w io.Writer, name string, labels []string, p metricPoint) {
+	var cumulative uint64
+	for i, bound := range p.histogram.bounds {
+		cumulative += p.histogram.counts[i]
+		le := "le=" + promLabel(strconv.FormatFloat(bound, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_bucket{%s} %d\n", name, strings.Join(append(slices.Clip(labels), le), ","), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s} %d\n", name, strings.Join(append(slices.Clip(labels), `le="+Inf"`), ","), p.value)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, strings.Join(labels, ","), strconv.FormatFloat(p.histogram.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, strings.Join(labels, ","), p.value)
+}
+
+// metricFamily is a single metric and its current values.
+type metricFamily struct {
+	promName string
+	otelName string
+	help     string
+	unit     string
+	kind     MetricKind
+	points   []metricPoint
+}
+
+// metricPoint is the value of a metric for one set of labels.
+type metricPoint struct {
+	labels    []metricLabel
+	value     int64
+	histogram *histogramValue
+}
+
+// metricLabel is a label (or attribute) that distinguishes metric points.
+type metricLabel struct {
+	name  string
+	value string
+}
+
+// collect returns the current values of all metrics. Families are always
+// returned in the same order, and points are sorted by their labels.
+func (m *Metrics) collect() []metricFamily {
+	m.mu.Lock()
+	records := maps.Clone(m.records)
+	errs := maps.Clone(m.errors)
+	queues := maps.Clone(m.queues)
+	droppedAttrs := maps.Clone(m.droppedAttrs)
+	logMetrics := m.collectLogMetrics()
+	m.mu.Unlock()
+
+	recordsFamily := metricFamily{
+		promName: "slogflags_records_total",
+		otelName: "slogflags.records",
+		help:     "Number of log records written, by level and destination.",
+		unit:     "{record}",
+		kind:     MetricKindCounter,
+	}
+	for _, k := range slices.SortedFunc(maps.Keys(records), func(a, b recordsKey) int {
+		return cmp.Or(cmp.Compare(a.sink, b.sink), cmp.Compare(a.level, b.level))
+	}) {
+		recordsFamily.points = append(recordsFamily.points, metricPoint{
+			labels: []metricLabel{{"level", k.level.String()}, {"sink", k.sink}},
+			value:  int64(records[k]),
+		})
+	}
+
+	errorsFamily := metricFamily{
+		promName: "slogflags_sink_errors_total",
+		otelName: "slogflags.sink.errors",
+		help:     "Number of errors returned when writing log records, by destination.",
+		unit:     "{error}",
+		kind:     MetricKindCounter,
+	}
+	for _, sink := range slices.Sorted(maps.Keys(errs)) {
+		errorsFamily.points = append(errorsFamily.points, metricPoint{
+			labels: []metricLabel{{"sink", sink}},
+			value:  int64(errs[sink]),
+		})
+	}
+
+	stats := Stats()
+	droppedFamily := metricFamily{
+		promName: "slogflags_records_dropped_total",
+		otelName: "slogflags.records.dropped",
+		help:     "Number of log records dropped, by reason and level.",
+		unit:     "{record}",
+		kind:     MetricKindCounter,
+	}
+	for _, reason := range []struct {
+		name   string
+		counts map[slog.Level]uint64
+	}{
+		{"queue_full", stats.QueueFull},
+		{"rate_limited", stats.RateLimited},
+		{"sampled", stats.Sampled},
+	} {
+		for _, level := range slices.Sorted(maps.Keys(reason.counts)) {
+			droppedFamily.points = append(droppedFamily.points, metricPoint{
+				labels: []metricLabel{{"level", level.String()}, {"reason", reason.name}},
+				value:  int64(reason.counts[level]),
+			})
+		}
+	}
+
+	queueFamily := metricFamily{
+		promName: "slogflags_queue_depth",
+		otelName: "slogflags.queue.depth",
+		help:     "Number of log records waiting in a queue.",
+		unit:     "{record}",
+		kind:     MetricKindGauge,
+	}
+	for _, name := range slices.Sorted(maps.Keys(queues)) {
+		queueFamily.points = append(queueFamily.points, metricPoint{
+			labels: []metricLabel{{"queue", name}},
+			value:  int64(queues[name]()),
+		})
+	}
+
+	droppedAttrsFamily := metricFamily{
+		promName: "slogflags_attrs_dropped_total",
+		otelName: "slogflags.attrs.dropped",
+		help:     "Number of log attributes dropped because they weren't allowed, by key.",
+		unit:     "{attribute}",
+		kind:     MetricKindCounter,
+	}
+	for _, key := range slices.Sorted(maps.Keys(droppedAttrs)) {
+		droppedAttrsFamily.points = append(droppedAttrsFamily.points, metricPoint{
+			labels: []metricLabel{{"key", key}},
+			value:  int64(droppedAttrs[key]),
+		})
+	}
+
+	return append([]metricFamily{recordsFamily, errorsFamily, droppedFamily, queueFamily, droppedAttrsFamily}, logMetrics...)
+}
+
+// promLabelReplacer escapes label values for the Prometheus text format.
+var promLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promLabel quotes a label value for the Prometheus text format.
+func promLabel(value string) string {
+	return `"` + promLabelReplacer.Replace(value) + `"`
+}
+
+// sinkName returns the name used to identify a sink in metrics. Sinks that
+// wrap another sink to change how it's written to are named after the sink
+// they wrap.
+func sinkName(sink Sink) string {
+	switch s := sink.(type) {
+	case *QueuedSink:
+		return sinkName(s.sink)
+	case *SpillSink:
+		return sinkName(s.sink)
+	}
+
+	t := reflect.TypeOf(sink)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// metricsHandler is a [log/slog.Handler] that counts the records handled by
+// the next handler, and any errors it returns.
+type metricsHandler struct {
+	next    slog.Handler
+	metrics *Metrics
+	name    string
+}
+
+func newMetricsHandler(next slog.Handler, metrics *Metrics, name string) *metricsHandler {
+	return &metricsHandler{next: next, metrics: metrics, name: name}
+}
+
+func (h *metricsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *metricsHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.next.Handle(ctx, r)
+	h.metrics.count(h.name, r.Level, err)
+	return err
+}
+
+func (h *metricsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	return &h2
+}
+
+func (h *metricsHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	return &h2
+}
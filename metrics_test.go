@@ -0,0 +1,93 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorSink is a sink that fails to write every record.
+type errorSink struct{}
+
+func (errorSink) Write(context.Context, slog.Record) error {
+	return errors.New("nope")
+}
+
+func (errorSink) Close() error {
+	return nil
+}
+
+func Test_Metrics_CountsRecordsAndErrors(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	metrics := NewMetrics()
+	l := LoggerForTest(new(bytes.Buffer), WithMetrics(metrics), WithSink(errorSink{}))
+	l.Info("one")
+	l.Info("two")
+	l.Warn("three")
+	l.Debug("not logged")
+
+	out := new(bytes.Buffer)
+	require.NoError(t, metrics.WritePrometheus(out))
+
+	assert.Contains(t, out.String(), "# TYPE slogflags_records_total counter\n"+
+		`slogflags_records_total{level="INFO",sink="errorSink"} 2`+"\n"+
+		`slogflags_records_total{level="WARN",sink="errorSink"} 1`+"\n"+
+		`slogflags_records_total{level="INFO",sink="output"} 2`+"\n"+
+		`slogflags_records_total{level="WARN",sink="output"} 1`+"\n")
+	assert.Contains(t, out.String(), `slogflags_sink_errors_total{sink="errorSink"} 3`+"\n")
+	assert.NotContains(t, out.String(), `slogflags_sink_errors_total{sink="output"}`)
+}
+
+func Test_Metrics_ReportsDroppedRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	metrics := NewMetrics()
+	l := LoggerForTest(new(bytes.Buffer), WithMetrics(metrics), WithSampling(slog.LevelInfo, 1, 0))
+	l.Info("hot loop")
+	l.Info("hot loop")
+
+	out := new(bytes.Buffer)
+	require.NoError(t, metrics.WritePrometheus(out))
+	assert.Regexp(t, `slogflags_records_dropped_total\{level="INFO",reason="sampled"\} [1-9]`, out.String())
+}
+
+func Test_Metrics_ReportsQueueDepth(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	inner := newGatedSink()
+	queued, err := NewQueuedSink(inner, QueueConfig{Size: 10})
+	require.NoError(t, err)
+
+	metrics := NewMetrics()
+	l := LoggerForTest(new(bytes.Buffer), WithMetrics(metrics), WithSink(queued), WithAsync(10))
+	l.Info("one")
+	<-inner.started
+	l.Info("two")
+	l.Info("three")
+	require.NoError(t, Flush())
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "# TYPE slogflags_queue_depth gauge\n"+
+		`slogflags_queue_depth{queue="async"} 0`+"\n"+
+		`slogflags_queue_depth{queue="gatedSink"} 2`+"\n")
+
+	close(inner.gate)
+	require.NoError(t, queued.Close())
+}
+
+func Test_PromLabel(t *testing.T) {
+	assert.Equal(t, `"a\\b\"c\nd"`, promLabel("a\\b\"c\nd"))
+}
@@ -0,0 +1,49 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// loggerKey is the context key used to store request-scoped loggers.
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx containing the logger, which can be
+// retrieved using [FromContext].
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by [ContextWithLogger] or
+// [LoggerMiddleware]. If there isn't one, it returns [L].
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return L()
+}
+
+// LoggerMiddleware wraps an HTTP handler so that each request's context
+// contains a logger, available from [FromContext], with attributes describing
+// the request: "method", "path", "remote_addr" and "request_id". Request IDs
+// are assigned as per [RequestIDMiddleware].
+//
+// The logger is derived from the given logger, or [L] if it is nil.
+func LoggerMiddleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := logger
+		if base == nil {
+			base = L()
+		}
+
+		id, _ := RequestIDFromContext(r.Context())
+		requestLogger := base.With(
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("request_id", id),
+		)
+		next.ServeHTTP(w, r.WithContext(ContextWithLogger(r.Context(), requestLogger)))
+	}))
+}
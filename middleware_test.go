@@ -0,0 +1,42 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoggerMiddleware(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+
+	handler := LoggerMiddleware(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).InfoContext(r.Context(), "Handling", "user", "bob")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/widgets?id=1", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Request-ID", "abc")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Handling method=POST path=/widgets remote_addr=192.0.2.1:1234 request_id=abc user=bob\n", w.String())
+}
+
+func Test_FromContext_DefaultsToL(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w)
+	FromContext(context.Background()).Info("Fallback")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Fallback\n", w.String())
+}
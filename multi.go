@@ -0,0 +1,47 @@
+package slogflags
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// multiHandler passes records to each of a number of handlers.
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make(multiHandler, len(m))
+	for i, h := range m {
+		res[i] = h.WithAttrs(attrs)
+	}
+	return res
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	res := make(multiHandler, len(m))
+	for i, h := range m {
+		res[i] = h.WithGroup(name)
+	}
+	return res
+}
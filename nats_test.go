@@ -0,0 +1,187 @@
+package slogflags
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type natsPub struct {
+	subject string
+	payload map[string]any
+}
+
+type fakeNATSServer struct {
+	listener net.Listener
+	ack      string
+
+	mu       sync.Mutex
+	connect  map[string]any
+	messages []natsPub
+}
+
+func newFakeNATSServer(t *testing.T, ack string) *fakeNATSServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	f := &fakeNATSServer{listener: l, ack: ack}
+	t.Cleanup(func() { _ = l.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeNATSServer) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeNATSServer) handle(conn net.Conn) {
+	defer conn.Close()
+	_, _ = io.WriteString(conn, "INFO {\"server_id\":\"test\"}\r\n")
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			f.mu.Lock()
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &f.connect)
+			f.mu.Unlock()
+		case line == "PING":
+			_, _ = io.WriteString(conn, "PONG\r\n")
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			_, _ = io.ReadFull(r, payload)
+
+			pub := natsPub{subject: fields[1]}
+			_ = json.Unmarshal(payload[:size], &pub.payload)
+			f.mu.Lock()
+			f.messages = append(f.messages, pub)
+			f.mu.Unlock()
+
+			if len(fields) == 4 {
+				_, _ = fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(f.ack), f.ack)
+			}
+		}
+	}
+}
+
+func Test_NATSSink_PublishesViaOutputFlag(t *testing.T) {
+	server := newFakeNATSServer(t, "")
+
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.output", fmt.Sprintf("nats://secret@%s/app.logs", server.listener.Addr()))
+	defer flag.Set("log.output", "")
+
+	l := LoggerForTest(io.Discard)
+	l.Info("Test", "arg1", "arg2")
+	l.Debug("Ignored")
+
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.messages) == 1
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, "secret", server.connect["auth_token"])
+	assert.Equal(t, "app.logs", server.messages[0].subject)
+	assert.Equal(t, "Test", server.messages[0].payload["msg"])
+	assert.Equal(t, "arg2", server.messages[0].payload["arg1"])
+}
+
+func Test_NATSSink_WaitsForJetStreamAck(t *testing.T) {
+	server := newFakeNATSServer(t, `{"stream":"logs","seq":1}`)
+
+	sink, err := NewNATSSink(NATSConfig{Address: server.listener.Addr().String(), JetStream: true})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	l := LoggerForTest(io.Discard, WithSink(sink))
+	l.Info("Test")
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.messages, 1)
+	assert.Equal(t, "logs", server.messages[0].subject)
+}
+
+func Test_NATSSink_ReportsJetStreamErrors(t *testing.T) {
+	server := newFakeNATSServer(t, `{"error":{"code":503,"description":"no responders"}}`)
+
+	sink, err := NewNATSSink(NATSConfig{Address: server.listener.Addr().String(), JetStream: true})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	l := LoggerForTest(io.Discard, WithSink(sink))
+	err = l.Handler().Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "Test", 0))
+	assert.ErrorContains(t, err, "no responders")
+}
+
+func Test_NATSSink_BatchesRecords(t *testing.T) {
+	server := newFakeNATSServer(t, "")
+
+	sink, err := NewNATSSink(NATSConfig{Address: server.listener.Addr().String(), BatchSize: 3, FlushInterval: time.Hour})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	count := func() int {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.messages)
+	}
+
+	l := LoggerForTest(io.Discard, WithSink(sink))
+	l.Info("One")
+	l.Info("Two")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, count())
+
+	l.Info("Three")
+	assert.Eventually(t, func() bool { return count() == 3 }, time.Second, time.Millisecond)
+
+	l.Info("Four")
+	require.NoError(t, sink.Flush())
+	assert.Eventually(t, func() bool { return count() == 4 }, time.Second, time.Millisecond)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "Four", server.messages[3].payload["msg"])
+}
+
+func Test_NATSSink_BatchSizeFromURL(t *testing.T) {
+	server := newFakeNATSServer(t, "")
+
+	u, err := url.Parse(fmt.Sprintf("nats://%s/logs?batch=50", server.listener.Addr()))
+	require.NoError(t, err)
+	sink, err := newNATSSinkFromURL(u)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	assert.Equal(t, 50, sink.(*NATSSink).config.BatchSize)
+}
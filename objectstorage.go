@@ -0,0 +1,175 @@
+package slogflags
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+// defaultObjectKeyTemplate is used to name objects if no template is
+// configured.
+var defaultObjectKeyTemplate = template.Must(template.New("key").Parse(
+	`{{.Time.Format "2006/01/02/15"}}/{{.Hostname}}-{{.Time.Format "20060102T150405Z"}}-{{.ID}}.ndjson.gz`,
+))
+
+// ObjectStore uploads objects to a storage service. [NewS3Store] and
+// [NewGCSStore] provide implementations for Amazon S3 and Google Cloud
+// Storage.
+type ObjectStore interface {
+	// Put uploads an object with the given key, replacing any existing
+	// object. Implementations are responsible for retrying any transient
+	// failures.
+	Put(ctx context.Context, key string, contentType string, body []byte) error
+}
+
+// ObjectKeyData is the data passed to the template used to name objects.
+type ObjectKeyData struct {
+	// Time is the time the object was created, in UTC.
+	Time time.Time
+
+	// Hostname is the name of the host the process is running on.
+	Hostname string
+
+	// ID is a random identifier, unique to each object.
+	ID string
+}
+
+// ObjectStorageConfig configures an [ObjectStorageSink].
+type ObjectStorageConfig struct {
+	// Store is the object store that objects are uploaded to.
+	Store ObjectStore
+
+	// KeyTemplate is used to name each object, and is executed with an
+	// [ObjectKeyData]. Defaults to a path containing the date and hour,
+	// followed by the hostname, time and ID, e.g.
+	// "2026/01/02/15/host-20260102T150405Z-0123abcd.ndjson.gz".
+	KeyTemplate *template.Template
+
+	// Prefix is prepended to each object key, e.g. "logs/".
+	Prefix string
+
+	// MaxBytes is the maximum uncompressed size of each object. Once this
+	// many bytes have been buffered they are uploaded. Defaults to 16 MiB.
+	MaxBytes int
+
+	// FlushInterval is the maximum amount of time records will be buffered
+	// before they are uploaded. Defaults to 5 minutes.
+	FlushInterval time.Duration
+}
+
+// ObjectStorageSink is a [Sink] that archives records to an object store,
+// such as Amazon S3 or Google Cloud Storage. Records are buffered and written
+// as gzip-compressed newline-delimited JSON objects whenever the size or
+// time threshold is reached.
+//
+// Buffered records are only guaranteed to be uploaded once the sink has been
+// closed.
+type ObjectStorageSink struct {
+	config   ObjectStorageConfig
+	hostname string
+	batcher  *batcher[[]byte]
+	pending  atomic.Int64
+}
+
+// NewObjectStorageSink creates a new [ObjectStorageSink] with the given
+// config.
+func NewObjectStorageSink(config ObjectStorageConfig) (*ObjectStorageSink, error) {
+	if config.Store == nil {
+		return nil, errors.New("object storage: no store specified")
+	}
+	if config.KeyTemplate == nil {
+		config.KeyTemplate = defaultObjectKeyTemplate
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 16 << 20
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Minute
+	}
+
+	s := &ObjectStorageSink{config: config}
+	s.hostname, _ = os.Hostname()
+	s.batcher = newBatcher(math.MaxInt, config.FlushInterval, s.send)
+	return s, nil
+}
+
+func (s *ObjectStorageSink) Write(ctx context.Context, r slog.Record) error {
+	line, err := json.Marshal(recordJSON(ctx, r))
+	if err != nil {
+		return fmt.Errorf("object storage: unable to encode record: %w", err)
+	}
+	line = append(line, '\n')
+
+	err = s.batcher.add(line)
+	if s.pending.Add(int64(len(line))) >= int64(s.config.MaxBytes) {
+		s.batcher.trigger()
+	}
+	return err
+}
+
+func (s *ObjectStorageSink) Flush() error {
+	return s.batcher.sync()
+}
+
+func (s *ObjectStorageSink) Close() error {
+	return s.batcher.close()
+}
+
+func (s *ObjectStorageSink) send(lines [][]byte) error {
+	var errs []error
+	for len(lines) > 0 {
+		n, size := 0, 0
+		for n < len(lines) && (n == 0 || size+len(lines[n]) <= s.config.MaxBytes) {
+			size += len(lines[n])
+			n++
+		}
+		s.pending.Add(-int64(size))
+
+		if err := s.upload(lines[:n]); err != nil {
+			errs = append(errs, fmt.Errorf("object storage: unable to upload %d records: %w", n, err))
+		}
+		lines = lines[n:]
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ObjectStorageSink) upload(lines [][]byte) error {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	key := new(strings.Builder)
+	key.WriteString(s.config.Prefix)
+	err := s.config.KeyTemplate.Execute(key, ObjectKeyData{
+		Time:     time.Now().UTC(),
+		Hostname: s.hostname,
+		ID:       hex.EncodeToString(id),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to render key: %w", err)
+	}
+
+	body := new(bytes.Buffer)
+	gz := gzip.NewWriter(body)
+	for _, line := range lines {
+		_, _ = gz.Write(line)
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	return s.config.Store.Put(ctx, key.String(), "application/gzip", body.Bytes())
+}
@@ -0,0 +1,169 @@
+package slogflags
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeObjectStore) Put(_ context.Context, key string, _ string, body []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.objects == nil {
+		f.objects = map[string][]byte{}
+	}
+	f.objects[key] = body
+	return nil
+}
+
+func (f *fakeObjectStore) records(t *testing.T) map[string][]map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	res := map[string][]map[string]any{}
+	for key, body := range f.objects {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		data, err := io.ReadAll(gz)
+		require.NoError(t, err)
+
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var record map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			res[key] = append(res[key], record)
+		}
+	}
+	return res
+}
+
+func Test_ObjectStorageSink_UploadsCompressedObjects(t *testing.T) {
+	store := &fakeObjectStore{}
+	sink, err := NewObjectStorageSink(ObjectStorageConfig{
+		Store:       store,
+		Prefix:      "logs/",
+		KeyTemplate: template.Must(template.New("").Parse(`{{.Time.Format "2006"}}/{{.ID}}.gz`)),
+	})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("one", "n", 1)
+	l.Info("two", "n", 2)
+	require.NoError(t, sink.Close())
+
+	records := store.records(t)
+	require.Len(t, records, 1)
+	for key, objects := range records {
+		assert.Regexp(t, `^logs/\d{4}/[0-9a-f]{16}\.gz$`, key)
+		require.Len(t, objects, 2)
+		assert.Equal(t, "one", objects[0]["msg"])
+		assert.Equal(t, float64(2), objects[1]["n"])
+	}
+}
+
+func Test_ObjectStorageSink_SplitsObjectsBySize(t *testing.T) {
+	store := &fakeObjectStore{}
+	sink, err := NewObjectStorageSink(ObjectStorageConfig{
+		Store:         store,
+		MaxBytes:      200,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	for range 10 {
+		l.Info("Test", "padding", strings.Repeat("x", 50))
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(store.records(t)) > 0
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, sink.Close())
+
+	total := 0
+	for _, objects := range store.records(t) {
+		assert.LessOrEqual(t, len(objects), 2)
+		total += len(objects)
+	}
+	assert.Equal(t, 10, total)
+}
+
+func Test_S3Store_PutsSignedObjects(t *testing.T) {
+	var path, auth, sha, contentType string
+	var body []byte
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		auth = r.Header.Get("Authorization")
+		sha = r.Header.Get("X-Amz-Content-Sha256")
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Bucket:       "bucket",
+		Region:       "eu-west-2",
+		Endpoint:     server.URL,
+		RetryBackoff: time.Millisecond,
+		Credentials: func(context.Context) (AWSCredentials, error) {
+			return AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret"}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(context.Background(), "logs/date=2026-01-02/a b.gz", "application/gzip", []byte("data")))
+	assert.Empty(t, statuses)
+	assert.Equal(t, "/bucket/logs/date%3D2026-01-02/a%20b.gz", path)
+	assert.Contains(t, auth, "Credential=id/")
+	assert.Contains(t, auth, "/eu-west-2/s3/aws4_request")
+	assert.Contains(t, auth, "x-amz-content-sha256")
+	assert.Equal(t, "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7", sha)
+	assert.Equal(t, "application/gzip", contentType)
+	assert.Equal(t, "data", string(body))
+}
+
+func Test_GCSStore_UploadsObjects(t *testing.T) {
+	var query, auth string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/upload/storage/v1/b/bucket/o", r.URL.Path)
+		query = r.URL.Query().Get("name")
+		auth = r.Header.Get("Authorization")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	store, err := NewGCSStore(GCSConfig{
+		Bucket:   "bucket",
+		Endpoint: server.URL,
+		Token: func(context.Context) (string, error) {
+			return "tok", nil
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(context.Background(), "logs/a+b.gz", "application/gzip", []byte("data")))
+	assert.Equal(t, "logs/a+b.gz", query)
+	assert.Equal(t, "Bearer tok", auth)
+	assert.Equal(t, "data", string(body))
+}
@@ -0,0 +1,115 @@
+package slogflags
+
+import (
+	"fmt"
+	"math"
+)
+
+// MetricKind is the type of instrument used to report a metric.
+type MetricKind int
+
+const (
+	// MetricKindCounter is a monotonically increasing count.
+	MetricKindCounter MetricKind = iota
+	// MetricKindGauge is a value that can go up and down.
+	MetricKindGauge
+)
+
+// OTelMeter creates asynchronous OpenTelemetry instruments.
+//
+// slogflags does not depend on the OpenTelemetry SDK; instead this interface
+// should be implemented using a small adapter around a meter obtained from
+// your meter provider. For example, using go.opentelemetry.io/otel/metric:
+//
+//	type otelMeter struct{ meter metric.Meter }
+//
+//	func (m otelMeter) Int64Observable(name, description, unit string, kind slogflags.MetricKind, callback func(observe func(value int64, attrs map[string]string))) error {
+//		opts := []metric.Int64ObservableOption{metric.WithDescription(description), metric.WithUnit(unit)}
+//		var inst metric.Int64Observable
+//		var err error
+//		if kind == slogflags.MetricKindGauge {
+//			inst, err = m.meter.Int64ObservableGauge(name, opts...)
+//		} else {
+//			inst, err = m.meter.Int64ObservableCounter(name, opts...)
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		_, err = m.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+//			callback(func(value int64, attrs map[string]string) {
+//				kvs := make([]attribute.KeyValue, 0, len(attrs))
+//				for k, v := range attrs {
+//					kvs = append(kvs, attribute.String(k, v))
+//				}
+//				o.ObserveInt64(inst, value, metric.WithAttributes(kvs...))
+//			})
+//			return nil
+//		}, inst)
+//		return err
+//	}
+type OTelMeter interface {
+	// Int64Observable creates an asynchronous instrument of the given kind.
+	// Whenever metrics are collected the callback should be invoked, and it
+	// will call observe once for each set of attributes.
+	Int64Observable(name, description, unit string, kind MetricKind, callback func(observe func(value int64, attrs map[string]string))) error
+}
+
+// RegisterOTel creates OpenTelemetry instruments that mirror the Prometheus
+// metrics exposed by m. Instruments are named "slogflags.records",
+// "slogflags.sink.errors", "slogflags.records.dropped",
+// "slogflags.queue.depth" and "slogflags.attrs.dropped", and have the same attributes as the equivalent
+// Prometheus labels. Instruments are also created for any metrics added with
+// [Metrics.AddLogMetric].
+func (m *Metrics) RegisterOTel(meter OTelMeter) error {
+	for i, f := range m.collect() {
+		if f.kind == metricKindHistogram {
+			if err := m.registerOTelHistogram(meter, i, f); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := meter.Int64Observable(f.otelName, f.help, f.unit, f.kind, func(observe func(int64, map[string]string)) {
+			for _, p := range m.collect()[i].points {
+				observe(p.value, otelAttrs(p))
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("metrics: unable to create instrument %s: %w", f.otelName, err)
+		}
+	}
+	return nil
+}
+
+// registerOTelHistogram creates a pair of counters for the count and the sum
+// of the histogram at index i, as histograms can't be observed
+// asynchronously.
+func (m *Metrics) registerOTelHistogram(meter OTelMeter, i int, f metricFamily) error {
+	for _, c := range []struct {
+		suffix string
+		unit   string
+		value  func(p metricPoint) int64
+	}{
+		{".count", "{record}", func(p metricPoint) int64 { return p.value }},
+		{".sum", f.unit, func(p metricPoint) int64 { return int64(math.Round(p.histogram.sum)) }},
+	} {
+		err := meter.Int64Observable(f.otelName+c.suffix, f.help, c.unit, MetricKindCounter, func(observe func(int64, map[string]string)) {
+			for _, p := range m.collect()[i].points {
+				observe(c.value(p), otelAttrs(p))
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("metrics: unable to create instrument %s: %w", f.otelName+c.suffix, err)
+		}
+	}
+	return nil
+}
+
+// otelAttrs returns the labels of a metric point as OpenTelemetry attributes.
+func otelAttrs(p metricPoint) map[string]string {
+	attrs := make(map[string]string, len(p.labels))
+	for _, l := range p.labels {
+		attrs[l.name] = l.value
+	}
+	return attrs
+}
@@ -0,0 +1,77 @@
+package slogflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMeter records instruments created by [Metrics.RegisterOTel].
+type fakeMeter struct {
+	kinds     map[string]MetricKind
+	units     map[string]string
+	callbacks map[string]func(observe func(value int64, attrs map[string]string))
+	err       error
+}
+
+func newFakeMeter() *fakeMeter {
+	return &fakeMeter{
+		kinds:     map[string]MetricKind{},
+		units:     map[string]string{},
+		callbacks: map[string]func(observe func(value int64, attrs map[string]string)){},
+	}
+}
+
+func (m *fakeMeter) Int64Observable(name, _, unit string, kind MetricKind, callback func(observe func(value int64, attrs map[string]string))) error {
+	m.kinds[name] = kind
+	m.units[name] = unit
+	m.callbacks[name] = callback
+	return m.err
+}
+
+// observe invokes the callback for the named instrument, and returns the
+// values observed keyed on the given attribute.
+func (m *fakeMeter) observe(name, key string) map[string]int64 {
+	res := map[string]int64{}
+	m.callbacks[name](func(value int64, attrs map[string]string) {
+		res[attrs[key]] += value
+	})
+	return res
+}
+
+func Test_Metrics_RegisterOTel(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	metrics := NewMetrics()
+	meter := newFakeMeter()
+	require.NoError(t, metrics.RegisterOTel(meter))
+
+	assert.Equal(t, map[string]MetricKind{
+		"slogflags.records":         MetricKindCounter,
+		"slogflags.sink.errors":     MetricKindCounter,
+		"slogflags.records.dropped": MetricKindCounter,
+		"slogflags.queue.depth":     MetricKindGauge,
+		"slogflags.attrs.dropped":   MetricKindCounter,
+	}, meter.kinds)
+	assert.Equal(t, "{record}", meter.units["slogflags.records"])
+
+	l := LoggerForTest(new(bytes.Buffer), WithMetrics(metrics), WithSink(errorSink{}), WithAsync(5))
+	l.Info("one")
+	l.Warn("two")
+	require.NoError(t, Flush())
+
+	assert.Equal(t, map[string]int64{"INFO": 2, "WARN": 2}, meter.observe("slogflags.records", "level"))
+	assert.Equal(t, map[string]int64{"errorSink": 2}, meter.observe("slogflags.sink.errors", "sink"))
+	assert.Equal(t, map[string]int64{"async": 0}, meter.observe("slogflags.queue.depth", "queue"))
+}
+
+func Test_Metrics_RegisterOTelError(t *testing.T) {
+	meter := newFakeMeter()
+	meter.err = errors.New("nope")
+	assert.ErrorContains(t, NewMetrics().RegisterOTel(meter), "slogflags.records")
+}
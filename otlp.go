@@ -0,0 +1,407 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLPProtocol is the transport protocol used to export logs via OTLP.
+type OTLPProtocol string
+
+const (
+	// OTLPProtocolHTTP exports protobuf-encoded logs over HTTP.
+	OTLPProtocolHTTP OTLPProtocol = "http/protobuf"
+	// OTLPProtocolGRPC exports logs using gRPC.
+	OTLPProtocolGRPC OTLPProtocol = "grpc"
+)
+
+const otlpGRPCPath = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+// OTLPConfig configures an [OTLPSink]. Unset values are read from the
+// standard OTEL_* environment variables where applicable.
+type OTLPConfig struct {
+	// Protocol is the transport protocol to use. Defaults to the value of
+	// OTEL_EXPORTER_OTLP_LOGS_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL, or
+	// [OTLPProtocolHTTP] if neither are set.
+	Protocol OTLPProtocol
+
+	// Endpoint is the URL logs are exported to. For HTTP this is the full
+	// URL including the path (e.g. "http://localhost:4318/v1/logs"); for
+	// gRPC it is the base URL of the collector (e.g.
+	// "http://localhost:4317"). Defaults to the value of
+	// OTEL_EXPORTER_OTLP_LOGS_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT, or a
+	// collector on localhost.
+	Endpoint string
+
+	// Headers are additional headers sent with each request. Defaults to the
+	// value of OTEL_EXPORTER_OTLP_LOGS_HEADERS or OTEL_EXPORTER_OTLP_HEADERS.
+	Headers map[string]string
+
+	// ResourceAttributes describe the entity producing logs. The value of
+	// OTEL_RESOURCE_ATTRIBUTES is always included, and "service.name"
+	// defaults to OTEL_SERVICE_NAME or the name of the executable.
+	ResourceAttributes map[string]string
+
+	// BatchSize is the maximum number of records that will be buffered before
+	// they are exported. Defaults to 512.
+	BatchSize int
+
+	// FlushInterval is the maximum amount of time records will be buffered
+	// before they are exported. Defaults to 1 second.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times an export will be retried if it fails
+	// due to a network error or a retryable status. Defaults to 3 if zero; a
+	// negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the time to wait before the first retry. It doubles
+	// after each subsequent attempt. Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to a client
+	// that supports HTTP/2, including over unencrypted connections for gRPC.
+	Client *http.Client
+}
+
+// OTLPSink is a [Sink] that converts records to OpenTelemetry log records and
+// exports them to a collector using OTLP over HTTP or gRPC.
+//
+// Buffered records are only guaranteed to be exported once the sink has been
+// closed.
+type OTLPSink struct {
+	config   OTLPConfig
+	resource []byte
+	batcher  *batcher[[]byte]
+}
+
+// NewOTLPSink creates a new [OTLPSink] with the given config.
+func NewOTLPSink(config OTLPConfig) (*OTLPSink, error) {
+	if config.Protocol == "" {
+		config.Protocol = OTLPProtocol(otelEnv("PROTOCOL", string(OTLPProtocolHTTP)))
+	}
+	if config.Protocol != OTLPProtocolHTTP && config.Protocol != OTLPProtocolGRPC {
+		return nil, fmt.Errorf("otlp: unsupported protocol %q", config.Protocol)
+	}
+
+	if config.Endpoint == "" {
+		config.Endpoint = otlpEndpoint(config.Protocol)
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("otlp: invalid endpoint: %w", err)
+	}
+
+	if config.Headers == nil {
+		config.Headers = parseOTelList(otelEnv("HEADERS", ""))
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 512
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.Client == nil {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(config.Protocol == OTLPProtocolHTTP)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(config.Protocol == OTLPProtocolGRPC)
+		config.Client = &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	}
+
+	s := &OTLPSink{config: config, resource: otlpResource(config.ResourceAttributes)}
+	s.batcher = newBatcher(config.BatchSize, config.FlushInterval, s.send)
+	return s, nil
+}
+
+func (s *OTLPSink) Write(ctx context.Context, r slog.Record) error {
+	var m protoBuffer
+	m.fixed64(1, uint64(r.Time.UnixNano()))
+	m.uint64(2, uint64(otlpSeverity(r.Level)))
+	m.string(3, levelName(ctx, r.Level))
+	m.message(5, func(b *protoBuffer) {
+		otlpAnyValue(b, slog.StringValue(r.Message))
+	})
+	r.Attrs(func(a slog.Attr) bool {
+		otlpKeyValue(&m, 6, a)
+		return true
+	})
+	m.fixed64(11, uint64(time.Now().UnixNano()))
+	return s.batcher.add(m)
+}
+
+func (s *OTLPSink) Flush() error {
+	return s.batcher.sync()
+}
+
+func (s *OTLPSink) Close() error {
+	return s.batcher.close()
+}
+
+func (s *OTLPSink) send(records [][]byte) error {
+	var req protoBuffer
+	req.message(1, func(rl *protoBuffer) {
+		rl.bytes(1, s.resource)
+		rl.message(2, func(sl *protoBuffer) {
+			sl.message(1, func(scope *protoBuffer) {
+				scope.string(1, "github.com/csmith/slogflags")
+			})
+			for _, r := range records {
+				sl.bytes(2, r)
+			}
+		})
+	})
+
+	err := withRetries(s.config.MaxRetries, s.config.RetryBackoff, func() error {
+		if s.config.Protocol == OTLPProtocolGRPC {
+			return s.exportGRPC(req)
+		}
+		return s.exportHTTP(req)
+	})
+	if err != nil {
+		return fmt.Errorf("otlp: unable to export %d records: %w", len(records), err)
+	}
+	return nil
+}
+
+func (s *OTLPSink) exportHTTP(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.config.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 300 {
+		err = fmt.Errorf("request returned status %d", res.StatusCode)
+		if retryableStatus(res.StatusCode) {
+			return retryable(err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *OTLPSink) exportGRPC(message []byte) error {
+	body := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(message)))
+	body = append(body, message...)
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(s.config.Endpoint, "/")+otlpGRPCPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.config.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		return retryable(fmt.Errorf("request returned status %d", res.StatusCode))
+	}
+
+	status := res.Trailer.Get("Grpc-Status")
+	if status == "" {
+		// Responses without a body may send the status in the headers.
+		status = res.Header.Get("Grpc-Status")
+	}
+	if status == "" || status == "0" {
+		return nil
+	}
+
+	err = fmt.Errorf("gRPC status %s: %s", status, res.Trailer.Get("Grpc-Message"))
+	switch status {
+	case "1", "4", "8", "10", "11", "14", "15":
+		// Cancelled, DeadlineExceeded, ResourceExhausted, Aborted, OutOfRange,
+		// Unavailable and DataLoss are retryable according to the OTLP spec.
+		return retryable(err)
+	default:
+		return err
+	}
+}
+
+// otlpSeverity maps a slog level to an OpenTelemetry severity number. The
+// levels are offset such that DEBUG, INFO, WARN and ERROR map exactly.
+func otlpSeverity(level slog.Level) int {
+	return min(max(int(level)+9, 1), 24)
+}
+
+// otlpKeyValue appends an attribute as a KeyValue message.
+func otlpKeyValue(b *protoBuffer, field int, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Key == "" && a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			otlpKeyValue(b, field, ga)
+		}
+		return
+	}
+
+	b.message(field, func(kv *protoBuffer) {
+		kv.string(1, a.Key)
+		kv.message(2, func(v *protoBuffer) {
+			otlpAnyValue(v, a.Value)
+		})
+	})
+}
+
+// otlpAnyValue encodes the contents of an AnyValue message. As the value is
+// a oneof, it is always written even if it is the default. Unsigned integers
+// too large for an int_value are written as strings, so they aren't read
+// back as negative numbers.
+func otlpAnyValue(b *protoBuffer, v slog.Value) {
+	switch v.Kind() {
+	case slog.KindString:
+		b.tag(1, protoBytes)
+		*b = binary.AppendUvarint(*b, uint64(len(v.String())))
+		*b = append(*b, v.String()...)
+	case slog.KindBool:
+		b.tag(2, protoVarint)
+		if v.Bool() {
+			*b = append(*b, 1)
+		} else {
+			*b = append(*b, 0)
+		}
+	case slog.KindInt64:
+		b.tag(3, protoVarint)
+		*b = binary.AppendUvarint(*b, uint64(v.Int64()))
+	case slog.KindUint64:
+		if v.Uint64() > math.MaxInt64 {
+			otlpAnyValue(b, slog.StringValue(strconv.FormatUint(v.Uint64(), 10)))
+			return
+		}
+		b.tag(3, protoVarint)
+		*b = binary.AppendUvarint(*b, v.Uint64())
+	case slog.KindFloat64:
+		b.tag(4, protoFixed64)
+		*b = binary.LittleEndian.AppendUint64(*b, math.Float64bits(v.Float64()))
+	case slog.KindDuration:
+		b.tag(3, protoVarint)
+		*b = binary.AppendUvarint(*b, uint64(v.Duration().Nanoseconds()))
+	case slog.KindGroup:
+		b.message(6, func(kvs *protoBuffer) {
+			for _, a := range v.Group() {
+				otlpKeyValue(kvs, 1, a)
+			}
+		})
+	default:
+		otlpAnyValue(b, slog.StringValue(fmt.Sprint(jsonValue(v))))
+	}
+}
+
+// otlpResource encodes a Resource message containing the resource attributes
+// from the environment and the given map.
+func otlpResource(attrs map[string]string) []byte {
+	merged := map[string]string{}
+	for k, v := range parseOTelList(os.Getenv("OTEL_RESOURCE_ATTRIBUTES")) {
+		merged[k] = v
+	}
+	for k, v := range attrs {
+		merged[k] = v
+	}
+
+	if _, ok := merged["service.name"]; !ok {
+		if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+			merged["service.name"] = name
+		} else if exe, err := os.Executable(); err == nil {
+			merged["service.name"] = "unknown_service:" + filepath.Base(exe)
+		}
+	}
+	merged["telemetry.sdk.name"] = "slogflags"
+	merged["telemetry.sdk.language"] = "go"
+
+	var res protoBuffer
+	for k, v := range merged {
+		otlpKeyValue(&res, 1, slog.String(k, v))
+	}
+	return res
+}
+
+// otlpEndpoint determines the default endpoint from the environment.
+func otlpEndpoint(protocol OTLPProtocol) string {
+	if e := os.Getenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"); e != "" {
+		return e
+	}
+
+	base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if protocol == OTLPProtocolGRPC {
+		if base == "" {
+			return "http://localhost:4317"
+		}
+		return base
+	}
+
+	if base == "" {
+		base = "http://localhost:4318"
+	}
+	return strings.TrimSuffix(base, "/") + "/v1/logs"
+}
+
+// otelEnv returns the value of the logs-specific OTEL_EXPORTER_OTLP_LOGS_*
+// environment variable, falling back to the generic OTEL_EXPORTER_OTLP_*
+// variable, or the fallback value.
+func otelEnv(name, fallback string) string {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_LOGS_" + name); v != "" {
+		return v
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_" + name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// parseOTelList parses a comma-separated list of key=value pairs with
+// URL-encoded values, as used in OTEL_RESOURCE_ATTRIBUTES.
+func parseOTelList(s string) map[string]string {
+	res := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(strings.TrimSpace(v)); err == nil {
+			v = unescaped
+		}
+		res[strings.TrimSpace(k)] = v
+	}
+	return res
+}
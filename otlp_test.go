@@ -0,0 +1,186 @@
+package slogflags
+
+import (
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// protoFields decodes a protobuf message into its raw fields, keyed by field
+// number. Varints are returned as uint64, fixed values as uint64 or uint32,
+// and length-delimited fields as []byte.
+func protoFields(t *testing.T, b []byte) map[int][]any {
+	res := map[int][]any{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		require.Greater(t, n, 0)
+		b = b[n:]
+
+		field := int(tag >> 3)
+		switch tag & 7 {
+		case protoVarint:
+			v, n := binary.Uvarint(b)
+			res[field] = append(res[field], v)
+			b = b[n:]
+		case protoFixed64:
+			res[field] = append(res[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case protoFixed32:
+			res[field] = append(res[field], binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case protoBytes:
+			l, n := binary.Uvarint(b)
+			res[field] = append(res[field], b[n:n+int(l)])
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+	}
+	return res
+}
+
+// otlpTestAttrs decodes repeated KeyValue messages into a map.
+func otlpTestAttrs(t *testing.T, kvs []any) map[string]any {
+	res := map[string]any{}
+	for _, kv := range kvs {
+		fields := protoFields(t, kv.([]byte))
+		value := protoFields(t, fields[2][0].([]byte))
+		key := string(fields[1][0].([]byte))
+		switch {
+		case value[1] != nil:
+			res[key] = string(value[1][0].([]byte))
+		case value[3] != nil:
+			res[key] = int64(value[3][0].(uint64))
+		case value[4] != nil:
+			res[key] = math.Float64frombits(value[4][0].(uint64))
+		case value[6] != nil:
+			res[key] = otlpTestAttrs(t, protoFields(t, value[6][0].([]byte))[1])
+		}
+	}
+	return res
+}
+
+type fakeOTLPCollector struct {
+	mu      sync.Mutex
+	bodies  [][]byte
+	headers http.Header
+	grpc    bool
+}
+
+func (f *fakeOTLPCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	f.headers = r.Header
+	if f.grpc {
+		body = body[5:]
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Header().Set("Content-Type", "application/grpc")
+		w.WriteHeader(http.StatusOK)
+		w.Header().Set("Grpc-Status", "0")
+	}
+	f.bodies = append(f.bodies, body)
+}
+
+func (f *fakeOTLPCollector) logRecords(t *testing.T) (map[string]any, []map[int][]any) {
+	require.Len(t, f.bodies, 1)
+	resourceLogs := protoFields(t, protoFields(t, f.bodies[0])[1][0].([]byte))
+	resource := otlpTestAttrs(t, protoFields(t, resourceLogs[1][0].([]byte))[1])
+	scopeLogs := protoFields(t, resourceLogs[2][0].([]byte))
+
+	var records []map[int][]any
+	for _, r := range scopeLogs[2] {
+		records = append(records, protoFields(t, r.([]byte)))
+	}
+	return resource, records
+}
+
+func Test_OTLPSink_ExportsOverHTTP(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=prod")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+
+	collector := &fakeOTLPCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	sink, err := NewOTLPSink(OTLPConfig{
+		Endpoint:           server.URL + "/v1/logs",
+		Headers:            map[string]string{"X-Api-Key": "secret"},
+		ResourceAttributes: map[string]string{"service.name": "app"},
+	})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.WithGroup("req").Warn("Test", "id", 4, "path", "/")
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, "secret", collector.headers.Get("X-Api-Key"))
+	assert.Equal(t, "application/x-protobuf", collector.headers.Get("Content-Type"))
+
+	resource, records := collector.logRecords(t)
+	assert.Equal(t, "app", resource["service.name"])
+	assert.Equal(t, "prod", resource["deployment.environment"])
+
+	require.Len(t, records, 1)
+	assert.Equal(t, []any{uint64(13)}, records[0][2])
+	assert.Equal(t, "WARN", string(records[0][3][0].([]byte)))
+	assert.Equal(t, "Test", string(protoFields(t, records[0][5][0].([]byte))[1][0].([]byte)))
+	assert.Equal(t, map[string]any{"req": map[string]any{"id": int64(4), "path": "/"}}, otlpTestAttrs(t, records[0][6]))
+}
+
+func Test_OTLPSink_ExportsOverGRPC(t *testing.T) {
+	collector := &fakeOTLPCollector{grpc: true}
+	server := httptest.NewUnstartedServer(collector)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	sink, err := NewOTLPSink(OTLPConfig{
+		Protocol: OTLPProtocolGRPC,
+		Endpoint: server.URL,
+		Client:   server.Client(),
+	})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("Test", "pi", 3.14)
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, "application/grpc", collector.headers.Get("Content-Type"))
+	_, records := collector.logRecords(t)
+	require.Len(t, records, 1)
+	assert.Equal(t, []any{uint64(9)}, records[0][2])
+	assert.Equal(t, map[string]any{"pi": 3.14}, otlpTestAttrs(t, records[0][6]))
+}
+
+func Test_OTLPAnyValue_Uint64(t *testing.T) {
+	var b protoBuffer
+	otlpKeyValue(&b, 1, slog.Uint64("small", 42))
+	otlpKeyValue(&b, 1, slog.Uint64("max", math.MaxInt64))
+	otlpKeyValue(&b, 1, slog.Uint64("large", math.MaxUint64))
+
+	attrs := otlpTestAttrs(t, protoFields(t, b)[1])
+	assert.Equal(t, map[string]any{
+		"small": int64(42),
+		"max":   int64(math.MaxInt64),
+		"large": "18446744073709551615",
+	}, attrs)
+}
+
+func Test_OTLPSeverity(t *testing.T) {
+	assert.Equal(t, 5, otlpSeverity(slog.LevelDebug))
+	assert.Equal(t, 9, otlpSeverity(slog.LevelInfo))
+	assert.Equal(t, 13, otlpSeverity(slog.LevelWarn))
+	assert.Equal(t, 17, otlpSeverity(slog.LevelError))
+	assert.Equal(t, 1, otlpSeverity(slog.LevelDebug-10))
+	assert.Equal(t, 24, otlpSeverity(slog.LevelError+20))
+}
@@ -0,0 +1,68 @@
+package slogflags
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+)
+
+// outputSinks maps URL schemes accepted by the `log.output` flag to funcs that
+// create the corresponding [Sink].
+var outputSinks = map[string]func(u *url.URL) (Sink, error){
+	"nats": newNATSSinkFromURL,
+}
+
+// output resolves the requested value of the `log.output` flag, which may be
+// "stdout", "stderr", a file path or "file://" URL, or the URL of a sink. It
+// returns either a writer that formatted records should be written to, or a
+// sink that records should be passed to. Any file or sink opened is
+// registered to be closed by [Close].
+func (c *config) output(requested string) (io.Writer, Sink, error) {
+	switch requested {
+	case "":
+		return c.writer, nil, nil
+	case "stdout":
+		return os.Stdout, nil, nil
+	case "stderr":
+		return stderrFile(), nil, nil
+	}
+
+	u, err := url.Parse(requested)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		path := requested
+		if u.Scheme == "file" {
+			path = u.Path
+		}
+		w, err := c.openFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.registerClose(w.Close)
+		return w, nil, nil
+	}
+
+	fn, ok := outputSinks[u.Scheme]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported output %q", requested)
+	}
+
+	sink, err := fn(u)
+	if err != nil {
+		return nil, nil, err
+	}
+	sink, err = queueFromFlags(sink, c.neverDropLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f, ok := sink.(Flusher); ok {
+		c.registerFlush(f.Flush)
+	}
+	c.registerClose(sink.Close)
+	return nil, sink, nil
+}
@@ -0,0 +1,93 @@
+package slogflags
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// protoBuffer is a minimal encoder for the protocol buffers wire format,
+// sufficient for building OTLP messages without depending on a protobuf
+// library.
+type protoBuffer []byte
+
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+func (b *protoBuffer) tag(field int, wireType int) {
+	*b = binary.AppendUvarint(*b, uint64(field)<<3|uint64(wireType))
+}
+
+// uint64 appends a varint field, omitting it if it has the default value.
+func (b *protoBuffer) uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, protoVarint)
+	*b = binary.AppendUvarint(*b, v)
+}
+
+// int64 appends an int64 (not sint64) field.
+func (b *protoBuffer) int64(field int, v int64) {
+	b.uint64(field, uint64(v))
+}
+
+func (b *protoBuffer) bool(field int, v bool) {
+	if v {
+		b.uint64(field, 1)
+	}
+}
+
+func (b *protoBuffer) fixed64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, protoFixed64)
+	*b = binary.LittleEndian.AppendUint64(*b, v)
+}
+
+func (b *protoBuffer) fixed32(field int, v uint32) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, protoFixed32)
+	*b = binary.LittleEndian.AppendUint32(*b, v)
+}
+
+func (b *protoBuffer) double(field int, v float64) {
+	if v == 0 {
+		return
+	}
+	b.fixed64(field, math.Float64bits(v))
+}
+
+func (b *protoBuffer) bytes(field int, v []byte) {
+	if len(v) == 0 {
+		return
+	}
+	b.tag(field, protoBytes)
+	*b = binary.AppendUvarint(*b, uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *protoBuffer) string(field int, v string) {
+	if v == "" {
+		return
+	}
+	b.tag(field, protoBytes)
+	*b = binary.AppendUvarint(*b, uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+// message appends an embedded message, using fn to encode its contents. The
+// message is always written, even if empty.
+func (b *protoBuffer) message(field int, fn func(m *protoBuffer)) {
+	var m protoBuffer
+	fn(&m)
+	b.tag(field, protoBytes)
+	*b = binary.AppendUvarint(*b, uint64(len(m)))
+	*b = append(*b, m...)
+}
@@ -0,0 +1,90 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+)
+
+// currentHandler is the handler created by the most recent call to [Logger].
+var currentHandler atomic.Pointer[slog.Handler]
+
+// currentSlot holds the handler used by the logger returned by the most
+// recent call to [Logger], and is replaced by [Reconfigure].
+var currentSlot atomic.Pointer[atomic.Pointer[slog.Handler]]
+
+// L returns a logger that forwards records to the logger created by the most
+// recent call to [Logger]. It can be obtained at any time, such as when a
+// package is initialised, and will start using a new configuration as soon as
+// [Logger] is called again. Before [Logger] has been called, records are
+// buffered as if they were logged using [Early].
+func L() *slog.Logger {
+	return slog.New(newProxyHandler(&currentHandler))
+}
+
+// proxyHandler is a [log/slog.Handler] that forwards records to the handler
+// currently stored in a pointer.
+type proxyHandler struct {
+	current *atomic.Pointer[slog.Handler]
+	goas    []groupOrAttrs
+	cache   *atomic.Pointer[proxyCache]
+}
+
+func newProxyHandler(current *atomic.Pointer[slog.Handler]) *proxyHandler {
+	return &proxyHandler{current: current, cache: new(atomic.Pointer[proxyCache])}
+}
+
+// proxyCache holds the current handler with a proxyHandler's groups and
+// attributes applied, so they're only applied once each time it changes.
+type proxyCache struct {
+	base    *slog.Handler
+	handler slog.Handler
+}
+
+// handler returns the current handler with the groups and attributes
+// applied.
+func (h *proxyHandler) handler() slog.Handler {
+	base := h.current.Load()
+	if base == nil {
+		return applyGroupOrAttrs(&earlyHandler{state: early}, h.goas)
+	}
+
+	if c := h.cache.Load(); c != nil && c.base == base {
+		return c.handler
+	}
+
+	handler := applyGroupOrAttrs(*base, h.goas)
+	h.cache.Store(&proxyCache{base: base, handler: handler})
+	return handler
+}
+
+func (h *proxyHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler().Enabled(ctx, level)
+}
+
+func (h *proxyHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h *proxyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *proxyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *proxyHandler) with(goa groupOrAttrs) *proxyHandler {
+	return &proxyHandler{
+		current: h.current,
+		goas:    append(slices.Clip(h.goas), goa),
+		cache:   new(atomic.Pointer[proxyCache]),
+	}
+}
@@ -0,0 +1,46 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_L_ForwardsToMostRecentLogger(t *testing.T) {
+	early = &earlyState{}
+	currentHandler.Store(nil)
+	defer func() { early = &earlyState{} }()
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	l := L().With("pkg", "db")
+	l.Info("Before")
+
+	first := new(bytes.Buffer)
+	LoggerForTest(first)
+	l.Info("First")
+
+	second := new(bytes.Buffer)
+	LoggerForTest(second)
+	l.WithGroup("g").Info("Second", "k", "v")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Before pkg=db\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=First pkg=db\n", first.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Second pkg=db g.k=v\n", second.String())
+}
+
+func Test_L_UsesCurrentLevel(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "warn")
+	defer flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w)
+	l := L()
+	l.Info("Hidden")
+	l.Warn("Shown")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=Shown\n", w.String())
+}
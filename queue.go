@@ -0,0 +1,372 @@
+package slogflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Backpressure determines what a [QueuedSink] does when its queue is full.
+type Backpressure int
+
+const (
+	// BackpressureBlock blocks the logging goroutine until there is space in
+	// the queue. No records are dropped, but logging may be slow while the
+	// sink is saturated.
+	BackpressureBlock Backpressure = iota
+
+	// BackpressureDropLowest drops the lowest-severity record, either from
+	// the queue or the one being logged. Where several records have the same
+	// level, the oldest is dropped. Records at or above
+	// [QueueConfig.NeverDrop] are never dropped; writing them blocks until
+	// there is space instead.
+	BackpressureDropLowest
+
+	// BackpressureSpill writes records to disk while the queue is full, and
+	// adds them back to the queue in order once there is space.
+	BackpressureSpill
+)
+
+// errQueueFull is returned internally when a record can't be added to a
+// queue without blocking.
+var errQueueFull = errors.New("queue is full")
+
+// errQueueClosed is returned when a record is written to a closed queue.
+var errQueueClosed = errors.New("queue: sink is closed")
+
+// QueueConfig configures a [QueuedSink].
+type QueueConfig struct {
+	// Size is the maximum number of records held in memory. Defaults to 1000.
+	Size int
+
+	// Backpressure determines what happens when the queue is full. Defaults
+	// to [BackpressureBlock].
+	Backpressure Backpressure
+
+	// SpillDir is the directory records are written to while the queue is
+	// full. It is required if Backpressure is [BackpressureSpill], and must
+	// not be shared with another sink.
+	SpillDir string
+
+	// SpillMaxBytes is the maximum amount of disk space used for spilled
+	// records. Defaults to 100 MiB.
+	SpillMaxBytes int64
+
+	// NeverDrop is the level at or above which records are never dropped by
+	// [BackpressureDropLowest]. If nil, records of any level may be dropped.
+	NeverDrop slog.Leveler
+}
+
+// queuedItem is a record waiting to be written to the sink.
+type queuedItem struct {
+	ctx    context.Context
+	record slog.Record
+}
+
+// QueuedSink wraps another [Sink], writing records to it from a bounded
+// in-memory queue on a background goroutine. This prevents a slow sink from
+// slowing down logging, and the behaviour when the sink can't keep up is
+// determined by the [Backpressure] strategy.
+//
+// Errors from the wrapped sink are returned from the next call to Write,
+// Flush or Close.
+type QueuedSink struct {
+	sink   Sink
+	config QueueConfig
+	spill  *SpillSink
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    []queuedItem
+	inFlight bool
+	closed   bool
+	err      error
+
+	done chan struct{}
+}
+
+// NewQueuedSink creates a new [QueuedSink] wrapping the given sink.
+func NewQueuedSink(sink Sink, config QueueConfig) (*QueuedSink, error) {
+	if config.Size <= 0 {
+		config.Size = 1000
+	}
+
+	s := &QueuedSink{
+		sink:   sink,
+		config: config,
+		done:   make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	if config.Backpressure == BackpressureSpill {
+		if config.SpillDir == "" {
+			return nil, errors.New("queue: no spill directory specified")
+		}
+
+		spill, err := NewSpillSink(queueAdder{s}, SpillConfig{
+			Dir:           config.SpillDir,
+			MaxBytes:      config.SpillMaxBytes,
+			RetryInterval: time.Second,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.spill = spill
+	}
+
+	go s.run()
+	return s, nil
+}
+
+func (s *QueuedSink) run() {
+	defer close(s.done)
+
+	for {
+		s.mu.Lock()
+		for len(s.items) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.items) == 0 {
+			s.mu.Unlock()
+			return
+		}
+
+		item := s.items[0]
+		s.items = s.items[1:]
+		s.inFlight = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+
+		err := s.sink.Write(item.ctx, item.record)
+
+		s.mu.Lock()
+		s.err = errors.Join(s.err, err)
+		s.inFlight = false
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}
+}
+
+func (s *QueuedSink) Write(ctx context.Context, r slog.Record) error {
+	item := queuedItem{ctx: context.WithoutCancel(ctx), record: r.Clone()}
+
+	var err error
+	switch s.config.Backpressure {
+	case BackpressureSpill:
+		// The spill sink reports that the queue was full when it starts
+		// spilling, but that's expected so isn't returned.
+		err = withoutQueueFull(s.spill.Write(ctx, r))
+	case BackpressureDropLowest:
+		if s.config.NeverDrop != nil && r.Level >= s.config.NeverDrop.Level() {
+			err = s.add(item, true)
+		} else {
+			err = s.addOrDrop(item)
+		}
+	default:
+		err = s.add(item, true)
+	}
+
+	return errors.Join(s.takeErr(), err)
+}
+
+// add adds an item to the queue. If the queue is full it either waits for
+// space, or returns errQueueFull.
+func (s *QueuedSink) add(item queuedItem, wait bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.items) >= s.config.Size && !s.closed {
+		if !wait {
+			return errQueueFull
+		}
+		s.cond.Wait()
+	}
+	if s.closed {
+		return errQueueClosed
+	}
+
+	s.items = append(s.items, item)
+	s.cond.Broadcast()
+	return nil
+}
+
+// addOrDrop adds an item to the queue. If the queue is full, the item with
+// the lowest level is dropped, preferring the oldest queued item if it has
+// the same level as the new one.
+func (s *QueuedSink) addOrDrop(item queuedItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errQueueClosed
+	}
+
+	if len(s.items) >= s.config.Size {
+		lowest := 0
+		for i := range s.items {
+			if s.items[i].record.Level < s.items[lowest].record.Level {
+				lowest = i
+			}
+		}
+
+		if item.record.Level < s.items[lowest].record.Level {
+			countDrop(dropQueueFull, item.record.Level)
+			return nil
+		}
+
+		countDrop(dropQueueFull, s.items[lowest].record.Level)
+		s.items = append(s.items[:lowest], s.items[lowest+1:]...)
+	}
+
+	s.items = append(s.items, item)
+	s.cond.Broadcast()
+	return nil
+}
+
+// depth returns the number of records waiting in the queue.
+func (s *QueuedSink) depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// takeErr returns and clears any errors from background writes.
+func (s *QueuedSink) takeErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// wait blocks until the queue is empty and no write is in progress.
+func (s *QueuedSink) wait() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.items) > 0 || s.inFlight {
+		s.cond.Wait()
+	}
+}
+
+// drain waits until all queued records have been written, including any
+// that have been spilled to disk.
+func (s *QueuedSink) drain() error {
+	for {
+		var err error
+		if s.spill != nil {
+			err = withoutQueueFull(s.spill.Flush())
+		}
+		s.wait()
+
+		// Spilled records are replayed until the queue fills up, so keep
+		// going until they've all been replayed.
+		if err != nil || s.spill == nil || !s.spill.isSpilling() {
+			return err
+		}
+	}
+}
+
+// Flush waits for all queued records to be written, including any that have
+// been spilled to disk, and then flushes the wrapped sink if it implements
+// [Flusher].
+func (s *QueuedSink) Flush() error {
+	err := s.drain()
+	if f, ok := s.sink.(Flusher); ok {
+		err = errors.Join(err, f.Flush())
+	}
+	return errors.Join(err, s.takeErr())
+}
+
+// Close writes all queued and spilled records, and then closes the wrapped
+// sink. Any records that can't be replayed from disk are left there, and will
+// be written the next time a QueuedSink is created with the same directory.
+func (s *QueuedSink) Close() error {
+	err := s.drain()
+	if s.spill != nil {
+		err = errors.Join(err, s.spill.Close())
+	}
+
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	<-s.done
+
+	err = errors.Join(err, s.takeErr())
+	return errors.Join(err, s.sink.Close())
+}
+
+// withoutQueueFull removes errQueueFull from an error or joined errors.
+func withoutQueueFull(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, withoutQueueFull(e))
+		}
+		return errors.Join(errs...)
+	}
+	if errors.Is(err, errQueueFull) {
+		return nil
+	}
+	return err
+}
+
+// queueAdder is a [Sink] that adds records to a QueuedSink's queue without
+// blocking, allowing it to be wrapped by a [SpillSink].
+type queueAdder struct {
+	s *QueuedSink
+}
+
+func (q queueAdder) Write(ctx context.Context, r slog.Record) error {
+	return q.s.add(queuedItem{ctx: context.WithoutCancel(ctx), record: r.Clone()}, false)
+}
+
+func (q queueAdder) Close() error {
+	return nil
+}
+
+// queueFromFlags wraps the sink in a [QueuedSink] if the `log.backpressure`
+// flag is set. Records at or above the never-drop level aren't dropped. The
+// sink is closed if the flags are invalid.
+func queueFromFlags(sink Sink, neverDrop slog.Level) (Sink, error) {
+	if *logBackpressure == "" {
+		return sink, nil
+	}
+
+	backpressure, err := parseBackpressure(*logBackpressure)
+	if err != nil {
+		_ = sink.Close()
+		return nil, err
+	}
+
+	queued, err := NewQueuedSink(sink, QueueConfig{
+		Size:         *logQueueSize,
+		Backpressure: backpressure,
+		SpillDir:     *logSpillDir,
+		NeverDrop:    neverDrop,
+	})
+	if err != nil {
+		_ = sink.Close()
+		return nil, err
+	}
+	return queued, nil
+}
+
+// parseBackpressure parses the name of a backpressure strategy.
+func parseBackpressure(name string) (Backpressure, error) {
+	switch name {
+	case "block":
+		return BackpressureBlock, nil
+	case "drop":
+		return BackpressureDropLowest, nil
+	case "spill":
+		return BackpressureSpill, nil
+	default:
+		return 0, fmt.Errorf("unknown backpressure strategy %q", name)
+	}
+}
@@ -0,0 +1,217 @@
+package slogflags
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedSink records messages, blocking on the first record until the gate is
+// opened.
+type gatedSink struct {
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+
+	mu       sync.Mutex
+	messages []string
+	closed   bool
+}
+
+func newGatedSink() *gatedSink {
+	return &gatedSink{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (s *gatedSink) Write(_ context.Context, r slog.Record) error {
+	s.once.Do(func() {
+		close(s.started)
+		<-s.gate
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, r.Message)
+	return nil
+}
+
+func (s *gatedSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *gatedSink) written() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func Test_QueuedSink_Block(t *testing.T) {
+	inner := newGatedSink()
+	sink, err := NewQueuedSink(inner, QueueConfig{Size: 1})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("one")
+	<-inner.started
+	l.Info("two")
+
+	logged := make(chan struct{})
+	go func() {
+		l.Info("three")
+		close(logged)
+	}()
+
+	select {
+	case <-logged:
+		t.Fatal("Write did not block while the queue was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.gate)
+	<-logged
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"one", "two", "three"}, inner.written())
+	assert.True(t, inner.closed)
+}
+
+func Test_QueuedSink_DropLowest(t *testing.T) {
+	before := Stats()
+	inner := newGatedSink()
+	sink, err := NewQueuedSink(inner, QueueConfig{Size: 2, Backpressure: BackpressureDropLowest})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelDebug))
+	l.Info("first")
+	<-inner.started
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+	l.Debug("debug")
+
+	close(inner.gate)
+	require.NoError(t, sink.Flush())
+	assert.Equal(t, []string{"first", "warn", "error"}, inner.written())
+
+	dropped := Stats().sub(before).QueueFull
+	assert.Equal(t, uint64(1), dropped[slog.LevelInfo])
+	assert.Equal(t, uint64(1), dropped[slog.LevelDebug])
+	require.NoError(t, sink.Close())
+}
+
+func Test_QueuedSink_DropLowestDropsOldestOfSameLevel(t *testing.T) {
+	inner := newGatedSink()
+	sink, err := NewQueuedSink(inner, QueueConfig{Size: 2, Backpressure: BackpressureDropLowest})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelDebug))
+	l.Info("first")
+	<-inner.started
+	l.Error("one")
+	l.Error("two")
+	l.Error("three")
+
+	close(inner.gate)
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"first", "two", "three"}, inner.written())
+}
+
+func Test_QueuedSink_DropLowestBlocksForNeverDropLevel(t *testing.T) {
+	inner := newGatedSink()
+	sink, err := NewQueuedSink(inner, QueueConfig{Size: 1, Backpressure: BackpressureDropLowest, NeverDrop: slog.LevelError})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelDebug))
+	l.Info("first")
+	<-inner.started
+	l.Error("one")
+
+	logged := make(chan struct{})
+	go func() {
+		l.Error("two")
+		close(logged)
+	}()
+
+	select {
+	case <-logged:
+		t.Fatal("Write did not block for a never-drop record while the queue was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.gate)
+	<-logged
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"first", "one", "two"}, inner.written())
+}
+
+func Test_QueuedSink_WriteAfterClose(t *testing.T) {
+	for _, backpressure := range []Backpressure{BackpressureBlock, BackpressureDropLowest} {
+		inner := newGatedSink()
+		close(inner.gate)
+		sink, err := NewQueuedSink(inner, QueueConfig{Backpressure: backpressure})
+		require.NoError(t, err)
+		require.NoError(t, sink.Close())
+
+		err = sink.Write(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "late", 0))
+		assert.EqualError(t, err, "queue: sink is closed")
+		assert.Empty(t, inner.written())
+	}
+}
+
+func Test_QueuedSink_Spill(t *testing.T) {
+	inner := newGatedSink()
+	sink, err := NewQueuedSink(inner, QueueConfig{Size: 2, Backpressure: BackpressureSpill, SpillDir: t.TempDir()})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("1")
+	<-inner.started
+
+	var want []string
+	for _, m := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		if m != "1" {
+			l.Info(m)
+		}
+		want = append(want, m)
+	}
+
+	close(inner.gate)
+	require.NoError(t, sink.Flush())
+	assert.Equal(t, want, inner.written())
+	require.NoError(t, sink.Close())
+}
+
+func Test_QueuedSink_SpillRequiresDir(t *testing.T) {
+	_, err := NewQueuedSink(newGatedSink(), QueueConfig{Backpressure: BackpressureSpill})
+	assert.Error(t, err)
+}
+
+func Test_QueueFromFlags(t *testing.T) {
+	inner := newGatedSink()
+	sink, err := queueFromFlags(inner, slog.LevelError)
+	require.NoError(t, err)
+	assert.Same(t, Sink(inner), sink)
+
+	_ = flag.Set("log.backpressure", "drop")
+	defer flag.Set("log.backpressure", "")
+
+	sink, err = queueFromFlags(inner, slog.LevelError)
+	require.NoError(t, err)
+	queued, ok := sink.(*QueuedSink)
+	require.True(t, ok)
+	assert.Equal(t, BackpressureDropLowest, queued.config.Backpressure)
+	assert.Equal(t, 1000, queued.config.Size)
+	assert.Equal(t, slog.LevelError, queued.config.NeverDrop)
+	require.NoError(t, queued.Close())
+
+	_ = flag.Set("log.backpressure", "sometimes")
+	_, err = queueFromFlags(newGatedSink(), slog.LevelError)
+	assert.Error(t, err)
+}
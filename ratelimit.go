@@ -0,0 +1,154 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// rateLimiter limits the number of records for each key in a fixed window.
+// At the end of each window a summary record is written for each key that
+// had records suppressed.
+type rateLimiter struct {
+	keyFunc   func(ctx context.Context, r slog.Record) string
+	limit     int
+	window    time.Duration
+	neverDrop slog.Level
+	handler   slog.Handler
+	onError   func(err error, r slog.Record)
+
+	mu         sync.Mutex
+	counts     map[string]int
+	suppressed map[string]int
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func newRateLimiter(handler slog.Handler, keyFunc func(ctx context.Context, r slog.Record) string, limit int, window time.Duration, neverDrop slog.Level, onError func(err error, r slog.Record)) *rateLimiter {
+	l := &rateLimiter{
+		keyFunc:    keyFunc,
+		limit:      limit,
+		window:     window,
+		neverDrop:  neverDrop,
+		handler:    handler,
+		onError:    onError,
+		counts:     map[string]int{},
+		suppressed: map[string]int{},
+		stop:       make(chan struct{}),
+	}
+
+	go l.run()
+	return l
+}
+
+func (l *rateLimiter) run() {
+	t := time.NewTicker(l.window)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C:
+			_ = l.flush()
+		}
+	}
+}
+
+// allow determines whether another record with the given key may be written
+// in the current window.
+func (l *rateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.counts[key] < l.limit {
+		l.counts[key]++
+		return true
+	}
+	l.suppressed[key]++
+	return false
+}
+
+// flush starts a new window, writing summaries for any keys that had records
+// suppressed in the previous one.
+func (l *rateLimiter) flush() error {
+	l.mu.Lock()
+	suppressed := l.suppressed
+	l.counts = map[string]int{}
+	l.suppressed = map[string]int{}
+	l.mu.Unlock()
+
+	ctx := context.Background()
+	if len(suppressed) == 0 || !l.handler.Enabled(ctx, slog.LevelWarn) {
+		return nil
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(suppressed)) {
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, "Log records suppressed by rate limit", 0)
+		r.AddAttrs(slog.String("key", key), slog.Int("suppressed", suppressed[key]))
+		if err := l.handler.Handle(ctx, r); err != nil && l.onError != nil {
+			l.onError(err, r)
+		}
+	}
+	return nil
+}
+
+// close stops the background goroutine and writes any pending summaries.
+func (l *rateLimiter) close() error {
+	l.once.Do(func() { close(l.stop) })
+	return l.flush()
+}
+
+// rateLimitHandler is a [log/slog.Handler] that uses a rateLimiter to drop
+// records that exceed the limit for their key.
+type rateLimitHandler struct {
+	next    slog.Handler
+	limiter *rateLimiter
+	goas    []groupOrAttrs
+}
+
+func newRateLimitHandler(next slog.Handler, limiter *rateLimiter) *rateLimitHandler {
+	return &rateLimitHandler{next: next, limiter: limiter}
+}
+
+func (h *rateLimitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *rateLimitHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.limiter.neverDrop {
+		return h.next.Handle(ctx, r)
+	}
+
+	key := h.limiter.keyFunc(ctx, resolveRecord(r, h.goas))
+	if key != "" && !h.limiter.allow(key) {
+		countDrop(dropRateLimited, r.Level)
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *rateLimitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs), groupOrAttrs{attrs: attrs})
+}
+
+func (h *rateLimitHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name), groupOrAttrs{group: name})
+}
+
+func (h *rateLimitHandler) with(next slog.Handler, goa groupOrAttrs) *rateLimitHandler {
+	h2 := *h
+	h2.next = next
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
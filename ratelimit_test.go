@@ -0,0 +1,80 @@
+package slogflags
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clientKeyForTest(_ context.Context, r slog.Record) string {
+	var key string
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "client" {
+			key = a.Value.String()
+		}
+		return true
+	})
+	return key
+}
+
+func Test_RateLimit_LimitsRecordsPerKey(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithRateLimit(clientKeyForTest, 2, time.Hour))
+	for range 5 {
+		l.Info("Request", "client", "a")
+		l.With("client", "b").Info("Request")
+		l.Info("Unkeyed")
+	}
+
+	assert.Equal(t, 2, strings.Count(w.String(), "client=a"))
+	assert.Equal(t, 2, strings.Count(w.String(), "client=b"))
+	assert.Equal(t, 5, strings.Count(w.String(), "msg=Unkeyed"))
+
+	require.NoError(t, Flush())
+	assert.Contains(t, w.String(), "level=WARN msg=\"Log records suppressed by rate limit\" key=a suppressed=3\n")
+	assert.Contains(t, w.String(), "level=WARN msg=\"Log records suppressed by rate limit\" key=b suppressed=3\n")
+
+	l.Info("Request", "client", "a")
+	assert.Equal(t, 3, strings.Count(w.String(), "msg=Request client=a"))
+}
+
+func Test_RateLimit_ResetsEachWindow(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithRateLimit(clientKeyForTest, 1, 20*time.Millisecond))
+	l.Info("Request", "client", "a")
+	l.Info("Request", "client", "a")
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(w.String(), "key=a suppressed=1\n")
+	}, time.Second, 5*time.Millisecond)
+
+	l.Info("Request", "client", "a")
+	assert.Equal(t, 2, strings.Count(w.String(), "msg=Request"))
+}
+
+func Test_RateLimit_NeverDropsHighSeverityRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithRateLimit(clientKeyForTest, 1, time.Hour), WithNeverDrop(slog.LevelError))
+	for range 3 {
+		l.Info("Request", "client", "a")
+		l.Error("Failed", "client", "a")
+	}
+
+	assert.Equal(t, 1, strings.Count(w.String(), "msg=Request"))
+	assert.Equal(t, 3, strings.Count(w.String(), "msg=Failed"))
+}
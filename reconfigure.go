@@ -0,0 +1,44 @@
+package slogflags
+
+import (
+	"errors"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Reconfigure rebuilds the logger created by the most recent call to [Logger]
+// according to the given options and the current values of the flags, and
+// atomically swaps it into place. Loggers already returned by [Logger] and
+// [L], and any loggers derived from them using With or WithGroup, start using
+// the new configuration immediately, so logging can be changed without
+// restarting the application, for example when a configuration file is
+// reloaded.
+//
+// The options are not combined with those originally passed to [Logger], so
+// all options must be given again. They are checked using [Validate] first,
+// and if there are any problems the existing configuration is left in place
+// and the errors are returned. Otherwise, the outputs opened for the previous
+// configuration are flushed and closed once the new one is in use, and any
+// errors from doing so are returned. Records being logged at the same time as
+// the swap may be written using either configuration.
+//
+// If [Logger] hasn't been called, Reconfigure configures the handler used by
+// [L] in the same way.
+func Reconfigure(opts ...Option) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	if err := validate(ValidateConfig{Options: opts}); err != nil {
+		return err
+	}
+
+	slot := currentSlot.Load()
+	if slot == nil {
+		slot = new(atomic.Pointer[slog.Handler])
+		currentSlot.Store(slot)
+	}
+
+	flushes, closes := takeOwned(slot)
+	configure(slot, opts)
+	return errors.Join(callReversed(flushes), callReversed(closes))
+}
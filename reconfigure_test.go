@@ -0,0 +1,92 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Reconfigure_SwapsExistingLoggers(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	first := new(bytes.Buffer)
+	l := LoggerForTest(first)
+	derived := l.With("pkg", "db")
+	l.Info("Before")
+
+	second := new(bytes.Buffer)
+	require.NoError(t, Reconfigure(WithWriter(second), WithClock(testClock), WithAttrs(slog.String("app", "test"))))
+	l.Info("After")
+	derived.WithGroup("g").Warn("Derived", "k", "v")
+	L().Info("Proxied")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Before\n", first.String())
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=After app=test\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=Derived app=test pkg=db g.k=v\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Proxied app=test\n", second.String())
+}
+
+func Test_Reconfigure_UsesNewFlags(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	defer flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.Debug("Hidden")
+
+	_ = flag.Set("log.level", "debug")
+	require.NoError(t, Reconfigure(WithWriter(w), WithClock(testClock)))
+	l.Debug("Shown")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=DEBUG msg=Shown\n", w.String())
+}
+
+func Test_Reconfigure_InvalidKeepsExistingConfiguration(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+
+	setFlags(t, map[string]string{"log.level": "loud"})
+	err := Reconfigure(WithWriter(new(bytes.Buffer)))
+	assert.EqualError(t, err, `log.level: unknown log level "loud"`)
+
+	l.Info("Unchanged")
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Unchanged\n", w.String())
+}
+
+func Test_Reconfigure_ClosesPreviousOutputs(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	dir := t.TempDir()
+	firstPath := filepath.Join(dir, "first.log")
+	secondPath := filepath.Join(dir, "second.log")
+	setFlags(t, map[string]string{"log.output": firstPath, "log.buffer-size": "4096"})
+
+	l := Logger(WithClock(testClock))
+	l.Info("First")
+
+	require.NoError(t, flag.Set("log.output", secondPath))
+	require.NoError(t, Reconfigure(WithClock(testClock)))
+	l.Info("Second")
+
+	first, err := os.ReadFile(firstPath)
+	require.NoError(t, err)
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=First\n", string(first))
+
+	require.NoError(t, Close())
+
+	second, err := os.ReadFile(secondPath)
+	require.NoError(t, err)
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Second\n", string(second))
+}
@@ -0,0 +1,92 @@
+package slogflags
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+)
+
+// LevelFatal is the level of records logged by [RecoverAndLog] and [Main]
+// when an application is about to exit. It is written as "FATAL".
+const LevelFatal = slog.LevelError + 4
+
+// RecoverAndLog recovers from a panic, logs it at [LevelFatal] along with the
+// stack trace of the panicking goroutine, and calls [Close] so that the
+// record reaches every sink before panicking again with the same value. It
+// must be deferred directly:
+//
+//	func main() {
+//		flag.Parse()
+//		slogflags.Logger(slogflags.WithSetDefault(true))
+//		defer slogflags.RecoverAndLog()
+//		...
+//	}
+//
+// Only panics in the goroutine that deferred the call are recovered.
+func RecoverAndLog() {
+	if v := recover(); v != nil {
+		logPanic(v, debug.Stack())
+		panic(v)
+	}
+}
+
+// Main runs fn, which is typically the body of an application's main func.
+// If fn returns an error or panics, a record is logged at [LevelFatal] and the
+// process exits with a non-zero status after calling [Close]. Otherwise, Main
+// calls [Close] and returns.
+//
+//	func main() {
+//		flag.Parse()
+//		slogflags.Logger(slogflags.WithSetDefault(true))
+//		slogflags.Main(run)
+//	}
+func Main(fn func() error) {
+	runMain(fn, os.Exit)
+}
+
+// runMain implements [Main], calling exit instead of [os.Exit].
+func runMain(fn func() error, exit func(int)) {
+	code := func() (code int) {
+		defer func() {
+			if v := recover(); v != nil {
+				logPanic(v, debug.Stack())
+				// Go exits with status 2 for unrecovered panics.
+				code = 2
+			}
+		}()
+
+		if err := fn(); err != nil {
+			currentLogger().Log(context.Background(), LevelFatal, "Exiting due to error", "error", err)
+			_ = Close()
+			return 1
+		}
+		_ = Close()
+		return 0
+	}()
+
+	if code != 0 {
+		exit(code)
+	}
+}
+
+// logPanic logs a record describing a panic, and closes all outputs.
+func logPanic(v any, stack []byte) {
+	value := fmt.Sprint(v)
+	if err, ok := v.(error); ok {
+		value = err.Error()
+	}
+
+	currentLogger().Log(context.Background(), LevelFatal, "Panic", "panic", value, "stack", string(stack))
+	_ = Close()
+}
+
+// currentLogger returns the logger created by the most recent call to [Logger],
+// or the default logger if it hasn't been called.
+func currentLogger() *slog.Logger {
+	if h := currentHandler.Load(); h != nil {
+		return slog.New(*h)
+	}
+	return slog.Default()
+}
@@ -0,0 +1,70 @@
+package slogflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecoverAndLog(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer RecoverAndLog()
+		panic("boom")
+	})
+	assert.Contains(t, w.String(), "level=FATAL msg=Panic panic=boom stack=\"goroutine ")
+	assert.Contains(t, w.String(), "Test_RecoverAndLog")
+}
+
+func Test_RecoverAndLog_NoPanic(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w)
+
+	assert.NotPanics(t, func() {
+		defer RecoverAndLog()
+	})
+	assert.Empty(t, w.String())
+}
+
+func Test_Main(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	tests := []struct {
+		name     string
+		fn       func() error
+		wantCode int
+		wantLog  string
+	}{
+		{"success", func() error { return nil }, 0, ""},
+		{"error", func() error { return errors.New("no config") }, 1, "level=FATAL msg=\"Exiting due to error\" error=\"no config\"\n"},
+		{"panic", func() error { panic(errors.New("nil map")) }, 2, "level=FATAL msg=Panic panic=\"nil map\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			LoggerForTest(w)
+
+			code := 0
+			runMain(tt.fn, func(c int) { code = c })
+			assert.Equal(t, tt.wantCode, code)
+			if tt.wantLog == "" {
+				assert.Empty(t, w.String())
+			} else {
+				assert.Contains(t, w.String(), tt.wantLog)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// redacted replaces the values of redacted attributes.
+const redacted = "[REDACTED]"
+
+// redactValue is the replacement func used by [WithRedactKeys].
+func redactValue(slog.Value) slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// redactHandler is a [log/slog.Handler] that replaces the values of
+// attributes with certain keys, at any depth, before passing records on.
+type redactHandler struct {
+	next    slog.Handler
+	keys    map[string]struct{}
+	replace func(slog.Value) slog.Value
+}
+
+func newRedactHandler(next slog.Handler, keys []string, replace func(slog.Value) slog.Value) *redactHandler {
+	h := &redactHandler{next: next, keys: make(map[string]struct{}, len(keys)), replace: replace}
+	for _, k := range keys {
+		h.keys[strings.ToLower(k)] = struct{}{}
+	}
+	return h
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	res := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		res.AddAttrs(h.redact(a))
+		return true
+	})
+	return h.next.Handle(ctx, res)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make([]slog.Attr, len(attrs))
+	for i := range attrs {
+		res[i] = h.redact(attrs[i])
+	}
+	return &redactHandler{next: h.next.WithAttrs(res), keys: h.keys, replace: h.replace}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name), keys: h.keys, replace: h.replace}
+}
+
+// redacts reports whether values with the given key should be redacted.
+func (h *redactHandler) redacts(key string) bool {
+	_, ok := h.keys[strings.ToLower(key)]
+	return ok
+}
+
+// redact returns the attribute with its value replaced if its key matches,
+// or with any matching attributes or fields within it replaced.
+func (h *redactHandler) redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if h.redacts(a.Key) {
+		return slog.Attr{Key: a.Key, Value: h.replace(a.Value)}
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		res := make([]slog.Attr, len(attrs))
+		for i := range attrs {
+			res[i] = h.redact(attrs[i])
+		}
+		a.Value = slog.GroupValue(res...)
+	case slog.KindAny:
+		if v, ok := h.redactJSONValue(a.Value.Any()); ok {
+			a.Value = slog.AnyValue(v)
+		}
+	}
+	return a
+}
+
+// redactJSONValue handles values such as structs and maps that are logged
+// whole. If the value's JSON encoding contains any fields that should be
+// replaced, the decoded JSON is returned with those fields replaced.
+// Otherwise, ok is false and the value should be logged as-is.
+func (h *redactHandler) redactJSONValue(v any) (res any, ok bool) {
+	if v == nil {
+		return nil, false
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return nil, false
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, false
+	}
+	return res, h.redactJSON(res)
+}
+
+// redactJSON replaces matching fields in decoded JSON in place, and reports
+// whether any were found.
+func (h *redactHandler) redactJSON(v any) bool {
+	found := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k := range v {
+			if h.redacts(k) {
+				v[k] = h.replace(slog.AnyValue(v[k])).Any()
+				found = true
+			} else if h.redactJSON(v[k]) {
+				found = true
+			}
+		}
+	case []any:
+		for i := range v {
+			if h.redactJSON(v[i]) {
+				found = true
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,70 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithRedactKeys_TopLevel(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithRedactKeys("password", "Authorization"))
+	l.Info("Login", "user", "alice", "password", "hunter2", "authorization", "Bearer abc")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Login user=alice password=[REDACTED] authorization=[REDACTED]\n", w.String())
+}
+
+func Test_WithRedactKeys_GroupsAndWith(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithRedactKeys("token"), WithAttrs(slog.String("token", "static")))
+	l.With("token", "child").WithGroup("req").Info("Request", slog.Group("headers", "token", "abc", "accept", "*/*"))
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Request token=[REDACTED] token=[REDACTED] req.headers.token=[REDACTED] req.headers.accept=*/*\n", w.String())
+}
+
+func Test_WithRedactKeys_Structs(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	type credentials struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+	type request struct {
+		Credentials []credentials
+		Other       map[string]int
+	}
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithRedactKeys("password"))
+	l.Info("Request", "req", &request{Credentials: []credentials{{"alice", "hunter2"}}}, "other", map[string]int{"a": 1})
+
+	assert.Equal(t, `{"time":"2026-01-02T03:04:05Z","level":"INFO","msg":"Request","req":{"Credentials":[{"password":"[REDACTED]","user":"alice"}],"Other":null},"other":{"a":1}}`+"\n", w.String())
+}
+
+type secretValuer string
+
+func (s secretValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("id", "1"), slog.String("password", string(s)))
+}
+
+func Test_WithRedactKeys_LogValuer(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithRedactKeys("password"))
+	l.Info("User", "user", secretValuer("hunter2"))
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=User user.id=1 user.password=[REDACTED]\n", w.String())
+}
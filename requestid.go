@@ -0,0 +1,68 @@
+package slogflags
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+)
+
+// RequestIDHeader is the HTTP header used by [RequestIDMiddleware].
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key used to store request IDs.
+type requestIDKey struct{}
+
+// NewRequestID returns a new random request ID, as 32 hex characters.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextWithRequestID returns a copy of ctx containing the request ID.
+// Records logged with the returned context (e.g. using
+// [log/slog.Logger.InfoContext]) by loggers created with [Logger] have a
+// "request_id" attribute added automatically.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by
+// [ContextWithRequestID], if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// RequestIDMiddleware wraps an HTTP handler so that each request's context
+// contains a request ID. The ID is taken from the X-Request-ID header if the
+// client sent one, or generated using [NewRequestID] otherwise, and is
+// included in the response headers. If the context already contains a request
+// ID, it is left unchanged.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := RequestIDFromContext(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 200 {
+			id = NewRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+	})
+}
+
+// requestIDAttrs returns a "request_id" attribute if ctx contains a request
+// ID.
+func requestIDAttrs(ctx context.Context) []slog.Attr {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return []slog.Attr{slog.String("request_id", id)}
+	}
+	return nil
+}
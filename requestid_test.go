@@ -0,0 +1,50 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewRequestID(t *testing.T) {
+	a, b := NewRequestID(), NewRequestID()
+	assert.Regexp(t, `^[0-9a-f]{32}$`, a)
+	assert.NotEqual(t, a, b)
+}
+
+func Test_RequestIDIsLogged(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.InfoContext(ContextWithRequestID(context.Background(), "req-1"), "Handled")
+	l.InfoContext(context.Background(), "Background")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Handled request_id=req-1\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Background\n", w.String())
+}
+
+func Test_RequestIDMiddleware(t *testing.T) {
+	var got string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = RequestIDFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "from-client")
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "from-client", got)
+	assert.Equal(t, "from-client", rec.Header().Get("X-Request-ID"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Regexp(t, `^[0-9a-f]{32}$`, got)
+	assert.Equal(t, got, rec.Header().Get("X-Request-ID"))
+}
@@ -0,0 +1,148 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+)
+
+// reservedKeysGroup is the group that attributes with reserved keys are moved
+// into.
+const reservedKeysGroup = "fields"
+
+// defaultReservedKeys are the keys used by handlers for the built-in fields
+// of a record, in all the formats accepted by the `log.format` flag.
+var defaultReservedKeys = []string{slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey}
+
+// builtInKeyer is implemented by sinks whose encoding puts attributes
+// alongside built-in fields with keys other than the default ones, such as
+// "@timestamp" and "message".
+type builtInKeyer interface {
+	builtInKeys() []string
+}
+
+// reservedKeysFor returns the reserved keys for output written in the
+// selected format and to the given sinks, which may include nil.
+func reservedKeysFor(sinks []Sink) map[string]bool {
+	keys := make(map[string]bool)
+	for _, k := range defaultReservedKeys {
+		keys[k] = true
+	}
+	for _, sink := range sinks {
+		for _, k := range sinkBuiltInKeys(sink) {
+			keys[k] = true
+		}
+	}
+	return keys
+}
+
+// sinkBuiltInKeys returns the keys of the built-in fields written by a sink,
+// looking through sinks that wrap others.
+func sinkBuiltInKeys(sink Sink) []string {
+	switch s := sink.(type) {
+	case *QueuedSink:
+		return sinkBuiltInKeys(s.sink)
+	case *SpillSink:
+		return sinkBuiltInKeys(s.sink)
+	case *CircuitBreakerSink:
+		return sinkBuiltInKeys(s.sink)
+	case builtInKeyer:
+		return s.builtInKeys()
+	}
+	return nil
+}
+
+// reservedKeysHandler is a [log/slog.Handler] that moves top-level attributes
+// whose keys collide with the built-in fields into a "fields" group, so that
+// they're written as "fields.level" in text formats, or in a nested object
+// in JSON, instead of duplicating the built-in keys. Attributes moved from
+// WithAttrs are held back and merged with those moved from each record, so
+// that only one "fields" group is written.
+type reservedKeysHandler struct {
+	next    slog.Handler
+	keys    map[string]bool
+	moved   []slog.Attr
+	grouped bool
+}
+
+func newReservedKeysHandler(next slog.Handler, keys map[string]bool) *reservedKeysHandler {
+	return &reservedKeysHandler{next: next, keys: keys}
+}
+
+func (h *reservedKeysHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *reservedKeysHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.grouped {
+		return h.next.Handle(ctx, r)
+	}
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	attrs, moved := h.split(attrs)
+	if len(h.moved) == 0 && len(moved) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+
+	res := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	res.AddAttrs(attrs...)
+	res.AddAttrs(slog.Attr{Key: reservedKeysGroup, Value: slog.GroupValue(append(h.moved[:len(h.moved):len(h.moved)], moved...)...)})
+	return h.next.Handle(ctx, res)
+}
+
+func (h *reservedKeysHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	if !h.grouped {
+		var moved []slog.Attr
+		attrs, moved = h.split(attrs)
+		h2.moved = append(h.moved[:len(h.moved):len(h.moved)], moved...)
+	}
+	if len(attrs) > 0 {
+		h2.next = h.next.WithAttrs(attrs)
+	}
+	return &h2
+}
+
+func (h *reservedKeysHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	// Records' attributes will be within the group from now on, so can't
+	// collide with the built-in keys. Any attributes already moved have to
+	// be written at the top level before the group is opened.
+	next := h.next
+	if len(h.moved) > 0 {
+		next = next.WithAttrs([]slog.Attr{{Key: reservedKeysGroup, Value: slog.GroupValue(h.moved...)}})
+	}
+	return &reservedKeysHandler{next: next.WithGroup(name), keys: h.keys, grouped: true}
+}
+
+// split separates attributes with reserved keys from the others, including
+// those within groups with empty keys (which are inlined by handlers).
+func (h *reservedKeysHandler) split(attrs []slog.Attr) (kept, moved []slog.Attr) {
+	changed := false
+	for _, a := range attrs {
+		switch {
+		case h.keys[a.Key]:
+			moved = append(moved, a)
+			changed = true
+		case a.Key == "" && a.Value.Kind() == slog.KindGroup:
+			if inner, innerMoved := h.split(a.Value.Group()); len(innerMoved) > 0 {
+				moved = append(moved, innerMoved...)
+				a.Value = slog.GroupValue(inner...)
+				changed = true
+			}
+			kept = append(kept, a)
+		default:
+			kept = append(kept, a)
+		}
+	}
+	if !changed {
+		return attrs, nil
+	}
+	return kept, moved
+}
@@ -0,0 +1,96 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReservedKeys_Text(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.With("source", "api").Info("Hello", "level", "high", "user", "alice", "msg", "hi")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Hello user=alice fields.source=api fields.level=high fields.msg=hi\n", w.String())
+}
+
+func Test_ReservedKeys_JSON(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.Info("Hello", "level", "high", slog.Group("", "source", "api"), "user", "alice")
+
+	assert.Equal(t, `{"time":"2026-01-02T03:04:05Z","level":"INFO","msg":"Hello","user":"alice","fields":{"level":"high","source":"api"}}`+"\n", w.String())
+}
+
+func Test_ReservedKeys_InGroups(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.WithGroup("job").Info("Hello", "level", "high")
+	l.Info("Hello", slog.Group("job", "msg", "soon"))
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Hello job.level=high\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Hello job.msg=soon\n", w.String())
+}
+
+func Test_WithRenameReservedKeys_Disabled(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithRenameReservedKeys(false))
+	l.Info("Hello", "level", "high")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Hello level=high\n", w.String())
+}
+
+func Test_ReservedKeys_MergesWithAttrs(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.With("level", "x").With("user", "alice").Info("m", "msg", "y")
+	l.With("level", "x").WithGroup("job").Info("m", "msg", "y")
+
+	assert.Equal(t, ""+
+		`{"time":"2026-01-02T03:04:05Z","level":"INFO","msg":"m","user":"alice","fields":{"level":"x","msg":"y"}}`+"\n"+
+		`{"time":"2026-01-02T03:04:05Z","level":"INFO","msg":"m","fields":{"level":"x"},"job":{"msg":"y"}}`+"\n", w.String())
+}
+
+func Test_ReservedKeys_SinkKeys(t *testing.T) {
+	es := NewElasticsearchSink(ElasticsearchConfig{URL: "http://localhost:9200"})
+	queued, err := NewQueuedSink(es, QueueConfig{})
+	require.NoError(t, err)
+	defer queued.Close()
+
+	keys := reservedKeysFor([]Sink{nil, queued})
+	assert.True(t, keys["msg"])
+	assert.True(t, keys["message"])
+	assert.True(t, keys["@timestamp"])
+
+	sink := &flakySink{}
+	l := slog.New(newReservedKeysHandler(newSinkHandler(sink, slog.LevelInfo), keys))
+	l.Info("Hello", "message", "hi", "user", "alice")
+
+	require.Len(t, sink.buffered, 1)
+	assert.Equal(t, map[string]any{
+		"user":   "alice",
+		"fields": map[string]any{"message": "hi"},
+	}, recordAttrs(sink.buffered[0]))
+}
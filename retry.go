@@ -0,0 +1,54 @@
+package slogflags
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// retryableError wraps an error to indicate the operation that caused it may
+// succeed if attempted again.
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// retryable marks err as being retryable.
+func retryable(err error) error {
+	return retryableError{err: err}
+}
+
+// retryableStatus returns true if an HTTP response with the given status code
+// indicates that the request may succeed if retried.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// withRetries calls fn until it succeeds, returns an error that is not
+// marked as [retryable], or has been retried maxRetries times. The delay
+// between attempts starts at backoff and doubles after each attempt.
+func withRetries(maxRetries int, backoff time.Duration, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+
+		var re retryableError
+		if err == nil || !errors.As(err, &re) {
+			return err
+		}
+
+		if attempt >= maxRetries {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, re.err)
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
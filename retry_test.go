@@ -0,0 +1,102 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NegativeMaxRetriesDisablesRetries(t *testing.T) {
+	token := func(context.Context) (string, error) {
+		return "tok", nil
+	}
+	credentials := func(context.Context) (AWSCredentials, error) {
+		return AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret"}, nil
+	}
+
+	// writeOne writes a single record to the sink, then closes it.
+	writeOne := func(sink Sink, err error) error {
+		if err != nil {
+			return err
+		}
+		slog.New(newSinkHandler(sink, slog.LevelInfo)).Info("One")
+		return sink.Close()
+	}
+
+	tests := []struct {
+		name  string
+		write func(endpoint string) error
+	}{
+		{"azure monitor", func(endpoint string) error {
+			return writeOne(NewAzureMonitorSink(AzureMonitorConfig{
+				Endpoint:   endpoint,
+				RuleID:     "dcr-123",
+				Stream:     "Custom-Logs_CL",
+				MaxRetries: -1,
+				Token:      token,
+			}))
+		}},
+		{"cloudwatch", func(endpoint string) error {
+			return writeOne(NewCloudWatchSink(CloudWatchConfig{
+				LogGroup:    "group",
+				LogStream:   "stream",
+				Region:      "eu-west-2",
+				Endpoint:    endpoint,
+				MaxRetries:  -1,
+				Credentials: credentials,
+			}))
+		}},
+		{"gcp logging", func(endpoint string) error {
+			return writeOne(NewGCPLoggingSink(GCPLoggingConfig{
+				ProjectID:  "proj",
+				LogID:      "app",
+				Resource:   &GCPResource{Type: "global"},
+				Endpoint:   endpoint,
+				MaxRetries: -1,
+				Token:      token,
+			}))
+		}},
+		{"gcs", func(endpoint string) error {
+			store, err := NewGCSStore(GCSConfig{Bucket: "bucket", Endpoint: endpoint, MaxRetries: -1, Token: token})
+			if err != nil {
+				return err
+			}
+			return store.Put(context.Background(), "key", "application/gzip", []byte("data"))
+		}},
+		{"otlp", func(endpoint string) error {
+			return writeOne(NewOTLPSink(OTLPConfig{Endpoint: endpoint + "/v1/logs", MaxRetries: -1}))
+		}},
+		{"s3", func(endpoint string) error {
+			store, err := NewS3Store(S3Config{
+				Bucket:      "bucket",
+				Region:      "eu-west-2",
+				Endpoint:    endpoint,
+				MaxRetries:  -1,
+				Credentials: credentials,
+			})
+			if err != nil {
+				return err
+			}
+			return store.Put(context.Background(), "key", "application/gzip", []byte("data"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			assert.ErrorContains(t, tt.write(server.URL), "giving up after 1 attempts")
+			assert.Equal(t, int32(1), requests.Load())
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package slogflags
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RingBuffer keeps the most recent records logged, regardless of the log
+// level, so that they can be inspected while an application is running. Pass
+// it to [Logger] using [WithRingBuffer].
+//
+// RingBuffer is also an [net/http.Handler] that writes the records it holds,
+// oldest first. It's intended to be served on an internal debugging endpoint,
+// as records may contain sensitive information:
+//
+//	buffer := slogflags.NewRingBuffer(1000)
+//	logger := slogflags.Logger(slogflags.WithRingBuffer(buffer))
+//	debugMux.Handle("/debug/logs", buffer)
+//
+// Records are written in the text format, or as JSON if the "format" query
+// parameter is "json". They can be filtered using the following query
+// parameters:
+//
+//   - level: the minimum level of records to include (e.g. "warn")
+//   - attr: an attribute the record must have, in the form "key=value". Keys
+//     within groups are separated by dots (e.g. "request.id=abc"). May be
+//     specified multiple times, in which case all must match.
+//   - since, until: the range of times to include, either as RFC 3339
+//     timestamps, or as durations relative to now (e.g. "5m")
+//
+// If the "follow" query parameter is "1", new records are streamed to the
+// client as they are logged, either as server-sent events or over a WebSocket
+// if the client requests an upgrade. For example:
+//
+//	curl -N 'http://localhost:8080/debug/logs?follow=1&level=warn'
+type RingBuffer struct {
+	mu          sync.Mutex
+	records     []slog.Record
+	next        int
+	size        int
+	subscribers map[chan slog.Record]struct{}
+}
+
+// NewRingBuffer creates a new [RingBuffer] that holds at most size records.
+func NewRingBuffer(size int) *RingBuffer {
+	return &RingBuffer{size: max(size, 1), subscribers: map[chan slog.Record]struct{}{}}
+}
+
+// add adds a record to the buffer, overwriting the oldest record if it is
+// full.
+func (b *RingBuffer) add(r slog.Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r = r.Clone()
+	for ch := range b.subscribers {
+		select {
+		case ch <- r:
+		default:
+			// Followers that can't keep up miss records, rather than slowing
+			// down logging.
+		}
+	}
+
+	if len(b.records) < b.size {
+		b.records = append(b.records, r)
+		return nil
+	}
+	b.records[b.next] = r
+	b.next = (b.next + 1) % b.size
+	return nil
+}
+
+// Records returns the records currently held in the buffer, oldest first.
+// Attributes added using [log/slog.Logger.With] are included in each record.
+func (b *RingBuffer) Records() []slog.Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.snapshot()
+}
+
+// snapshot returns the records currently held in the buffer, oldest first.
+// It must be called with the mutex held.
+func (b *RingBuffer) snapshot() []slog.Record {
+	res := make([]slog.Record, 0, len(b.records))
+	res = append(res, b.records[b.next:]...)
+	return append(res, b.records[:b.next]...)
+}
+
+// subscribe returns the records currently held in the buffer, and a channel
+// that receives records added after that point. The returned func must be
+// called to stop receiving records.
+func (b *RingBuffer) subscribe() ([]slog.Record, <-chan slog.Record, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan slog.Record, 100)
+	b.subscribers[ch] = struct{}{}
+	return b.snapshot(), ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, ch)
+	}
+}
+
+// ServeHTTP writes the records held in the buffer that match the filters in
+// the query string, and then streams new records if requested.
+func (b *RingBuffer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	filter, err := parseRecordFilter(req.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if follow := req.URL.Query().Get("follow"); follow == "1" || follow == "true" {
+		b.follow(w, req, filter)
+		return
+	}
+
+	format := req.URL.Query().Get("format")
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	handler := recordViewHandler(w, format)
+	for _, r := range b.Records() {
+		if filter.match(r) {
+			_ = handler.Handle(req.Context(), r)
+		}
+	}
+}
+
+// recordViewHandler returns a handler that writes records of any level in the
+// requested format ("json", or text otherwise).
+func recordViewHandler(w io.Writer, format string) slog.Handler {
+	opts := &slog.HandlerOptions{Level: slog.Level(math.MinInt)}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// recordFilter selects records to be shown by a [RingBuffer].
+type recordFilter struct {
+	level slog.Level
+	attrs map[string]string
+	since time.Time
+	until time.Time
+}
+
+// parseRecordFilter creates a filter from the query parameters described in
+// the [RingBuffer] docs. Relative times are resolved against now.
+func parseRecordFilter(q url.Values, now time.Time) (recordFilter, error) {
+	f := recordFilter{level: slog.Level(math.MinInt), attrs: map[string]string{}}
+
+	if level := q.Get("level"); level != "" {
+		if err := f.level.UnmarshalText([]byte(level)); err != nil {
+			return f, fmt.Errorf("invalid level %q", level)
+		}
+	}
+
+	for _, attr := range q["attr"] {
+		key, value, ok := strings.Cut(attr, "=")
+		if !ok || key == "" {
+			return f, fmt.Errorf("invalid attr %q, expected key=value", attr)
+		}
+		f.attrs[key] = value
+	}
+
+	var err error
+	if f.since, err = parseFilterTime(q.Get("since"), now); err != nil {
+		return f, fmt.Errorf("invalid since: %w", err)
+	}
+	if f.until, err = parseFilterTime(q.Get("until"), now); err != nil {
+		return f, fmt.Errorf("invalid until: %w", err)
+	}
+
+	return f, nil
+}
+
+// parseFilterTime parses an RFC 3339 timestamp, or a duration before now. An
+// empty value returns the zero time.
+func parseFilterTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// match reports whether the record satisfies the filter.
+func (f recordFilter) match(r slog.Record) bool {
+	if r.Level < f.level {
+		return false
+	}
+	if !f.since.IsZero() && r.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && r.Time.After(f.until) {
+		return false
+	}
+
+	if len(f.attrs) > 0 {
+		attrs := recordAttrs(r)
+		for key, want := range f.attrs {
+			if v, ok := lookupAttr(attrs, key); !ok || fmt.Sprint(v) != want {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RingBuffer_KeepsMostRecentRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	buffer := NewRingBuffer(3)
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithRingBuffer(buffer))
+	for _, msg := range []string{"one", "two", "three", "four"} {
+		l.Debug(msg)
+	}
+	l.With("k", "v").Info("five")
+
+	var messages []string
+	for _, r := range buffer.Records() {
+		messages = append(messages, r.Message)
+	}
+	assert.Equal(t, []string{"three", "four", "five"}, messages)
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=five k=v\n", w.String())
+}
+
+func Test_RingBuffer_ServeHTTP(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	l := slog.New(newTapHandler(slog.DiscardHandler, slog.Level(-100), buffer.add))
+	l.Debug("Debug", "request", slog.GroupValue(slog.String("id", "a")))
+	l.Warn("Warn", "request", slog.GroupValue(slog.String("id", "b")))
+	l.Error("Error", "request", slog.GroupValue(slog.String("id", "a")), "status", 500)
+
+	get := func(query string) (int, string) {
+		rec := httptest.NewRecorder()
+		buffer.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs?"+query, nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	_, body := get("")
+	assert.Contains(t, body, "level=DEBUG msg=Debug request.id=a\n")
+	assert.Contains(t, body, "level=ERROR msg=Error request.id=a status=500\n")
+
+	_, body = get("level=warn")
+	assert.NotContains(t, body, "msg=Debug")
+	assert.Contains(t, body, "msg=Warn")
+
+	_, body = get("attr=request.id%3Da")
+	assert.Contains(t, body, "msg=Debug")
+	assert.NotContains(t, body, "msg=Warn")
+	assert.Contains(t, body, "msg=Error")
+
+	_, body = get("attr=request.id%3Da&attr=status%3D500&format=json")
+	assert.Regexp(t, `^\{"time":"[^"]+","level":"ERROR","msg":"Error","request":\{"id":"a"\},"status":500\}\n$`, body)
+
+	_, body = get("until=1h")
+	assert.Empty(t, body)
+
+	code, _ := get("level=loud")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func Test_ParseRecordFilter_Times(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	f, err := parseRecordFilter(url.Values{"since": {"5m"}, "until": {"2025-06-01T11:59:00Z"}}, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-5*time.Minute), f.since)
+	assert.Equal(t, now.Add(-time.Minute), f.until)
+
+	_, err = parseRecordFilter(url.Values{"since": {"yesterday"}}, now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RPCKind describes the shape of an RPC.
+type RPCKind string
+
+const (
+	// RPCUnary is an RPC with a single request and response.
+	RPCUnary RPCKind = "unary"
+	// RPCClientStream is an RPC where the client sends a stream of messages.
+	RPCClientStream RPCKind = "client_stream"
+	// RPCServerStream is an RPC where the server sends a stream of messages.
+	RPCServerStream RPCKind = "server_stream"
+	// RPCBidiStream is an RPC where both sides send a stream of messages.
+	RPCBidiStream RPCKind = "bidi_stream"
+)
+
+// RPCInfo describes a single RPC logged by [StartRPC].
+type RPCInfo struct {
+	// Method is the full name of the method, e.g. "/pkg.Service/Method".
+	Method string
+
+	// Peer is the address of the other side of the connection, if known.
+	Peer string
+
+	// Kind is the shape of the RPC. Defaults to [RPCUnary].
+	Kind RPCKind
+
+	// Client should be true if the RPC is being made, rather than served.
+	Client bool
+}
+
+// RPCConfig configures how RPCs are logged by [StartRPC].
+type RPCConfig struct {
+	// Logger is used to log RPCs, and is the base of the per-RPC loggers.
+	// Defaults to [L].
+	Logger *slog.Logger
+
+	// Level is the level completed RPCs are logged at. RPCs that fail are
+	// logged at this level or [log/slog.LevelWarn], whichever is higher.
+	// Defaults to [log/slog.LevelInfo].
+	Level slog.Level
+
+	// Code returns the name of the status code for an error returned by an
+	// RPC, such as "NotFound". When using gRPC this should normally be
+	// `status.Code(err).String()`. Defaults to "OK" for nil errors, and
+	// "Unknown" otherwise.
+	Code func(err error) string
+}
+
+// StartRPC logs the start of an RPC at debug level, and returns a context
+// containing a logger with attributes describing the RPC, available from
+// [FromContext]. The returned func must be called with the error returned by
+// the RPC once it has finished, and logs its status code and duration.
+//
+// slogflags does not depend on gRPC; instead StartRPC should be called from
+// small interceptors. For example, using google.golang.org/grpc:
+//
+//	rpcs := slogflags.RPCConfig{Code: func(err error) string { return status.Code(err).String() }}
+//
+//	func unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+//		rpc := slogflags.RPCInfo{Method: info.FullMethod}
+//		if p, ok := peer.FromContext(ctx); ok {
+//			rpc.Peer = p.Addr.String()
+//		}
+//		ctx, finish := slogflags.StartRPC(ctx, rpcs, rpc)
+//		resp, err := handler(ctx, req)
+//		finish(err)
+//		return resp, err
+//	}
+//
+//	func streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+//		rpc := slogflags.RPCInfo{Method: info.FullMethod, Kind: slogflags.RPCBidiStream}
+//		ctx, finish := slogflags.StartRPC(ss.Context(), rpcs, rpc)
+//		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+//		finish(err)
+//		return err
+//	}
+//
+// Client interceptors can be written in the same way, setting
+// [RPCInfo.Client].
+func StartRPC(ctx context.Context, config RPCConfig, info RPCInfo) (context.Context, func(err error)) {
+	logger := config.Logger
+	if logger == nil {
+		logger = L()
+	}
+
+	kind := info.Kind
+	if kind == "" {
+		kind = RPCUnary
+	}
+
+	side := "server"
+	if info.Client {
+		side = "client"
+	}
+
+	attrs := []any{
+		slog.String("rpc.method", info.Method),
+		slog.String("rpc.kind", string(kind)),
+		slog.String("rpc.side", side),
+	}
+	if info.Peer != "" {
+		attrs = append(attrs, slog.String("rpc.peer", info.Peer))
+	}
+	logger = logger.With(attrs...)
+	ctx = ContextWithLogger(ctx, logger)
+
+	start := time.Now()
+	logger.DebugContext(ctx, "RPC started")
+
+	return ctx, func(err error) {
+		code := rpcCode(config.Code, err)
+		level := config.Level
+		rpcAttrs := []slog.Attr{
+			slog.String("rpc.code", code),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			level = max(level, slog.LevelWarn)
+			rpcAttrs = append(rpcAttrs, slog.Any("error", err))
+		}
+		logger.LogAttrs(ctx, level, "RPC finished", rpcAttrs...)
+	}
+}
+
+// rpcCode returns the name of the status code for an error.
+func rpcCode(fn func(error) string, err error) string {
+	if fn != nil {
+		return fn(err)
+	}
+	if err == nil {
+		return "OK"
+	}
+	return "Unknown"
+}
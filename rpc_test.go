@@ -0,0 +1,54 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StartRPC(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "debug")
+	defer flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+
+	ctx, finish := StartRPC(context.Background(), RPCConfig{Logger: l}, RPCInfo{Method: "/pkg.Widgets/Get", Peer: "192.0.2.1:1234"})
+	FromContext(ctx).InfoContext(ctx, "Fetching")
+	finish(nil)
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=DEBUG msg=\"RPC started\" rpc.method=/pkg.Widgets/Get rpc.kind=unary rpc.side=server rpc.peer=192.0.2.1:1234\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Fetching rpc.method=/pkg.Widgets/Get rpc.kind=unary rpc.side=server rpc.peer=192.0.2.1:1234\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"RPC finished\" rpc.method=/pkg.Widgets/Get rpc.kind=unary rpc.side=server rpc.peer=192.0.2.1:1234 rpc.code=OK duration=X\n",
+		durationPattern.ReplaceAllString(w.String(), "duration=X"))
+}
+
+func Test_StartRPC_Failure(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+
+	config := RPCConfig{
+		Logger: l,
+		Code: func(err error) string {
+			if err != nil {
+				return "NotFound"
+			}
+			return "OK"
+		},
+	}
+	_, finish := StartRPC(context.Background(), config, RPCInfo{Method: "/pkg.Widgets/Watch", Kind: RPCServerStream, Client: true})
+	finish(errors.New("no such widget"))
+
+	assert.Equal(t,
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"RPC finished\" rpc.method=/pkg.Widgets/Watch rpc.kind=server_stream rpc.side=client rpc.code=NotFound duration=X error=\"no such widget\"\n",
+		durationPattern.ReplaceAllString(w.String(), "duration=X"))
+}
@@ -0,0 +1,156 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Config configures an [S3Store].
+type S3Config struct {
+	// Bucket is the name of the bucket to upload objects to.
+	Bucket string
+
+	// Region is the AWS region the bucket is in. Defaults to the value of the
+	// AWS_REGION or AWS_DEFAULT_REGION environment variables.
+	Region string
+
+	// Endpoint overrides the URL used for S3 requests, for use with
+	// S3-compatible services. If set, path-style requests are made to
+	// "<endpoint>/<bucket>/<key>".
+	Endpoint string
+
+	// StorageClass is the storage class of uploaded objects, e.g.
+	// "STANDARD_IA". Defaults to the bucket's default storage class.
+	StorageClass string
+
+	// Credentials is used to obtain credentials to sign requests. Defaults to
+	// [DefaultAWSCredentials].
+	Credentials AWSCredentialsFunc
+
+	// MaxRetries is the number of times a request will be retried if it fails
+	// due to a network error or a retryable status. Defaults to 3 if zero; a
+	// negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the time to wait before the first retry. It doubles
+	// after each subsequent attempt. Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// S3Store is an [ObjectStore] that uploads objects to an Amazon S3 bucket
+// using the PutObject API.
+type S3Store struct {
+	config S3Config
+}
+
+// NewS3Store creates a new [S3Store] with the given config.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("s3: no bucket specified")
+	}
+	if config.Region == "" {
+		config.Region = awsRegion()
+	}
+	if config.Region == "" {
+		return nil, errors.New("s3: no region specified")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", config.Bucket, config.Region)
+	} else {
+		config.Endpoint = strings.TrimSuffix(config.Endpoint, "/") + "/" + awsURIEncode(config.Bucket)
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Credentials == nil {
+		config.Credentials = DefaultAWSCredentials(config.Client)
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+
+	return &S3Store{config: config}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, contentType string, body []byte) error {
+	err := withRetries(s.config.MaxRetries, s.config.RetryBackoff, func() error {
+		return s.put(ctx, key, contentType, body)
+	})
+	if err != nil {
+		return fmt.Errorf("s3: unable to upload %q: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3Store) put(ctx context.Context, key string, contentType string, body []byte) error {
+	creds, err := s.config.Credentials(ctx)
+	if err != nil {
+		return retryable(err)
+	}
+
+	// The key is escaped manually, as AWS requires characters such as '=' to
+	// be escaped when signing while net/url leaves them as-is.
+	u := s.config.Endpoint + "/" + awsURIEncode(strings.TrimPrefix(key, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	payloadHash := sha256.Sum256(body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	if s.config.StorageClass != "" {
+		req.Header.Set("X-Amz-Storage-Class", s.config.StorageClass)
+	}
+	signAWSRequest(req, body, creds, s.config.Region, "s3", time.Now())
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		err = fmt.Errorf("request returned status %d: %s", res.StatusCode, b)
+		if retryableStatus(res.StatusCode) {
+			return retryable(err)
+		}
+		return err
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// awsURIEncode escapes a path the way AWS expects for signing: every byte
+// other than unreserved characters and slashes is percent-encoded.
+func awsURIEncode(path string) string {
+	b := new(strings.Builder)
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			_, _ = fmt.Fprintf(b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
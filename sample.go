@@ -0,0 +1,132 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// samplerKey identifies records that are counted together when sampling.
+type samplerKey struct {
+	level   slog.Level
+	message string
+}
+
+// sampler decides which records to keep when sampling. Each second, the first
+// few records with a given level and message are kept, and after that only
+// every nth record is kept. Records above the sampling level are always kept.
+type sampler struct {
+	level      slog.Level
+	first      uint64
+	thereafter uint64
+
+	mu     sync.Mutex
+	tick   int64
+	counts map[samplerKey]uint64
+}
+
+func newSampler(level slog.Level, first, thereafter int) *sampler {
+	return &sampler{
+		level:      level,
+		first:      uint64(max(first, 0)),
+		thereafter: uint64(max(thereafter, 0)),
+		counts:     map[samplerKey]uint64{},
+	}
+}
+
+// keep determines whether the record should be passed on.
+func (s *sampler) keep(r slog.Record) bool {
+	if r.Level > s.level {
+		return true
+	}
+
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tick := t.Unix(); tick != s.tick {
+		clear(s.counts)
+		s.tick = tick
+	}
+
+	key := samplerKey{level: r.Level, message: r.Message}
+	n := s.counts[key] + 1
+	s.counts[key] = n
+
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// samplingHandler is a [log/slog.Handler] that only passes records to the
+// next handler if they're kept by a sampler.
+type samplingHandler struct {
+	next    slog.Handler
+	sampler *sampler
+}
+
+func newSamplingHandler(next slog.Handler, sampler *sampler) *samplingHandler {
+	return &samplingHandler{next: next, sampler: sampler}
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.keep(r) {
+		countDrop(dropSampled, r.Level)
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return newSamplingHandler(h.next.WithAttrs(attrs), h.sampler)
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return newSamplingHandler(h.next.WithGroup(name), h.sampler)
+}
+
+// sampling creates a sampler according to the options and flags, or returns
+// nil if sampling is disabled. The returned bool is false if the
+// `log.sample-level` flag couldn't be parsed.
+func (c *config) sampling() (*sampler, bool) {
+	level, levelOK := c.samplingLevel, true
+	if *logSampleLevel != "" {
+		var requested slog.Level
+		if requested, levelOK = c.level(*logSampleLevel); levelOK {
+			level = requested
+		}
+	}
+
+	if level >= c.neverDropLevel {
+		level = c.neverDropLevel - 1
+	}
+
+	first, thereafter := c.samplingFirst, c.samplingThereafter
+	if *logSampleFirst > 0 {
+		first = *logSampleFirst
+	}
+	if *logSampleThereafter > 0 {
+		thereafter = *logSampleThereafter
+	}
+
+	if first <= 0 {
+		return nil, levelOK
+	}
+	return newSampler(level, first, thereafter), levelOK
+}
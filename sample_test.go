@@ -0,0 +1,87 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Sampler_KeepsFirstThenEveryNth(t *testing.T) {
+	s := newSampler(slog.LevelInfo, 2, 3)
+	now := time.Unix(1700000000, 0)
+
+	var kept []int
+	for i := 1; i <= 10; i++ {
+		if s.keep(slog.NewRecord(now, slog.LevelInfo, "hot", 0)) {
+			kept = append(kept, i)
+		}
+	}
+	assert.Equal(t, []int{1, 2, 5, 8}, kept)
+
+	assert.True(t, s.keep(slog.NewRecord(now, slog.LevelInfo, "other", 0)))
+	assert.True(t, s.keep(slog.NewRecord(now, slog.LevelDebug, "hot", 0)))
+	assert.True(t, s.keep(slog.NewRecord(now.Add(time.Second), slog.LevelInfo, "hot", 0)))
+}
+
+func Test_Sampler_KeepsRecordsAboveLevel(t *testing.T) {
+	s := newSampler(slog.LevelInfo, 1, 0)
+	now := time.Unix(1700000000, 0)
+
+	assert.True(t, s.keep(slog.NewRecord(now, slog.LevelInfo, "hot", 0)))
+	assert.False(t, s.keep(slog.NewRecord(now, slog.LevelInfo, "hot", 0)))
+	for range 5 {
+		assert.True(t, s.keep(slog.NewRecord(now, slog.LevelWarn, "hot", 0)))
+	}
+}
+
+func Test_SamplesOutput(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSampling(slog.LevelInfo, 3, 0))
+	for range 10 {
+		l.With("key", "value").Info("Loop")
+		l.Warn("Uh oh")
+	}
+
+	assert.Equal(t, 3, strings.Count(w.String(), "msg=Loop"))
+	assert.Equal(t, 10, strings.Count(w.String(), "msg=\"Uh oh\""))
+}
+
+func Test_SamplingFlagsOverrideOptions(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.sample-level", "warn")
+	_ = flag.Set("log.sample-first", "1")
+	defer flag.Set("log.sample-level", "")
+	defer flag.Set("log.sample-first", "0")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSampling(slog.LevelInfo, 5, 0))
+	for range 10 {
+		l.Warn("Uh oh")
+	}
+
+	assert.Equal(t, 1, strings.Count(w.String(), "msg=\"Uh oh\""))
+}
+
+func Test_SamplingNeverDropsHighSeverityRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSampling(slog.LevelError, 1, 0), WithNeverDrop(slog.LevelError))
+	for range 5 {
+		l.Warn("Uh oh")
+		l.Error("Oh no")
+	}
+
+	assert.Equal(t, 1, strings.Count(w.String(), "msg=\"Uh oh\""))
+	assert.Equal(t, 5, strings.Count(w.String(), "msg=\"Oh no\""))
+}
@@ -0,0 +1,46 @@
+package slogflags
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ansiEscapes matches ANSI escape sequences, such as those used to change the
+// colour of text or move the cursor.
+var ansiEscapes = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// sanitize removes ANSI escape sequences from s, and escapes any other
+// control characters (including CR and LF) so that s can't be used to forge
+// additional log lines or corrupt a terminal. Tabs are left alone.
+func sanitize(s string) string {
+	if !strings.ContainsFunc(s, isUnsafeRune) {
+		return s
+	}
+
+	s = ansiEscapes.ReplaceAllLiteralString(s, "")
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case isUnsafeRune(r) && r <= 0xff:
+			_, _ = fmt.Fprintf(&b, `\x%02x`, r)
+		case isUnsafeRune(r):
+			_, _ = fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isUnsafeRune reports whether r is a control character other than tab, or a
+// Unicode line or paragraph separator.
+func isUnsafeRune(r rune) bool {
+	return (unicode.IsControl(r) && r != '\t') || r == '\u2028' || r == '\u2029'
+}
@@ -0,0 +1,45 @@
+package slogflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sanitize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain text", "plain text"},
+		{"tab\tseparated", "tab\tseparated"},
+		{"héllo wörld", "héllo wörld"},
+		{"user\nlevel=ERROR msg=forged", `user\nlevel=ERROR msg=forged`},
+		{"line\r\nbreak", `line\r\nbreak`},
+		{"\x1b[31mred\x1b[0m", "red"},
+		{"\x1b]0;title\x07text", "text"},
+		{"bell\x07 null\x00", `bell\x07 null\x00`},
+		{"sep\u2028arator", `sep\u2028arator`},
+		{"c1\u0085", `c1\x85`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sanitize(tt.input), tt.input)
+	}
+}
+
+func Test_WithSanitize(t *testing.T) {
+	_ = flag.Set("log.format", "fasttext")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSanitize(true))
+	l.With("agent", "curl\x1b[2J").Info("Login for alice\nforged", "error", errors.New("bad\r\nthing"))
+
+	assert.NotContains(t, w.String(), "\x1b")
+	assert.Equal(t, 1, bytes.Count(w.Bytes(), []byte("\n")))
+	assert.Contains(t, w.String(), "agent=curl ")
+}
@@ -0,0 +1,155 @@
+package slogflags
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// EventSchema describes the attributes expected on records with a particular
+// message. Attributes in groups are identified by their full path, with
+// group names separated by dots (e.g. "http.status").
+type EventSchema struct {
+	// Required lists the keys that must be present.
+	Required []string
+
+	// Types gives the expected kind of value for keys, if they're present.
+	Types map[string]slog.Kind
+}
+
+// SchemaConfig configures the validation performed by [WithSchema].
+type SchemaConfig struct {
+	// Events maps record messages to the schema they must follow.
+	Events map[string]EventSchema
+
+	// Strict causes records with messages that aren't in Events to be
+	// treated as violations.
+	Strict bool
+
+	// Panic causes violations to panic with a [*SchemaError], instead of
+	// being logged as a warning. This is useful in tests.
+	Panic bool
+}
+
+// SchemaError describes a record that doesn't match its schema.
+type SchemaError struct {
+	// Event is the message of the record.
+	Event string
+
+	// Problems describes each way in which the record didn't match.
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("log record %q does not match schema: %s", e.Event, strings.Join(e.Problems, "; "))
+}
+
+// schemaHandler is a [log/slog.Handler] that validates records against a
+// schema before passing them on.
+type schemaHandler struct {
+	next   slog.Handler
+	config SchemaConfig
+	goas   []groupOrAttrs
+}
+
+func newSchemaHandler(next slog.Handler, config SchemaConfig) *schemaHandler {
+	return &schemaHandler{next: next, config: config}
+}
+
+func (h *schemaHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *schemaHandler) Handle(ctx context.Context, r slog.Record) error {
+	if err := h.validate(r); err != nil {
+		if h.config.Panic {
+			panic(err)
+		}
+		if h.next.Enabled(ctx, slog.LevelWarn) {
+			w := slog.NewRecord(r.Time, slog.LevelWarn, "Log record does not match schema", r.PC)
+			w.AddAttrs(slog.String("event", err.Event), slog.Any("problems", err.Problems))
+			_ = h.next.Handle(ctx, w)
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *schemaHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.goas = append(slices.Clip(h.goas), groupOrAttrs{attrs: attrs})
+	return &h2
+}
+
+func (h *schemaHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.goas = append(slices.Clip(h.goas), groupOrAttrs{group: name})
+	return &h2
+}
+
+// validate returns an error describing any ways in which r doesn't match
+// its schema, or nil if it does.
+func (h *schemaHandler) validate(r slog.Record) *SchemaError {
+	schema, ok := h.config.Events[r.Message]
+	if !ok {
+		if h.config.Strict {
+			return &SchemaError{Event: r.Message, Problems: []string{"unknown event"}}
+		}
+		return nil
+	}
+
+	values := map[string]slog.Value{}
+	resolveRecord(r, h.goas).Attrs(func(a slog.Attr) bool {
+		flattenAttr(values, "", a)
+		return true
+	})
+
+	var problems []string
+	for _, key := range schema.Required {
+		if _, ok := values[key]; !ok {
+			problems = append(problems, fmt.Sprintf("missing %q", key))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(schema.Types)) {
+		if v, ok := values[key]; ok && v.Kind() != schema.Types[key] {
+			problems = append(problems, fmt.Sprintf("%q is %s, expected %s", key, v.Kind(), schema.Types[key]))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &SchemaError{Event: r.Message, Problems: problems}
+}
+
+// flattenAttr adds the resolved values of a to m, keyed by their full path.
+// Groups are included as well as the attributes within them.
+func flattenAttr(m map[string]slog.Value, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	path := prefix + a.Key
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			m[path] = a.Value
+			prefix = path + "."
+		}
+		for _, ga := range a.Value.Group() {
+			flattenAttr(m, prefix, ga)
+		}
+		return
+	}
+	m[path] = a.Value
+}
@@ -0,0 +1,63 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSchema = SchemaConfig{
+	Events: map[string]EventSchema{
+		"Order placed": {
+			Required: []string{"order.id", "order.total", "user"},
+			Types: map[string]slog.Kind{
+				"order.id":    slog.KindString,
+				"order.total": slog.KindFloat64,
+			},
+		},
+	},
+}
+
+func Test_WithSchema_Valid(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSchema(testSchema))
+	l.With("user", "alice").WithGroup("order").Info("Order placed", "id", "o-1", "total", 9.99)
+	l.Info("Something else")
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"Order placed\" user=alice order.id=o-1 order.total=9.99\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"Something else\"\n", w.String())
+}
+
+func Test_WithSchema_LogsViolations(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSchema(testSchema))
+	l.Info("Order placed", slog.Group("order", "id", 1, "total", 9.99))
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"Log record does not match schema\" event=\"Order placed\" problems=\"[missing \\\"user\\\" \\\"order.id\\\" is Int64, expected String]\"\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=\"Order placed\" order.id=1 order.total=9.99\n", w.String())
+}
+
+func Test_WithSchema_StrictPanics(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	config := testSchema
+	config.Strict = true
+	config.Panic = true
+	l := LoggerForTest(new(bytes.Buffer), WithSchema(config))
+
+	assert.PanicsWithError(t, `log record "Unknown" does not match schema: unknown event`, func() {
+		l.Info("Unknown")
+	})
+}
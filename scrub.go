@@ -0,0 +1,90 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+)
+
+var (
+	// ScrubEmails matches email addresses.
+	ScrubEmails = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// ScrubCreditCards matches credit card numbers of 13 to 19 digits,
+	// optionally separated by spaces or hyphens.
+	ScrubCreditCards = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+
+	// ScrubBearerTokens matches bearer tokens, such as those sent in an
+	// Authorization header.
+	ScrubBearerTokens = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// scrubPatterns returns a func that masks all matches of the patterns.
+func scrubPatterns(patterns []*regexp.Regexp) func(string) string {
+	return func(s string) string {
+		for _, p := range patterns {
+			s = p.ReplaceAllLiteralString(s, redacted)
+		}
+		return s
+	}
+}
+
+// scrubHandler is a [log/slog.Handler] that rewrites the message and string
+// values of records, for example to mask text matching a set of patterns.
+type scrubHandler struct {
+	next  slog.Handler
+	clean func(string) string
+}
+
+func newScrubHandler(next slog.Handler, clean func(string) string) *scrubHandler {
+	return &scrubHandler{next: next, clean: clean}
+}
+
+func (h *scrubHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *scrubHandler) Handle(ctx context.Context, r slog.Record) error {
+	res := slog.NewRecord(r.Time, r.Level, h.clean(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		res.AddAttrs(h.scrub(a))
+		return true
+	})
+	return h.next.Handle(ctx, res)
+}
+
+func (h *scrubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make([]slog.Attr, len(attrs))
+	for i := range attrs {
+		res[i] = h.scrub(attrs[i])
+	}
+	return &scrubHandler{next: h.next.WithAttrs(res), clean: h.clean}
+}
+
+func (h *scrubHandler) WithGroup(name string) slog.Handler {
+	return &scrubHandler{next: h.next.WithGroup(name), clean: h.clean}
+}
+
+// scrub returns the attribute with its string value rewritten. Errors are
+// replaced with their rewritten message if it's different.
+func (h *scrubHandler) scrub(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(h.clean(a.Value.String()))
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		res := make([]slog.Attr, len(attrs))
+		for i := range attrs {
+			res[i] = h.scrub(attrs[i])
+		}
+		a.Value = slog.GroupValue(res...)
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			if msg := h.clean(err.Error()); msg != err.Error() {
+				a.Value = slog.StringValue(msg)
+			}
+		}
+	}
+	return a
+}
@@ -0,0 +1,86 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+)
+
+// secret is a value that is only logged if secrets are revealed.
+type secret struct {
+	value any
+}
+
+// Secret wraps a sensitive value, such as a struct containing credentials,
+// so that it's logged as "[REDACTED]" instead of its contents:
+//
+//	logger.Info("Connecting", "config", slogflags.Secret(dbConfig))
+//
+// The value is only logged by loggers created with [WithRevealSecrets], for
+// example while debugging locally. Other loggers, and handlers that aren't
+// created by this package, always redact it.
+func Secret(v any) slog.LogValuer {
+	return secret{value: v}
+}
+
+func (s secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// String ensures the value isn't revealed if it's formatted directly.
+func (s secret) String() string {
+	return redacted
+}
+
+// revealSecretsHandler is a [log/slog.Handler] that replaces values wrapped
+// with [Secret] with their contents, before any later handler resolves them.
+type revealSecretsHandler struct {
+	next slog.Handler
+}
+
+func newRevealSecretsHandler(next slog.Handler) *revealSecretsHandler {
+	return &revealSecretsHandler{next: next}
+}
+
+func (h *revealSecretsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *revealSecretsHandler) Handle(ctx context.Context, r slog.Record) error {
+	res := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		res.AddAttrs(revealSecret(a))
+		return true
+	})
+	return h.next.Handle(ctx, res)
+}
+
+func (h *revealSecretsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make([]slog.Attr, len(attrs))
+	for i := range attrs {
+		res[i] = revealSecret(attrs[i])
+	}
+	return &revealSecretsHandler{next: h.next.WithAttrs(res)}
+}
+
+func (h *revealSecretsHandler) WithGroup(name string) slog.Handler {
+	return &revealSecretsHandler{next: h.next.WithGroup(name)}
+}
+
+// revealSecret returns the attribute with any [Secret] values, including
+// those within groups, replaced by their contents.
+func revealSecret(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindLogValuer:
+		if s, ok := a.Value.Any().(secret); ok {
+			a.Value = slog.AnyValue(s.value)
+		}
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		res := make([]slog.Attr, len(attrs))
+		for i := range attrs {
+			res[i] = revealSecret(attrs[i])
+		}
+		a.Value = slog.GroupValue(res...)
+	}
+	return a
+}
@@ -0,0 +1,57 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type credentials struct {
+	User     string
+	Password string
+}
+
+func Test_Secret_Redacted(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.Info("Connecting", "creds", Secret(credentials{"alice", "hunter2"}))
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Connecting creds=[REDACTED]\n", w.String())
+	assert.Equal(t, "[REDACTED]", fmt.Sprint(Secret("hunter2")))
+}
+
+func Test_Secret_Revealed(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithRevealSecrets(true))
+	l.Info("Connecting", "creds", Secret(credentials{"alice", "hunter2"}))
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"Secret values are being logged in full\"\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Connecting creds=\"{User:alice Password:hunter2}\"\n", w.String())
+}
+
+func Test_Secret_RevealedOnlyByConfiguredLogger(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	revealed := new(bytes.Buffer)
+	rl := LoggerForTest(revealed, WithRevealSecrets(true)).With("token", Secret("abc")).WithGroup("db")
+	redacted := new(bytes.Buffer)
+	l := LoggerForTest(redacted).With("token", Secret("abc")).WithGroup("db")
+
+	rl.Info("Connecting", slog.Group("auth", "password", Secret("hunter2")))
+	l.Info("Connecting", slog.Group("auth", "password", Secret("hunter2")))
+
+	assert.Contains(t, revealed.String(), "msg=Connecting token=abc db.auth.password=hunter2\n")
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Connecting token=[REDACTED] db.auth.password=[REDACTED]\n", redacted.String())
+}
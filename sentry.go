@@ -0,0 +1,284 @@
+package slogflags
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SentryConfig configures a [Sentry] integration.
+type SentryConfig struct {
+	// DSN is the Sentry DSN for the project, e.g.
+	// "https://public@o0.ingest.sentry.io/123".
+	DSN string
+
+	// Level is the minimum level of records that are sent to Sentry. Defaults
+	// to [log/slog.LevelError].
+	Level slog.Leveler
+
+	// Environment and Release are added to each event, if set.
+	Environment string
+	Release     string
+
+	// ServerName is added to each event. Defaults to the hostname.
+	ServerName string
+
+	// TagKeys are the keys of top-level attributes that are sent as tags.
+	// All other attributes are sent as extra data.
+	TagKeys []string
+
+	// FlushInterval is the maximum amount of time events will be buffered
+	// before they are sent. Defaults to 1 second.
+	FlushInterval time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// Sentry forwards log records to Sentry as events. It is used with the
+// [WithSentry] option, and must be closed to ensure all events are sent.
+//
+// Each event includes the record's message and level, its attributes as tags
+// or extra data, and the stack trace of the goroutine that logged it. Any
+// attributes with error values are reported as exceptions.
+type Sentry struct {
+	config   SentryConfig
+	endpoint string
+	auth     string
+	batcher  *batcher[[]byte]
+}
+
+// NewSentry creates a new [Sentry] integration with the given config.
+func NewSentry(config SentryConfig) (*Sentry, error) {
+	dsn, err := url.Parse(config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("sentry: invalid DSN: %w", err)
+	}
+	if dsn.User == nil || dsn.User.Username() == "" {
+		return nil, errors.New("sentry: DSN does not contain a public key")
+	}
+
+	project := dsn.Path[strings.LastIndex(dsn.Path, "/")+1:]
+	if project == "" {
+		return nil, errors.New("sentry: DSN does not contain a project ID")
+	}
+
+	if config.Level == nil {
+		config.Level = slog.LevelError
+	}
+	if config.ServerName == "" {
+		config.ServerName, _ = os.Hostname()
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+
+	s := &Sentry{
+		config: config,
+		endpoint: fmt.Sprintf(
+			"%s://%s%s/api/%s/envelope/",
+			dsn.Scheme,
+			dsn.Host,
+			dsn.Path[:strings.LastIndex(dsn.Path, "/")],
+			project,
+		),
+		auth: fmt.Sprintf("Sentry sentry_version=7, sentry_client=slogflags, sentry_key=%s", dsn.User.Username()),
+	}
+	s.batcher = newBatcher(100, config.FlushInterval, s.send)
+	return s, nil
+}
+
+// Close sends any buffered events.
+func (s *Sentry) Close() error {
+	return s.batcher.close()
+}
+
+// capture converts the record to a Sentry event and queues it to be sent. It
+// must be called synchronously from the goroutine that logged the record.
+func (s *Sentry) capture(r slog.Record) error {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	event := map[string]any{
+		"event_id":    hex.EncodeToString(id),
+		"timestamp":   r.Time.UTC().Format(time.RFC3339Nano),
+		"level":       sentryLevel(r.Level),
+		"logger":      "slogflags",
+		"platform":    "go",
+		"server_name": s.config.ServerName,
+		"message":     map[string]any{"formatted": r.Message},
+	}
+	if s.config.Environment != "" {
+		event["environment"] = s.config.Environment
+	}
+	if s.config.Release != "" {
+		event["release"] = s.config.Release
+	}
+
+	tags := map[string]string{}
+	extra := map[string]any{}
+	var exceptions []map[string]any
+	r.Attrs(func(a slog.Attr) bool {
+		a.Value = a.Value.Resolve()
+		if err, ok := a.Value.Any().(error); ok {
+			exceptions = append(exceptions, map[string]any{
+				"type":  fmt.Sprintf("%T", err),
+				"value": err.Error(),
+			})
+		}
+
+		if slices.Contains(s.config.TagKeys, a.Key) && a.Value.Kind() != slog.KindGroup {
+			tags[a.Key] = a.Value.String()
+		} else {
+			addAttr(extra, a)
+		}
+		return true
+	})
+
+	if len(tags) > 0 {
+		event["tags"] = tags
+	}
+	if len(extra) > 0 {
+		event["extra"] = extra
+	}
+
+	frames := sentryFrames(callerStack(r.PC))
+	if len(exceptions) > 0 {
+		exceptions[len(exceptions)-1]["stacktrace"] = map[string]any{"frames": frames}
+		event["exception"] = map[string]any{"values": exceptions}
+	} else if len(frames) > 0 {
+		event["threads"] = map[string]any{"values": []map[string]any{{
+			"current":    true,
+			"stacktrace": map[string]any{"frames": frames},
+		}}}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("sentry: unable to encode event: %w", err)
+	}
+
+	header, _ := json.Marshal(map[string]any{"event_id": event["event_id"], "sent_at": time.Now().UTC().Format(time.RFC3339)})
+	itemHeader, _ := json.Marshal(map[string]any{"type": "event", "length": len(body)})
+
+	envelope := slices.Concat(header, []byte{'\n'}, itemHeader, []byte{'\n'}, body, []byte{'\n'})
+	return s.batcher.add(envelope)
+}
+
+func (s *Sentry) send(envelopes [][]byte) error {
+	var errs []error
+	for _, envelope := range envelopes {
+		if err := s.post(envelope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Sentry) post(envelope []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return fmt.Errorf("sentry: unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sentry: request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("sentry: request returned status %d: %s", res.StatusCode, b)
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// sentryFrames converts program counters to Sentry stack frames. Sentry
+// expects the outermost frame first.
+func sentryFrames(stack []uintptr) []map[string]any {
+	var frames []map[string]any
+	it := runtime.CallersFrames(stack)
+	for {
+		frame, more := it.Next()
+		if frame.Function != "" {
+			module, function := splitFunctionName(frame.Function)
+			frames = append(frames, map[string]any{
+				"function": function,
+				"module":   module,
+				"abs_path": frame.File,
+				"filename": frame.File[strings.LastIndex(frame.File, "/")+1:],
+				"lineno":   frame.Line,
+				"in_app":   !strings.HasPrefix(frame.Function, "runtime.") && strings.Contains(module, "."),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	slices.Reverse(frames)
+	return frames
+}
+
+// splitFunctionName splits a fully qualified function name, such as
+// "github.com/foo/bar.(*Baz).Qux", into its package and function.
+func splitFunctionName(name string) (string, string) {
+	lastSlash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[lastSlash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:lastSlash+1+dot], name[lastSlash+2+dot:]
+}
+
+// sentryLevel maps a slog level to the closest Sentry level.
+func sentryLevel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError+4:
+		return "fatal"
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// callerStack returns the stack of the current goroutine, starting from the
+// frame identified by pc. If pc is not found on the stack (for example, if
+// the record is being handled asynchronously) only pc itself is returned.
+func callerStack(pc uintptr) []uintptr {
+	if pc == 0 {
+		return nil
+	}
+
+	stack := make([]uintptr, 64)
+	stack = stack[:runtime.Callers(1, stack)]
+	if i := slices.Index(stack, pc); i >= 0 {
+		return stack[i:]
+	}
+	return []uintptr{pc}
+}
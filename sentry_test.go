@@ -0,0 +1,114 @@
+package slogflags
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSentry struct {
+	mu     sync.Mutex
+	path   string
+	auth   string
+	events []map[string]any
+}
+
+func (f *fakeSentry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.path = r.URL.Path
+	f.auth = r.Header.Get("X-Sentry-Auth")
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(nil, 1024*1024)
+	scanner.Scan()
+	scanner.Scan()
+	scanner.Scan()
+
+	var event map[string]any
+	_ = json.Unmarshal(scanner.Bytes(), &event)
+	f.events = append(f.events, event)
+}
+
+func newTestSentry(t *testing.T, f *fakeSentry, config SentryConfig) *Sentry {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	config.DSN = strings.Replace(server.URL, "http://", "http://key@", 1) + "/42"
+	s, err := NewSentry(config)
+	require.NoError(t, err)
+	return s
+}
+
+func Test_Sentry_SendsErrorsAndWritesOutput(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	f := &fakeSentry{}
+	s := newTestSentry(t, f, SentryConfig{Environment: "prod", TagKeys: []string{"user"}})
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSentry(s))
+	l.Info("Ignored")
+	l.With("user", "bob").Error("Failed", "err", errors.New("boom"), "count", 3)
+	require.NoError(t, s.Close())
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Ignored\ntime=2026-01-02T03:04:05.000Z level=ERROR msg=Failed user=bob err=boom count=3\n", w.String())
+
+	assert.Equal(t, "/api/42/envelope/", f.path)
+	assert.Contains(t, f.auth, "sentry_key=key")
+	require.Len(t, f.events, 1)
+
+	event := f.events[0]
+	assert.Equal(t, "error", event["level"])
+	assert.Equal(t, "prod", event["environment"])
+	assert.Equal(t, map[string]any{"formatted": "Failed"}, event["message"])
+	assert.Equal(t, map[string]any{"user": "bob"}, event["tags"])
+	assert.Equal(t, map[string]any{"err": "boom", "count": float64(3)}, event["extra"])
+
+	exceptions := event["exception"].(map[string]any)["values"].([]any)
+	require.Len(t, exceptions, 1)
+	assert.Equal(t, "*errors.errorString", exceptions[0].(map[string]any)["type"])
+	assert.Equal(t, "boom", exceptions[0].(map[string]any)["value"])
+
+	frames := exceptions[0].(map[string]any)["stacktrace"].(map[string]any)["frames"].([]any)
+	last := frames[len(frames)-1].(map[string]any)
+	assert.Equal(t, "Test_Sentry_SendsErrorsAndWritesOutput", last["function"])
+	assert.Equal(t, "github.com/csmith/slogflags", last["module"])
+}
+
+func Test_Sentry_SendsRecordsBelowLogLevel(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "error")
+	defer flag.Set("log.level", "")
+
+	f := &fakeSentry{}
+	s := newTestSentry(t, f, SentryConfig{Level: slog.LevelWarn})
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSentry(s))
+	l.Warn("Careful")
+	require.NoError(t, s.Close())
+
+	assert.Empty(t, w.String())
+	require.Len(t, f.events, 1)
+	assert.Equal(t, "warning", f.events[0]["level"])
+	assert.Contains(t, f.events[0], "threads")
+}
+
+func Test_NewSentry_RejectsInvalidDSN(t *testing.T) {
+	_, err := NewSentry(SentryConfig{DSN: "https://o0.ingest.sentry.io/42"})
+	assert.ErrorContains(t, err, "public key")
+}
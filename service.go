@@ -0,0 +1,49 @@
+package slogflags
+
+import "log/slog"
+
+// serviceInfo describes the service that is logging, as set by
+// [WithService].
+type serviceInfo struct {
+	name        string
+	version     string
+	environment string
+}
+
+// attrs returns the attributes describing the service, named according to
+// the conventions that go with the trace format.
+func (s serviceInfo) attrs(format TraceFormat) []slog.Attr {
+	var attrs []slog.Attr
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, slog.String(key, value))
+		}
+	}
+
+	switch format {
+	case TraceFormatDatadog:
+		add("service", s.name)
+		add("version", s.version)
+		add("env", s.environment)
+		return attrs
+	case TraceFormatGCP:
+		add("service", s.name)
+		add("version", s.version)
+		if len(attrs) == 0 {
+			return nil
+		}
+		res := []slog.Attr{{Key: "serviceContext", Value: slog.GroupValue(attrs...)}}
+		if s.environment != "" {
+			res = append(res, slog.String("environment", s.environment))
+		}
+		return res
+	default:
+		add("name", s.name)
+		add("version", s.version)
+		add("environment", s.environment)
+		if len(attrs) == 0 {
+			return nil
+		}
+		return []slog.Attr{{Key: "service", Value: slog.GroupValue(attrs...)}}
+	}
+}
@@ -0,0 +1,44 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithService(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	tests := []struct {
+		name   string
+		format TraceFormat
+		want   string
+	}{
+		{"otel", TraceFormatOTel, "service.name=widgets service.version=1.2.3 service.environment=prod"},
+		{"datadog", TraceFormatDatadog, "service=widgets version=1.2.3 env=prod"},
+		{"gcp", TraceFormatGCP, "serviceContext.service=widgets serviceContext.version=1.2.3 environment=prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			l := LoggerForTest(w, WithService("widgets", "1.2.3", "prod"), WithTraceCorrelation(TraceConfig{Format: tt.format}))
+			l.Info("Started")
+
+			assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Started "+tt.want+"\n", w.String())
+		})
+	}
+}
+
+func Test_WithService_OmitsEmptyValues(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithService("widgets", "", ""))
+	l.Info("Started")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Started service.name=widgets\n", w.String())
+}
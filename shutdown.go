@@ -0,0 +1,70 @@
+package slogflags
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// shutdownSignals are the signals handled by [WithShutdownOnSignal].
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// shutdownHookOnce ensures signals are only hooked once, regardless of how
+// many loggers are created.
+var shutdownHookOnce sync.Once
+
+// Shutdown flushes and closes everything as per [Close], but stops waiting
+// once ctx is done. If records are still being written when the context
+// expires, Shutdown returns the context's error and the remaining work
+// continues in the background.
+func Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- Close()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hookShutdownSignals starts a goroutine that calls [Shutdown] and then exits
+// when the process receives SIGINT or SIGTERM.
+func hookShutdownSignals(timeout time.Duration) {
+	shutdownHookOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, shutdownSignals...)
+		go shutdownOnSignal(signals, timeout, exitWithSignal)
+	})
+}
+
+// shutdownOnSignal waits for a signal, shuts down with the given timeout, and
+// then calls exit.
+func shutdownOnSignal(signals <-chan os.Signal, timeout time.Duration, exit func(os.Signal)) {
+	sig := <-signals
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_ = Shutdown(ctx)
+
+	exit(sig)
+}
+
+// exitWithSignal restores the default behaviour for shutdown signals and
+// sends sig to the process again, so that it exits in the same way it would
+// have if the signal wasn't handled. If that isn't possible, it exits with a
+// non-zero status.
+func exitWithSignal(sig os.Signal) {
+	signal.Reset(shutdownSignals...)
+	if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+		// Give the runtime a chance to act on the signal.
+		time.Sleep(time.Second)
+	}
+	os.Exit(1)
+}
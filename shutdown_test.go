@@ -0,0 +1,65 @@
+package slogflags
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Shutdown_FlushesAndCloses(t *testing.T) {
+	var calls []string
+	registerClose(func() error {
+		calls = append(calls, "close")
+		return nil
+	})
+	registerFlush(func() error {
+		calls = append(calls, "flush")
+		return nil
+	})
+
+	require.NoError(t, Shutdown(context.Background()))
+	assert.Equal(t, []string{"flush", "close"}, calls)
+}
+
+func Test_Shutdown_StopsWaitingAtDeadline(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	registerFlush(func() error {
+		<-unblock
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, Shutdown(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func Test_ShutdownOnSignal(t *testing.T) {
+	flushed := false
+	registerFlush(func() error {
+		flushed = true
+		return nil
+	})
+
+	signals := make(chan os.Signal, 1)
+	exited := make(chan os.Signal, 1)
+	go shutdownOnSignal(signals, time.Second, func(sig os.Signal) {
+		exited <- sig
+	})
+
+	signals <- os.Interrupt
+	select {
+	case sig := <-exited:
+		assert.Equal(t, os.Interrupt, sig)
+		assert.True(t, flushed)
+	case <-time.After(time.Second):
+		t.Fatal("exit was not called")
+	}
+}
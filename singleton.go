@@ -0,0 +1,120 @@
+package slogflags
+
+import (
+	"log/slog"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+var (
+	singletonMu     sync.Mutex
+	singleton       *slog.Logger
+	singletonConfig *config
+)
+
+// singletonLogger returns the logger created by the first call to [Logger]
+// with [WithSingleton] enabled, creating it if necessary. c is the
+// configuration requested by the current call, and is compared to the one
+// the logger was created with.
+func singletonLogger(c *config, opts []Option) *slog.Logger {
+	singletonMu.Lock()
+	defer singletonMu.Unlock()
+
+	if singleton != nil {
+		if diff := configDiff(singletonConfig, c); len(diff) > 0 {
+			singleton.Warn("Logger called with conflicting options, using existing logger", "differences", diff)
+		}
+		return singleton
+	}
+
+	singleton = Logger(append(opts, WithSingleton(false))...)
+	singletonConfig = c
+	registerClose(func() error {
+		singletonMu.Lock()
+		defer singletonMu.Unlock()
+		singleton, singletonConfig = nil, nil
+		return nil
+	})
+	return singleton
+}
+
+// configDiff returns the names of the fields that differ between two
+// configurations, ignoring whether they enable [WithSingleton].
+func configDiff(a, b *config) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	visited := map[[2]unsafe.Pointer]bool{}
+
+	var diff []string
+	for i := range va.NumField() {
+		name := va.Type().Field(i).Name
+		if name == "singleton" {
+			continue
+		}
+		if !sameValue(va.Field(i), vb.Field(i), visited) {
+			diff = append(diff, name)
+		}
+	}
+	return diff
+}
+
+// sameValue reports whether two values of the same type are deeply equal.
+// Unlike [reflect.DeepEqual] it can be used on unexported fields, and funcs
+// are considered equal if they have the same code.
+func sameValue(a, b reflect.Value, visited map[[2]unsafe.Pointer]bool) bool {
+	switch a.Kind() {
+	case reflect.Func:
+		return a.IsNil() == b.IsNil() && (a.IsNil() || a.Pointer() == b.Pointer())
+	case reflect.Pointer:
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		if a.IsNil() || b.IsNil() {
+			return false
+		}
+		key := [2]unsafe.Pointer{a.UnsafePointer(), b.UnsafePointer()}
+		if visited[key] {
+			return true
+		}
+		visited[key] = true
+		return sameValue(a.Elem(), b.Elem(), visited)
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return a.Elem().Type() == b.Elem().Type() && sameValue(a.Elem(), b.Elem(), visited)
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := range a.Len() {
+			if !sameValue(a.Index(i), b.Index(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			v := b.MapIndex(iter.Key())
+			if !v.IsValid() || !sameValue(iter.Value(), v, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if !sameValue(a.Field(i), b.Field(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	default:
+		return a.Equal(b)
+	}
+}
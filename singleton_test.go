@@ -0,0 +1,61 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithSingleton_ReturnsSameLogger(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	defer func() { _ = Close() }()
+
+	w := new(bytes.Buffer)
+	levels := map[string]slog.Level{"shrug": 2}
+	first := LoggerForTest(w, WithSingleton(true), WithCustomLevels(levels))
+	second := LoggerForTest(w, WithSingleton(true), WithCustomLevels(map[string]slog.Level{"shrug": 2}))
+
+	assert.Same(t, first, second)
+	assert.Empty(t, w.String())
+}
+
+func Test_WithSingleton_WarnsAboutConflicts(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	defer func() { _ = Close() }()
+
+	w := new(bytes.Buffer)
+	first := LoggerForTest(w, WithSingleton(true))
+	second := LoggerForTest(w, WithSingleton(true), WithAddSource(true), WithAttrs(slog.String("k", "v")))
+
+	assert.Same(t, first, second)
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=\"Logger called with conflicting options, using existing logger\" differences=\"[addSource goas]\"\n", w.String())
+}
+
+func Test_WithSingleton_ForgottenOnClose(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	first := LoggerForTest(new(bytes.Buffer), WithSingleton(true))
+	require.NoError(t, Close())
+	second := LoggerForTest(new(bytes.Buffer), WithSingleton(true))
+	defer func() { _ = Close() }()
+
+	assert.NotSame(t, first, second)
+}
+
+func Test_WithSingleton_DisabledCreatesNewLoggers(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	defer func() { _ = Close() }()
+
+	first := LoggerForTest(new(bytes.Buffer), WithSingleton(true))
+	second := LoggerForTest(new(bytes.Buffer))
+
+	assert.NotSame(t, first, second)
+}
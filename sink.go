@@ -0,0 +1,139 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// Sink is a destination for log records that takes care of its own encoding
+// and delivery, such as a remote log aggregation service.
+//
+// Records passed to a sink are fully resolved: any attributes added with
+// [log/slog.Logger.With] and groups opened with [log/slog.Logger.WithGroup]
+// are already present in the record's attributes.
+type Sink interface {
+	// Write delivers a single record. Sinks may buffer records and deliver
+	// them at a later point. Sinks that write the level as a string should
+	// use the name carried by ctx for custom levels. Sinks that report errors delivering previously
+	// buffered records from Write should wrap them in a [BatchError].
+	Write(ctx context.Context, r slog.Record) error
+
+	// Close flushes any buffered records and releases any resources held by
+	// the sink. The sink must not be used after it has been closed.
+	Close() error
+}
+
+// BatchError is returned by a sink's Write method when delivery of records
+// buffered by an earlier call failed. The record passed to Write was accepted.
+type BatchError struct {
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Flusher is implemented by sinks that buffer records. Flush sends any
+// buffered records immediately, and returns any errors encountered delivering
+// them or previously buffered records.
+type Flusher interface {
+	Flush() error
+}
+
+// sinkHandler adapts a [Sink] into a [log/slog.Handler].
+type sinkHandler struct {
+	sink  Sink
+	level slog.Leveler
+	goas  []groupOrAttrs
+
+	// names are passed to the sink in the context, so that custom levels are
+	// written with the same names as other outputs.
+	names levelNames
+}
+
+// groupOrAttrs holds either a group name or a set of attributes, in the order
+// they were applied to a handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// applyGroupOrAttrs applies groups and attributes to a handler, in order.
+func applyGroupOrAttrs(handler slog.Handler, goas []groupOrAttrs) slog.Handler {
+	for _, goa := range goas {
+		if goa.group != "" {
+			handler = handler.WithGroup(goa.group)
+		} else {
+			handler = handler.WithAttrs(goa.attrs)
+		}
+	}
+	return handler
+}
+
+func newSinkHandler(sink Sink, level slog.Leveler) *sinkHandler {
+	return &sinkHandler{sink: sink, level: level}
+}
+
+func (h *sinkHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *sinkHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.names != nil {
+		ctx = withLevelNames(ctx, h.names)
+	}
+	return h.sink.Write(ctx, resolveRecord(r, h.goas))
+}
+
+func (h *sinkHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *sinkHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *sinkHandler) with(goa groupOrAttrs) *sinkHandler {
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
+
+// resolveRecord returns a copy of r with the attributes and groups in goas
+// merged into its attributes.
+func resolveRecord(r slog.Record, goas []groupOrAttrs) slog.Record {
+	if len(goas) == 0 {
+		return r
+	}
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	for i := len(goas) - 1; i >= 0; i-- {
+		if goas[i].group != "" {
+			if len(attrs) > 0 {
+				attrs = []slog.Attr{{Key: goas[i].group, Value: slog.GroupValue(attrs...)}}
+			}
+		} else {
+			attrs = append(slices.Clip(goas[i].attrs), attrs...)
+		}
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	return nr
+}
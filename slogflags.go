@@ -1,16 +1,44 @@
 package slogflags
 
 import (
+	"context"
 	"flag"
 	"io"
 	"log/slog"
+	"math"
 	"os"
+	"regexp"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 var (
 	logLevel  = flag.String("log.level", "", "Lowest level of logs that should be output")
-	logFormat = flag.String("log.format", "text", "Format of log output ('json' or 'text')")
+	logFormat = flag.String("log.format", "text", "Format of log output ('json', 'text', 'fasttext' or 'expanded')")
+	logOutput = flag.String("log.output", "", "Where to send log output ('stdout', 'stderr', a file path, or a URL such as 'nats://host:4222/subject')")
+
+	logBufferSize    = flag.Int("log.buffer-size", 0, "Size in bytes of the buffer used for log output, or 0 to write each record immediately")
+	logFlushInterval = flag.Duration("log.flush-interval", time.Second, "Maximum time that buffered log output is held before being written")
+	logFsync         = flag.Bool("log.fsync", false, "Sync log files to disk after every write, so records survive power loss at the cost of performance")
+
+	logNoTime   = flag.Bool("log.no-time", false, "Omit the time from log output, for environments that add their own timestamps")
+	logTimezone = flag.String("log.timezone", "", "Time zone used for times in log output, such as 'UTC' or 'Europe/London'; defaults to the local time zone")
+
+	logBackpressure = flag.String("log.backpressure", "", "What to do when a log sink can't keep up ('block', 'drop' or 'spill'); if unset, records are written to the sink directly")
+	logQueueSize    = flag.Int("log.queue-size", 1000, "Number of records queued in memory for a log sink when log.backpressure is set")
+	logSpillDir     = flag.String("log.spill-dir", "", "Directory used to store records when log.backpressure is 'spill'")
+
+	logSampleLevel      = flag.String("log.sample-level", "", "Highest level of logs that are subject to sampling")
+	logSampleFirst      = flag.Int("log.sample-first", 0, "Number of logs with the same level and message to output each second before sampling")
+	logSampleThereafter = flag.Int("log.sample-thereafter", 0, "Output only every nth log with the same level and message once sampling starts")
+
+	logAccessFormat = flag.String("log.access-format", "structured", "Format of HTTP access logs ('structured', 'common' or 'combined')")
+
+	logApp      = flag.String("log.app", "", "Name of the application, added to every record as the 'app' attribute")
+	logHostname = flag.Bool("log.hostname", false, "Add the machine's hostname to every record as the 'hostname' attribute")
+	logPID      = flag.Bool("log.pid", false, "Add the process ID to every record as the 'pid' attribute")
 
 	defaultLevels = map[string]slog.Level{
 		"debug": slog.LevelDebug,
@@ -23,58 +51,433 @@ var (
 // Logger creates a new [log/slog.Logger] configured according to the options
 // and flags.
 //
-// [flag.Parse] must be called prior to calling this method.
+// [flag.Parse] must be called prior to calling this method. Logger is safe to
+// call from multiple goroutines; loggers are configured one at a time, and
+// process-wide state such as the default logger is left matching the logger
+// created last. Flags should only be changed concurrently using [SetFlag].
 func Logger(opts ...Option) *slog.Logger {
+	if c := newConfig(opts); c.singleton {
+		return singletonLogger(c, opts)
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	slot := new(atomic.Pointer[slog.Handler])
+	currentSlot.Store(slot)
+	return configure(slot, opts)
+}
+
+// Handler creates a new [log/slog.Handler] configured according to the options
+// and flags, for use with other handlers or with frameworks that accept a
+// handler rather than a logger. It's the handler used by the logger that
+// [Logger] would return, and has the same effects: for example, it's used by
+// [L], and is updated by [Reconfigure].
+//
+// [flag.Parse] must be called prior to calling this method.
+func Handler(opts ...Option) slog.Handler {
+	return Logger(opts...).Handler()
+}
+
+// configure builds a handler according to the options and flags, and stores
+// it in slot. It returns a logger that uses whichever handler is in slot.
+// configMu must be held.
+func configure(slot *atomic.Pointer[slog.Handler], opts []Option) *slog.Logger {
 	c := newConfig(opts)
+	c.owner = slot
+	if c.deterministic {
+		c.addSource = false
+		c.console = false
+		if c.clock == nil {
+			c.clock = deterministicClock
+		}
+	}
+	if c.sourceTrimModule && c.sourceTrimPrefix == "" {
+		c.sourceTrimPrefix = moduleRoot()
+	}
+	if *logNoTime {
+		c.noTime = true
+	}
+
+	var timeZoneErr error
+	if *logTimezone != "" {
+		var loc *time.Location
+		if loc, timeZoneErr = time.LoadLocation(*logTimezone); timeZoneErr == nil {
+			c.timeZone = loc
+		}
+	}
 
 	slog.SetLogLoggerLevel(c.oldLogLevel)
 
 	resolvedLevel, levelOK := c.level(*logLevel)
 
+	// If the flight recorder is enabled, it decides which records are output
+	// so everything must be passed through to the handlers.
+	outputLevel := resolvedLevel
+	if c.flightRecorderSize > 0 {
+		outputLevel = slog.Level(math.MinInt)
+	}
+
+	// If levels can be lowered using the context, the output handlers must
+	// accept everything and a contextLevelHandler decides instead.
+	handlerLevel := outputLevel
+	if c.contextLevels {
+		handlerLevel = slog.Level(math.MinInt)
+	}
+
 	var handlerOpts = &slog.HandlerOptions{
 		AddSource:   c.addSource,
-		Level:       resolvedLevel,
+		Level:       handlerLevel,
 		ReplaceAttr: c.levelReplaceAttr,
 	}
 
-	var handler slog.Handler
-	if *logFormat == "json" {
-		handler = slog.NewJSONHandler(c.writer, handlerOpts)
+	var stderrCaptured bool
+	var stderrCaptureErr error
+	if c.stderrCapture {
+		stderrCaptured, stderrCaptureErr = captureStderr(c.stderrCaptureLevel)
+	}
+	c.writer = uncaptured(c.writer)
+	c.fallbackWriter = uncaptured(c.fallbackWriter)
+
+	writer, outputSink, outputErr := c.output(*logOutput)
+
+	var handlers multiHandler
+	if outputSink != nil {
+		handlers = append(handlers, c.withMetrics(c.newSinkHandler(outputSink, handlerLevel), outputSink))
 	} else {
-		handler = slog.NewTextHandler(c.writer, handlerOpts)
+		if outputErr != nil {
+			writer = c.writer
+		}
+		console := c.console && isTerminal(writer)
+		writer = newFallbackWriter(writer, c.fallbackWriter)
+
+		if *logBufferSize > 0 {
+			bw := newBufferedWriter(writer, *logBufferSize, *logFlushInterval)
+			c.registerFlush(bw.Flush)
+			c.registerClose(bw.Close)
+			writer = bw
+		}
+
+		if len(c.levelFormats) > 0 {
+			writer = &lockedWriter{w: writer}
+		}
+
+		output := formatHandler(*logFormat, writer, handlerOpts, console)
+		if len(c.levelFormats) > 0 {
+			output = newLevelFormatHandler(output, c.levelFormats, writer, handlerOpts, console)
+		}
+
+		if c.metrics != nil {
+			output = newMetricsHandler(output, c.metrics, "output")
+		}
+		handlers = append(handlers, output)
+	}
+
+	for _, s := range c.sinks {
+		handlers = append(handlers, c.withMetrics(c.newSinkHandler(s, handlerLevel), s))
+	}
+
+	var handler slog.Handler = handlers
+	if len(handlers) == 1 {
+		handler = handlers[0]
+	}
+
+	if c.deterministic {
+		handler = newSortedHandler(handler)
+	}
+
+	if c.contextLevels {
+		handler = newContextLevelHandler(handler, outputLevel)
+	}
+
+	if c.flightRecorderSize > 0 {
+		handler = newFlightRecorderHandler(handler, resolvedLevel, c.flightRecorderSize)
+	}
+
+	var debugFileErr error
+	if c.debugFile != "" {
+		var w io.WriteCloser
+		w, debugFileErr = c.openFile(c.debugFile)
+		if debugFileErr == nil {
+			c.registerClose(w.Close)
+			handler = multiHandler{handler, slog.NewJSONHandler(w, &slog.HandlerOptions{
+				AddSource:   !c.deterministic,
+				Level:       slog.LevelDebug,
+				ReplaceAttr: c.levelReplaceAttr,
+			})}
+		}
+	}
+
+	if c.asyncQueueSize > 0 {
+		queue := newAsyncQueue(c.asyncQueueSize, c.asyncDropPolicy, c.neverDropLevel, c.errorHandler)
+		c.registerFlush(queue.flush)
+		c.registerClose(queue.close)
+		if c.metrics != nil {
+			c.metrics.addQueue("async", queue.depth)
+		}
+		handler = newAsyncHandler(handler, queue)
+	}
+
+	if c.dropSummaryInterval > 0 {
+		summary := newDropSummary(handler, c.dropSummaryInterval)
+		c.registerClose(summary.close)
+	}
+
+	sampler, sampleLevelOK := c.sampling()
+	if sampler != nil {
+		handler = newSamplingHandler(handler, sampler)
+	}
+
+	if c.dedupeWindow > 0 {
+		deduper := newDeduper(c.dedupeWindow, c.errorHandler)
+		c.registerFlush(deduper.flush)
+		c.registerClose(deduper.close)
+		handler = newDedupeHandler(handler, deduper)
+	}
+
+	if c.errorSummary != nil {
+		summarizer := newErrorSummarizer(handler, *c.errorSummary, c.errorHandler)
+		c.registerFlush(summarizer.flush)
+		c.registerClose(summarizer.close)
+		handler = newErrorSummaryHandler(handler, summarizer)
+	}
+
+	if c.rateLimitFunc != nil && c.rateLimitWindow > 0 {
+		limiter := newRateLimiter(handler, c.rateLimitFunc, c.rateLimit, c.rateLimitWindow, c.neverDropLevel, c.errorHandler)
+		c.registerFlush(limiter.flush)
+		c.registerClose(limiter.close)
+		handler = newRateLimitHandler(handler, limiter)
+	}
+
+	if c.ringBuffer != nil {
+		handler = newTapHandler(handler, slog.Level(math.MinInt), c.ringBuffer.add)
+	}
+
+	if c.metrics != nil && c.metrics.hasLogMetrics() {
+		handler = newTapHandler(handler, resolvedLevel, c.metrics.observe)
+	}
+
+	if c.alerter != nil {
+		handler = newTapHandler(handler, c.alerter.config.Level, func(r slog.Record) error {
+			return c.alerter.alert(r, c.customLevelNames)
+		})
+	}
+
+	if c.sentry != nil {
+		handler = newTapHandler(handler, c.sentry.config.Level, c.sentry.capture)
+	}
+
+	if c.errorBurst != nil {
+		monitor := newErrorBurstMonitor(handler, *c.errorBurst)
+		handler = newTapHandler(handler, monitor.config.Level, monitor.record)
+	}
+
+	if c.errorHandler != nil {
+		handler = newErrorHandler(handler, c.errorHandler)
+	}
+
+	if c.allowedKeys != nil {
+		handler = newAllowlistHandler(handler, c.allowedKeys, c.metrics)
+	}
+
+	if len(c.redactKeys) > 0 {
+		handler = newRedactHandler(handler, c.redactKeys, redactValue)
+	}
+
+	if len(c.hashKeys) > 0 {
+		handler = newRedactHandler(handler, c.hashKeys, hashValue(c.hashSecret))
+	}
+
+	if len(c.scrubPatterns) > 0 {
+		handler = newScrubHandler(handler, scrubPatterns(c.scrubPatterns))
+	}
+
+	if c.schema != nil {
+		handler = newSchemaHandler(handler, *c.schema)
+	}
+
+	if c.sanitize {
+		handler = newScrubHandler(handler, sanitize)
+	}
+
+	if !c.keepReservedKeys {
+		handler = newReservedKeysHandler(handler, reservedKeysFor(append([]Sink{outputSink}, c.sinks...)))
+	}
+
+	if c.sequenceNumbers {
+		handler = newStampHandler(handler, sequenceAttr)
+	}
+
+	if c.goroutineID {
+		handler = newStampHandler(handler, goroutineIDAttr)
+	}
+
+	handler = newContextAttrsHandler(handler, append([]func(context.Context) []slog.Attr{requestIDAttrs}, c.contextAttrs...))
+	handler = newCanonicalHandler(handler)
+
+	if c.revealSecrets {
+		handler = newRevealSecretsHandler(handler)
+	}
+
+	if attrs := c.enrichmentAttrs(); len(attrs) > 0 {
+		handler = handler.WithAttrs(attrs)
+	}
+	handler = applyGroupOrAttrs(handler, c.goas)
+	if len(c.onLog) > 0 || len(c.onLogged) > 0 {
+		handler = newHookHandler(handler, c.onLog, c.onLogged)
+	}
+	handler = newCallerSkipHandler(handler, c.callerSkip)
+	if c.clock != nil {
+		handler = newClockHandler(handler, c.clock)
 	}
 
-	logger := slog.New(handler)
+	slot.Store(&handler)
+	logger := slog.New(newProxyHandler(slot))
 	if c.setDefault {
 		slog.SetDefault(logger)
 	}
 
+	early.bind(handler)
+	currentHandler.Store(&handler)
+
+	if c.shutdownTimeout > 0 {
+		hookShutdownSignals(c.shutdownTimeout)
+	}
+
+	if len(c.goroutineDumpSigs) > 0 {
+		hookGoroutineDump(c.goroutineDumpLevel, c.goroutineDumpSigs)
+	}
+
+	if stderrCaptured {
+		// Registered after the outputs, so that any remaining output is logged
+		// before they're closed.
+		registerClose(releaseStderr)
+	}
+
+	if c.buildInfo && !c.buildInfoAllRecords {
+		if build, ok := buildInfoAttr(); ok {
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "Build information", build)
+		}
+	}
+
 	if !levelOK {
 		logger.Warn("Unknown log level, using default", "requested", *logLevel, "default", resolvedLevel)
 	}
 
+	if !sampleLevelOK {
+		logger.Warn("Unknown sampling level, using default", "requested", *logSampleLevel, "default", c.samplingLevel)
+	}
+
+	if outputErr != nil {
+		logger.Warn("Unable to configure log output, using default", "requested", *logOutput, "error", outputErr)
+	}
+
+	if debugFileErr != nil {
+		logger.Warn("Unable to open debug log file", "path", c.debugFile, "error", debugFileErr)
+	}
+
+	if timeZoneErr != nil {
+		logger.Warn("Unknown time zone, using default", "requested", *logTimezone, "error", timeZoneErr)
+	}
+
+	if stderrCaptureErr != nil {
+		logger.Warn("Unable to capture stderr", "error", stderrCaptureErr)
+	}
+
+	if c.revealSecrets {
+		logger.Warn("Secret values are being logged in full")
+	}
+
 	return logger
 }
 
 type config struct {
-	addSource        bool
-	customLevels     map[string]slog.Level
-	customLevelNames map[slog.Level]string
-	defaultLevel     slog.Level
-	oldLogLevel      slog.Level
-	replaceAttr      func(groups []string, a slog.Attr) slog.Attr
-	setDefault       bool
-	writer           io.Writer
+	addSource           bool
+	alerter             *Alerter
+	allowedKeys         []string
+	appName             string
+	asyncDropPolicy     DropPolicy
+	asyncQueueSize      int
+	buildInfo           bool
+	buildInfoAllRecords bool
+	callerSkip          int
+	clock               func() time.Time
+	console             bool
+	contextAttrs        []func(ctx context.Context) []slog.Attr
+	contextLevels       bool
+	customLevels        map[string]slog.Level
+	customLevelNames    levelNames
+	debugFile           string
+	dedupeWindow        time.Duration
+	defaultLevel        slog.Level
+	deterministic       bool
+	dropSummaryInterval time.Duration
+	errorBurst          *ErrorBurstConfig
+	errorHandler        func(err error, r slog.Record)
+	errorSummary        *ErrorSummaryConfig
+	fallbackWriter      io.Writer
+	fileEncryption      func(w io.Writer) (io.WriteCloser, error)
+	flightRecorderSize  int
+	fsync               bool
+	goas                []groupOrAttrs
+	goroutineDumpLevel  slog.Level
+	goroutineID         bool
+	goroutineDumpSigs   []os.Signal
+	gzipFlushInterval   time.Duration
+	hashKeys            []string
+	hashSecret          []byte
+	hostname            bool
+	keepReservedKeys    bool
+	kubernetes          bool
+	levelFormats        map[slog.Level]string
+	metrics             *Metrics
+	neverDropLevel      slog.Level
+	noTime              bool
+	oldLogLevel         slog.Level
+	onLog               []func(ctx context.Context, r *slog.Record)
+	onLogged            []func(ctx context.Context, r slog.Record)
+	owner               *atomic.Pointer[slog.Handler]
+	pid                 bool
+	rateLimit           int
+	rateLimitFunc       func(ctx context.Context, r slog.Record) string
+	rateLimitWindow     time.Duration
+	redactKeys          []string
+	replaceAttr         func(groups []string, a slog.Attr) slog.Attr
+	revealSecrets       bool
+	ringBuffer          *RingBuffer
+	samplingFirst       int
+	sanitize            bool
+	samplingLevel       slog.Level
+	samplingThereafter  int
+	schema              *SchemaConfig
+	scrubPatterns       []*regexp.Regexp
+	sentry              *Sentry
+	sequenceNumbers     bool
+	setDefault          bool
+	service             serviceInfo
+	shutdownTimeout     time.Duration
+	singleton           bool
+	sinks               []Sink
+	sourceTrimModule    bool
+	sourceTrimPrefix    string
+	stderrCapture       bool
+	stderrCaptureLevel  slog.Level
+	timeZone            *time.Location
+	traceFormat         TraceFormat
+	writer              io.Writer
 }
 
 func newConfig(opts []Option) *config {
 	c := &config{
 		addSource:        false,
 		defaultLevel:     slog.LevelInfo,
+		fallbackWriter:   os.Stderr,
+		neverDropLevel:   slog.Level(math.MaxInt),
 		oldLogLevel:      slog.LevelInfo,
 		customLevels:     map[string]slog.Level{},
-		customLevelNames: map[slog.Level]string{},
+		customLevelNames: levelNames{LevelFatal: "FATAL"},
 		replaceAttr:      nil,
+		samplingLevel:    slog.LevelInfo,
 		setDefault:       false,
 		writer:           os.Stdout,
 	}
@@ -91,23 +494,56 @@ func (c *config) level(requested string) (slog.Level, bool) {
 		return c.defaultLevel, true
 	}
 
-	target := strings.ToLower(requested)
-
-	if r, ok := defaultLevels[target]; ok {
-		return r, true
+	level, err := ParseLevel(requested, c.customLevels)
+	if err != nil {
+		return c.defaultLevel, false
 	}
+	return level, true
+}
 
-	if r, ok := c.customLevels[target]; ok {
-		return r, true
+// newSinkHandler creates a handler for the sink, which passes it the names of
+// any custom levels.
+func (c *config) newSinkHandler(sink Sink, level slog.Leveler) *sinkHandler {
+	h := newSinkHandler(sink, level)
+	h.names = c.customLevelNames
+	return h
+}
+
+// withMetrics wraps a sink's handler so that its records are counted, if
+// metrics are enabled. Queued sinks also have their queue depth reported.
+func (c *config) withMetrics(h slog.Handler, sink Sink) slog.Handler {
+	if c.metrics == nil {
+		return h
 	}
 
-	return c.defaultLevel, false
+	name := sinkName(sink)
+	if q, ok := sink.(*QueuedSink); ok {
+		c.metrics.addQueue(name, q.depth)
+	}
+	return newMetricsHandler(h, c.metrics, name)
 }
 
 func (c *config) levelReplaceAttr(groups []string, a slog.Attr) slog.Attr {
-	if a.Key == slog.LevelKey {
-		if name, ok := c.customLevelNames[a.Value.Any().(slog.Level)]; ok {
-			a = slog.String(slog.LevelKey, name)
+	if a.Key == slog.TimeKey && len(groups) == 0 {
+		if c.noTime {
+			return slog.Attr{}
+		}
+		if c.timeZone != nil && a.Value.Kind() == slog.KindTime {
+			a.Value = slog.TimeValue(a.Value.Time().In(c.timeZone))
+		}
+	}
+
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			if name, ok := c.customLevelNames[level]; ok {
+				a = slog.String(slog.LevelKey, name)
+			}
+		}
+	}
+
+	if a.Key == slog.SourceKey && len(groups) == 0 {
+		if src, ok := a.Value.Any().(*slog.Source); ok {
+			a.Value = slog.AnyValue(c.trimSource(src))
 		}
 	}
 
@@ -128,6 +564,155 @@ func WithAddSource(addSource bool) Option {
 	}
 }
 
+// WithAlerter sends records at or above the level configured in the
+// [Alerter] to a Slack or Discord webhook. Records are still written to the
+// normal output as well.
+func WithAlerter(alerter *Alerter) Option {
+	return func(c *config) {
+		c.alerter = alerter
+	}
+}
+
+// WithAllowedKeys enables a strict mode where only attributes with the given
+// keys are logged, and all others are dropped. This can be used to guarantee
+// which data can appear in logs. Attributes in groups are identified by their
+// full path, with group names separated by dots (e.g. "http.method"); if a
+// group's key is allowed, all attributes within it are too. Attributes added
+// by other options, such as [WithAppName], must be allowed to be logged. If
+// metrics are enabled with [WithMetrics], dropped attributes are counted by
+// key. If the option is given multiple times, all the keys are allowed.
+func WithAllowedKeys(keys ...string) Option {
+	return func(c *config) {
+		if c.allowedKeys == nil {
+			c.allowedKeys = []string{}
+		}
+		c.allowedKeys = append(c.allowedKeys, keys...)
+	}
+}
+
+// WithAppName adds an "app" attribute with the given name to every record.
+// It can be overridden using the `log.app` flag.
+func WithAppName(name string) Option {
+	return func(c *config) {
+		c.appName = name
+	}
+}
+
+// WithAttrs adds attributes to every record logged by the returned logger,
+// as if [log/slog.Logger.With] had been called on it. They are added within
+// any groups opened by earlier [WithGroup] options.
+func WithAttrs(attrs ...slog.Attr) Option {
+	return func(c *config) {
+		if len(attrs) > 0 {
+			c.goas = append(c.goas, groupOrAttrs{attrs: attrs})
+		}
+	}
+}
+
+// WithAsync writes log output and delivers records to sinks on a background
+// goroutine, using a queue that holds up to queueSize records. This reduces
+// the time spent logging on hot paths. When the queue is full the behaviour
+// is determined by [WithAsyncDropPolicy]; by default the logging goroutine
+// blocks until there is space.
+//
+// Records passed to [WithSentry] and [WithAlerter] are still processed
+// synchronously. Call [Flush] before exiting to ensure queued records are
+// written.
+func WithAsync(queueSize int) Option {
+	return func(c *config) {
+		c.asyncQueueSize = queueSize
+	}
+}
+
+// WithAsyncDropPolicy sets what happens when a record is logged but the queue
+// created by [WithAsync] is full. Defaults to [DropPolicyBlock].
+func WithAsyncDropPolicy(policy DropPolicy) Option {
+	return func(c *config) {
+		c.asyncDropPolicy = policy
+	}
+}
+
+// WithBuildInfo logs a record when the logger is created with a "build" group
+// describing the running binary: the main module's version, the VCS revision
+// it was built from, and whether the working tree had uncommitted changes.
+// This helps to correlate logs with releases. Values that aren't recorded in
+// the binary's build information are omitted.
+func WithBuildInfo(enabled bool) Option {
+	return func(c *config) {
+		c.buildInfo = enabled
+	}
+}
+
+// WithBuildInfoOnAllRecords adds the "build" group described in
+// [WithBuildInfo] to every record, instead of logging it once.
+func WithBuildInfoOnAllRecords(enabled bool) Option {
+	return func(c *config) {
+		c.buildInfoAllRecords = enabled
+	}
+}
+
+// WithCallerSkip moves the source location of every record logged with the
+// returned logger up the stack by n frames. This should be used when the
+// logger is only called by a wrapper package, so that the source attribute
+// reports the wrapper's caller rather than the wrapper itself. See
+// [SkipCallers] to adjust individual child loggers instead.
+func WithCallerSkip(n int) Option {
+	return func(c *config) {
+		c.callerSkip = n
+	}
+}
+
+// WithClock sets the func used to get the time of each record, instead of
+// the time it was created by the [log/slog.Logger]. This is mainly useful to
+// make the output of tests deterministic:
+//
+//	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+//	logger := slogflags.Logger(slogflags.WithClock(func() time.Time { return fixed }))
+//
+// The clock is used for every record handled by the logger, including those
+// from other logging libraries whose time would otherwise be preserved.
+func WithClock(clock func() time.Time) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// WithConsole writes log output in a compact, colourised format if it is
+// going to a terminal, and the `log.format` flag hasn't been set to "json",
+// "fasttext" or "expanded". Colours can be disabled by setting the NO_COLOR environment
+// variable. See also [WithDevAndFile].
+func WithConsole(enabled bool) Option {
+	return func(c *config) {
+		c.console = enabled
+	}
+}
+
+// WithContextAttrs adds the attributes returned by fn to every record, based
+// on the context it was logged with (e.g. using
+// [log/slog.Logger.InfoContext]). This can be used to include values such as
+// request or user IDs that are stored in the context. If the option is given
+// multiple times, attributes from each func are added in order.
+//
+// As with other attributes on the record, they are added within any groups
+// opened with [log/slog.Logger.WithGroup]. Attributes are skipped if the
+// logger already has one with the same key, added using
+// [log/slog.Logger.With].
+func WithContextAttrs(fn func(ctx context.Context) []slog.Attr) Option {
+	return func(c *config) {
+		c.contextAttrs = append(c.contextAttrs, fn)
+	}
+}
+
+// WithContextLevels allows the log level to be lowered for records logged
+// with a particular context, using [ContextWithLevel] or [DebugMiddleware].
+// This is disabled by default as it makes checking whether records below the
+// configured level are enabled slightly slower.
+func WithContextLevels(enabled bool) Option {
+	return func(c *config) {
+		c.contextLevels = enabled
+	}
+}
+
 // WithCustomLevels adds extra levels to the defaults available in the
 // `log.level` flag. The same level may be specified with multiple different
 // keys to provide aliases.
@@ -149,6 +734,19 @@ func WithCustomLevels(levels map[string]slog.Level) Option {
 	}
 }
 
+// WithDeduplication suppresses identical records (with the same level,
+// message and attributes) that are logged within window of the first. Once
+// the window has passed, the most recent duplicate is written with an extra
+// "repeated" attribute giving the number of records that were suppressed.
+// This prevents retry loops and similar from flooding the output.
+//
+// Call [Flush] or [Close] before exiting to write any pending duplicates.
+func WithDeduplication(window time.Duration) Option {
+	return func(c *config) {
+		c.dedupeWindow = window
+	}
+}
+
 // WithDefaultLogLevel sets the default level that will be used if the
 // `log.level` flag is not set. If not provided, the default
 // is [log/slog.LevelInfo].
@@ -158,6 +756,279 @@ func WithDefaultLogLevel(level slog.Level) Option {
 	}
 }
 
+// WithDeterministic makes the output byte-for-byte identical across machines
+// and runs, for use in tests. Every record has the time
+// 2000-01-01T00:00:00Z (unless [WithClock] is also used), attributes are
+// sorted by key, source locations are omitted, and colours are disabled,
+// overriding [WithAddSource] and [WithDevAndFile].
+func WithDeterministic(enabled bool) Option {
+	return func(c *config) {
+		c.deterministic = enabled
+	}
+}
+
+// WithDevAndFile is a preset for local development. Log output is written in
+// a compact, colourised format if it is going to a terminal (and the
+// `log.format` flag hasn't been set to "json"), and every record at debug
+// level or above is also written to the file at path as JSON, including its
+// source location.
+func WithDevAndFile(path string) Option {
+	return func(c *config) {
+		c.console = true
+		c.debugFile = path
+	}
+}
+
+// WithDropSummary logs a warning at the given interval if any records have
+// been dropped by [WithSampling], [WithRateLimit] or [WithAsync] since the
+// previous warning, giving the number dropped for each reason and level.
+// The same figures are available at any time from [Stats].
+func WithDropSummary(interval time.Duration) Option {
+	return func(c *config) {
+		c.dropSummaryInterval = interval
+	}
+}
+
+// WithErrorBurst monitors the rate of error records, and calls
+// [ErrorBurstConfig.OnBurst] when more than [ErrorBurstConfig.Threshold] are
+// logged within [ErrorBurstConfig.Window], acting as a lightweight
+// in-process alerting trigger. If no func is given, an "Error burst detected"
+// record is logged instead. Once a burst has been reported, another won't be
+// reported until a full window has passed.
+//
+// Records are counted using their time, so bursts are detected consistently
+// when a clock is given to [WithClock].
+func WithErrorBurst(burst ErrorBurstConfig) Option {
+	return func(c *config) {
+		c.errorBurst = &burst
+	}
+}
+
+// WithErrorHandler sets a func that is called whenever a record can't be
+// handled, for example because a sink failed to deliver it or the output
+// couldn't be written to. The record passed to the func includes any
+// attributes and groups added to the logger.
+//
+// Sinks that buffer records may report errors from delivering earlier
+// records, so the error may not relate to the record passed alongside it.
+func WithErrorHandler(fn func(err error, r slog.Record)) Option {
+	return func(c *config) {
+		c.errorHandler = fn
+	}
+}
+
+// WithErrorSummary aggregates repeated error records to reduce noise. In
+// each interval, the first few records with the same key (by default, the
+// same message) are written in full and any further ones are only counted.
+// At the end of the interval an "Error occurred repeatedly" record is logged
+// for each key that had records held back, giving the total number of
+// occurrences. See [ErrorSummaryConfig] for the defaults.
+func WithErrorSummary(summary ErrorSummaryConfig) Option {
+	return func(c *config) {
+		c.errorSummary = &summary
+	}
+}
+
+// WithFallbackWriter sets a writer that log output will be written to if
+// writing to the primary writer fails, along with a diagnostic message the
+// first time each failure occurs. Defaults to [os.Stderr]. Passing nil
+// disables the fallback, causing records that can't be written to be lost.
+func WithFallbackWriter(w io.Writer) Option {
+	return func(c *config) {
+		c.fallbackWriter = w
+	}
+}
+
+// WithFileEncryption encrypts log output when the `log.output` flag specifies
+// a file. The encrypt func is called with the file when it's opened, and
+// returns a writer that encrypts data before writing it to the file. Closing
+// the returned writer must finish the encrypted stream without closing the
+// file. If it returns an error, output falls back to stderr.
+//
+// The github.com/csmith/slogflags/slogflagsage module provides an option
+// that encrypts files using age.
+//
+// As encrypted files generally can't be appended to, any existing file at
+// the output path is renamed with a timestamp suffix before a new one is
+// created.
+func WithFileEncryption(encrypt func(w io.Writer) (io.WriteCloser, error)) Option {
+	return func(c *config) {
+		c.fileEncryption = encrypt
+	}
+}
+
+// WithFlightRecorder retains up to size of the most recent records that are
+// below the configured log level, instead of discarding them. When a record
+// at [log/slog.LevelError] or above is logged, the retained records are
+// written first, giving context for the failure without having to output
+// debug logs all the time.
+//
+// By default a single recording is kept for the whole process. Use
+// [ContextWithFlightRecorder] to create separate recordings, e.g. for each
+// request.
+func WithFlightRecorder(size int) Option {
+	return func(c *config) {
+		c.flightRecorderSize = size
+	}
+}
+
+// WithFsync makes file output durable by syncing the file to disk after every
+// write, as if the `log.fsync` flag was set. Each record is normally written
+// separately, so this greatly reduces how quickly records can be logged; use
+// the `log.buffer-size` flag to write and sync records in batches instead.
+//
+// Compressed or encrypted output is only synced once the compressor or
+// encryptor writes it to the file.
+func WithFsync(fsync bool) Option {
+	return func(c *config) {
+		c.fsync = fsync
+	}
+}
+
+// WithGoroutineDump logs the stack trace of every goroutine at the given
+// level when the process receives one of the signals (SIGQUIT if none are
+// given), so that diagnostics for stuck processes end up alongside other
+// logs. A record with the number of goroutines is logged, followed by one
+// record per goroutine.
+//
+// Handling SIGQUIT replaces Go's default behaviour of printing the stacks to
+// stderr and exiting; the process keeps running after the dump is logged.
+func WithGoroutineDump(level slog.Level, signals ...os.Signal) Option {
+	return func(c *config) {
+		if len(signals) == 0 {
+			signals = []os.Signal{syscall.SIGQUIT}
+		}
+		c.goroutineDumpLevel = level
+		c.goroutineDumpSigs = signals
+	}
+}
+
+// WithGoroutineID adds a "goroutine" attribute with the ID of the goroutine
+// that logged each record. This is useful when debugging interleaved output
+// from concurrent code, but is relatively expensive as the ID is obtained
+// from a stack trace.
+func WithGoroutineID(enabled bool) Option {
+	return func(c *config) {
+		c.goroutineID = enabled
+	}
+}
+
+// WithGroup opens a group on the returned logger, as if
+// [log/slog.Logger.WithGroup] had been called on it. Attributes added by
+// later [WithAttrs] options, and all attributes of each record, are added
+// within the group.
+func WithGroup(name string) Option {
+	return func(c *config) {
+		if name != "" {
+			c.goas = append(c.goas, groupOrAttrs{group: name})
+		}
+	}
+}
+
+// WithGzipFileOutput compresses log output with gzip when the `log.output`
+// flag specifies a file. The compressor is flushed at the given interval, so
+// that records can be read from the file without waiting for it to be closed.
+// If the file already exists a new gzip member is appended to it, which most
+// tools will read as a single stream.
+func WithGzipFileOutput(flushInterval time.Duration) Option {
+	return func(c *config) {
+		c.gzipFlushInterval = flushInterval
+	}
+}
+
+// WithHashKeys replaces the values of attributes with the given keys with a
+// digest, so that records about the same user (for example) can be
+// correlated without the identifier itself being logged. Digests are the
+// first 128 bits of an HMAC-SHA256 of the value, hex encoded, using the
+// given secret. The secret should be kept private and stay the same over
+// time, so that the same value always produces the same digest. Keys are
+// matched in the same way as [WithRedactKeys]. If the option is given
+// multiple times, all the keys are hashed using the last secret.
+func WithHashKeys(secret []byte, keys ...string) Option {
+	return func(c *config) {
+		c.hashSecret = secret
+		c.hashKeys = append(c.hashKeys, keys...)
+	}
+}
+
+// WithHostname controls whether a "hostname" attribute with the machine's
+// hostname is added to every record. It can also be enabled using the
+// `log.hostname` flag.
+func WithHostname(hostname bool) Option {
+	return func(c *config) {
+		c.hostname = hostname
+	}
+}
+
+// WithKubernetesMetadata adds a "k8s" group to every record, containing
+// "pod", "namespace" and "node" attributes taken from the POD_NAME,
+// POD_NAMESPACE and NODE_NAME environment variables. These should be
+// populated using the downward API in the pod spec:
+//
+//	env:
+//	  - name: POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	  - name: POD_NAMESPACE
+//	    valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	  - name: NODE_NAME
+//	    valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//
+// Variables that aren't set are omitted.
+func WithKubernetesMetadata(enabled bool) Option {
+	return func(c *config) {
+		c.kubernetes = enabled
+	}
+}
+
+// WithLevelFormat writes records at or above the given level in a different
+// format to the one selected by the `log.format` flag, such as "expanded" for
+// errors so that their causes and stack traces are easy to read while routine
+// records stay on a single line:
+//
+//	logger := slogflags.Logger(slogflags.WithLevelFormat(slog.LevelError, "expanded"))
+//
+// The format may be any value accepted by the `log.format` flag. It may be
+// specified multiple times, and each record is written using the format for
+// the highest level it reaches. Records sent to sinks are unaffected.
+func WithLevelFormat(level slog.Level, format string) Option {
+	return func(c *config) {
+		if c.levelFormats == nil {
+			c.levelFormats = map[slog.Level]string{}
+		}
+		c.levelFormats[level] = format
+	}
+}
+
+// WithMetrics counts the records written by the logger, and any errors
+// writing them, in the given [Metrics].
+func WithMetrics(metrics *Metrics) Option {
+	return func(c *config) {
+		c.metrics = metrics
+	}
+}
+
+// WithNeverDrop ensures that records at or above the given level are never
+// dropped by [WithAsync], [WithSampling] or [WithRateLimit], or by the queue
+// used when the `log.backpressure` flag is "drop". If a queue is full,
+// logging such a record blocks until there is space, regardless of the
+// [DropPolicy] or [Backpressure].
+func WithNeverDrop(level slog.Level) Option {
+	return func(c *config) {
+		c.neverDropLevel = level
+	}
+}
+
+// WithNoTime omits the time from records written to the log output, as if
+// the `log.no-time` flag was set. This is useful in environments such as
+// systemd and most container runtimes and cloud platforms, which record the
+// time each line was received themselves. Records passed to sinks still
+// have their time.
+func WithNoTime(enabled bool) Option {
+	return func(c *config) {
+		c.noTime = enabled
+	}
+}
+
 // WithOldLogLevel sets the level that should be used when interoping with the
 // older [log] package. See [log/slog.SetLogLoggerLevel]. If not provided, the
 // default is [log/slog.LevelInfo].
@@ -167,6 +1038,86 @@ func WithOldLogLevel(level slog.Level) Option {
 	}
 }
 
+// WithOnLog adds a func that is called with each record that is logged,
+// before it's formatted or passed to any other handler. The func may modify
+// the record, for example to add attributes derived from the context or to
+// change the message. Attributes added using With are not included in the
+// record passed to the func, and the record has already been checked against
+// the configured level, so changing its level won't cause it to be dropped.
+//
+// WithOnLog may be specified multiple times, and funcs are called in the order
+// they were given.
+func WithOnLog(fn func(ctx context.Context, r *slog.Record)) Option {
+	return func(c *config) {
+		c.onLog = append(c.onLog, fn)
+	}
+}
+
+// WithOnLogged adds a func that is called with each record once it has been
+// handled successfully, for side effects such as counting records. The
+// record includes any attributes added using With. If the logger writes
+// asynchronously (see [WithAsync]), records are considered handled once they
+// have been queued.
+//
+// WithOnLogged may be specified multiple times, and funcs are called in the
+// order they were given.
+func WithOnLogged(fn func(ctx context.Context, r slog.Record)) Option {
+	return func(c *config) {
+		c.onLogged = append(c.onLogged, fn)
+	}
+}
+
+// WithPID controls whether a "pid" attribute with the process ID is added to
+// every record. It can also be enabled using the `log.pid` flag.
+func WithPID(pid bool) Option {
+	return func(c *config) {
+		c.pid = pid
+	}
+}
+
+// WithRateLimit limits the number of records that are written for each key
+// returned by keyFunc to limit in every window. This can be used to stop
+// abusive or broken callers from flooding the output, for example by
+// deriving the key from an attribute containing the client's IP address.
+// If keyFunc returns an empty string, the record isn't rate limited.
+//
+// The record passed to keyFunc includes any attributes and groups added to
+// the logger. At the end of each window, a warning is logged for each key
+// that had records suppressed, giving the number that were dropped.
+func WithRateLimit(keyFunc func(ctx context.Context, r slog.Record) string, limit int, window time.Duration) Option {
+	return func(c *config) {
+		c.rateLimitFunc = keyFunc
+		c.rateLimit = limit
+		c.rateLimitWindow = window
+	}
+}
+
+// WithRedactKeys replaces the values of attributes with the given keys with
+// "[REDACTED]", so that secrets aren't written to any output or sink. Keys
+// are matched case-insensitively, and are redacted within groups and in
+// structs, maps and slices that are logged whole (using the names from their
+// JSON encoding). Values containing a redacted field are logged as their
+// decoded JSON. If the option is given multiple times, all the keys are
+// redacted.
+func WithRedactKeys(keys ...string) Option {
+	return func(c *config) {
+		c.redactKeys = append(c.redactKeys, keys...)
+	}
+}
+
+// WithRenameReservedKeys controls whether attributes that collide with the
+// built-in keys ("time", "level", "msg" and "source") are moved into a
+// "fields" group, so they're written as e.g. "fields.level" in text formats
+// or nested in a "fields" object in JSON. Sinks that use other keys for
+// built-in fields, such as "message" for [GCPLoggingSink], have those keys
+// moved as well. This is enabled by default, to avoid ambiguous duplicate
+// keys; attributes within groups are unaffected.
+func WithRenameReservedKeys(enabled bool) Option {
+	return func(c *config) {
+		c.keepReservedKeys = !enabled
+	}
+}
+
 // WithReplaceAttr allows setting an attribute replacement func on the logger.
 // This can be used to rewrite attribute names or values.
 // See [log/slog.HandlerOptions.ReplaceAttr].
@@ -176,6 +1127,121 @@ func WithReplaceAttr(fn func(groups []string, a slog.Attr) slog.Attr) Option {
 	}
 }
 
+// WithRevealSecrets causes values wrapped with [Secret] to be logged in full
+// by this logger, rather than as "[REDACTED]". This should only be enabled
+// while debugging, and a warning is logged when it is.
+func WithRevealSecrets(enabled bool) Option {
+	return func(c *config) {
+		c.revealSecrets = enabled
+	}
+}
+
+// WithRingBuffer keeps the most recent records logged in the given
+// [RingBuffer], including those below the log level.
+func WithRingBuffer(buffer *RingBuffer) Option {
+	return func(c *config) {
+		c.ringBuffer = buffer
+	}
+}
+
+// WithSampling samples records at or below the given level, to prevent hot
+// loops from flooding the output. Each second, the first keepFirst records
+// with the same level and message are kept, and after that only every
+// thereafterEvery-th record is kept. If thereafterEvery is zero, all records
+// after the first keepFirst are dropped.
+//
+// These values can be overridden by the `log.sample-level`,
+// `log.sample-first` and `log.sample-thereafter` flags, which also enable
+// sampling if this option isn't used.
+func WithSampling(level slog.Level, keepFirst, thereafterEvery int) Option {
+	return func(c *config) {
+		c.samplingLevel = level
+		c.samplingFirst = keepFirst
+		c.samplingThereafter = thereafterEvery
+	}
+}
+
+// WithSanitize escapes CR, LF and other control characters, and removes ANSI
+// escape sequences, in the message and string values of records. This
+// prevents user-controlled input from forging additional log lines or
+// corrupting a terminal, regardless of the output format or sink. Errors are
+// logged as their sanitized message if it's different.
+func WithSanitize(enabled bool) Option {
+	return func(c *config) {
+		c.sanitize = enabled
+	}
+}
+
+// WithSchema validates records against a declared schema, checking that
+// each event (identified by the record's message) has the required
+// attributes with the expected kinds of values. Violations are logged as
+// warnings, or cause a panic if [SchemaConfig.Panic] is set. This is
+// intended for development and tests, to catch changes to logging that
+// would break anything consuming the logs; validation adds overhead to every
+// record.
+func WithSchema(schema SchemaConfig) Option {
+	return func(c *config) {
+		c.schema = &schema
+	}
+}
+
+// WithScrubPatterns masks any text matching the given patterns in the
+// message and string values of records, replacing it with "[REDACTED]".
+// Errors are logged as their masked message if it contains a match. This
+// reduces the risk of personal information or secrets leaking into logs,
+// and is applied before records reach any output or sink. Presets are
+// available for common patterns:
+//
+//	slogflags.WithScrubPatterns(slogflags.ScrubEmails, slogflags.ScrubCreditCards, slogflags.ScrubBearerTokens)
+//
+// If the option is given multiple times, all the patterns are applied.
+func WithScrubPatterns(patterns ...*regexp.Regexp) Option {
+	return func(c *config) {
+		c.scrubPatterns = append(c.scrubPatterns, patterns...)
+	}
+}
+
+// WithSentry sends records at or above the level configured in the [Sentry]
+// integration to Sentry as events. Records are still written to the normal
+// output as well.
+func WithSentry(sentry *Sentry) Option {
+	return func(c *config) {
+		c.sentry = sentry
+	}
+}
+
+// WithSequenceNumbers adds a "seq" attribute to every record, containing a
+// number that increases by one for each record logged by the process. This
+// allows records that are delivered out of order, for example by
+// asynchronous sinks or log collectors, to be detected and sorted.
+//
+// Sequence numbers are shared between all loggers in the process, and records
+// that are dropped (for example by sampling) leave gaps in the sequence. As
+// with [WithContextAttrs], the attribute is added within any open groups.
+func WithSequenceNumbers(enabled bool) Option {
+	return func(c *config) {
+		c.sequenceNumbers = enabled
+	}
+}
+
+// WithService adds attributes describing the service to every record, using
+// the standard fields for the format selected with [WithTraceCorrelation]:
+//
+//   - [TraceFormatOTel] (the default): a "service" group with "name",
+//     "version" and "environment" attributes, as used by the Elastic Common
+//     Schema and OpenTelemetry
+//   - [TraceFormatDatadog]: "service", "version" and "env" attributes
+//   - [TraceFormatGCP]: a "serviceContext" group with "service" and
+//     "version" attributes, as used by Error Reporting, and an "environment"
+//     attribute
+//
+// Empty values are omitted.
+func WithService(name, version, environment string) Option {
+	return func(c *config) {
+		c.service = serviceInfo{name: name, version: version, environment: environment}
+	}
+}
+
 // WithSetDefault sets whether the logger should be set as the default [log/slog]
 // logger. See [log/slog.SetDefault].
 func WithSetDefault(setDefault bool) Option {
@@ -184,6 +1250,113 @@ func WithSetDefault(setDefault bool) Option {
 	}
 }
 
+// WithShutdownOnSignal makes the process call [Shutdown] when it receives
+// SIGINT or SIGTERM, waiting at most timeout for records to be written, and
+// then exit. This is intended for simple binaries that don't otherwise handle
+// signals; applications that shut down gracefully should call [Shutdown]
+// themselves instead.
+func WithShutdownOnSignal(timeout time.Duration) Option {
+	return func(c *config) {
+		c.shutdownTimeout = timeout
+	}
+}
+
+// WithSingleton sets whether [Logger] should only configure a single logger.
+// The first call to Logger with this option enabled creates a logger as
+// usual, and later calls with it enabled return the same logger without
+// re-applying any configuration, such as the level used by the [log] package.
+// This allows several packages to call Logger safely.
+//
+// If a later call is passed options that differ from the first, a warning is
+// logged and the existing logger is still returned. Options are compared by
+// value; funcs are considered the same if they come from the same source
+// code, even if they capture different variables.
+//
+// The logger is forgotten when [Close] is called.
+func WithSingleton(singleton bool) Option {
+	return func(c *config) {
+		c.singleton = singleton
+	}
+}
+
+// WithSink adds a [Sink] that will receive all records at or above the
+// configured level, in addition to the output sent to the writer (see
+// [WithWriter]). It may be specified multiple times to add multiple sinks.
+//
+// The caller is responsible for closing the sink once it is no longer needed.
+func WithSink(sink Sink) Option {
+	return func(c *config) {
+		c.sinks = append(c.sinks, sink)
+	}
+}
+
+// WithSourceTrimModuleRoot removes the directory containing the main module
+// from the file names in source locations (see [WithAddSource]), so that they
+// are relative to the root of the repository (e.g. "internal/db/conn.go"),
+// rather than showing where the binary was built. The directory is detected
+// automatically; this requires [Logger] to be called from the main
+// goroutine, unless the binary was built with -trimpath.
+func WithSourceTrimModuleRoot(enabled bool) Option {
+	return func(c *config) {
+		c.sourceTrimModule = enabled
+	}
+}
+
+// WithSourceTrimPrefix removes the given prefix from the file names in source
+// locations (see [WithAddSource]). For example, with the prefix
+// "/home/build/app/", the file "/home/build/app/internal/db/conn.go" is
+// shown as "internal/db/conn.go". Files that don't have the prefix are
+// unchanged.
+func WithSourceTrimPrefix(prefix string) Option {
+	return func(c *config) {
+		c.sourceTrimPrefix = prefix
+	}
+}
+
+// WithStderrCapture redirects the process's stderr file descriptor through
+// a pipe, and logs each line written to it at the given level with a
+// "stream" attribute of "stderr". This captures output that would otherwise
+// bypass the logger, such as runtime panics, println debugging, and messages
+// from C libraries. Output written to [os.Stderr] by the logger itself, for
+// example with `-log.output stderr`, goes to the original stderr instead.
+//
+// The capture lasts until [Close] is called, and affects the whole process,
+// including child processes that inherit stderr. Because the captured output
+// is read back by the process itself, the report of a fatal panic may not be
+// logged before the process exits, so it is also written to the original
+// stderr. Capturing is only supported on Linux, macOS, and some BSDs;
+// elsewhere a warning is logged.
+func WithStderrCapture(level slog.Level) Option {
+	return func(c *config) {
+		c.stderrCapture = true
+		c.stderrCaptureLevel = level
+	}
+}
+
+// WithTimeZone sets the time zone used for the times of records written to
+// the log output, rather than the local time zone of the host. It can be
+// overridden using the `log.timezone` flag, e.g. `--log.timezone=UTC`.
+//
+// Named time zones are loaded using [time.LoadLocation], which needs the
+// system's time zone database. Applications that run where there isn't one,
+// such as in minimal containers, can import [time/tzdata] to embed it.
+func WithTimeZone(loc *time.Location) Option {
+	return func(c *config) {
+		c.timeZone = loc
+	}
+}
+
+// WithTraceCorrelation adds attributes identifying the active trace and span
+// to every record logged with a context containing a span (e.g. using
+// [log/slog.Logger.InfoContext]), so that logs can be correlated with traces.
+// See [TraceConfig] for details.
+func WithTraceCorrelation(trace TraceConfig) Option {
+	return func(c *config) {
+		c.contextAttrs = append(c.contextAttrs, trace.attrs)
+		c.traceFormat = trace.Format
+	}
+}
+
 // WithWriter sets a custom writer to be used for the log output. Defaults to
 // [os.Stdout].
 func WithWriter(w io.Writer) Option {
@@ -8,15 +8,21 @@ import (
 	"log"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// testTime is the time of all records logged by loggers from [LoggerForTest].
+var testTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testClock() time.Time {
+	return testTime
+}
+
 func LoggerForTest(w io.Writer, opts ...Option) *slog.Logger {
-	testOpts := append(opts, WithWriter(w), WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
-		if a.Key == "time" {
-			return slog.Attr{Key: "time", Value: slog.StringValue("fake-time")}
-		} else if a.Key == "file" {
+	testOpts := append(opts, WithWriter(w), WithClock(testClock), WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == "file" {
 			return slog.Attr{Key: "file", Value: slog.StringValue("file.go")}
 		} else if a.Key == "function" {
 			return slog.Attr{Key: "function", Value: slog.StringValue("github.com/csmith/slogflags.Test")}
@@ -37,7 +43,7 @@ func Test_DefaultsToTextLogger(t *testing.T) {
 	l := LoggerForTest(w)
 	l.Warn("Test", "arg1", "arg2")
 
-	assert.Equal(t, "time=fake-time level=WARN msg=Test arg1=arg2\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=Test arg1=arg2\n", w.String())
 }
 
 func Test_SetFormatToJson(t *testing.T) {
@@ -47,7 +53,7 @@ func Test_SetFormatToJson(t *testing.T) {
 	l := LoggerForTest(w)
 	l.Warn("Test", "arg1", "arg2")
 
-	assert.JSONEq(t, `{"arg1": "arg2", "level": "WARN", "msg": "Test", "time": "fake-time"}`, w.String())
+	assert.JSONEq(t, `{"arg1": "arg2", "level": "WARN", "msg": "Test", "time": "2026-01-02T03:04:05Z"}`, w.String())
 }
 
 func Test_AddingSource(t *testing.T) {
@@ -61,7 +67,7 @@ func Test_AddingSource(t *testing.T) {
 		"arg1": "arg2",
 		"level": "WARN",
 		"msg": "Test",
-		"time": "fake-time",
+		"time": "2026-01-02T03:04:05Z",
 		"source": {
 			"file": "file.go",
 			"function": "github.com/csmith/slogflags.Test",
@@ -81,7 +87,7 @@ func Test_DefaultsToInfoLevel(t *testing.T) {
 	l.Warn("Test")
 	l.Error("Test")
 
-	assert.Equal(t, "time=fake-time level=INFO msg=Test\ntime=fake-time level=WARN msg=Test\ntime=fake-time level=ERROR msg=Test\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Test\ntime=2026-01-02T03:04:05.000Z level=WARN msg=Test\ntime=2026-01-02T03:04:05.000Z level=ERROR msg=Test\n", w.String())
 }
 
 func Test_WithCustomDefaultLevel(t *testing.T) {
@@ -93,7 +99,7 @@ func Test_WithCustomDefaultLevel(t *testing.T) {
 	l.Warn("Test")
 	l.Error("Test")
 
-	assert.Equal(t, "time=fake-time level=WARN msg=Test\ntime=fake-time level=ERROR msg=Test\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=Test\ntime=2026-01-02T03:04:05.000Z level=ERROR msg=Test\n", w.String())
 }
 
 func Test_SetsBuiltInLevel(t *testing.T) {
@@ -107,7 +113,7 @@ func Test_SetsBuiltInLevel(t *testing.T) {
 	l.Warn("Test")
 	l.Error("Test")
 
-	assert.Equal(t, "time=fake-time level=ERROR msg=Test\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=ERROR msg=Test\n", w.String())
 }
 
 func Test_SetsCustomLevel(t *testing.T) {
@@ -123,7 +129,7 @@ func Test_SetsCustomLevel(t *testing.T) {
 	l.Log(context.Background(), custom, "Test")
 	l.Error("Test")
 
-	assert.Equal(t, "time=fake-time level=SHRUG msg=Test\ntime=fake-time level=ERROR msg=Test\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=SHRUG msg=Test\ntime=2026-01-02T03:04:05.000Z level=ERROR msg=Test\n", w.String())
 }
 
 func Test_SetsDefault(t *testing.T) {
@@ -135,7 +141,7 @@ func Test_SetsDefault(t *testing.T) {
 
 	slog.Error("Test")
 
-	assert.Equal(t, "time=fake-time level=ERROR msg=Test\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=ERROR msg=Test\n", w.String())
 }
 
 func Test_SetsOldLogLevel(t *testing.T) {
@@ -147,7 +153,7 @@ func Test_SetsOldLogLevel(t *testing.T) {
 
 	log.Printf("Test")
 
-	assert.Equal(t, "time=fake-time level=ERROR msg=Test\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=ERROR msg=Test\n", w.String())
 }
 
 func Test_CustomLevelWithReplaceAttr(t *testing.T) {
@@ -195,7 +201,7 @@ func Test_CustomLevelJSON(t *testing.T) {
 		"arg1": "arg2",
 		"level": "SHRUG",
 		"msg": "Test",
-		"time": "fake-time"
+		"time": "2026-01-02T03:04:05Z"
 	}`, w.String())
 }
 
@@ -213,7 +219,7 @@ func Test_CustomLevelAliasLexicographicOrder(t *testing.T) {
 
 	l.Log(context.Background(), custom, "Test")
 
-	assert.Equal(t, "time=fake-time level=ALPHA msg=Test\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=ALPHA msg=Test\n", w.String())
 
 	w.Reset()
 	l = LoggerForTest(w,
@@ -223,7 +229,7 @@ func Test_CustomLevelAliasLexicographicOrder(t *testing.T) {
 
 	l.Log(context.Background(), custom, "Test")
 
-	assert.Equal(t, "time=fake-time level=ALPHA msg=Test\n", w.String())
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=ALPHA msg=Test\n", w.String())
 }
 
 func Test_WarnsOnUnknownLevel(t *testing.T) {
@@ -237,3 +243,119 @@ func Test_WarnsOnUnknownLevel(t *testing.T) {
 	assert.Contains(t, w.String(), "level=WARN msg=\"Unknown log level, using default\" requested=bogus default=INFO\n")
 	assert.Contains(t, w.String(), "level=INFO msg=Test\n")
 }
+
+func Test_WarnsOnInvalidOutput(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.output", "bogus://example")
+	defer flag.Set("log.output", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.Info("Test")
+
+	assert.Contains(t, w.String(), "level=WARN msg=\"Unable to configure log output, using default\" requested=bogus://example error=\"unsupported output \\\"bogus://example\\\"\"\n")
+	assert.Contains(t, w.String(), "level=INFO msg=Test\n")
+}
+
+func Test_WithAttrsAndGroup(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithAttrs(slog.String("component", "billing")), WithGroup("req"), WithAttrs(slog.Int("attempt", 1)))
+	l.Info("Charged", "amount", 5)
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Charged component=billing req.attempt=1 req.amount=5\n", w.String())
+}
+
+func Test_WithAttrs_AppliesToL(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w, WithAttrs(slog.String("component", "billing")))
+	L().Info("Proxied")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Proxied component=billing\n", w.String())
+}
+
+func Test_WithNoTime(t *testing.T) {
+	_ = flag.Set("log.level", "")
+
+	for _, format := range []string{"text", "json", "fasttext"} {
+		t.Run(format, func(t *testing.T) {
+			_ = flag.Set("log.format", format)
+			defer flag.Set("log.format", "")
+
+			w := new(bytes.Buffer)
+			l := LoggerForTest(w, WithNoTime(true))
+			l.Info("Test", "time", "attr")
+			l.WithGroup("g").Info("Test", "time", "grouped")
+
+			assert.NotContains(t, w.String(), "2026")
+			assert.Contains(t, w.String(), "attr")
+			assert.Contains(t, w.String(), "grouped")
+		})
+	}
+}
+
+func Test_NoTimeFlag(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.no-time", "true")
+	defer flag.Set("log.no-time", "false")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w)
+	l.Info("Test", "arg1", "arg2")
+
+	assert.Equal(t, "level=INFO msg=Test arg1=arg2\n", w.String())
+}
+
+func Test_WithTimeZone(t *testing.T) {
+	_ = flag.Set("log.level", "")
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	for format, want := range map[string]string{
+		"text":     "time=2026-01-02T12:04:05.000+09:00 level=INFO msg=Test at=2026-01-02T03:04:05.000Z\n",
+		"fasttext": "time=2026-01-02T12:04:05.000+09:00 level=INFO msg=Test at=2026-01-02T03:04:05.000Z\n",
+		"json":     `{"time":"2026-01-02T12:04:05+09:00","level":"INFO","msg":"Test","at":"2026-01-02T03:04:05Z"}` + "\n",
+	} {
+		t.Run(format, func(t *testing.T) {
+			_ = flag.Set("log.format", format)
+			defer flag.Set("log.format", "")
+
+			w := new(bytes.Buffer)
+			l := LoggerForTest(w, WithTimeZone(tokyo))
+			l.Info("Test", "at", testTime)
+
+			assert.Equal(t, want, w.String())
+		})
+	}
+}
+
+func Test_TimezoneFlag(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.timezone", "UTC")
+	defer flag.Set("log.timezone", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithTimeZone(time.FixedZone("JST", 9*60*60)))
+	l.Info("Test")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=INFO msg=Test\n", w.String())
+}
+
+func Test_WarnsOnUnknownTimezone(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	_ = flag.Set("log.timezone", "Nowhere/Special")
+	defer flag.Set("log.timezone", "")
+
+	w := new(bytes.Buffer)
+	_ = LoggerForTest(w)
+
+	assert.Contains(t, w.String(), "level=WARN msg=\"Unknown time zone, using default\" requested=Nowhere/Special")
+}
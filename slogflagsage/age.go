@@ -0,0 +1,50 @@
+package slogflagsage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"github.com/csmith/slogflags"
+)
+
+// WithFileOutput returns an option that encrypts log output to the given
+// X25519 recipients (of the form "age1...") when the `log.output` flag
+// specifies a file:
+//
+//	logger := slogflags.Logger(slogflagsage.WithFileOutput("age1..."))
+//
+// The file can be decrypted with any age implementation, e.g.
+// `age -d -i key.txt app.log.age`. Data is encrypted in chunks of 64 KiB, and
+// the final chunk is only written when the logger is closed using
+// [slogflags.Close].
+//
+// If any recipient is invalid, output falls back to stderr. As age files
+// can't be appended to, any existing file at the output path is renamed with
+// a timestamp suffix before a new one is created.
+func WithFileOutput(recipients ...string) slogflags.Option {
+	return slogflags.WithFileEncryption(func(w io.Writer) (io.WriteCloser, error) {
+		return NewWriter(w, recipients...)
+	})
+}
+
+// NewWriter returns a writer that encrypts data to the given X25519
+// recipients and writes it to w. The age header is written immediately.
+// Closing the writer writes the final chunk, but doesn't close w.
+func NewWriter(w io.Writer, recipients ...string) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("age: no recipients specified")
+	}
+
+	parsed := make([]age.Recipient, len(recipients))
+	for i, r := range recipients {
+		recipient, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return nil, fmt.Errorf("age: invalid recipient %q: %w", r, err)
+		}
+		parsed[i] = recipient
+	}
+
+	return age.Encrypt(w, parsed...)
+}
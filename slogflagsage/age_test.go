@@ -0,0 +1,98 @@
+package slogflagsage
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/csmith/slogflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decrypt decrypts data using the reference age implementation.
+func decrypt(t *testing.T, identity age.Identity, data []byte) string {
+	r, err := age.Decrypt(bytes.NewReader(data), identity)
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func Test_NewWriter_EncryptsMultipleChunks(t *testing.T) {
+	first, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	second, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	w, err := NewWriter(out, first.Recipient().String(), second.Recipient().String())
+	require.NoError(t, err)
+
+	plaintext := strings.Repeat("0123456789abcdef", 64<<10/16*2) + "end"
+	_, err = io.WriteString(w, plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.True(t, strings.HasPrefix(out.String(), "age-encryption.org/v1\n"))
+	assert.NotContains(t, out.String(), "0123456789abcdef")
+	assert.Equal(t, plaintext, decrypt(t, first, out.Bytes()))
+	assert.Equal(t, plaintext, decrypt(t, second, out.Bytes()))
+}
+
+func Test_NewWriter_DecryptsWithAgeCommand(t *testing.T) {
+	bin, err := exec.LookPath("age")
+	if err != nil {
+		t.Skip("age command not installed")
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(keyPath, []byte(identity.String()+"\n"), 0o600))
+
+	out := new(bytes.Buffer)
+	w, err := NewWriter(out, identity.Recipient().String())
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "Hello\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	cmd := exec.Command(bin, "-d", "-i", keyPath)
+	cmd.Stdin = out
+	b, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Hello\n", string(b))
+}
+
+func Test_NewWriter_RejectsInvalidRecipients(t *testing.T) {
+	_, err := NewWriter(io.Discard)
+	assert.EqualError(t, err, "age: no recipients specified")
+
+	_, err = NewWriter(io.Discard, "age1bogus")
+	assert.ErrorContains(t, err, `age: invalid recipient "age1bogus"`)
+}
+
+func Test_WithFileOutput(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "app.log.age")
+	_ = flag.Set("log.output", path)
+	defer flag.Set("log.output", "")
+
+	l := slogflags.Logger(slogflags.WithWriter(io.Discard), WithFileOutput(identity.Recipient().String()))
+	l.Info("Encrypted", "user", "alice")
+	require.NoError(t, slogflags.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, decrypt(t, identity, b), "level=INFO msg=Encrypted user=alice\n")
+}
@@ -0,0 +1,6 @@
+// Package slogflagsage encrypts slogflags log files using age
+// (https://age-encryption.org), so that they can only be read by the holders
+// of the identities corresponding to the given recipients.
+//
+// It's a separate module so that slogflags itself doesn't depend on age.
+package slogflagsage
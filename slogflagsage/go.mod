@@ -0,0 +1,19 @@
+module github.com/csmith/slogflags/slogflagsage
+
+go 1.24.2
+
+require (
+	filippo.io/age v1.2.1
+	github.com/csmith/slogflags v1.2.0
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.24.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/csmith/slogflags => ../
@@ -0,0 +1,7 @@
+// Package slogflagshclog provides a github.com/hashicorp/go-hclog logger that
+// forwards entries to slogflags, so that HashiCorp libraries such as raft and
+// go-plugin log with the output format, level and sinks controlled by the
+// slogflags flags.
+//
+// It's a separate module so that slogflags itself doesn't depend on hclog.
+package slogflagshclog
@@ -0,0 +1,323 @@
+package slogflagshclog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/csmith/slogflags"
+	"github.com/hashicorp/go-hclog"
+)
+
+// logger is an [hclog.Logger] that forwards entries and their arguments to a
+// [log/slog.Handler].
+type logger struct {
+	handler slog.Handler
+	name    string
+	implied []any
+
+	// level is set by SetLevel, and shared with loggers derived from this
+	// one. If it's [hclog.NoLevel], the handler decides which levels are
+	// enabled.
+	level *atomic.Int32
+}
+
+var _ hclog.Logger = (*logger)(nil)
+
+// New creates an [hclog.Logger] that forwards entries to the given logger's
+// handler, or to [slogflags.L] if it is nil:
+//
+//	config := raft.DefaultConfig()
+//	config.Logger = slogflagshclog.New(nil).Named("raft")
+//
+// Whether a level is enabled is decided by the handler, so the `log.level`
+// flag controls which entries are logged. The logger's name is added as a
+// "logger" attribute.
+func New(l *slog.Logger) hclog.Logger {
+	if l == nil {
+		l = slogflags.L()
+	}
+	return &logger{handler: l.Handler(), level: new(atomic.Int32)}
+}
+
+// Log logs a message at the given level.
+func (l *logger) Log(level hclog.Level, msg string, args ...any) {
+	l.log(level, msg, args)
+}
+
+// Trace logs a message at [hclog.Trace] level.
+func (l *logger) Trace(msg string, args ...any) {
+	l.log(hclog.Trace, msg, args)
+}
+
+// Debug logs a message at [hclog.Debug] level.
+func (l *logger) Debug(msg string, args ...any) {
+	l.log(hclog.Debug, msg, args)
+}
+
+// Info logs a message at [hclog.Info] level.
+func (l *logger) Info(msg string, args ...any) {
+	l.log(hclog.Info, msg, args)
+}
+
+// Warn logs a message at [hclog.Warn] level.
+func (l *logger) Warn(msg string, args ...any) {
+	l.log(hclog.Warn, msg, args)
+}
+
+// Error logs a message at [hclog.Error] level.
+func (l *logger) Error(msg string, args ...any) {
+	l.log(hclog.Error, msg, args)
+}
+
+// log passes a message to the handler as a record, if the level is enabled.
+// It must be called directly by the exported logging methods, so that the
+// caller's source location is recorded.
+func (l *logger) log(level hclog.Level, msg string, args []any) {
+	if !l.enabled(level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	l.handle(level, msg, args, pcs[0])
+}
+
+// handle passes a message to the handler as a record.
+func (l *logger) handle(level hclog.Level, msg string, args []any, pc uintptr) {
+	r := slog.NewRecord(time.Now(), slogLevel(level), msg, pc)
+	if l.name != "" {
+		r.AddAttrs(slog.String("logger", l.name))
+	}
+	r.AddAttrs(argAttrs(args)...)
+	_ = l.handler.Handle(context.Background(), r)
+}
+
+// enabled reports whether messages at the given level should be logged.
+func (l *logger) enabled(level hclog.Level) bool {
+	if level == hclog.Off || level == hclog.NoLevel {
+		return false
+	}
+	if minLevel := hclog.Level(l.level.Load()); minLevel != hclog.NoLevel && level < minLevel {
+		return false
+	}
+	return l.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+// IsTrace reports whether messages at [hclog.Trace] level are logged.
+func (l *logger) IsTrace() bool {
+	return l.enabled(hclog.Trace)
+}
+
+// IsDebug reports whether messages at [hclog.Debug] level are logged.
+func (l *logger) IsDebug() bool {
+	return l.enabled(hclog.Debug)
+}
+
+// IsInfo reports whether messages at [hclog.Info] level are logged.
+func (l *logger) IsInfo() bool {
+	return l.enabled(hclog.Info)
+}
+
+// IsWarn reports whether messages at [hclog.Warn] level are logged.
+func (l *logger) IsWarn() bool {
+	return l.enabled(hclog.Warn)
+}
+
+// IsError reports whether messages at [hclog.Error] level are logged.
+func (l *logger) IsError() bool {
+	return l.enabled(hclog.Error)
+}
+
+// ImpliedArgs returns the arguments added with With.
+func (l *logger) ImpliedArgs() []any {
+	return l.implied
+}
+
+// With returns a logger that adds the arguments to every message.
+func (l *logger) With(args ...any) hclog.Logger {
+	if len(args) == 0 {
+		return l
+	}
+	c := *l
+	c.handler = l.handler.WithAttrs(argAttrs(args))
+	c.implied = append(l.implied[:len(l.implied):len(l.implied)], args...)
+	return &c
+}
+
+// Name returns the logger's name.
+func (l *logger) Name() string {
+	return l.name
+}
+
+// Named returns a logger with the name appended to this logger's name,
+// separated by a dot.
+func (l *logger) Named(name string) hclog.Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	return l.ResetNamed(name)
+}
+
+// ResetNamed returns a logger with the given name.
+func (l *logger) ResetNamed(name string) hclog.Logger {
+	c := *l
+	c.name = name
+	return &c
+}
+
+// SetLevel sets the minimum level logged by this logger and loggers derived
+// from it, in addition to the handler's level. Setting it to [hclog.NoLevel]
+// leaves it to the handler.
+func (l *logger) SetLevel(level hclog.Level) {
+	l.level.Store(int32(level))
+}
+
+// GetLevel returns the lowest level that's logged, or [hclog.Off] if none
+// are.
+func (l *logger) GetLevel() hclog.Level {
+	for level := hclog.Trace; level < hclog.Off; level++ {
+		if l.enabled(level) {
+			return level
+		}
+	}
+	return hclog.Off
+}
+
+// StandardLogger returns a [log.Logger] that logs through this logger.
+func (l *logger) StandardLogger(opts *hclog.StandardLoggerOptions) *log.Logger {
+	return log.New(l.StandardWriter(opts), "", 0)
+}
+
+// StandardWriter returns an [io.Writer] that logs each write as a message.
+// Levels are inferred from prefixes such as "[WARN]" if the options ask for
+// it.
+func (l *logger) StandardWriter(opts *hclog.StandardLoggerOptions) io.Writer {
+	if opts == nil {
+		opts = &hclog.StandardLoggerOptions{}
+	}
+	return &standardWriter{logger: l, opts: *opts}
+}
+
+// standardWriter is an [io.Writer] that logs each write as a message.
+type standardWriter struct {
+	logger *logger
+	opts   hclog.StandardLoggerOptions
+}
+
+// timestampRegexp matches the timestamp written by the standard logger.
+var timestampRegexp = regexp.MustCompile(`^[\d\s:/.+-TZ]*`)
+
+func (w *standardWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), " \t\n")
+	level := hclog.Info
+
+	switch {
+	case w.opts.ForceLevel != hclog.NoLevel:
+		_, msg = inferLevel(msg)
+		level = w.opts.ForceLevel
+	case w.opts.InferLevels:
+		if w.opts.InferLevelsWithTimestamp {
+			msg = msg[timestampRegexp.FindStringIndex(msg)[1]:]
+		}
+		level, msg = inferLevel(msg)
+	}
+
+	if w.logger.enabled(level) {
+		w.logger.handle(level, msg, nil, 0)
+	}
+	return len(p), nil
+}
+
+// inferLevel removes a level prefix such as "[WARN]" from a message, and
+// returns the corresponding level, or [hclog.Info] if there isn't one.
+func inferLevel(msg string) (hclog.Level, string) {
+	for _, p := range []struct {
+		prefix string
+		level  hclog.Level
+	}{
+		{"[TRACE]", hclog.Trace},
+		{"[DEBUG]", hclog.Debug},
+		{"[INFO]", hclog.Info},
+		{"[WARN]", hclog.Warn},
+		{"[ERROR]", hclog.Error},
+		{"[ERR]", hclog.Error},
+	} {
+		if rest, ok := strings.CutPrefix(msg, p.prefix); ok {
+			return p.level, strings.TrimSpace(rest)
+		}
+	}
+	return hclog.Info, msg
+}
+
+// slogLevel maps an hclog level to the equivalent slog level.
+func slogLevel(level hclog.Level) slog.Level {
+	switch level {
+	case hclog.Trace:
+		return slog.LevelDebug - 4
+	case hclog.Debug:
+		return slog.LevelDebug
+	case hclog.Warn:
+		return slog.LevelWarn
+	case hclog.Error:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// argAttrs converts hclog's alternating keys and values to attributes. A
+// trailing value without a key is added under [hclog.MissingKey], or as a
+// "stacktrace" attribute if it was captured by [hclog.Stacktrace].
+func argAttrs(args []any) []slog.Attr {
+	attrs := make([]slog.Attr, 0, (len(args)+1)/2)
+	for i := 0; i < len(args); i += 2 {
+		if i == len(args)-1 {
+			if st, ok := args[i].(hclog.CapturedStacktrace); ok {
+				attrs = append(attrs, slog.String("stacktrace", string(st)))
+			} else {
+				attrs = append(attrs, slog.Any(hclog.MissingKey, args[i]))
+			}
+			break
+		}
+
+		key, ok := args[i].(string)
+		if !ok {
+			key = fmt.Sprint(args[i])
+		}
+		attrs = append(attrs, slog.Any(key, argValue(args[i+1])))
+	}
+	return attrs
+}
+
+// argValue converts hclog's formatting types to the values they represent.
+func argValue(v any) any {
+	switch v := v.(type) {
+	case hclog.Format:
+		if len(v) == 0 {
+			return ""
+		}
+		format, ok := v[0].(string)
+		if !ok {
+			return fmt.Sprint(v...)
+		}
+		return fmt.Sprintf(format, v[1:]...)
+	case hclog.Hex:
+		return fmt.Sprintf("0x%x", int(v))
+	case hclog.Octal:
+		return fmt.Sprintf("0%o", int(v))
+	case hclog.Binary:
+		return fmt.Sprintf("0b%b", int(v))
+	case hclog.CapturedStacktrace:
+		return string(v)
+	default:
+		return v
+	}
+}
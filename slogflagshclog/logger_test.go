@@ -0,0 +1,97 @@
+package slogflagshclog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(w *bytes.Buffer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func Test_Logger_ForwardsEntries(t *testing.T) {
+	w := new(bytes.Buffer)
+	logger := New(newTestLogger(w, slog.LevelDebug-4)).Named("raft").Named("snapshot").With("id", 7)
+
+	logger.Trace("Starting", "index", 42)
+	logger.Info("Progress", "rate", hclog.Fmt("%d/s", 5), "mode", hclog.Hex(255))
+	logger.Error("Failed", "error", "disk full", "orphan")
+
+	assert.Equal(t, ""+
+		"level=DEBUG-4 msg=Starting id=7 logger=raft.snapshot index=42\n"+
+		"level=INFO msg=Progress id=7 logger=raft.snapshot rate=5/s mode=0xff\n"+
+		"level=ERROR msg=Failed id=7 logger=raft.snapshot error=\"disk full\" EXTRA_VALUE_AT_END=orphan\n", w.String())
+	assert.Equal(t, "raft.snapshot", logger.Name())
+	assert.Equal(t, []any{"id", 7}, logger.ImpliedArgs())
+	assert.Equal(t, "other", logger.ResetNamed("other").Name())
+}
+
+func Test_Logger_UsesHandlerLevel(t *testing.T) {
+	w := new(bytes.Buffer)
+	logger := New(newTestLogger(w, slog.LevelWarn))
+
+	assert.False(t, logger.IsInfo())
+	assert.True(t, logger.IsWarn())
+	assert.Equal(t, hclog.Warn, logger.GetLevel())
+
+	logger.Info("Hidden")
+	logger.Warn("Shown")
+
+	assert.Equal(t, "level=WARN msg=Shown\n", w.String())
+}
+
+func Test_Logger_SetLevel(t *testing.T) {
+	w := new(bytes.Buffer)
+	logger := New(newTestLogger(w, slog.LevelDebug))
+	named := logger.Named("child")
+
+	logger.SetLevel(hclog.Error)
+	assert.Equal(t, hclog.Error, named.GetLevel())
+	named.Warn("Hidden")
+
+	logger.SetLevel(hclog.NoLevel)
+	assert.Equal(t, hclog.Debug, named.GetLevel())
+	named.Debug("Shown")
+
+	logger.SetLevel(hclog.Off)
+	assert.Equal(t, hclog.Off, logger.GetLevel())
+	logger.Error("Hidden")
+
+	assert.Equal(t, "level=DEBUG msg=Shown logger=child\n", w.String())
+}
+
+func Test_Logger_StandardLogger(t *testing.T) {
+	w := new(bytes.Buffer)
+	logger := New(newTestLogger(w, slog.LevelDebug))
+
+	logger.StandardLogger(nil).Print("[WARN] plain")
+	logger.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true}).Print("[WARN] inferred")
+	logger.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true, InferLevelsWithTimestamp: true}).Print("2026/01/02 03:04:05 [ERR] stamped")
+	logger.StandardLogger(&hclog.StandardLoggerOptions{ForceLevel: hclog.Debug}).Print("[ERROR] forced")
+
+	assert.Equal(t, ""+
+		"level=INFO msg=\"[WARN] plain\"\n"+
+		"level=WARN msg=inferred\n"+
+		"level=ERROR msg=stamped\n"+
+		"level=DEBUG msg=forced\n", w.String())
+}
+
+func Test_Logger_Caller(t *testing.T) {
+	w := new(bytes.Buffer)
+	l := slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true}))
+	New(l).Info("Hello")
+
+	assert.Contains(t, w.String(), "logger_test.go")
+}
@@ -0,0 +1,6 @@
+// Package slogflagslogrus provides a github.com/sirupsen/logrus hook that
+// forwards entries to slogflags, so that dependencies still using logrus log
+// with the output format, level and sinks controlled by the slogflags flags.
+//
+// It's a separate module so that slogflags itself doesn't depend on logrus.
+package slogflagslogrus
@@ -0,0 +1,126 @@
+package slogflagslogrus
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/csmith/slogflags"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a [logrus.Hook] that forwards entries and their fields to a
+// [log/slog.Handler]. It should be used with [Formatter], so that logrus
+// doesn't also write the entries itself.
+type Hook struct {
+	handler slog.Handler
+}
+
+var _ logrus.Hook = (*Hook)(nil)
+
+// NewHook creates a Hook that forwards entries to the given logger's handler,
+// or to [slogflags.L] if it is nil.
+//
+// The entry's caller is used as the record's source if the logrus logger
+// reports callers.
+func NewHook(logger *slog.Logger) *Hook {
+	if logger == nil {
+		logger = slogflags.L()
+	}
+	return &Hook{handler: logger.Handler()}
+}
+
+// Levels returns all logrus levels. Entries are filtered by the handler when
+// they're fired.
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire passes the entry to the handler as a record, if its level is enabled.
+// Panic and fatal entries flush any buffered output using [slogflags.Flush],
+// as they normally end the process.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	level := slogLevel(entry.Level)
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	var pc uintptr
+	if entry.Caller != nil {
+		pc = entry.Caller.PC
+	}
+
+	r := slog.NewRecord(entry.Time, level, entry.Message, pc)
+	for _, k := range slices.Sorted(maps.Keys(entry.Data)) {
+		r.AddAttrs(slog.Any(k, entry.Data[k]))
+	}
+	err := h.handler.Handle(ctx, r)
+
+	if entry.Level <= logrus.FatalLevel {
+		_ = slogflags.Flush()
+	}
+	return err
+}
+
+// Formatter is a [logrus.Formatter] that formats entries as nothing, so that
+// a logger using [Hook] doesn't write its entries twice.
+type Formatter struct{}
+
+var _ logrus.Formatter = Formatter{}
+
+// Format returns no output.
+func (Formatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+// Install configures a logrus logger to forward its entries to the given slog
+// logger, or to [slogflags.L] if it is nil:
+//
+//	slogflagslogrus.Install(logrus.StandardLogger(), nil)
+//
+// It adds a [Hook], sets the formatter to [Formatter], discards the logger's
+// own output, and sets its level to the lowest level enabled by the slog
+// logger, so that the `log.level` flag controls which entries logrus creates.
+func Install(l *logrus.Logger, logger *slog.Logger) {
+	hook := NewHook(logger)
+	l.AddHook(hook)
+	l.SetFormatter(Formatter{})
+	l.SetOutput(io.Discard)
+	l.SetLevel(hook.minLevel())
+}
+
+// minLevel returns the most verbose logrus level enabled by the handler.
+func (h *Hook) minLevel() logrus.Level {
+	for _, level := range slices.Backward(logrus.AllLevels) {
+		if h.handler.Enabled(context.Background(), slogLevel(level)) {
+			return level
+		}
+	}
+	return logrus.PanicLevel
+}
+
+// slogLevel maps a logrus level to the equivalent slog level. Panics are
+// logged at [slogflags.LevelFatal], as they normally end the process.
+func slogLevel(level logrus.Level) slog.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return slog.LevelDebug - 4
+	case logrus.DebugLevel:
+		return slog.LevelDebug
+	case logrus.InfoLevel:
+		return slog.LevelInfo
+	case logrus.WarnLevel:
+		return slog.LevelWarn
+	case logrus.ErrorLevel:
+		return slog.LevelError
+	default:
+		return slogflags.LevelFatal
+	}
+}
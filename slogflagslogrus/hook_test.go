@@ -0,0 +1,83 @@
+package slogflagslogrus
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(w *bytes.Buffer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func Test_Hook_ForwardsEntries(t *testing.T) {
+	w := new(bytes.Buffer)
+	l := logrus.New()
+	Install(l, newTestLogger(w, slog.LevelDebug))
+
+	entry := l.WithField("component", "cache")
+	entry.Debug("Looking up")
+	entry.WithError(errors.New("miss")).WithField("attempt", 2).Warn("Lookup failed")
+	entry.Trace("Hidden")
+
+	assert.Equal(t, ""+
+		"level=DEBUG msg=\"Looking up\" component=cache\n"+
+		"level=WARN msg=\"Lookup failed\" attempt=2 component=cache error=miss\n", w.String())
+}
+
+func Test_Hook_FiltersByHandlerLevel(t *testing.T) {
+	w := new(bytes.Buffer)
+	l := logrus.New()
+	l.SetOutput(new(bytes.Buffer))
+	l.SetLevel(logrus.TraceLevel)
+	l.AddHook(NewHook(newTestLogger(w, slog.LevelWarn)))
+
+	l.Info("Hidden")
+	l.Warn("Shown")
+
+	assert.Equal(t, "level=WARN msg=Shown\n", w.String())
+}
+
+func Test_Install_SetsLevel(t *testing.T) {
+	l := logrus.New()
+	Install(l, newTestLogger(new(bytes.Buffer), slog.LevelWarn))
+	assert.Equal(t, logrus.WarnLevel, l.GetLevel())
+
+	l = logrus.New()
+	Install(l, newTestLogger(new(bytes.Buffer), slog.LevelDebug-4))
+	assert.Equal(t, logrus.TraceLevel, l.GetLevel())
+}
+
+func Test_Formatter(t *testing.T) {
+	out := new(bytes.Buffer)
+	l := logrus.New()
+	l.SetOutput(out)
+	l.SetFormatter(Formatter{})
+
+	l.Info("Hello")
+
+	assert.Empty(t, out.String())
+}
+
+func Test_Hook_Caller(t *testing.T) {
+	w := new(bytes.Buffer)
+	l := logrus.New()
+	l.SetReportCaller(true)
+	Install(l, slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true})))
+
+	l.Info("Hello")
+
+	assert.Contains(t, w.String(), "hook_test.go")
+}
@@ -0,0 +1,184 @@
+package slogflagstest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that causes [AssertGolden] to
+// write golden files instead of comparing against them, e.g.
+// `SLOGFLAGSTEST_UPDATE=1 go test ./...`.
+const UpdateGoldenEnv = "SLOGFLAGSTEST_UPDATE"
+
+// NormalizeJSON normalizes log output in the JSON format, so that it can be
+// compared against a golden file. Each record is re-encoded with its keys
+// sorted, and its time and source removed, as these change between runs and
+// refactors. Blank lines are ignored.
+func NormalizeJSON(output []byte) ([]byte, error) {
+	var res bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		dec.UseNumber()
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		delete(record, slog.TimeKey)
+		delete(record, slog.SourceKey)
+
+		b, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		res.Write(b)
+		res.WriteByte('\n')
+	}
+	return res.Bytes(), scanner.Err()
+}
+
+// Golden returns the entries in a normalized form suitable for comparing
+// against a golden file: one JSON object per line, with sorted keys, and
+// without the time.
+func (e Entries) Golden() []byte {
+	var res bytes.Buffer
+	for _, entry := range e {
+		record := map[string]any{
+			slog.LevelKey:   entry.Level.String(),
+			slog.MessageKey: entry.Message,
+		}
+		for _, a := range entry.Attrs {
+			addAttr(record, a)
+		}
+		b, err := json.Marshal(record)
+		if err != nil {
+			b, _ = json.Marshal(map[string]string{"error": err.Error()})
+		}
+		res.Write(b)
+		res.WriteByte('\n')
+	}
+	return res.Bytes()
+}
+
+// addAttr adds the resolved value of a to m, with groups as nested maps.
+func addAttr(m map[string]any, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		if a.Key != "" {
+			if err, ok := v.Any().(error); ok {
+				m[a.Key] = err.Error()
+			} else {
+				m[a.Key] = v.Any()
+			}
+		}
+		return
+	}
+
+	target := m
+	if a.Key != "" {
+		target = map[string]any{}
+		m[a.Key] = target
+	}
+	for _, ga := range v.Group() {
+		addAttr(target, ga)
+	}
+}
+
+// AssertGolden compares got against the contents of the golden file at path,
+// reporting a test failure with a line-by-line diff if they differ. If the
+// [UpdateGoldenEnv] environment variable is set, the golden file is written
+// with got instead.
+func AssertGolden(t testing.TB, path string, got []byte) bool {
+	t.Helper()
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Errorf("unable to create directory for golden file: %v", err)
+			return false
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Errorf("unable to write golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("unable to read golden file (set %s=1 to create it): %v", UpdateGoldenEnv, err)
+		return false
+	}
+	if bytes.Equal(want, got) {
+		return true
+	}
+
+	t.Errorf("output does not match golden file %s (set %s=1 to update it):\n%s", path, UpdateGoldenEnv, diffLines(string(want), string(got)))
+	return false
+}
+
+// diffLines returns a diff between two texts, with removed lines prefixed by
+// "-", added lines by "+", and unchanged lines by a space.
+func diffLines(a, b string) string {
+	x := strings.SplitAfter(a, "\n")
+	y := strings.SplitAfter(b, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var res strings.Builder
+	line := func(prefix, s string) {
+		if s == "" {
+			return
+		}
+		res.WriteString(prefix)
+		res.WriteString(strings.TrimSuffix(s, "\n"))
+		res.WriteByte('\n')
+	}
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			line(" ", x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			line("-", x[i])
+			i++
+		default:
+			line("+", y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		line("-", x[i])
+	}
+	for ; j < len(y); j++ {
+		line("+", y[j])
+	}
+	return res.String()
+}
@@ -0,0 +1,63 @@
+package slogflagstest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/csmith/slogflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Entries_Golden(t *testing.T) {
+	l, o := newObservedLogger(t)
+	l.With("user", "alice").WithGroup("req").Info("Request handled", "status", 200, "path", "/")
+	l.Error("Failed", "error", errors.New("boom"))
+
+	AssertGolden(t, filepath.Join("testdata", "entries.golden"), o.Entries().Golden())
+}
+
+func Test_NormalizeJSON(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := slogflags.Logger(slogflags.WithWriter(w), slogflags.WithAddSource(true))
+	l.Info("Hello", "zebra", 1, "apple", 2.5, slog.Group("g", "b", true, "a", nil))
+
+	got, err := NormalizeJSON(w.Bytes())
+	require.NoError(t, err)
+	AssertGolden(t, filepath.Join("testdata", "normalize.golden"), got)
+
+	_, err = NormalizeJSON([]byte("{}\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2:")
+}
+
+func Test_AssertGolden_Mismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.golden")
+	require.NoError(t, os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o644))
+
+	tb := &fakeTB{}
+	assert.True(t, AssertGolden(tb, path, []byte("one\ntwo\nthree\n")))
+	assert.False(t, AssertGolden(tb, path, []byte("one\n2\nthree\nfour\n")))
+	assert.Equal(t, []string{
+		"output does not match golden file " + path + " (set SLOGFLAGSTEST_UPDATE=1 to update it):\n" +
+			" one\n-two\n+2\n three\n+four\n",
+	}, tb.errors)
+}
+
+func Test_AssertGolden_Update(t *testing.T) {
+	t.Setenv(UpdateGoldenEnv, "1")
+	path := filepath.Join(t.TempDir(), "new", "test.golden")
+
+	assert.True(t, AssertGolden(t, path, []byte("content\n")))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "content\n", string(b))
+}
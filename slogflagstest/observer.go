@@ -0,0 +1,241 @@
+// Package slogflagstest provides helpers for testing code that logs using
+// loggers created by [slogflags.Logger].
+//
+// An [Observer] captures records in memory, so that tests can check what was
+// logged:
+//
+//	func TestSomething(t *testing.T) {
+//		observer := slogflagstest.NewObserver()
+//		logger := slogflags.Logger(slogflagstest.WithObserver(observer))
+//		...
+//		observer.AssertLogged(t, slog.LevelWarn, "retrying")
+//		assert.Equal(t, 1, observer.Entries().FilterAttr("attempt").Len())
+//	}
+//
+// Logging output can also be snapshot tested using [AssertGolden], with
+// records normalized by [Entries.Golden] or [NormalizeJSON].
+package slogflagstest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/csmith/slogflags"
+)
+
+// Entry is a record captured by an [Observer].
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+
+	// Attrs are the record's attributes, including any added to the logger
+	// using [log/slog.Logger.With] or [log/slog.Logger.WithGroup].
+	Attrs []slog.Attr
+}
+
+// Attr returns the resolved value of the attribute with the given key.
+// Attributes in groups can be found by separating the group names and key
+// with dots, e.g. "request.id".
+func (e Entry) Attr(key string) (slog.Value, bool) {
+	return findAttr(e.Attrs, key)
+}
+
+// findAttr finds the attribute with the given dotted path within attrs.
+func findAttr(attrs []slog.Attr, key string) (slog.Value, bool) {
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if a.Key == key {
+			return v, true
+		}
+		if v.Kind() != slog.KindGroup {
+			continue
+		}
+		if a.Key == "" {
+			if v, ok := findAttr(v.Group(), key); ok {
+				return v, true
+			}
+		} else if rest, ok := strings.CutPrefix(key, a.Key+"."); ok {
+			if v, ok := findAttr(v.Group(), rest); ok {
+				return v, true
+			}
+		}
+	}
+	return slog.Value{}, false
+}
+
+// String formats the entry for use in test failures.
+func (e Entry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q", e.Level, e.Message)
+	for _, a := range e.Attrs {
+		fmt.Fprintf(&b, " %s", a)
+	}
+	return b.String()
+}
+
+// Entries is a list of captured records, which can be filtered.
+type Entries []Entry
+
+// Len returns the number of entries.
+func (e Entries) Len() int {
+	return len(e)
+}
+
+// String formats the entries one per line, for use in test failures.
+func (e Entries) String() string {
+	var b strings.Builder
+	for _, entry := range e {
+		b.WriteString("\n\t")
+		b.WriteString(entry.String())
+	}
+	return b.String()
+}
+
+// Filter returns the entries for which fn returns true.
+func (e Entries) Filter(fn func(Entry) bool) Entries {
+	var res Entries
+	for _, entry := range e {
+		if fn(entry) {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+// FilterLevel returns the entries at or above the given level.
+func (e Entries) FilterLevel(level slog.Level) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.Level >= level
+	})
+}
+
+// FilterLevelExact returns the entries at exactly the given level.
+func (e Entries) FilterLevelExact(level slog.Level) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.Level == level
+	})
+}
+
+// FilterMessage returns the entries with exactly the given message.
+func (e Entries) FilterMessage(msg string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.Message == msg
+	})
+}
+
+// FilterMessageSnippet returns the entries whose message contains the given
+// text.
+func (e Entries) FilterMessageSnippet(snippet string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return strings.Contains(entry.Message, snippet)
+	})
+}
+
+// FilterAttr returns the entries that have an attribute with the given key,
+// as described in [Entry.Attr].
+func (e Entries) FilterAttr(key string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		_, ok := entry.Attr(key)
+		return ok
+	})
+}
+
+// FilterAttrValue returns the entries that have an attribute with the given
+// key and value. Values are compared using [log/slog.Value.Equal].
+func (e Entries) FilterAttrValue(key string, value any) Entries {
+	want := slog.AnyValue(value)
+	return e.Filter(func(entry Entry) bool {
+		v, ok := entry.Attr(key)
+		return ok && v.Equal(want)
+	})
+}
+
+// Observer is a [slogflags.Sink] that captures records in memory.
+type Observer struct {
+	mu      sync.Mutex
+	entries Entries
+}
+
+// NewObserver creates a new, empty, [Observer].
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// WithObserver returns an option that captures every record written by the
+// logger in the observer. Records below the log level aren't captured.
+func WithObserver(o *Observer) slogflags.Option {
+	return slogflags.WithSink(o)
+}
+
+func (o *Observer) Write(_ context.Context, r slog.Record) error {
+	entry := Entry{Time: r.Time, Level: r.Level, Message: r.Message}
+	r.Attrs(func(a slog.Attr) bool {
+		entry.Attrs = append(entry.Attrs, a)
+		return true
+	})
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = append(o.entries, entry)
+	return nil
+}
+
+// Close does nothing; records can still be queried after the observer has
+// been closed.
+func (o *Observer) Close() error {
+	return nil
+}
+
+// Entries returns all the records captured so far.
+func (o *Observer) Entries() Entries {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append(Entries(nil), o.entries...)
+}
+
+// TakeAll returns all the records captured so far, and clears them.
+func (o *Observer) TakeAll() Entries {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	res := o.entries
+	o.entries = nil
+	return res
+}
+
+// Len returns the number of records captured so far.
+func (o *Observer) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// AssertLogged reports a test failure unless a record was captured at the
+// given level with a message containing the snippet.
+func (o *Observer) AssertLogged(t testing.TB, level slog.Level, snippet string) bool {
+	t.Helper()
+	entries := o.Entries()
+	if entries.FilterLevelExact(level).FilterMessageSnippet(snippet).Len() > 0 {
+		return true
+	}
+	t.Errorf("no %s record with message containing %q was logged; got:%s", level, snippet, entries)
+	return false
+}
+
+// AssertNotLogged reports a test failure if a record was captured at the
+// given level or above with a message containing the snippet. An empty
+// snippet matches all messages.
+func (o *Observer) AssertNotLogged(t testing.TB, level slog.Level, snippet string) bool {
+	t.Helper()
+	matches := o.Entries().FilterLevel(level).FilterMessageSnippet(snippet)
+	if matches.Len() == 0 {
+		return true
+	}
+	t.Errorf("unexpected records at or above %s with message containing %q:%s", level, snippet, matches)
+	return false
+}
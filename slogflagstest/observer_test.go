@@ -0,0 +1,81 @@
+package slogflagstest
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/csmith/slogflags"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func newObservedLogger(t *testing.T) (*slog.Logger, *Observer) {
+	_ = flag.Set("log.level", "debug")
+	t.Cleanup(func() { _ = flag.Set("log.level", "") })
+
+	o := NewObserver()
+	return slogflags.Logger(slogflags.WithWriter(io.Discard), WithObserver(o)), o
+}
+
+func Test_Observer_CapturesRecords(t *testing.T) {
+	l, o := newObservedLogger(t)
+	l.With("user", "alice").WithGroup("req").Info("Request handled", "status", 200)
+	l.Debug("Cache miss", "key", "k1")
+	l.Warn("Retrying request", "attempt", 2)
+
+	entries := o.Entries()
+	assert.Equal(t, 3, entries.Len())
+	assert.Equal(t, "Request handled", entries[0].Message)
+
+	status, ok := entries[0].Attr("req.status")
+	assert.True(t, ok)
+	assert.Equal(t, int64(200), status.Int64())
+
+	assert.Equal(t, 2, entries.FilterLevel(slog.LevelInfo).Len())
+	assert.Equal(t, 1, entries.FilterLevelExact(slog.LevelDebug).Len())
+	assert.Equal(t, 1, entries.FilterMessage("Cache miss").Len())
+	assert.Equal(t, 2, entries.FilterMessageSnippet("equest").Len())
+	assert.Equal(t, 1, entries.FilterAttr("user").Len())
+	assert.Equal(t, 1, entries.FilterAttrValue("attempt", 2).Len())
+	assert.Equal(t, 0, entries.FilterAttrValue("attempt", 3).Len())
+}
+
+func Test_Observer_TakeAll(t *testing.T) {
+	l, o := newObservedLogger(t)
+	l.Info("One")
+	l.Info("Two")
+
+	assert.Equal(t, 2, o.TakeAll().Len())
+	assert.Equal(t, 0, o.Len())
+}
+
+func Test_Observer_Assertions(t *testing.T) {
+	l, o := newObservedLogger(t)
+	l.Warn("Retrying request", "attempt", 2)
+
+	tb := &fakeTB{}
+	assert.True(t, o.AssertLogged(tb, slog.LevelWarn, "Retrying"))
+	assert.True(t, o.AssertNotLogged(tb, slog.LevelError, ""))
+	assert.Empty(t, tb.errors)
+
+	assert.False(t, o.AssertLogged(tb, slog.LevelError, "Retrying"))
+	assert.False(t, o.AssertNotLogged(tb, slog.LevelInfo, "request"))
+	assert.Equal(t, []string{
+		"no ERROR record with message containing \"Retrying\" was logged; got:\n\tWARN \"Retrying request\" attempt=2",
+		"unexpected records at or above INFO with message containing \"request\":\n\tWARN \"Retrying request\" attempt=2",
+	}, tb.errors)
+}
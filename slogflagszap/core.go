@@ -0,0 +1,167 @@
+package slogflagszap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/csmith/slogflags"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core is a [zapcore.Core] that forwards entries and their fields to a
+// [log/slog.Handler], rather than encoding them itself. Whether an entry is
+// enabled is decided by the handler, so the `log.level` flag controls which
+// entries zap creates.
+type Core struct {
+	handler slog.Handler
+}
+
+var _ zapcore.Core = (*Core)(nil)
+
+// NewCore creates a Core that forwards entries to the given logger's handler,
+// or to [slogflags.L] if it is nil:
+//
+//	logger := zap.New(slogflagszap.NewCore(nil), zap.AddCaller())
+//
+// The logger's name is added as a "logger" attribute, and stack traces as a
+// "stack" attribute. Source locations are included if [zap.AddCaller] is
+// used and the slogflags logger adds sources.
+func NewCore(logger *slog.Logger) *Core {
+	if logger == nil {
+		logger = slogflags.L()
+	}
+	return &Core{handler: logger.Handler()}
+}
+
+// Enabled reports whether the handler is enabled for the equivalent slog
+// level.
+func (c *Core) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+// With returns a Core that adds the fields to every entry.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	attrs := fieldAttrs(fields)
+	if len(attrs) == 0 {
+		return c
+	}
+	return &Core{handler: c.handler.WithAttrs(attrs)}
+}
+
+// Check adds the Core to the checked entry if the entry's level is enabled.
+func (c *Core) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write passes the entry to the handler as a record.
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var pc uintptr
+	if entry.Caller.Defined {
+		pc = entry.Caller.PC
+	}
+
+	r := slog.NewRecord(entry.Time, slogLevel(entry.Level), entry.Message, pc)
+	if entry.LoggerName != "" {
+		r.AddAttrs(slog.String("logger", entry.LoggerName))
+	}
+	r.AddAttrs(fieldAttrs(fields)...)
+	if entry.Stack != "" {
+		r.AddAttrs(slog.String("stack", entry.Stack))
+	}
+	return c.handler.Handle(context.Background(), r)
+}
+
+// Sync flushes any buffered output, using [slogflags.Flush].
+func (c *Core) Sync() error {
+	return slogflags.Flush()
+}
+
+// slogLevel maps a zap level to the equivalent slog level. Panics are logged
+// at [slogflags.LevelFatal], as they normally end the process.
+func slogLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level < zapcore.DebugLevel:
+		return slog.LevelDebug - slog.Level(zapcore.DebugLevel-level)
+	case level == zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	case level == zapcore.ErrorLevel, level == zapcore.DPanicLevel:
+		return slog.LevelError
+	default:
+		return slogflags.LevelFatal
+	}
+}
+
+// fieldAttrs converts zap fields to attributes. Fields following a namespace
+// field are added to a group with the namespace's name.
+func fieldAttrs(fields []zapcore.Field) []slog.Attr {
+	var attrs []slog.Attr
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.SkipType:
+		case zapcore.NamespaceType:
+			return append(attrs, slog.Attr{Key: f.Key, Value: slog.GroupValue(fieldAttrs(fields[i+1:])...)})
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok {
+				attrs = append(attrs, slog.Any(f.Key, err))
+				continue
+			}
+			attrs = append(attrs, encodedAttrs(f)...)
+		case zapcore.DurationType:
+			attrs = append(attrs, slog.Duration(f.Key, time.Duration(f.Integer)))
+		case zapcore.StringerType:
+			attrs = append(attrs, slog.String(f.Key, stringer(f.Interface)))
+		default:
+			attrs = append(attrs, encodedAttrs(f)...)
+		}
+	}
+	return attrs
+}
+
+// encodedAttrs converts a field to attributes using zap's map encoder, which
+// handles every field type.
+func encodedAttrs(f zapcore.Field) []slog.Attr {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return mapAttrs(enc.Fields)
+}
+
+// mapAttrs converts the values produced by zap's map encoder to attributes,
+// sorted by key.
+func mapAttrs(m map[string]any) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		attrs = append(attrs, slog.Attr{Key: k, Value: value(m[k])})
+	}
+	return attrs
+}
+
+// value converts a value produced by zap's map encoder to a slog value.
+// Objects become groups.
+func value(v any) slog.Value {
+	if m, ok := v.(map[string]any); ok {
+		return slog.GroupValue(mapAttrs(m)...)
+	}
+	return slog.AnyValue(v)
+}
+
+// stringer calls String on v, recovering from panics caused by nil
+// receivers in the same way as zap.
+func stringer(v any) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("PANIC=%v", r)
+		}
+	}()
+	return v.(fmt.Stringer).String()
+}
@@ -0,0 +1,72 @@
+package slogflagszap
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestLogger(w *bytes.Buffer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func Test_Core_ForwardsEntries(t *testing.T) {
+	w := new(bytes.Buffer)
+	logger := zap.New(NewCore(newTestLogger(w, slog.LevelDebug))).Named("db").With(zap.String("component", "pool"))
+
+	logger.Debug("Connecting", zap.Int("attempt", 2), zap.Duration("timeout", 3*time.Second))
+	logger.Warn("Slow query", zap.Namespace("query"), zap.String("table", "widgets"), zap.Bool("cached", false))
+	logger.Error("Failed", zap.Error(errors.New("connection refused")), zap.Strings("hosts", []string{"a", "b"}))
+
+	assert.Equal(t, ""+
+		"level=DEBUG msg=Connecting component=pool logger=db attempt=2 timeout=3s\n"+
+		"level=WARN msg=\"Slow query\" component=pool logger=db query.table=widgets query.cached=false\n"+
+		"level=ERROR msg=Failed component=pool logger=db error=\"connection refused\" hosts=\"[a b]\"\n", w.String())
+}
+
+func Test_Core_UsesHandlerLevel(t *testing.T) {
+	w := new(bytes.Buffer)
+	core := NewCore(newTestLogger(w, slog.LevelWarn))
+
+	assert.False(t, core.Enabled(zapcore.InfoLevel))
+	assert.True(t, core.Enabled(zapcore.WarnLevel))
+
+	logger := zap.New(core)
+	assert.Nil(t, logger.Check(zapcore.InfoLevel, "Hidden"))
+	logger.Info("Hidden")
+	logger.Warn("Shown")
+
+	assert.Equal(t, "level=WARN msg=Shown\n", w.String())
+}
+
+func Test_Core_Caller(t *testing.T) {
+	w := new(bytes.Buffer)
+	l := slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true}))
+	zap.New(NewCore(l), zap.AddCaller()).Info("Hello")
+
+	assert.Contains(t, w.String(), `"file":`)
+	assert.Contains(t, w.String(), "core_test.go")
+}
+
+func Test_SlogLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug-1, slogLevel(zapcore.DebugLevel-1))
+	assert.Equal(t, slog.LevelDebug, slogLevel(zapcore.DebugLevel))
+	assert.Equal(t, slog.LevelInfo, slogLevel(zapcore.InfoLevel))
+	assert.Equal(t, slog.LevelWarn, slogLevel(zapcore.WarnLevel))
+	assert.Equal(t, slog.LevelError, slogLevel(zapcore.DPanicLevel))
+	assert.Equal(t, slog.LevelError+4, slogLevel(zapcore.FatalLevel))
+}
@@ -0,0 +1,6 @@
+// Package slogflagszap provides a go.uber.org/zap core that forwards entries
+// to slogflags, so that code using zap can be migrated gradually while the
+// output format, level and sinks are controlled by the slogflags flags.
+//
+// It's a separate module so that slogflags itself doesn't depend on zap.
+package slogflagszap
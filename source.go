@@ -0,0 +1,59 @@
+package slogflags
+
+import (
+	"log/slog"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// trimSource returns a copy of the source with the configured prefix removed
+// from its file name.
+func (c *config) trimSource(src *slog.Source) *slog.Source {
+	if c.sourceTrimPrefix == "" {
+		return src
+	}
+	file, ok := strings.CutPrefix(src.File, c.sourceTrimPrefix)
+	if !ok {
+		return src
+	}
+	res := *src
+	res.File = file
+	return &res
+}
+
+// moduleRoot returns the directory containing the main module, with a
+// trailing slash, so it can be trimmed from source file names. When built
+// with -trimpath this is just the module path. Otherwise it's found using
+// the location of main.main, which must be on the calling goroutine's stack.
+// Returns an empty string if the root can't be determined.
+func moduleRoot() string {
+	info, ok := readBuildInfo()
+	if !ok || info.Main.Path == "" {
+		return ""
+	}
+
+	for _, s := range info.Settings {
+		if s.Key == "-trimpath" && s.Value == "true" {
+			return info.Main.Path + "/"
+		}
+	}
+
+	// The main package's directory, relative to the module root.
+	rel := strings.TrimPrefix(info.Path, info.Main.Path)
+
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "main.main" {
+			if root, ok := strings.CutSuffix(path.Dir(frame.File), rel); ok {
+				return root + "/"
+			}
+			return ""
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithSourceTrimPrefix_JSON(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	_, file, _, _ := runtime.Caller(0)
+	w := new(bytes.Buffer)
+	l := Logger(WithWriter(w), WithAddSource(true), WithSourceTrimPrefix(filepath.Dir(file)+"/"))
+	l.Info("Hello")
+
+	assert.Contains(t, w.String(), `"file":"source_test.go"`)
+}
+
+func Test_WithSourceTrimPrefix_Text(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	_, file, _, _ := runtime.Caller(0)
+	w := new(bytes.Buffer)
+	l := Logger(WithWriter(w), WithAddSource(true), WithSourceTrimPrefix(filepath.Dir(file)+"/"))
+	l.Info("Hello")
+
+	assert.Regexp(t, ` source=source_test.go:\d+ `, w.String())
+}
+
+func Test_WithSourceTrimPrefix_Console(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	c := newConfig([]Option{WithSourceTrimPrefix(filepath.Dir(file) + "/")})
+	w := new(bytes.Buffer)
+	l := slog.New(newConsoleHandler(w, &slog.HandlerOptions{AddSource: true, ReplaceAttr: c.levelReplaceAttr}))
+	l.Info("Hello")
+
+	assert.Contains(t, w.String(), " "+ansiFaint+"source_test.go:")
+	assert.NotContains(t, w.String(), file)
+}
+
+func Test_WithSourceTrimPrefix_NoMatch(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	_, file, _, _ := runtime.Caller(0)
+	w := new(bytes.Buffer)
+	l := Logger(WithWriter(w), WithAddSource(true), WithSourceTrimPrefix("/nowhere/"))
+	l.Info("Hello")
+
+	assert.Contains(t, w.String(), `"file":"`+file+`"`)
+}
+
+func Test_WithSourceTrimPrefix_ReplaceAttr(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	_, file, _, _ := runtime.Caller(0)
+	var seen string
+	w := new(bytes.Buffer)
+	l := Logger(
+		WithWriter(w),
+		WithAddSource(true),
+		WithSourceTrimPrefix(filepath.Dir(file)+"/"),
+		WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+			if src, ok := a.Value.Any().(*slog.Source); ok {
+				seen = src.File
+			}
+			return a
+		}),
+	)
+	l.Info("Hello")
+
+	assert.Equal(t, "source_test.go", seen)
+}
+
+func Test_moduleRoot_NoBuildInfo(t *testing.T) {
+	fakeBuildInfo(t, nil)
+	assert.Equal(t, "", moduleRoot())
+}
+
+func Test_moduleRoot_TrimPath(t *testing.T) {
+	fakeBuildInfo(t, &debug.BuildInfo{
+		Path:     "example.com/app/cmd/app",
+		Main:     debug.Module{Path: "example.com/app"},
+		Settings: []debug.BuildSetting{{Key: "-trimpath", Value: "true"}},
+	})
+	assert.Equal(t, "example.com/app/", moduleRoot())
+}
+
+func Test_moduleRoot_NotMainGoroutine(t *testing.T) {
+	fakeBuildInfo(t, &debug.BuildInfo{Path: "example.com/app/cmd/app", Main: debug.Module{Path: "example.com/app"}})
+	assert.Equal(t, "", moduleRoot())
+}
@@ -0,0 +1,455 @@
+package slogflags
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// spillFileName is the name of the file spilled records are appended to.
+	spillFileName = "spill.wal"
+
+	// spillOffsetFileName is the name of the file that records how much of
+	// the spill file has been replayed.
+	spillOffsetFileName = "spill.offset"
+
+	// spillReplayBatch is the number of records replayed before the wrapped
+	// sink is flushed and progress is recorded.
+	spillReplayBatch = 100
+)
+
+// SpillConfig configures a [SpillSink].
+type SpillConfig struct {
+	// Dir is the directory spilled records are stored in. It will be created
+	// if it does not exist, and must not be shared with another SpillSink.
+	Dir string
+
+	// MaxBytes is the maximum amount of disk space used for spilled records.
+	// Records that would exceed it are dropped. Defaults to 100 MiB.
+	MaxBytes int64
+
+	// RetryInterval is how often delivery to the wrapped sink is retried
+	// while records are being spilled. Defaults to 10 seconds.
+	RetryInterval time.Duration
+}
+
+// SpillSink wraps another [Sink], and spills records to an append-only file
+// on disk if the wrapped sink fails. Spilled records are replayed in order
+// once the wrapped sink recovers, and any records written in the meantime are
+// spilled behind them to preserve ordering. Records left on disk when the
+// process exits are replayed the next time a SpillSink is created with the
+// same directory.
+//
+// Only records the wrapped sink rejects are spilled. Errors wrapped in a
+// [BatchError] relate to records the sink had already accepted, so they are
+// returned without spilling anything. Records that were already buffered by
+// the wrapped sink when it started failing are subject to its own retry
+// policy, and may be lost.
+//
+// If the wrapped sink implements [Flusher], it is flushed after each batch of
+// replayed records to confirm they were delivered. Delivery is at-least-once:
+// records may be replayed more than once if the sink fails part way through a
+// batch.
+type SpillSink struct {
+	sink   Sink
+	config SpillConfig
+	path   string
+
+	// replayMu ensures only one replay happens at a time.
+	replayMu sync.Mutex
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	spilling bool
+	dropped  int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSpillSink creates a new [SpillSink] wrapping the given sink. If the
+// directory contains records spilled previously, they will be replayed.
+func NewSpillSink(sink Sink, config SpillConfig) (*SpillSink, error) {
+	if config.Dir == "" {
+		return nil, errors.New("spill: no directory specified")
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 100 << 20
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 10 * time.Second
+	}
+
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("spill: unable to create directory: %w", err)
+	}
+
+	s := &SpillSink{
+		sink:   sink,
+		config: config,
+		path:   filepath.Join(config.Dir, spillFileName),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("spill: unable to open spill file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("spill: unable to open spill file: %w", err)
+	}
+
+	s.file = f
+	s.size = info.Size()
+	s.spilling = s.size > s.offset()
+
+	go s.run()
+	return s, nil
+}
+
+func (s *SpillSink) Write(ctx context.Context, r slog.Record) error {
+	s.mu.Lock()
+	spilling := s.spilling
+	s.mu.Unlock()
+
+	if !spilling {
+		err := s.sink.Write(ctx, r)
+		if err == nil || isBatchError(err) {
+			return err
+		}
+		return errors.Join(err, s.spill(ctx, r))
+	}
+
+	return s.spill(ctx, r)
+}
+
+// Flush attempts to replay any spilled records, and flushes the wrapped sink
+// if it implements [Flusher].
+func (s *SpillSink) Flush() error {
+	err := s.replay()
+	if f, ok := s.sink.(Flusher); ok && err == nil {
+		err = f.Flush()
+	}
+	return err
+}
+
+// Close makes a final attempt to replay any spilled records, then closes the
+// wrapped sink. Records that could not be replayed are left on disk.
+func (s *SpillSink) Close() error {
+	close(s.stop)
+	<-s.done
+
+	err := s.replay()
+
+	s.mu.Lock()
+	err = errors.Join(err, s.file.Close())
+	s.mu.Unlock()
+
+	return errors.Join(err, s.sink.Close())
+}
+
+func (s *SpillSink) run() {
+	defer close(s.done)
+
+	t := time.NewTicker(s.config.RetryInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+			_ = s.replay()
+		}
+	}
+}
+
+// isBatchError reports whether err only relates to records that were
+// written before the current one, meaning the current record was accepted.
+func isBatchError(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if !isBatchError(e) {
+				return false
+			}
+		}
+		return true
+	}
+	var batchErr *BatchError
+	return errors.As(err, &batchErr)
+}
+
+// isSpilling reports whether records are currently being spilled to disk.
+func (s *SpillSink) isSpilling() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spilling
+}
+
+// spill appends the record to the spill file, and marks the sink as
+// spilling. Both happen under the same lock, so a concurrent replay that has
+// just emptied the file can't leave the record behind.
+func (s *SpillSink) spill(ctx context.Context, r slog.Record) error {
+	line, err := json.Marshal(spillRecordFrom(ctx, r))
+	if err != nil {
+		return fmt.Errorf("spill: unable to encode record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size+int64(len(line)) > s.config.MaxBytes {
+		s.dropped++
+		return fmt.Errorf("spill: spill file is full, dropped %d records", s.dropped)
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if n > 0 {
+		s.spilling = true
+	}
+	if err != nil {
+		return fmt.Errorf("spill: unable to write record: %w", err)
+	}
+	return nil
+}
+
+// replay writes spilled records to the wrapped sink, stopping at the first
+// error. Once all records have been replayed the spill file is truncated and
+// records are once again written directly to the wrapped sink.
+func (s *SpillSink) replay() error {
+	s.mu.Lock()
+	spilling := s.spilling
+	s.mu.Unlock()
+	if !spilling {
+		return nil
+	}
+
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	offset := s.offset()
+	for {
+		s.mu.Lock()
+		end := s.size
+		if offset >= end {
+			// Nothing has been spilled since we last checked, so it's safe to
+			// start writing to the sink directly again.
+			err := s.reset()
+			s.mu.Unlock()
+			return err
+		}
+		s.mu.Unlock()
+
+		n, replayErr := s.replayBatch(offset, end)
+		if n > 0 {
+			offset += n
+			if err := os.WriteFile(filepath.Join(s.config.Dir, spillOffsetFileName), []byte(strconv.FormatInt(offset, 10)), 0o600); err != nil {
+				return fmt.Errorf("spill: unable to record progress: %w", err)
+			}
+		}
+		if replayErr != nil {
+			return fmt.Errorf("spill: unable to replay records: %w", replayErr)
+		}
+	}
+}
+
+// replayBatch replays up to spillReplayBatch records from between the given
+// offsets, returning the number of bytes consumed. If the wrapped sink fails
+// part way through a batch, the records it accepted are only counted as
+// consumed if it doesn't buffer them.
+func (s *SpillSink) replayBatch(offset, end int64) (int64, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(io.NewSectionReader(f, offset, end-offset))
+	var consumed int64
+	for range spillReplayBatch {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Any partial line left by an interrupted write can't be parsed,
+			// so it is skipped.
+			consumed += int64(len(line))
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		consumed += int64(len(line))
+
+		sr, err := parseSpillRecord(line)
+		if err != nil {
+			// There's no point retrying a corrupt record, so skip over it.
+			continue
+		}
+		if err := s.sink.Write(sr.context(context.Background()), sr.record()); err != nil {
+			if _, ok := s.sink.(Flusher); ok {
+				return 0, err
+			}
+			return consumed - int64(len(line)), err
+		}
+	}
+
+	if f, ok := s.sink.(Flusher); ok {
+		if err := f.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return consumed, nil
+}
+
+// offset returns how much of the spill file has already been replayed.
+func (s *SpillSink) offset() int64 {
+	b, err := os.ReadFile(filepath.Join(s.config.Dir, spillOffsetFileName))
+	if err != nil {
+		return 0
+	}
+	offset, _ := strconv.ParseInt(string(b), 10, 64)
+	return offset
+}
+
+// reset truncates the spill file once everything has been replayed. It must
+// be called with the mutex held.
+func (s *SpillSink) reset() error {
+	s.spilling = false
+	s.dropped = 0
+	s.size = 0
+	if err := s.file.Truncate(0); err != nil {
+		return fmt.Errorf("spill: unable to truncate spill file: %w", err)
+	}
+	if err := os.Remove(filepath.Join(s.config.Dir, spillOffsetFileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("spill: unable to remove offset file: %w", err)
+	}
+	return nil
+}
+
+// spillRecord is the on-disk representation of a record. The name of the
+// level is only stored if it has a custom name.
+type spillRecord struct {
+	Time      time.Time   `json:"t"`
+	Level     slog.Level  `json:"l"`
+	LevelName string      `json:"n,omitempty"`
+	Message   string      `json:"m"`
+	Attrs     []spillAttr `json:"a,omitempty"`
+}
+
+// spillAttr is the on-disk representation of an attribute. The kind is stored
+// alongside the value so it can be restored faithfully.
+type spillAttr struct {
+	Key   string      `json:"k"`
+	Kind  slog.Kind   `json:"t"`
+	Value any         `json:"v,omitempty"`
+	Group []spillAttr `json:"g,omitempty"`
+}
+
+func spillRecordFrom(ctx context.Context, r slog.Record) spillRecord {
+	res := spillRecord{Time: r.Time, Level: r.Level, Message: r.Message}
+	if name := levelName(ctx, r.Level); name != r.Level.String() {
+		res.LevelName = name
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		res.Attrs = append(res.Attrs, spillAttrFrom(a))
+		return true
+	})
+	return res
+}
+
+func spillAttrFrom(a slog.Attr) spillAttr {
+	a.Value = a.Value.Resolve()
+	res := spillAttr{Key: a.Key, Kind: a.Value.Kind()}
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		for _, ga := range a.Value.Group() {
+			res.Group = append(res.Group, spillAttrFrom(ga))
+		}
+	case slog.KindDuration:
+		res.Value = a.Value.Duration().Nanoseconds()
+	default:
+		res.Value = jsonValue(a.Value)
+	}
+	return res
+}
+
+func parseSpillRecord(line []byte) (spillRecord, error) {
+	var sr spillRecord
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	err := d.Decode(&sr)
+	return sr, err
+}
+
+// record restores the record that was spilled.
+func (sr spillRecord) record() slog.Record {
+	r := slog.NewRecord(sr.Time, sr.Level, sr.Message, 0)
+	for _, a := range sr.Attrs {
+		r.AddAttrs(a.attr())
+	}
+	return r
+}
+
+// context returns a context carrying the custom name of the record's level,
+// if it had one.
+func (sr spillRecord) context(ctx context.Context) context.Context {
+	if sr.LevelName == "" {
+		return ctx
+	}
+	return withLevelNames(ctx, levelNames{sr.Level: sr.LevelName})
+}
+
+func (a spillAttr) attr() slog.Attr {
+	n, _ := a.Value.(json.Number)
+	switch a.Kind {
+	case slog.KindGroup:
+		attrs := make([]any, len(a.Group))
+		for i, ga := range a.Group {
+			attrs[i] = ga.attr()
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindString:
+		s, _ := a.Value.(string)
+		return slog.String(a.Key, s)
+	case slog.KindInt64:
+		i, _ := n.Int64()
+		return slog.Int64(a.Key, i)
+	case slog.KindUint64:
+		u, _ := strconv.ParseUint(n.String(), 10, 64)
+		return slog.Uint64(a.Key, u)
+	case slog.KindFloat64:
+		// Non-finite values are stored as strings.
+		f, _ := n.Float64()
+		if s, ok := a.Value.(string); ok {
+			f, _ = strconv.ParseFloat(s, 64)
+		}
+		return slog.Float64(a.Key, f)
+	case slog.KindBool:
+		b, _ := a.Value.(bool)
+		return slog.Bool(a.Key, b)
+	case slog.KindDuration:
+		i, _ := n.Int64()
+		return slog.Duration(a.Key, time.Duration(i))
+	case slog.KindTime:
+		s, _ := a.Value.(string)
+		t, _ := time.Parse(time.RFC3339Nano, s)
+		return slog.Time(a.Key, t)
+	default:
+		return slog.Any(a.Key, a.Value)
+	}
+}
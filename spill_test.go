@@ -0,0 +1,260 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySink is a buffering sink that fails to deliver records while down.
+type flakySink struct {
+	mu        sync.Mutex
+	down      bool
+	buffered  []slog.Record
+	delivered []string
+}
+
+func (f *flakySink) Write(_ context.Context, r slog.Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("sink is down")
+	}
+	f.buffered = append(f.buffered, r)
+	return nil
+}
+
+func (f *flakySink) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		f.buffered = nil
+		return errors.New("sink is down")
+	}
+	for _, r := range f.buffered {
+		f.delivered = append(f.delivered, r.Message)
+	}
+	f.buffered = nil
+	return nil
+}
+
+func (f *flakySink) Close() error {
+	return f.Flush()
+}
+
+func (f *flakySink) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *flakySink) messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.delivered...)
+}
+
+func Test_SpillSink_ReplaysInOrderAfterRecovery(t *testing.T) {
+	dir := t.TempDir()
+	inner := &flakySink{}
+	sink, err := NewSpillSink(inner, SpillConfig{Dir: dir, RetryInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("one")
+	require.NoError(t, sink.Flush())
+
+	inner.setDown(true)
+	l.Info("two")
+	l.Info("three")
+	require.Error(t, sink.Flush())
+	assert.Equal(t, []string{"one"}, inner.messages())
+
+	info, err := os.Stat(filepath.Join(dir, spillFileName))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Size())
+
+	inner.setDown(false)
+	assert.Eventually(t, func() bool {
+		return len(inner.messages()) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	l.Info("four")
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"one", "two", "three", "four"}, inner.messages())
+
+	info, err = os.Stat(filepath.Join(dir, spillFileName))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+// batchingSink sends records in the background using a batcher, failing to
+// deliver them while down.
+type batchingSink struct {
+	*flakySink
+	batcher *batcher[slog.Record]
+	sent    chan struct{}
+}
+
+func newBatchingSink() *batchingSink {
+	s := &batchingSink{flakySink: &flakySink{}, sent: make(chan struct{}, 10)}
+	s.batcher = newBatcher(1, time.Hour, s.send)
+	return s
+}
+
+func (s *batchingSink) send(records []slog.Record) error {
+	defer func() { s.sent <- struct{}{} }()
+	for _, r := range records {
+		if err := s.flakySink.Write(context.Background(), r); err != nil {
+			return err
+		}
+	}
+	return s.flakySink.Flush()
+}
+
+func (s *batchingSink) Write(_ context.Context, r slog.Record) error {
+	return s.batcher.add(r)
+}
+
+func (s *batchingSink) Flush() error {
+	return s.batcher.sync()
+}
+
+func (s *batchingSink) Close() error {
+	return s.batcher.close()
+}
+
+func Test_SpillSink_DoesNotSpillAcceptedRecordsForBatchErrors(t *testing.T) {
+	dir := t.TempDir()
+	inner := newBatchingSink()
+	sink, err := NewSpillSink(inner, SpillConfig{Dir: dir, RetryInterval: time.Hour})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	inner.setDown(true)
+	l.Info("one")
+	<-inner.sent
+
+	inner.setDown(false)
+	var batchErr *BatchError
+	require.ErrorAs(t, sink.Write(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "two", 0)), &batchErr)
+	assert.False(t, sink.isSpilling())
+
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"two"}, inner.messages())
+
+	info, err := os.Stat(filepath.Join(dir, spillFileName))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+func Test_SpillSink_ResumesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	inner := &flakySink{down: true}
+	sink, err := NewSpillSink(inner, SpillConfig{Dir: dir, RetryInterval: time.Hour})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("one")
+	l.Info("two")
+	require.Error(t, sink.Close())
+
+	inner = &flakySink{}
+	sink, err = NewSpillSink(inner, SpillConfig{Dir: dir, RetryInterval: time.Hour})
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"one", "two"}, inner.messages())
+}
+
+func Test_SpillSink_DropsRecordsOverLimit(t *testing.T) {
+	inner := &flakySink{down: true}
+	sink, err := NewSpillSink(inner, SpillConfig{Dir: t.TempDir(), MaxBytes: 100, RetryInterval: time.Hour})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "Test", 0)
+	require.Error(t, sink.Write(context.Background(), r))
+	assert.ErrorContains(t, sink.Write(context.Background(), r), "spill file is full, dropped 1 records")
+}
+
+func Test_SpillRecord_RoundTripsAttributes(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	r := slog.NewRecord(now, slog.LevelWarn+1, "Test", 0)
+	r.AddAttrs(
+		slog.String("s", "str"),
+		slog.Int("i", -4),
+		slog.Uint64("u", 1<<63),
+		slog.Float64("f", 1.5),
+		slog.Float64("nan", math.NaN()),
+		slog.Float64("inf", math.Inf(-1)),
+		slog.Bool("b", false),
+		slog.Duration("d", time.Minute),
+		slog.Time("t", now),
+		slog.Group("g", slog.Int("n", 1)),
+		slog.Any("a", []string{"x"}),
+	)
+
+	line, err := json.Marshal(spillRecordFrom(t.Context(), r))
+	require.NoError(t, err)
+	sr, err := parseSpillRecord(line)
+	require.NoError(t, err)
+	r2 := sr.record()
+	assert.Equal(t, now, r2.Time)
+	assert.Equal(t, slog.LevelWarn+1, r2.Level)
+	assert.Equal(t, "Test", r2.Message)
+
+	var got []string
+	r2.Attrs(func(a slog.Attr) bool {
+		got = append(got, a.String())
+		return true
+	})
+	assert.Equal(t, []string{
+		"s=str",
+		"i=-4",
+		"u=9223372036854775808",
+		"f=1.5",
+		"nan=NaN",
+		"inf=-Inf",
+		"b=false",
+		"d=1m0s",
+		"t=" + now.String(),
+		"g=[n=1]",
+		"a=[x]",
+	}, got)
+}
+
+func Test_SpillRecord_RoundTripsLevelNames(t *testing.T) {
+	ctx := withLevelNames(t.Context(), levelNames{LevelFatal: "FATAL"})
+
+	for _, level := range []slog.Level{slog.LevelInfo, LevelFatal} {
+		line, err := json.Marshal(spillRecordFrom(ctx, slog.NewRecord(time.Now(), level, "Test", 0)))
+		require.NoError(t, err)
+		sr, err := parseSpillRecord(line)
+		require.NoError(t, err)
+		assert.Equal(t, levelName(ctx, level), levelName(sr.context(t.Context()), level))
+	}
+}
+
+func Test_SpillSink_ReplaysRecordSpilledAfterReset(t *testing.T) {
+	inner := &flakySink{}
+	sink, err := NewSpillSink(inner, SpillConfig{Dir: t.TempDir(), RetryInterval: time.Hour})
+	require.NoError(t, err)
+
+	// Simulate a Write that saw the sink spilling, but only appended the
+	// record after a replay had emptied the file and reset the sink.
+	require.NoError(t, sink.spill(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "late", 0)))
+	assert.True(t, sink.isSpilling())
+
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"late"}, inner.messages())
+}
@@ -0,0 +1,51 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// sequence is the last sequence number assigned to a record.
+var sequence atomic.Uint64
+
+// sequenceAttr returns a "seq" attribute with the next sequence number.
+func sequenceAttr() slog.Attr {
+	return slog.Uint64("seq", sequence.Add(1))
+}
+
+// goroutineIDAttr returns a "goroutine" attribute with the ID of the calling
+// goroutine.
+func goroutineIDAttr() slog.Attr {
+	return slog.Int("goroutine", goroutineID())
+}
+
+// stampHandler is a [log/slog.Handler] that adds an attribute computed when
+// each record is handled, such as a sequence number. It must be called
+// synchronously by the logging goroutine.
+type stampHandler struct {
+	next  slog.Handler
+	stamp func() slog.Attr
+}
+
+func newStampHandler(next slog.Handler, stamp func() slog.Attr) *stampHandler {
+	return &stampHandler{next: next, stamp: stamp}
+}
+
+func (h *stampHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *stampHandler) Handle(ctx context.Context, r slog.Record) error {
+	r = r.Clone()
+	r.AddAttrs(h.stamp())
+	return h.next.Handle(ctx, r)
+}
+
+func (h *stampHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &stampHandler{next: h.next.WithAttrs(attrs), stamp: h.stamp}
+}
+
+func (h *stampHandler) WithGroup(name string) slog.Handler {
+	return &stampHandler{next: h.next.WithGroup(name), stamp: h.stamp}
+}
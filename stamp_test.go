@@ -0,0 +1,26 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithSequenceNumbers(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	l := LoggerForTest(w, WithSequenceNumbers(true))
+	first := sequence.Load() + 1
+	l.Info("One")
+	l.WithGroup("g").Info("Two", "key", "value")
+	l.Debug("Not sequenced")
+
+	assert.Equal(t, fmt.Sprintf(""+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=One seq=%d\n"+
+		"time=2026-01-02T03:04:05.000Z level=INFO msg=Two g.key=value g.seq=%d\n", first, first+1), w.String())
+}
@@ -0,0 +1,170 @@
+package slogflags
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RecordStats contains the number of records that have been dropped, by the
+// level of the record.
+type RecordStats struct {
+	// Sampled is the number of records dropped by [WithSampling].
+	Sampled map[slog.Level]uint64
+
+	// RateLimited is the number of records dropped by [WithRateLimit].
+	RateLimited map[slog.Level]uint64
+
+	// QueueFull is the number of records dropped because the queue created
+	// by [WithAsync] or a [QueuedSink] was full.
+	QueueFull map[slog.Level]uint64
+}
+
+// Total returns the total number of records that have been dropped.
+func (s RecordStats) Total() uint64 {
+	var total uint64
+	for _, m := range []map[slog.Level]uint64{s.Sampled, s.RateLimited, s.QueueFull} {
+		for _, n := range m {
+			total += n
+		}
+	}
+	return total
+}
+
+// sub returns the difference between s and an earlier snapshot.
+func (s RecordStats) sub(earlier RecordStats) RecordStats {
+	diff := func(now, then map[slog.Level]uint64) map[slog.Level]uint64 {
+		res := map[slog.Level]uint64{}
+		for level, n := range now {
+			if d := n - then[level]; d > 0 {
+				res[level] = d
+			}
+		}
+		return res
+	}
+
+	return RecordStats{
+		Sampled:     diff(s.Sampled, earlier.Sampled),
+		RateLimited: diff(s.RateLimited, earlier.RateLimited),
+		QueueFull:   diff(s.QueueFull, earlier.QueueFull),
+	}
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = RecordStats{
+		Sampled:     map[slog.Level]uint64{},
+		RateLimited: map[slog.Level]uint64{},
+		QueueFull:   map[slog.Level]uint64{},
+	}
+)
+
+// Stats returns the number of records that have been dropped by all loggers
+// created with [Logger] since the process started.
+func Stats() RecordStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	return RecordStats{
+		Sampled:     maps.Clone(stats.Sampled),
+		RateLimited: maps.Clone(stats.RateLimited),
+		QueueFull:   maps.Clone(stats.QueueFull),
+	}
+}
+
+// dropReason identifies why a record was dropped.
+type dropReason int
+
+const (
+	dropSampled dropReason = iota
+	dropRateLimited
+	dropQueueFull
+)
+
+// countDrop increments the counter for records dropped for the given reason.
+func countDrop(reason dropReason, level slog.Level) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	switch reason {
+	case dropSampled:
+		stats.Sampled[level]++
+	case dropRateLimited:
+		stats.RateLimited[level]++
+	case dropQueueFull:
+		stats.QueueFull[level]++
+	}
+}
+
+// dropSummary periodically writes a record summarising the records that have
+// been dropped since the last summary.
+type dropSummary struct {
+	handler slog.Handler
+	last    RecordStats
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func newDropSummary(handler slog.Handler, interval time.Duration) *dropSummary {
+	d := &dropSummary{
+		handler: handler,
+		last:    Stats(),
+		stop:    make(chan struct{}),
+	}
+
+	go d.run(interval)
+	return d
+}
+
+func (d *dropSummary) run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-t.C:
+			_ = d.emit()
+		}
+	}
+}
+
+// emit writes a summary if any records have been dropped since the last one.
+func (d *dropSummary) emit() error {
+	current := Stats()
+	diff := current.sub(d.last)
+	d.last = current
+
+	ctx := context.Background()
+	if diff.Total() == 0 || !d.handler.Enabled(ctx, slog.LevelWarn) {
+		return nil
+	}
+
+	group := func(name string, counts map[slog.Level]uint64) slog.Attr {
+		var attrs []slog.Attr
+		for _, level := range slices.Sorted(maps.Keys(counts)) {
+			attrs = append(attrs, slog.Uint64(level.String(), counts[level]))
+		}
+		return slog.Attr{Key: name, Value: slog.GroupValue(attrs...)}
+	}
+
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "Log records dropped", 0)
+	r.AddAttrs(
+		slog.Uint64("total", diff.Total()),
+		group("sampled", diff.Sampled),
+		group("rate_limited", diff.RateLimited),
+		group("queue_full", diff.QueueFull),
+	)
+	return d.handler.Handle(ctx, r)
+}
+
+// close stops the background goroutine.
+func (d *dropSummary) close() error {
+	d.once.Do(func() { close(d.stop) })
+	return nil
+}
@@ -0,0 +1,50 @@
+package slogflags
+
+import (
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Stats_CountsDroppedRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	before := Stats()
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w,
+		WithSampling(slog.LevelInfo, 1, 0),
+		WithRateLimit(clientKeyForTest, 1, time.Hour),
+	)
+	for range 3 {
+		l.Debug("Loop")
+		l.Info("Loop")
+		l.Warn("Request", "client", "a")
+	}
+
+	diff := Stats().sub(before)
+	assert.Equal(t, map[slog.Level]uint64{slog.LevelInfo: 2}, diff.Sampled)
+	assert.Equal(t, map[slog.Level]uint64{slog.LevelWarn: 2}, diff.RateLimited)
+	assert.Empty(t, diff.QueueFull)
+	assert.Equal(t, uint64(4), diff.Total())
+}
+
+func Test_DropSummary_LogsDroppedRecords(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(syncBuffer)
+	l := LoggerForTest(w, WithSampling(slog.LevelInfo, 1, 0), WithDropSummary(20*time.Millisecond))
+	for range 4 {
+		l.Info("Loop")
+	}
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(w.String(), "level=WARN msg=\"Log records dropped\" total=3 sampled.INFO=3\n")
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,110 @@
+package slogflags
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// stderrCloseTimeout is how long releaseStderr waits for output that was
+// written to the captured stderr before it was restored. Child processes that
+// inherited the captured stderr may keep it open after it is restored.
+const stderrCloseTimeout = time.Second
+
+// stderrCapture holds the state of the capture of the process's stderr.
+var stderrCapture struct {
+	mu       sync.Mutex
+	original *os.File // A duplicate of the original stderr, or nil if not captured.
+	reader   *os.File
+	writer   *lineWriter
+	done     chan struct{}
+}
+
+// captureStderr redirects the process's stderr into a pipe, and logs each
+// line written to it using [L] at the given level. It returns true if
+// stderr wasn't already being captured; otherwise the level is updated.
+func captureStderr(level slog.Level) (bool, error) {
+	stderrCapture.mu.Lock()
+	defer stderrCapture.mu.Unlock()
+
+	if stderrCapture.original != nil {
+		stderrCapture.writer.mu.Lock()
+		stderrCapture.writer.level = level
+		stderrCapture.writer.mu.Unlock()
+		return false, nil
+	}
+
+	original, r, err := redirectStderr()
+	if err != nil {
+		return false, err
+	}
+
+	// Make sure crash reports still reach the original stderr, as the process
+	// may exit before they can be read back from the pipe and logged.
+	_ = debug.SetCrashOutput(original, debug.CrashOptions{})
+
+	w := &lineWriter{logger: L().With("stream", "stderr"), level: level}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(w, r)
+		w.flush()
+	}()
+
+	stderrCapture.original = original
+	stderrCapture.reader = r
+	stderrCapture.writer = w
+	stderrCapture.done = done
+	return true, nil
+}
+
+// releaseStderr restores the process's original stderr, and logs any
+// remaining output that was written to the captured stderr.
+func releaseStderr() error {
+	stderrCapture.mu.Lock()
+	defer stderrCapture.mu.Unlock()
+
+	if stderrCapture.original == nil {
+		return nil
+	}
+
+	_ = debug.SetCrashOutput(nil, debug.CrashOptions{})
+	err := restoreStderr(stderrCapture.original)
+	if err == nil {
+		_ = stderrCapture.reader.SetReadDeadline(time.Now().Add(stderrCloseTimeout))
+		<-stderrCapture.done
+	}
+
+	err = errors.Join(err, stderrCapture.reader.Close(), stderrCapture.original.Close())
+	stderrCapture.original = nil
+	stderrCapture.reader = nil
+	stderrCapture.writer = nil
+	stderrCapture.done = nil
+	return err
+}
+
+// stderrFile returns a file that writes to the process's original stderr,
+// bypassing any capture, so records written to stderr aren't captured and
+// logged again.
+func stderrFile() *os.File {
+	stderrCapture.mu.Lock()
+	defer stderrCapture.mu.Unlock()
+
+	if stderrCapture.original != nil {
+		return stderrCapture.original
+	}
+	return os.Stderr
+}
+
+// uncaptured returns a writer that writes to the original stderr if w is
+// [os.Stderr], or w otherwise.
+func uncaptured(w io.Writer) io.Writer {
+	if f, ok := w.(*os.File); ok && f == os.Stderr {
+		return stderrFile()
+	}
+	return w
+}
@@ -0,0 +1,9 @@
+package slogflags
+
+import "syscall"
+
+// dup2 makes newfd a copy of oldfd. Dup2 isn't available on all Linux
+// architectures, so Dup3 is used instead.
+func dup2(oldfd, newfd int) error {
+	return syscall.Dup3(oldfd, newfd, 0)
+}
@@ -0,0 +1,18 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd
+
+package slogflags
+
+import (
+	"errors"
+	"os"
+)
+
+// redirectStderr isn't supported on this platform.
+func redirectStderr() (original, r *os.File, err error) {
+	return nil, nil, errors.ErrUnsupported
+}
+
+// restoreStderr isn't supported on this platform.
+func restoreStderr(*os.File) error {
+	return errors.ErrUnsupported
+}
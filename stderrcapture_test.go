@@ -0,0 +1,39 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd
+
+package slogflags
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithStderrCapture(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+	t.Cleanup(func() { _ = releaseStderr() })
+
+	w := &syncBuffer{}
+	_ = LoggerForTest(w, WithStderrCapture(slog.LevelWarn))
+	assert.NotEqual(t, os.Stderr, stderrFile(), "records written to stderr should bypass the capture")
+
+	_, _ = fmt.Fprintln(os.Stderr, "written to os.Stderr")
+	println("debugging")
+	_, _ = fmt.Fprint(os.Stderr, "no trailing newline")
+	require.NoError(t, releaseStderr())
+
+	assert.Equal(t, ""+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"written to os.Stderr\" stream=stderr\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=debugging stream=stderr\n"+
+		"time=2026-01-02T03:04:05.000Z level=WARN msg=\"no trailing newline\" stream=stderr\n", w.String())
+
+	// Stderr has been restored, so nothing more is logged.
+	_, _ = fmt.Fprintln(os.Stderr, "after release")
+	assert.NotContains(t, w.String(), "after release")
+	assert.Equal(t, os.Stderr, stderrFile())
+}
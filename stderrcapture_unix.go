@@ -0,0 +1,43 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd
+
+package slogflags
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectStderr replaces the process's stderr with the write end of a
+// pipe, returning a duplicate of the original stderr and the read end of the
+// pipe.
+func redirectStderr() (original, r *os.File, err error) {
+	syscall.ForkLock.RLock()
+	fd, err := syscall.Dup(2)
+	if err == nil {
+		syscall.CloseOnExec(fd)
+	}
+	syscall.ForkLock.RUnlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	original = os.NewFile(uintptr(fd), "/dev/stderr")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		_ = original.Close()
+		return nil, nil, err
+	}
+	defer w.Close()
+
+	if err := dup2(int(w.Fd()), 2); err != nil {
+		_ = original.Close()
+		_ = r.Close()
+		return nil, nil, err
+	}
+	return original, r, nil
+}
+
+// restoreStderr makes the process's stderr refer to the original again.
+func restoreStderr(original *os.File) error {
+	return dup2(int(original.Fd()), 2)
+}
@@ -0,0 +1,37 @@
+package slogflags
+
+import (
+	"log"
+	"log/slog"
+)
+
+// NewStdLoggerAt returns a [log.Logger] that logs each line written to it as a
+// record at the given level, using the logger created by the most recent call
+// to [Logger] (see [L]). The prefix, if any, is added to the start of each
+// message.
+//
+// This allows components that require a [log.Logger] to each be bridged at
+// an appropriate level, unlike [WithOldLogLevel] which sets a single level
+// for the global logger in the [log] package. For example:
+//
+//	server := &http.Server{ErrorLog: slogflags.NewStdLoggerAt(slog.LevelWarn, "http: ")}
+func NewStdLoggerAt(level slog.Level, prefix string) *log.Logger {
+	logger := slog.NewLogLogger(L().Handler(), level)
+	logger.SetPrefix(prefix)
+	return logger
+}
+
+// NewLogBridge returns a [log.Logger] for a legacy subsystem, which logs each
+// line written to it as a record at the given level with a "subsystem"
+// attribute containing the name. Like [NewStdLoggerAt], records are logged
+// using the logger created by the most recent call to [Logger] (see [L]).
+//
+// Applications with several components that use the [log] package can create
+// a bridge for each, so that their output can be told apart and logged at
+// appropriate levels:
+//
+//	cache := legacycache.New(slogflags.NewLogBridge("cache", slog.LevelDebug))
+//	queue := legacyqueue.New(slogflags.NewLogBridge("queue", slog.LevelInfo))
+func NewLogBridge(name string, level slog.Level) *log.Logger {
+	return slog.NewLogLogger(L().With("subsystem", name).Handler(), level)
+}
@@ -0,0 +1,38 @@
+package slogflags
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewStdLoggerAt(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	LoggerForTest(w)
+
+	NewStdLoggerAt(slog.LevelWarn, "http: ").Printf("TLS handshake error from %s", "192.0.2.1")
+	NewStdLoggerAt(slog.LevelDebug, "db: ").Print("Connection opened")
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=\"http: TLS handshake error from 192.0.2.1\"\n", w.String())
+}
+
+func Test_NewLogBridge(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	w := new(bytes.Buffer)
+	cache := NewLogBridge("cache", slog.LevelDebug)
+	queue := NewLogBridge("queue", slog.LevelWarn)
+	LoggerForTest(w)
+
+	cache.Print("Evicted 10 entries")
+	queue.Printf("Backlog of %d messages", 500)
+
+	assert.Equal(t, "time=2026-01-02T03:04:05.000Z level=WARN msg=\"Backlog of 500 messages\" subsystem=queue\n", w.String())
+}
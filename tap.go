@@ -0,0 +1,59 @@
+package slogflags
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+)
+
+// tapHandler is a [log/slog.Handler] that passes fully resolved records at or
+// above a level to a func, as well as passing them to the next handler. The
+// func is called synchronously.
+type tapHandler struct {
+	next  slog.Handler
+	level slog.Leveler
+	fn    func(r slog.Record) error
+	goas  []groupOrAttrs
+}
+
+func newTapHandler(next slog.Handler, level slog.Leveler, fn func(r slog.Record) error) *tapHandler {
+	return &tapHandler{next: next, level: level, fn: fn}
+}
+
+func (h *tapHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() || h.next.Enabled(ctx, level)
+}
+
+func (h *tapHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	if r.Level >= h.level.Level() {
+		errs = append(errs, h.fn(resolveRecord(r, h.goas)))
+	}
+
+	if h.next.Enabled(ctx, r.Level) {
+		errs = append(errs, h.next.Handle(ctx, r))
+	}
+	return errors.Join(errs...)
+}
+
+func (h *tapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs), groupOrAttrs{attrs: attrs})
+}
+
+func (h *tapHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name), groupOrAttrs{group: name})
+}
+
+func (h *tapHandler) with(next slog.Handler, goa groupOrAttrs) *tapHandler {
+	h2 := *h
+	h2.next = next
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
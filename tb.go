@@ -0,0 +1,57 @@
+package slogflags
+
+import (
+	"bytes"
+	"sync"
+)
+
+// TB is the subset of [testing.TB] used by [WithTB]. It's implemented by
+// [testing.T], [testing.B] and [testing.F].
+type TB interface {
+	Cleanup(func())
+	Helper()
+	Logf(format string, args ...any)
+}
+
+// WithTB writes log output to a test's log using t.Logf, instead of the
+// writer selected by [WithWriter]. Output is attached to the test that is
+// running, and is only shown if it fails or tests are run with -v. Records
+// are still formatted and filtered according to the flags.
+//
+// Records logged after the test has finished (for example by goroutines
+// that outlive it) are discarded, as [testing.T] doesn't allow logging
+// at that point.
+func WithTB(t TB) Option {
+	return func(c *config) {
+		c.writer = newTBWriter(t)
+	}
+}
+
+// tbWriter is an [io.Writer] that writes each line to a test's log.
+type tbWriter struct {
+	t TB
+
+	mu   sync.Mutex
+	done bool
+}
+
+func newTBWriter(t TB) *tbWriter {
+	w := &tbWriter{t: t}
+	t.Cleanup(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.done = true
+	})
+	return w
+}
+
+func (w *tbWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.done {
+		w.t.Helper()
+		w.t.Logf("%s", bytes.TrimSuffix(p, []byte("\n")))
+	}
+	return len(p), nil
+}
@@ -0,0 +1,54 @@
+package slogflags
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB records the lines logged by a test.
+type fakeTB struct {
+	lines    []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Logf(format string, args ...any) {
+	f.lines = append(f.lines, fmt.Sprintf(format, args...))
+}
+
+var _ TB = (testing.TB)(nil)
+
+func Test_WithTB(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	tb := &fakeTB{}
+	l := Logger(WithTB(tb))
+	l.Debug("Hidden")
+	l.Info("Shown", "key", "value")
+	assert.Len(t, tb.lines, 1)
+	assert.Regexp(t, `^time=\S+ level=INFO msg=Shown key=value$`, tb.lines[0])
+
+	for _, fn := range tb.cleanups {
+		fn()
+	}
+	l.Info("After the test")
+	assert.Len(t, tb.lines, 1)
+}
+
+func Test_WithTB_RealTest(t *testing.T) {
+	_ = flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	assert.NotPanics(t, func() {
+		Logger(WithTB(t)).Info("Logged to the test")
+	})
+}
@@ -0,0 +1,102 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+)
+
+// TraceFormat determines the attributes used to correlate records with
+// traces.
+type TraceFormat int
+
+const (
+	// TraceFormatOTel adds "trace_id", "span_id" and "trace_flags"
+	// attributes, as hex strings, following the OpenTelemetry conventions.
+	TraceFormatOTel TraceFormat = iota
+
+	// TraceFormatDatadog adds a "dd" group containing "trace_id" and
+	// "span_id" attributes, as decimal strings of the lower 64 bits of each
+	// ID, as expected by Datadog.
+	TraceFormatDatadog
+
+	// TraceFormatGCP adds the "logging.googleapis.com/trace",
+	// "logging.googleapis.com/spanId" and
+	// "logging.googleapis.com/trace_sampled" attributes recognised by Google
+	// Cloud Logging.
+	TraceFormatGCP
+)
+
+// SpanContext identifies a span within a trace.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+}
+
+// TraceConfig configures trace correlation using [WithTraceCorrelation].
+type TraceConfig struct {
+	// SpanContext returns the span that is active in the context, if any.
+	// Defaults to [SpanContextFromTraceParent].
+	//
+	// slogflags does not depend on the OpenTelemetry SDK; instead this func
+	// should use your tracing library to find the span. For example, using
+	// go.opentelemetry.io/otel/trace:
+	//
+	//	func(ctx context.Context) (slogflags.SpanContext, bool) {
+	//		sc := trace.SpanContextFromContext(ctx)
+	//		return slogflags.SpanContext{
+	//			TraceID:    sc.TraceID(),
+	//			SpanID:     sc.SpanID(),
+	//			TraceFlags: byte(sc.TraceFlags()),
+	//		}, sc.IsValid()
+	//	}
+	SpanContext func(ctx context.Context) (SpanContext, bool)
+
+	// Format determines the attributes that are added. Defaults to
+	// [TraceFormatOTel].
+	Format TraceFormat
+
+	// GCPProjectID is the ID of the Google Cloud project traces are stored
+	// in. It is required if Format is [TraceFormatGCP].
+	GCPProjectID string
+}
+
+// attrs returns the attributes identifying the span active in ctx.
+func (c TraceConfig) attrs(ctx context.Context) []slog.Attr {
+	spanContext := c.SpanContext
+	if spanContext == nil {
+		spanContext = SpanContextFromTraceParent
+	}
+
+	sc, ok := spanContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	traceID := hex.EncodeToString(sc.TraceID[:])
+	spanID := hex.EncodeToString(sc.SpanID[:])
+	sampled := sc.TraceFlags&0x01 != 0
+
+	switch c.Format {
+	case TraceFormatDatadog:
+		return []slog.Attr{slog.Group("dd",
+			slog.String("trace_id", strconv.FormatUint(binary.BigEndian.Uint64(sc.TraceID[8:]), 10)),
+			slog.String("span_id", strconv.FormatUint(binary.BigEndian.Uint64(sc.SpanID[:]), 10)),
+		)}
+	case TraceFormatGCP:
+		return []slog.Attr{
+			slog.String("logging.googleapis.com/trace", "projects/"+c.GCPProjectID+"/traces/"+traceID),
+			slog.String("logging.googleapis.com/spanId", spanID),
+			slog.Bool("logging.googleapis.com/trace_sampled", sampled),
+		}
+	default:
+		return []slog.Attr{
+			slog.String("trace_id", traceID),
+			slog.String("span_id", spanID),
+			slog.String("trace_flags", hex.EncodeToString([]byte{sc.TraceFlags})),
+		}
+	}
+}
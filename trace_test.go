@@ -0,0 +1,63 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+func testSpanContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanKey{}).(SpanContext)
+	return sc, ok
+}
+
+func Test_WithTraceCorrelation(t *testing.T) {
+	_ = flag.Set("log.format", "json")
+	defer flag.Set("log.format", "")
+	_ = flag.Set("log.level", "")
+
+	ctx := context.WithValue(context.Background(), spanKey{}, SpanContext{
+		TraceID:    [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: 0x01,
+	})
+
+	tests := []struct {
+		name   string
+		config TraceConfig
+		want   string
+	}{
+		{
+			name:   "otel",
+			config: TraceConfig{SpanContext: testSpanContext},
+			want:   `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01"}`,
+		},
+		{
+			name:   "datadog",
+			config: TraceConfig{SpanContext: testSpanContext, Format: TraceFormatDatadog},
+			want:   `"dd":{"trace_id":"11803532876627986230","span_id":"67667974448284343"}}`,
+		},
+		{
+			name:   "gcp",
+			config: TraceConfig{SpanContext: testSpanContext, Format: TraceFormatGCP, GCPProjectID: "my-project"},
+			want:   `"logging.googleapis.com/trace":"projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736","logging.googleapis.com/spanId":"00f067aa0ba902b7","logging.googleapis.com/trace_sampled":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			l := LoggerForTest(w, WithTraceCorrelation(tt.config))
+			l.InfoContext(ctx, "Traced")
+			l.Info("Untraced")
+
+			assert.Equal(t, `{"time":"2026-01-02T03:04:05Z","level":"INFO","msg":"Traced",`+tt.want+"\n"+
+				`{"time":"2026-01-02T03:04:05Z","level":"INFO","msg":"Untraced"}`+"\n", w.String())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package slogflags
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+)
+
+// traceParentKey is the context key used to store a SpanContext parsed from a
+// traceparent header.
+type traceParentKey struct{}
+
+// ContextWithTraceParent returns a copy of ctx containing the trace and span
+// IDs from a W3C traceparent header (e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"). If the header
+// is invalid, ctx is returned unchanged.
+//
+// This allows [WithTraceCorrelation] to be used by services that propagate
+// trace headers but don't use a tracing library: if [TraceConfig.SpanContext]
+// is nil, IDs are read from contexts returned by this func.
+func ContextWithTraceParent(ctx context.Context, header string) context.Context {
+	sc, err := parseTraceParent(header)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, traceParentKey{}, sc)
+}
+
+// SpanContextFromTraceParent returns the span stored in ctx by
+// [ContextWithTraceParent], if any.
+func SpanContextFromTraceParent(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(traceParentKey{}).(SpanContext)
+	return sc, ok
+}
+
+// parseTraceParent parses a traceparent header as defined by the W3C Trace
+// Context specification.
+func parseTraceParent(header string) (SpanContext, error) {
+	var sc SpanContext
+
+	if len(header) < 55 || header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return sc, errors.New("traceparent: malformed header")
+	}
+
+	version, err := decodeLowerHex(header[:2])
+	if err != nil || version[0] == 0xff {
+		return sc, errors.New("traceparent: invalid version")
+	}
+	// Future versions may append fields, but version 00 must not.
+	if (version[0] == 0 && len(header) != 55) || (len(header) > 55 && header[55] != '-') {
+		return sc, errors.New("traceparent: malformed header")
+	}
+
+	traceID, err := decodeLowerHex(header[3:35])
+	if err != nil || [16]byte(traceID) == [16]byte{} {
+		return sc, errors.New("traceparent: invalid trace ID")
+	}
+	spanID, err := decodeLowerHex(header[36:52])
+	if err != nil || [8]byte(spanID) == [8]byte{} {
+		return sc, errors.New("traceparent: invalid span ID")
+	}
+	flags, err := decodeLowerHex(header[53:55])
+	if err != nil {
+		return sc, errors.New("traceparent: invalid flags")
+	}
+
+	sc.TraceID = [16]byte(traceID)
+	sc.SpanID = [8]byte(spanID)
+	sc.TraceFlags = flags[0]
+	return sc, nil
+}
+
+// decodeLowerHex decodes a hex string, which must only use lowercase letters.
+func decodeLowerHex(s string) ([]byte, error) {
+	for i := range len(s) {
+		if s[i] >= 'A' && s[i] <= 'F' {
+			return nil, errors.New("uppercase hex")
+		}
+	}
+	return hex.DecodeString(s)
+}
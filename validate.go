@@ -0,0 +1,178 @@
+package slogflags
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ValidateConfig configures [Validate].
+type ValidateConfig struct {
+	// Options are the options that will be passed to [Logger], such as
+	// [WithCustomLevels], which affect how flags are interpreted.
+	Options []Option
+
+	// CheckRemote causes any remote sinks specified by the `log.output` and
+	// `audit.output` flags to be created and closed again, checking that
+	// they're reachable. Otherwise only their URLs are checked.
+	CheckRemote bool
+}
+
+// Validate checks the logging configuration given by the parsed flags and
+// options without creating a logger, so that mistakes can be caught before
+// an application is deployed. It checks that levels and formats are valid,
+// that output files can be written to, and optionally that remote sinks are
+// reachable. All problems found are returned, joined using [errors.Join].
+//
+// [flag.Parse] must be called prior to calling this method. Validate doesn't
+// leave any files behind, but may connect to remote sinks.
+func Validate(config ValidateConfig) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return validate(config)
+}
+
+// validate implements [Validate]. configMu must be held.
+func validate(config ValidateConfig) error {
+	c := newConfig(config.Options)
+	var errs []error
+
+	if *logLevel != "" {
+		if _, err := ParseLevel(*logLevel, c.customLevels); err != nil {
+			errs = append(errs, fmt.Errorf("log.level: %w", err))
+		}
+	}
+
+	if *logSampleLevel != "" {
+		if _, err := ParseLevel(*logSampleLevel, c.customLevels); err != nil {
+			errs = append(errs, fmt.Errorf("log.sample-level: %w", err))
+		}
+	}
+
+	switch *logFormat {
+	case "", "text", "json", "fasttext", "expanded":
+	default:
+		errs = append(errs, fmt.Errorf("log.format: unknown format %q", *logFormat))
+	}
+
+	for _, level := range slices.Sorted(maps.Keys(c.levelFormats)) {
+		switch format := c.levelFormats[level]; format {
+		case "text", "json", "fasttext", "expanded":
+		default:
+			errs = append(errs, fmt.Errorf("level format for %s: unknown format %q", level, format))
+		}
+	}
+
+	if *logTimezone != "" {
+		if _, err := time.LoadLocation(*logTimezone); err != nil {
+			errs = append(errs, fmt.Errorf("log.timezone: %w", err))
+		}
+	}
+
+	switch AccessLogFormat(*logAccessFormat) {
+	case "", AccessLogStructured, AccessLogCommon, AccessLogCombined:
+	default:
+		errs = append(errs, fmt.Errorf("log.access-format: unknown format %q", *logAccessFormat))
+	}
+
+	if *logBufferSize < 0 {
+		errs = append(errs, errors.New("log.buffer-size: must not be negative"))
+	}
+
+	if *logBackpressure != "" {
+		backpressure, err := parseBackpressure(*logBackpressure)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("log.backpressure: %w", err))
+		}
+		if *logQueueSize <= 0 {
+			errs = append(errs, errors.New("log.queue-size: must be positive"))
+		}
+		if backpressure == BackpressureSpill {
+			if err := checkWritableDir(*logSpillDir); err != nil {
+				errs = append(errs, fmt.Errorf("log.spill-dir: %w", err))
+			}
+		}
+	}
+
+	if err := validateOutput(*logOutput, config.CheckRemote); err != nil {
+		errs = append(errs, fmt.Errorf("log.output: %w", err))
+	}
+
+	if err := validateOutput(*auditOutput, config.CheckRemote); err != nil {
+		errs = append(errs, fmt.Errorf("audit.output: %w", err))
+	}
+
+	if c.debugFile != "" {
+		if err := checkWritableFile(c.debugFile); err != nil {
+			errs = append(errs, fmt.Errorf("debug file: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateOutput checks the value of an output flag, which is resolved in
+// the same way as `log.output`.
+func validateOutput(requested string, checkRemote bool) error {
+	switch requested {
+	case "", "stdout", "stderr":
+		return nil
+	}
+
+	u, err := url.Parse(requested)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "":
+		return checkWritableFile(requested)
+	case "file":
+		return checkWritableFile(u.Path)
+	}
+
+	fn, ok := outputSinks[u.Scheme]
+	if !ok {
+		return fmt.Errorf("unsupported output %q", requested)
+	}
+	if !checkRemote {
+		return nil
+	}
+
+	sink, err := fn(u)
+	if err != nil {
+		return err
+	}
+	return sink.Close()
+}
+
+// checkWritableFile checks that the file at path can be appended to, or
+// created if it doesn't exist, without creating it.
+func checkWritableFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err == nil {
+		return f.Close()
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return checkWritableDir(filepath.Dir(path))
+}
+
+// checkWritableDir checks that files can be created in the directory at
+// path, by creating and removing a temporary file.
+func checkWritableDir(path string) error {
+	if path == "" {
+		return errors.New("no directory specified")
+	}
+	f, err := os.CreateTemp(path, ".slogflags-validate-*")
+	if err != nil {
+		return err
+	}
+	return errors.Join(f.Close(), os.Remove(f.Name()))
+}
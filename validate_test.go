@@ -0,0 +1,81 @@
+package slogflags
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setFlags sets flags for the duration of a test.
+func setFlags(t *testing.T, values map[string]string) {
+	for name, value := range values {
+		old := flag.Lookup(name).Value.String()
+		require.NoError(t, flag.Set(name, value))
+		t.Cleanup(func() { _ = flag.Set(name, old) })
+	}
+}
+
+func Test_Validate_Valid(t *testing.T) {
+	dir := t.TempDir()
+	setFlags(t, map[string]string{
+		"log.level":        "shrug",
+		"log.format":       "json",
+		"log.output":       filepath.Join(dir, "app.log"),
+		"log.backpressure": "spill",
+		"log.spill-dir":    dir,
+	})
+
+	err := Validate(ValidateConfig{Options: []Option{WithCustomLevels(map[string]slog.Level{"shrug": 2})}})
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func Test_Validate_ReportsAllErrors(t *testing.T) {
+	setFlags(t, map[string]string{
+		"log.level":         "loud",
+		"log.sample-level":  "info+x",
+		"log.format":        "yaml",
+		"log.access-format": "apache",
+		"log.output":        filepath.Join(t.TempDir(), "missing", "app.log"),
+		"audit.output":      "bogus://example",
+		"log.backpressure":  "panic",
+		"log.timezone":      "Nowhere/Special",
+	})
+
+	err := Validate(ValidateConfig{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `log.level: unknown log level "loud"`)
+	assert.ErrorContains(t, err, `log.sample-level: invalid offset in log level "info+x"`)
+	assert.ErrorContains(t, err, `log.format: unknown format "yaml"`)
+	assert.ErrorContains(t, err, `log.access-format: unknown format "apache"`)
+	assert.ErrorContains(t, err, `log.backpressure: unknown backpressure strategy "panic"`)
+	assert.ErrorContains(t, err, "log.timezone: ")
+	assert.ErrorContains(t, err, "log.output: ")
+	assert.ErrorContains(t, err, `audit.output: unsupported output "bogus://example"`)
+}
+
+func Test_Validate_SpillDirRequired(t *testing.T) {
+	setFlags(t, map[string]string{"log.backpressure": "spill"})
+
+	assert.EqualError(t, Validate(ValidateConfig{}), "log.spill-dir: no directory specified")
+}
+
+func Test_Validate_ExistingReadOnlyFile(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only files")
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, nil, 0o444))
+	setFlags(t, map[string]string{"log.output": path})
+
+	assert.ErrorContains(t, Validate(ValidateConfig{}), "log.output: ")
+}
@@ -0,0 +1,136 @@
+package slogflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WebhookConfig configures a [WebhookSink].
+type WebhookConfig struct {
+	// URL is the endpoint that batches of records are POSTed to.
+	URL string
+
+	// Headers are additional headers sent with each request, such as those
+	// used for authentication.
+	Headers http.Header
+
+	// BatchSize is the maximum number of records that will be buffered before
+	// they are sent. Defaults to 100.
+	BatchSize int
+
+	// FlushInterval is the maximum amount of time records will be buffered
+	// before they are sent. Defaults to 5 seconds.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a request will be retried if it fails
+	// due to a network error or a retryable status (429 or 5xx). Defaults
+	// to 3 if zero; a negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the time to wait before the first retry. It doubles
+	// after each subsequent attempt. Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// [net/http.DefaultClient].
+	Client *http.Client
+}
+
+// WebhookSink is a [Sink] that POSTs batches of records to an HTTP endpoint as
+// newline-delimited JSON. Buffered records are only guaranteed to be sent once
+// the sink has been closed.
+type WebhookSink struct {
+	config  WebhookConfig
+	batcher *batcher[[]byte]
+}
+
+// NewWebhookSink creates a new [WebhookSink] with the given config.
+func NewWebhookSink(config WebhookConfig) (*WebhookSink, error) {
+	if config.URL == "" {
+		return nil, errors.New("webhook: no URL specified")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+
+	s := &WebhookSink{config: config}
+	s.batcher = newBatcher(config.BatchSize, config.FlushInterval, s.send)
+	return s, nil
+}
+
+func (s *WebhookSink) Write(ctx context.Context, r slog.Record) error {
+	line, err := json.Marshal(recordJSON(ctx, r))
+	if err != nil {
+		return fmt.Errorf("webhook: unable to encode record: %w", err)
+	}
+	return s.batcher.add(line)
+}
+
+func (s *WebhookSink) Flush() error {
+	return s.batcher.sync()
+}
+
+func (s *WebhookSink) Close() error {
+	return s.batcher.close()
+}
+
+func (s *WebhookSink) send(lines [][]byte) error {
+	body := bytes.Join(lines, []byte{'\n'})
+	body = append(body, '\n')
+
+	err := withRetries(s.config.MaxRetries, s.config.RetryBackoff, func() error {
+		return s.post(body)
+	})
+	if err != nil {
+		return fmt.Errorf("webhook: unable to send %d records: %w", len(lines), err)
+	}
+	return nil
+}
+
+func (s *WebhookSink) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.config.Headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 300 {
+		err = fmt.Errorf("request returned status %d", res.StatusCode)
+		if retryableStatus(res.StatusCode) {
+			return retryable(err)
+		}
+		return err
+	}
+	return nil
+}
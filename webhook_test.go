@@ -0,0 +1,170 @@
+package slogflags
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebhook struct {
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	headers  http.Header
+	records  []map[string]any
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := http.StatusOK
+	if f.requests < len(f.statuses) {
+		status = f.statuses[f.requests]
+	}
+	f.requests++
+	f.headers = r.Header
+
+	if status == http.StatusOK {
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var record map[string]any
+			_ = json.Unmarshal(scanner.Bytes(), &record)
+			f.records = append(f.records, record)
+		}
+	}
+	w.WriteHeader(status)
+}
+
+func Test_WebhookSink_PostsNDJSON(t *testing.T) {
+	hook := &fakeWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{
+		URL:     server.URL,
+		Headers: http.Header{"Authorization": []string{"Bearer abc"}},
+	})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One", "arg1", "arg2")
+	l.Warn("Two")
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, 1, hook.requests)
+	assert.Equal(t, "Bearer abc", hook.headers.Get("Authorization"))
+	assert.Equal(t, "application/x-ndjson", hook.headers.Get("Content-Type"))
+	require.Len(t, hook.records, 2)
+	assert.Equal(t, "One", hook.records[0]["msg"])
+	assert.Equal(t, "arg2", hook.records[0]["arg1"])
+	assert.Equal(t, "WARN", hook.records[1]["level"])
+}
+
+func Test_WebhookSink_UsesCustomLevelNames(t *testing.T) {
+	hook := &fakeWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	l := LoggerForTest(io.Discard, WithSink(sink), WithCustomLevels(map[string]slog.Level{"notice": slog.LevelInfo + 2}))
+	l.Log(t.Context(), slog.LevelInfo+2, "One")
+	l.Log(t.Context(), LevelFatal, "Two")
+	l.Info("Three")
+	require.NoError(t, sink.Close())
+
+	require.Len(t, hook.records, 3)
+	assert.Equal(t, "NOTICE", hook.records[0]["level"])
+	assert.Equal(t, "FATAL", hook.records[1]["level"])
+	assert.Equal(t, "INFO", hook.records[2]["level"])
+}
+
+func Test_WebhookSink_EncodesNonFiniteFloats(t *testing.T) {
+	hook := &fakeWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("Test", "nan", math.NaN(), "inf", math.Inf(1), "ratio", 0.5)
+	require.NoError(t, sink.Close())
+
+	require.Len(t, hook.records, 1)
+	assert.Equal(t, "NaN", hook.records[0]["nan"])
+	assert.Equal(t, "+Inf", hook.records[0]["inf"])
+	assert.Equal(t, 0.5, hook.records[0]["ratio"])
+}
+
+func Test_WebhookSink_RetriesFailures(t *testing.T) {
+	hook := &fakeWebhook{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{URL: server.URL, RetryBackoff: time.Millisecond})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, 3, hook.requests)
+	assert.Len(t, hook.records, 1)
+}
+
+func Test_WebhookSink_GivesUp(t *testing.T) {
+	hook := &fakeWebhook{statuses: []int{500, 500, 500}}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{URL: server.URL, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+
+	assert.ErrorContains(t, sink.Close(), "giving up after 3 attempts: request returned status 500")
+}
+
+func Test_WebhookSink_NegativeMaxRetriesDisablesRetries(t *testing.T) {
+	hook := &fakeWebhook{statuses: []int{500}}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{URL: server.URL, MaxRetries: -1})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+
+	assert.ErrorContains(t, sink.Close(), "giving up after 1 attempts: request returned status 500")
+	assert.Equal(t, 1, hook.requests)
+}
+
+func Test_WebhookSink_DoesNotRetryClientErrors(t *testing.T) {
+	hook := &fakeWebhook{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{URL: server.URL, RetryBackoff: time.Millisecond})
+	require.NoError(t, err)
+
+	l := slog.New(newSinkHandler(sink, slog.LevelInfo))
+	l.Info("One")
+
+	assert.ErrorContains(t, sink.Close(), "request returned status 400")
+	assert.Equal(t, 1, hook.requests)
+}